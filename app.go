@@ -9,6 +9,7 @@ import (
 	"log"
 	"net/http"
 	"time"
+	_ "time/tzdata" // image alpine tidak membawa tzdata; dibutuhkan untuk zona waktu company
 
 	"github.com/joho/godotenv"
 )
@@ -95,29 +96,49 @@ func main() {
 	recipeRepo := repositories.NewRecipeRepository(dbConn)
 	cashierShiftRepo := repositories.NewCashierShiftRepository(dbConn)
 	purchaseRepo := repositories.NewPurchaseRepository(dbConn)
+	payableRepo := repositories.NewPayableRepository(dbConn)
 	stockRepo := repositories.NewStockRepository(dbConn)
 	stockMovementRepo := repositories.NewStockMovementRepository(dbConn)
+	stockThresholdRepo := repositories.NewStockThresholdRepository(dbConn)
+	saleRepo := repositories.NewSaleRepository(dbConn)
+	promoCodeRepo := repositories.NewPromoCodeRepository(dbConn)
+	outletProductRepo := repositories.NewOutletProductRepository(dbConn)
+	stockOpnameRepo := repositories.NewStockOpnameRepository(dbConn)
+	stockTransferRepo := repositories.NewStockTransferRepository(dbConn)
 	emailRepo := repositories.NewEmailRepository()
+	passwordResetRepo := repositories.NewPasswordResetRepository(dbConn)
+	sessionRepo := repositories.NewSessionRepository(dbConn)
+	posDeviceRepo := repositories.NewPosDeviceRepository(dbConn)
 	// Setup Services
 	todoService := services.NewTodoService(todoRepo)
 	categoryService := services.NewCategoryService(categoryRepo)
 	addOnService := services.NewAddOnService(addOnRepo)
 	systemService := services.NewSystemService(systemRepo)
-	authService := services.NewAuthService(userRepo, companyRepo, outletRepo, emailRepo, dbConn)
+	authService := services.NewAuthService(userRepo, companyRepo, outletRepo, emailRepo, passwordResetRepo, sessionRepo, posDeviceRepo, dbConn)
+	userService := services.NewUserService(userRepo, sessionRepo, roleRepo, companyRepo)
+	posDeviceService := services.NewPosDeviceService(posDeviceRepo, outletRepo, sessionRepo)
 	orderTypeService := services.NewOrderTypeService(orderTypeRepo)
 	outletService := services.NewOutletService(outletRepo)
-	productService := services.NewProductService(productRepo, storageRepo)
+	productService := services.NewProductService(productRepo, storageRepo, orderTypeRepo, outletRepo, companyRepo)
 	customerService := services.NewCustomerService(customerRepo)
-	discountService := services.NewDiscountService(discountRepo)
-	taxService := services.NewTaxService(taxRepo)
+	promoCodeService := services.NewPromoCodeService(promoCodeRepo, discountRepo, companyRepo, saleRepo, customerRepo)
+	discountService := services.NewDiscountService(discountRepo, companyRepo, promoCodeService)
+	taxService := services.NewTaxService(taxRepo, outletRepo, productRepo, categoryRepo)
 	roleService := services.NewRoleService(roleRepo)
-	unitService := services.NewUnitService(unitRepo)
+	unitConverter := services.NewUnitConverter(unitRepo)
+	unitService := services.NewUnitService(unitRepo, unitConverter)
 	supplierService := services.NewSupplierService(supplierRepo)
-	recipeService := services.NewRecipeService(recipeRepo)
-	cashierShiftService := services.NewCashierShiftService(cashierShiftRepo)
-	purchaseService := services.NewPurchaseService(purchaseRepo)
+	recipeService := services.NewRecipeService(recipeRepo, productRepo, unitConverter)
+	cashierShiftService := services.NewCashierShiftService(cashierShiftRepo, companyRepo)
+	purchaseService := services.NewPurchaseService(purchaseRepo, taxRepo, productRepo, supplierRepo, outletRepo, companyRepo, unitConverter)
+	payableService := services.NewPayableService(payableRepo, purchaseRepo)
 	stockService := services.NewStockService(stockRepo)
-	stockMovementService := services.NewStockMovementService(stockMovementRepo)
+	stockMovementService := services.NewStockMovementService(stockMovementRepo, companyRepo)
+	stockThresholdService := services.NewStockThresholdService(stockThresholdRepo, productRepo, outletRepo, supplierRepo)
+	saleService := services.NewSaleService(saleRepo, taxRepo, productRepo, orderTypeRepo, addOnRepo, outletProductRepo, recipeRepo, outletRepo, customerRepo, unitConverter)
+	outletProductService := services.NewOutletProductService(outletProductRepo, outletRepo, productRepo)
+	stockOpnameService := services.NewStockOpnameService(stockOpnameRepo, outletRepo, productRepo, unitConverter)
+	stockTransferService := services.NewStockTransferService(stockTransferRepo, outletRepo, productRepo, unitConverter)
 
 	// Setup Handlers
 	todoHandler := handlers.NewTodoHandler(todoService)
@@ -125,6 +146,8 @@ func main() {
 	addOnHandler := handlers.NewAddOnHandler(addOnService)
 	systemHandler := handlers.NewSystemHandler(systemService)
 	authHandler := handlers.NewAuthHandler(authService)
+	userHandler := handlers.NewUserHandler(userService)
+	posDeviceHandler := handlers.NewPosDeviceHandler(posDeviceService)
 	orderTypeHandler := handlers.NewOrderTypeHandler(orderTypeService)
 	outletHandler := handlers.NewOutletHandler(outletService)
 	productHandler := handlers.NewProductHandler(productService)
@@ -137,29 +160,49 @@ func main() {
 	recipeHandler := handlers.NewRecipeHandler(recipeService)
 	cashierShiftHandler := handlers.NewCashierShiftHandler(cashierShiftService)
 	purchaseHandler := handlers.NewPurchaseHandler(purchaseService)
+	payableHandler := handlers.NewPayableHandler(payableService)
 	stockHandler := handlers.NewStockHandler(stockService)
 	stockMovementHandler := handlers.NewStockMovementHandler(stockMovementService)
+	stockThresholdHandler := handlers.NewStockThresholdHandler(stockThresholdService)
+	saleHandler := handlers.NewSaleHandler(saleService)
+	promoCodeHandler := handlers.NewPromoCodeHandler(promoCodeService)
+	outletProductHandler := handlers.NewOutletProductHandler(outletProductService)
+	stockOpnameHandler := handlers.NewStockOpnameHandler(stockOpnameService)
+	stockTransferHandler := handlers.NewStockTransferHandler(stockTransferService)
+
+	// JWT milik sesi yang sudah logout atau terbit sebelum reset password ditolak mw.Auth.
+	// Permission route dihitung dari role user: admin semua, role kustom, atau default role bawaan
+	mw := handlers.NewMiddleware(sessionRepo.FindAuthState, roleService.UserPermissions)
 
 	mux := http.NewServeMux()
-	routes.RegisterTodoRoutes(mux, todoHandler)
-	routes.RegisterCategoryRoutes(mux, categoryHandler)
-	routes.RegisterSystemRoutes(mux, systemHandler)
-	routes.RegisterAuthRoutes(mux, authHandler)
-	routes.RegisterAddOnRoutes(mux, addOnHandler)
-	routes.RegisterOrderTypesRoutes(mux, orderTypeHandler)
-	routes.RegisterOutletRoutes(mux, outletHandler)
-	routes.RegisterProductRoutes(mux, productHandler)
-	routes.RegisterCustomerRoutes(mux, customerHandler)
-	routes.RegisterDiscountRoutes(mux, discountHandler)
-	routes.RegisterTaxRoutes(mux, taxHandler)
-	routes.RegisterRoleRoutes(mux, roleHandler)
-	routes.RegisterUnitRoutes(mux, unitHandler)
-	routes.RegisterSupplierRoutes(mux, supplierHandler)
-	routes.RegisterRecipeRoutes(mux, recipeHandler)
-	routes.RegisterCashierShiftRoutes(mux, cashierShiftHandler)
-	routes.RegisterPurchaseRoutes(mux, purchaseHandler)
-	routes.RegisterStockRoutes(mux, stockHandler)
-	routes.RegisterStockMovementRoutes(mux, stockMovementHandler)
+	routes.RegisterTodoRoutes(mux, todoHandler, mw)
+	routes.RegisterCategoryRoutes(mux, categoryHandler, mw)
+	routes.RegisterSystemRoutes(mux, systemHandler, mw)
+	routes.RegisterAuthRoutes(mux, authHandler, mw)
+	routes.RegisterUserRoutes(mux, userHandler, mw)
+	routes.RegisterPosDeviceRoutes(mux, posDeviceHandler, mw)
+	routes.RegisterAddOnRoutes(mux, addOnHandler, mw)
+	routes.RegisterOrderTypesRoutes(mux, orderTypeHandler, mw)
+	routes.RegisterOutletRoutes(mux, outletHandler, mw)
+	routes.RegisterProductRoutes(mux, productHandler, mw)
+	routes.RegisterCustomerRoutes(mux, customerHandler, mw)
+	routes.RegisterDiscountRoutes(mux, discountHandler, mw)
+	routes.RegisterTaxRoutes(mux, taxHandler, mw)
+	routes.RegisterRoleRoutes(mux, roleHandler, mw)
+	routes.RegisterUnitRoutes(mux, unitHandler, mw)
+	routes.RegisterSupplierRoutes(mux, supplierHandler, mw)
+	routes.RegisterRecipeRoutes(mux, recipeHandler, mw)
+	routes.RegisterCashierShiftRoutes(mux, cashierShiftHandler, mw)
+	routes.RegisterPurchaseRoutes(mux, purchaseHandler, mw)
+	routes.RegisterPayableRoutes(mux, payableHandler, mw)
+	routes.RegisterStockRoutes(mux, stockHandler, mw)
+	routes.RegisterStockMovementRoutes(mux, stockMovementHandler, mw)
+	routes.RegisterStockThresholdRoutes(mux, stockThresholdHandler, mw)
+	routes.RegisterSaleRoutes(mux, saleHandler, mw)
+	routes.RegisterPromoCodeRoutes(mux, promoCodeHandler, mw)
+	routes.RegisterOutletProductRoutes(mux, outletProductHandler, mw)
+	routes.RegisterStockOpnameRoutes(mux, stockOpnameHandler, mw)
+	routes.RegisterStockTransferRoutes(mux, stockTransferHandler, mw)
 
 	server := &http.Server{
 		Addr:         ":8080",
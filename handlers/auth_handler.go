@@ -2,10 +2,13 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"gowes/models"
 	"gowes/services"
+	"net"
 	"net/http"
+	"strings"
 )
 
 type AuthHandler struct {
@@ -54,6 +57,8 @@ func (c *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
 		return
 	}
+	input.UserAgent = r.UserAgent()
+	input.IPAddress = clientIP(r)
 
 	response, err := c.authService.Login(input)
 	if err != nil {
@@ -88,6 +93,118 @@ func (c *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
 	writeSuccess(w, http.StatusOK, nil, "email verified", nil)
 }
 
+func (c *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	var input models.ForgotPasswordInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+		return
+	}
+
+	if err := c.authService.ForgotPassword(input); err != nil {
+		if errors.Is(err, services.ErrResetEmailRequired) {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to send reset password email")
+		return
+	}
+
+	// Respons sama untuk email terdaftar maupun tidak
+	writeSuccess(w, http.StatusOK, nil, "if the email is registered, a reset password link has been sent", nil)
+}
+
+func (c *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	var input models.ResetPasswordInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+		return
+	}
+
+	if err := c.authService.ResetPassword(input); err != nil {
+		if errors.Is(err, services.ErrResetTokenInvalid) || errors.Is(err, services.ErrPasswordTooShort) {
+			writeError(w, http.StatusBadRequest, "RESET_PASSWORD_FAILED", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to reset password")
+		return
+	}
+
+	writeSuccess(w, http.StatusOK, nil, "password has been reset, please login again", nil)
+}
+
+func (c *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	var input models.RefreshTokenInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+		return
+	}
+	input.UserAgent = r.UserAgent()
+	input.IPAddress = clientIP(r)
+
+	response, err := c.authService.Refresh(input)
+	if err != nil {
+		if errors.Is(err, services.ErrRefreshTokenInvalid) {
+			writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to refresh token")
+		return
+	}
+
+	writeSuccess(w, http.StatusOK, response, "token refreshed", nil)
+}
+
+// PinLogin: login PIN kasir di terminal POS; device_key didapat saat perangkat didaftarkan.
+func (c *AuthHandler) PinLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	var input models.PinLoginInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+		return
+	}
+	input.UserAgent = r.UserAgent()
+	input.IPAddress = clientIP(r)
+
+	response, err := c.authService.PinLogin(input)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrPosDeviceInvalid), errors.Is(err, services.ErrPinLoginInvalid):
+			writeError(w, http.StatusUnauthorized, "LOGIN_FAILED", err.Error())
+		case errors.Is(err, services.ErrPinLocked):
+			writeError(w, http.StatusTooManyRequests, "PIN_LOCKED", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to login with pin")
+		}
+		return
+	}
+
+	writeSuccess(w, http.StatusOK, response, "login successful", nil)
+}
+
+// Logout mencabut sesi access token yang dipakai; refresh token sesi itu ikut tidak berlaku.
 func (c *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		w.Header().Set("Allow", "POST")
@@ -95,5 +212,54 @@ func (c *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user info missing")
+		return
+	}
+
+	if err := c.authService.Logout(user.ID, user.SessionID); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			writeError(w, http.StatusNotFound, "NOT_FOUND", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to logout")
+		return
+	}
+
 	writeSuccess(w, http.StatusOK, nil, "logout successful", nil)
 }
+
+// LogoutAll mencabut semua sesi user, termasuk sesi yang sedang dipakai.
+func (c *AuthHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user info missing")
+		return
+	}
+
+	if err := c.authService.LogoutAll(user.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to logout from all devices")
+		return
+	}
+
+	writeSuccess(w, http.StatusOK, nil, "logged out from all devices", nil)
+}
+
+// clientIP mengambil IP client; X-Forwarded-For dipakai jika aplikasi berada di balik proxy.
+func clientIP(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		ip, _, _ := strings.Cut(forwarded, ",")
+		return strings.TrimSpace(ip)
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
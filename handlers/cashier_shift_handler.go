@@ -6,6 +6,7 @@ import (
 	"errors"
 	"gowes/models"
 	"gowes/services"
+	"gowes/utils"
 	"io"
 	"net/http"
 	"strings"
@@ -19,6 +20,41 @@ func NewCashierShiftHandler(service services.CashierShiftService) *CashierShiftH
 	return &CashierShiftHandler{service: service}
 }
 
+func (h *CashierShiftHandler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	params := utils.ParsePaginationParams(r)
+	filter, err := utils.ParseFilterParams(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		return
+	}
+
+	// Token PIN hanya melihat shift outlet terminal-nya
+	if user.OutletID != "" {
+		filter.Values["outlet_id"] = []string{user.OutletID}
+	}
+
+	shifts, total, err := h.service.ListShifts(*user.CompanyID, params, filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to list cashier shifts")
+		return
+	}
+
+	meta := utils.CalculateMeta(total, params)
+	writeSuccess(w, http.StatusOK, shifts, "cashier shift list", meta)
+}
+
 func (h *CashierShiftHandler) StartShift(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		w.Header().Set("Allow", "POST")
@@ -43,6 +79,9 @@ func (h *CashierShiftHandler) StartShift(w http.ResponseWriter, r *http.Request)
 		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
 		return
 	}
+	if !scopeOutlet(w, user, &input.OutletID) {
+		return
+	}
 
 	if strings.TrimSpace(input.OutletID) == "" {
 		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "outlet_id cannot be empty")
@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"gowes/models"
 	"gowes/services"
@@ -29,7 +31,26 @@ func (h *DiscountHandler) ListOrCreate(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
 		params := utils.ParsePaginationParams(r)
-		discounts, total, err := h.service.ListDiscounts(*user.CompanyID, params)
+
+		// Filter opsional: ?active_at=<RFC3339> atau ?active=true (sekarang), dan ?outlet_id=
+		q := r.URL.Query()
+		filter := models.DiscountFilter{OutletID: strings.TrimSpace(q.Get("outlet_id"))}
+		if !scopeOutlet(w, user, &filter.OutletID) {
+			return
+		}
+		if raw := strings.TrimSpace(q.Get("active_at")); raw != "" {
+			activeAt, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "active_at must be in RFC3339 format")
+				return
+			}
+			filter.ActiveAt = &activeAt
+		} else if q.Get("active") == "true" {
+			now := time.Now()
+			filter.ActiveAt = &now
+		}
+
+		discounts, total, err := h.service.ListDiscounts(*user.CompanyID, params, filter)
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to list discounts")
 			return
@@ -64,6 +85,10 @@ func (h *DiscountHandler) ListOrCreate(w http.ResponseWriter, r *http.Request) {
 
 		discount, err := h.service.CreateDiscount(*user.CompanyID, input)
 		if err != nil {
+			if isDiscountValidationError(err) {
+				writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+				return
+			}
 			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
 			return
 		}
@@ -118,6 +143,10 @@ func (h *DiscountHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
 
 		updated, err := h.service.UpdateDiscount(id, input)
 		if err != nil {
+			if isDiscountValidationError(err) {
+				writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+				return
+			}
 			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
 			return
 		}
@@ -135,3 +164,60 @@ func (h *DiscountHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
 	}
 }
+
+func (h *DiscountHandler) Evaluate(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.CompanyID == nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "company info missing")
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "failed to read body")
+		return
+	}
+	var input models.DiscountCartInput
+	if err := json.Unmarshal(body, &input); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+		return
+	}
+
+	if !scopeOutlet(w, user, &input.OutletID) {
+		return
+	}
+
+	result, err := h.service.EvaluateCart(*user.CompanyID, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrDiscountCartOutletRequired),
+			errors.Is(err, services.ErrDiscountCartLinesRequired),
+			errors.Is(err, services.ErrDiscountCartProductRequired),
+			errors.Is(err, services.ErrDiscountCartQtyInvalid),
+			errors.Is(err, services.ErrDiscountCartPriceInvalid),
+			errors.Is(err, services.ErrDiscountCartPromoInvalid),
+			errors.Is(err, services.ErrPromoCodeRequired):
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to evaluate discounts")
+		}
+		return
+	}
+	writeSuccess(w, http.StatusOK, result, "discount evaluation", nil)
+}
+
+func isDiscountValidationError(err error) bool {
+	return errors.Is(err, services.ErrDiscountNameRequired) ||
+		errors.Is(err, services.ErrDiscountValueInvalid) ||
+		errors.Is(err, services.ErrDiscountTypeInvalid) ||
+		errors.Is(err, services.ErrDiscountPctExceeded) ||
+		errors.Is(err, services.ErrDiscountPeriodInvalid) ||
+		errors.Is(err, services.ErrDiscountScheduleDayInvalid) ||
+		errors.Is(err, services.ErrDiscountScheduleTimeInvalid)
+}
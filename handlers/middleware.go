@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -15,8 +17,42 @@ type contextKey string
 
 const UserContextKey contextKey = "user"
 
-func AuthMiddleware(next http.Handler) http.Handler {
+// permissionCacheTTL: permission user disimpan sebentar agar tidak query DB di setiap request.
+// Perubahan role / permission berlaku paling lambat setelah TTL ini.
+const permissionCacheTTL = 30 * time.Second
+
+// Middleware memeriksa JWT, sesi, dan permission. Lookup diisi dari app.go lewat NewMiddleware;
+// jika ada lookup yang kosong, request ditolak (fail closed).
+type Middleware struct {
+	// sessionLookup mengambil user, token_version, dan status aktif sesi (claim "sid") dalam satu query
+	sessionLookup func(sessionID string) (models.SessionAuthState, error)
+	// permissionLookup mengambil permission user dari role-nya
+	permissionLookup func(userID string) ([]models.Permission, error)
+
+	mu          sync.Mutex
+	permissions map[string]cachedPermissions
+}
+
+type cachedPermissions struct {
+	permissions []models.Permission
+	expiresAt   time.Time
+}
+
+func NewMiddleware(sessionLookup func(sessionID string) (models.SessionAuthState, error), permissionLookup func(userID string) ([]models.Permission, error)) *Middleware {
+	return &Middleware{
+		sessionLookup:    sessionLookup,
+		permissionLookup: permissionLookup,
+		permissions:      map[string]cachedPermissions{},
+	}
+}
+
+func (m *Middleware) Auth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m == nil || m.sessionLookup == nil {
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "authentication is not configured")
+			return
+		}
+
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
 			writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "missing authorization header")
@@ -62,19 +98,153 @@ func AuthMiddleware(next http.Handler) http.Handler {
 		// Inject user info into context
 		// Note: ID is stored as "sub"
 		userID, _ := claims["sub"].(string)
+
+		// Sesi harus milik user token, belum logout / dicabut, dan token_version sama dengan claim "ver"
+		// (token yang terbit sebelum reset password punya "ver" lebih kecil)
+		sessionID, _ := claims["sid"].(string)
+		if sessionID == "" {
+			writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "invalid token claims")
+			return
+		}
+		tokenVersion, _ := claims["ver"].(float64)
+		state, err := m.sessionLookup(sessionID)
+		if err != nil || !state.Active || state.UserID != userID || int(tokenVersion) != state.TokenVersion {
+			writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "session has been revoked, please login again")
+			return
+		}
+
 		role, _ := claims["role"].(string)
 		companyID, _ := claims["company_id"].(string)
-		fmt.Println("companyID:", companyID)
-		fmt.Println("userID:", userID)
-		fmt.Println("role:", role)
+
+		outletID, _ := claims["outlet_id"].(string)
+		deviceID, _ := claims["device_id"].(string)
 
 		user := models.User{
 			ID:        userID,
 			Role:      models.UserRole(role),
 			CompanyID: &companyID,
+			SessionID: sessionID,
+			OutletID:  outletID,
+			DeviceID:  deviceID,
 		}
 
 		ctx := context.WithValue(r.Context(), UserContextKey, user)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// RequirePermission menolak request (403) jika user di context tidak memiliki permission.
+// Dipasang di dalam Auth.
+func (m *Middleware) RequirePermission(permission models.Permission, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !m.checkPermission(w, r, permission) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireReadWrite: GET / HEAD butuh permission read, method lain butuh permission write.
+func (m *Middleware) RequireReadWrite(read models.Permission, write models.Permission, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		permission := write
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			permission = read
+		}
+		if !m.checkPermission(w, r, permission) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (m *Middleware) checkPermission(w http.ResponseWriter, r *http.Request, permission models.Permission) bool {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user info missing")
+		return false
+	}
+
+	// Token login PIN (punya device_id) dibatasi ke permission kasir
+	if user.DeviceID != "" && !hasPermission(models.PosSessionPermissions, permission) {
+		writeError(w, http.StatusForbidden, "FORBIDDEN", fmt.Sprintf("permission %s is not available on POS session", permission))
+		return false
+	}
+
+	permissions, err := m.userPermissions(user.ID)
+	if err != nil {
+		writeError(w, http.StatusForbidden, "FORBIDDEN", "failed to resolve user permissions")
+		return false
+	}
+
+	if !hasPermission(permissions, permission) {
+		writeError(w, http.StatusForbidden, "FORBIDDEN", fmt.Sprintf("missing permission %s", permission))
+		return false
+	}
+	return true
+}
+
+func hasPermission(permissions []models.Permission, permission models.Permission) bool {
+	for _, granted := range permissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// userPermissions memakai cache permissionCacheTTL sebelum memanggil permissionLookup
+func (m *Middleware) userPermissions(userID string) ([]models.Permission, error) {
+	if m == nil || m.permissionLookup == nil {
+		return nil, fmt.Errorf("permission lookup is not configured")
+	}
+
+	now := time.Now()
+	m.mu.Lock()
+	cached, ok := m.permissions[userID]
+	m.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.permissions, nil
+	}
+
+	permissions, err := m.permissionLookup(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	for id, entry := range m.permissions {
+		if !now.Before(entry.expiresAt) {
+			delete(m.permissions, id)
+		}
+	}
+	m.permissions[userID] = cachedPermissions{permissions: permissions, expiresAt: now.Add(permissionCacheTTL)}
+	m.mu.Unlock()
+	return permissions, nil
+}
+
+// outletAllowed menolak (403) resource outlet lain untuk token hasil login PIN
+func outletAllowed(w http.ResponseWriter, user models.User, outletID string) bool {
+	if user.OutletID == "" || outletID == user.OutletID {
+		return true
+	}
+	writeError(w, http.StatusForbidden, "FORBIDDEN", "token is scoped to another outlet")
+	return false
+}
+
+// scopeOutlet membatasi token hasil login PIN ke outlet terminal POS-nya. outlet_id kosong diisi
+// dengan outlet token; outlet lain ditolak dengan 403.
+func scopeOutlet(w http.ResponseWriter, user models.User, outletID *string) bool {
+	if user.OutletID == "" {
+		return true
+	}
+	if strings.TrimSpace(*outletID) == "" {
+		*outletID = user.OutletID
+		return true
+	}
+	if strings.TrimSpace(*outletID) != user.OutletID {
+		writeError(w, http.StatusForbidden, "FORBIDDEN", "token is scoped to another outlet")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"gowes/models"
+	"gowes/services"
+	"gowes/utils"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type OutletProductHandler struct {
+	service services.OutletProductService
+}
+
+func NewOutletProductHandler(service services.OutletProductService) *OutletProductHandler {
+	return &OutletProductHandler{service: service}
+}
+
+func (h *OutletProductHandler) ListOrCreate(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		params := utils.ParsePaginationParams(r)
+		filter := models.OutletProductFilter{
+			OutletID:  strings.TrimSpace(r.URL.Query().Get("outlet_id")),
+			ProductID: strings.TrimSpace(r.URL.Query().Get("product_id")),
+		}
+		if !scopeOutlet(w, user, &filter.OutletID) {
+			return
+		}
+		outletProducts, total, err := h.service.ListOutletProducts(*user.CompanyID, params, filter)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to list outlet products")
+			return
+		}
+		meta := utils.CalculateMeta(total, params)
+		writeSuccess(w, http.StatusOK, outletProducts, "outlet product list", meta)
+	case http.MethodPost:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "failed to read body")
+			return
+		}
+
+		var input models.OutletProductInput
+		if err := json.Unmarshal(body, &input); err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+			return
+		}
+
+		outletProduct, err := h.service.CreateOutletProduct(*user.CompanyID, input)
+		if err != nil {
+			writeOutletProductWriteError(w, err, "failed to create outlet product")
+			return
+		}
+		writeSuccess(w, http.StatusCreated, outletProduct, "outlet product created", nil)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+	}
+}
+
+func (h *OutletProductHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	id := strings.TrimSpace(r.PathValue("id"))
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "id cannot be empty")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		outletProduct, err := h.service.GetOutletProduct(id, *user.CompanyID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				writeError(w, http.StatusNotFound, "NOT_FOUND", "outlet product not found")
+				return
+			}
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to get outlet product")
+			return
+		}
+		if !outletAllowed(w, user, outletProduct.OutletID) {
+			return
+		}
+		writeSuccess(w, http.StatusOK, outletProduct, "outlet product detail", nil)
+	case http.MethodPut:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "failed to read body")
+			return
+		}
+
+		var input models.OutletProductInput
+		if err := json.Unmarshal(body, &input); err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+			return
+		}
+
+		outletProduct, err := h.service.UpdateOutletProduct(id, *user.CompanyID, input)
+		if err != nil {
+			writeOutletProductWriteError(w, err, "failed to update outlet product")
+			return
+		}
+		writeSuccess(w, http.StatusOK, outletProduct, "outlet product updated", nil)
+	case http.MethodDelete:
+		if err := h.service.DeleteOutletProduct(id, *user.CompanyID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				writeError(w, http.StatusNotFound, "NOT_FOUND", "outlet product not found")
+				return
+			}
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to delete outlet product")
+			return
+		}
+		writeSuccess(w, http.StatusOK, nil, "outlet product deleted", nil)
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+	}
+}
+
+func (h *OutletProductHandler) Bulk(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "failed to read body")
+		return
+	}
+
+	var input models.OutletProductBulkInput
+	if err := json.Unmarshal(body, &input); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+		return
+	}
+
+	count, err := h.service.BulkAssign(*user.CompanyID, input)
+	if err != nil {
+		writeOutletProductWriteError(w, err, "failed to assign outlet products")
+		return
+	}
+	writeSuccess(w, http.StatusOK, map[string]int{"affected": count}, "outlet products assigned", nil)
+}
+
+func writeOutletProductWriteError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "outlet product not found")
+	case errors.Is(err, services.ErrOutletProductOutletRequired),
+		errors.Is(err, services.ErrOutletProductProductRequired),
+		errors.Is(err, services.ErrOutletProductOutletNotFound),
+		errors.Is(err, services.ErrOutletProductProductNotFound),
+		errors.Is(err, services.ErrOutletProductPriceInvalid),
+		errors.Is(err, services.ErrOutletProductCostInvalid):
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	case errors.Is(err, services.ErrOutletProductDuplicate):
+		writeError(w, http.StatusConflict, "CONFLICT", err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", fallback)
+	}
+}
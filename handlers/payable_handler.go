@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"gowes/models"
+	"gowes/services"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type PayableHandler struct {
+	service services.PayableService
+}
+
+func NewPayableHandler(service services.PayableService) *PayableHandler {
+	return &PayableHandler{service: service}
+}
+
+func (h *PayableHandler) Invoice(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	if r.Method != http.MethodPut {
+		w.Header().Set("Allow", "PUT")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	id := strings.TrimSpace(r.PathValue("id"))
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "id cannot be empty")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "failed to read body")
+		return
+	}
+
+	var input models.PurchaseInvoiceInput
+	if err := json.Unmarshal(body, &input); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+		return
+	}
+
+	purchase, err := h.service.RecordInvoice(id, *user.CompanyID, input)
+	if err != nil {
+		writePayableError(w, err, "failed to record purchase invoice")
+		return
+	}
+	writeSuccess(w, http.StatusOK, purchase, "purchase invoice recorded", nil)
+}
+
+func (h *PayableHandler) Payments(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	id := strings.TrimSpace(r.PathValue("id"))
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "id cannot be empty")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		payments, err := h.service.ListPayments(id, *user.CompanyID)
+		if err != nil {
+			writePayableError(w, err, "failed to list purchase payments")
+			return
+		}
+		writeSuccess(w, http.StatusOK, payments, "purchase payment list", nil)
+	case http.MethodPost:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "failed to read body")
+			return
+		}
+
+		var input models.PurchasePaymentInput
+		if err := json.Unmarshal(body, &input); err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+			return
+		}
+
+		purchase, err := h.service.AddPayment(id, *user.CompanyID, user.ID, input)
+		if err != nil {
+			writePayableError(w, err, "failed to record purchase payment")
+			return
+		}
+		writeSuccess(w, http.StatusCreated, purchase, "purchase payment recorded", nil)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+	}
+}
+
+func (h *PayableHandler) Aging(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	filter := models.PayableAgingFilter{
+		SupplierID: strings.TrimSpace(r.URL.Query().Get("supplier_id")),
+		OutletID:   strings.TrimSpace(r.URL.Query().Get("outlet_id")),
+	}
+	if raw := strings.TrimSpace(r.URL.Query().Get("as_of")); raw != "" {
+		asOf, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "as_of must use the YYYY-MM-DD format")
+			return
+		}
+		filter.AsOf = asOf
+	}
+
+	report, err := h.service.GetAging(*user.CompanyID, filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to get payable aging")
+		return
+	}
+	writeSuccess(w, http.StatusOK, report, "payable aging", nil)
+}
+
+func writePayableError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "purchase not found")
+	case errors.Is(err, services.ErrPayableInvoiceNumberRequired),
+		errors.Is(err, services.ErrPayableDateInvalid),
+		errors.Is(err, services.ErrPayableTermInvalid),
+		errors.Is(err, services.ErrPayableDueBeforeInvoice),
+		errors.Is(err, services.ErrPayableAmountInvalid),
+		errors.Is(err, services.ErrPayablePaidAtInvalid),
+		errors.Is(err, services.ErrPurchasePaymentExceeds):
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	case errors.Is(err, services.ErrPurchaseNotPayable):
+		writeError(w, http.StatusConflict, "CONFLICT", err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", fallback)
+	}
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"gowes/models"
+	"gowes/services"
+	"gowes/utils"
+	"net/http"
+	"strings"
+)
+
+type PosDeviceHandler struct {
+	service services.PosDeviceService
+}
+
+func NewPosDeviceHandler(service services.PosDeviceService) *PosDeviceHandler {
+	return &PosDeviceHandler{service: service}
+}
+
+// ListOrCreate: GET daftar terminal POS, POST mendaftarkan terminal baru. Butuh permission user.manage.
+func (h *PosDeviceHandler) ListOrCreate(w http.ResponseWriter, r *http.Request) {
+	user, ok := companyUser(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		params := utils.ParsePaginationParams(r)
+		devices, total, err := h.service.ListDevices(*user.CompanyID, params)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to list pos devices")
+			return
+		}
+		meta := utils.CalculateMeta(total, params)
+		writeSuccess(w, http.StatusOK, devices, "pos device list", meta)
+	case http.MethodPost:
+		var input models.PosDeviceInput
+		if !decodeJSONBody(w, r, &input) {
+			return
+		}
+		device, err := h.service.RegisterDevice(*user.CompanyID, user.ID, input)
+		if err != nil {
+			writePosDeviceError(w, err, "failed to register pos device")
+			return
+		}
+		writeSuccess(w, http.StatusCreated, device, "pos device registered, store the device_key on the terminal", nil)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+	}
+}
+
+// HandleByID: GET detail, DELETE menonaktifkan terminal (sesi kasir di terminal itu ikut dicabut).
+func (h *PosDeviceHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
+	user, ok := companyUser(w, r)
+	if !ok {
+		return
+	}
+
+	id := strings.TrimSpace(r.PathValue("id"))
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "id cannot be empty")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		device, err := h.service.GetDevice(id, *user.CompanyID)
+		if err != nil {
+			writePosDeviceError(w, err, "failed to get pos device")
+			return
+		}
+		writeSuccess(w, http.StatusOK, device, "pos device detail", nil)
+	case http.MethodDelete:
+		if err := h.service.DeactivateDevice(id, *user.CompanyID); err != nil {
+			writePosDeviceError(w, err, "failed to deactivate pos device")
+			return
+		}
+		writeSuccess(w, http.StatusOK, nil, "pos device deactivated", nil)
+	default:
+		w.Header().Set("Allow", "GET, DELETE")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+	}
+}
+
+func writePosDeviceError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "pos device not found")
+	case errors.Is(err, services.ErrPosDeviceNameRequired),
+		errors.Is(err, services.ErrPosDeviceOutletRequired),
+		errors.Is(err, services.ErrPosDeviceOutletNotFound):
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", fallback)
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"gowes/models"
 	"gowes/services"
@@ -28,7 +29,12 @@ func (h *ProductHandler) ListOrCreate(w http.ResponseWriter, r *http.Request) {
 	case http.MethodGet:
 		// Get pagination params
 		params := utils.ParsePaginationParams(r)
-		products, total, err := h.service.FindAll(*user.CompanyID, params)
+		filter, err := utils.ParseFilterParams(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+			return
+		}
+		products, total, err := h.service.FindAll(*user.CompanyID, params, filter)
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, err.Error(), "Failed to get products")
 			return
@@ -48,6 +54,8 @@ func (h *ProductHandler) ListOrCreate(w http.ResponseWriter, r *http.Request) {
 		unitID := r.FormValue("unit_id")
 		cost := r.FormValue("cost")
 		categoryID := r.FormValue("category_id")
+		productType := strings.TrimSpace(r.FormValue("type"))
+		costingMethod := strings.TrimSpace(r.FormValue("costing_method"))
 		addOnIDs := r.FormValue("add_on_ids")
 		var addOnIDList []string
 		if addOnIDs != "" {
@@ -80,11 +88,18 @@ func (h *ProductHandler) ListOrCreate(w http.ResponseWriter, r *http.Request) {
 			Cost:       utils.ParseFloat64(cost),
 			CategoryID: categoryID,
 			CompanyID:  *user.CompanyID,
+			Type:       models.ProductType(productType),
 			ImageURL:   "",
+
+			CostingMethod: models.CostingMethod(costingMethod),
 		}
 
 		product, err := h.service.Create(*user.CompanyID, payload, file, header, addOnIDList)
 		if err != nil {
+			if errors.Is(err, services.ErrProductTypeInvalid) || errors.Is(err, services.ErrProductCostingMethodInvalid) {
+				writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+				return
+			}
 			writeError(w, http.StatusInternalServerError, err.Error(), "Failed to create product")
 			return
 		}
@@ -136,6 +151,8 @@ func (h *ProductHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
 		unitID := r.FormValue("unit_id")
 		cost := r.FormValue("cost")
 		categoryID := r.FormValue("category_id")
+		productType := strings.TrimSpace(r.FormValue("type"))
+		costingMethod := strings.TrimSpace(r.FormValue("costing_method"))
 		if name == "" || price == "" || sku == "" || unit == "" || unitID == "" || cost == "" || categoryID == "" {
 			writeError(w, http.StatusBadRequest, "bad_request", "missing required fields")
 			return
@@ -150,6 +167,9 @@ func (h *ProductHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
 			Cost:       utils.ParseFloat64(cost),
 			CategoryID: categoryID,
 			CompanyID:  *user.CompanyID,
+			Type:       models.ProductType(productType),
+
+			CostingMethod: models.CostingMethod(costingMethod),
 		}
 
 		// Gambar bersifat opsional saat update — jika tidak dikirim, tetap pakai gambar lama
@@ -163,6 +183,10 @@ func (h *ProductHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
 
 		updated, err := h.service.Update(id, payload, imageFile, imageHeader)
 		if err != nil {
+			if errors.Is(err, services.ErrProductTypeInvalid) || errors.Is(err, services.ErrProductCostingMethodInvalid) {
+				writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+				return
+			}
 			writeError(w, http.StatusInternalServerError, err.Error(), "Failed to update product")
 			return
 		}
@@ -179,8 +203,22 @@ func (h *ProductHandler) HandleMobile(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
 		return
 	}
-	products, err := h.service.FindAllMobile(*user.CompanyID)
+	// Token PIN memakai outlet terminal-nya bila outlet_id tidak dikirim
+	outletID := strings.TrimSpace(r.URL.Query().Get("outlet_id"))
+	if !scopeOutlet(w, user, &outletID) {
+		return
+	}
+	orderTypeID := strings.TrimSpace(r.URL.Query().Get("order_type_id"))
+	products, err := h.service.FindAllMobile(*user.CompanyID, outletID, orderTypeID)
 	if err != nil {
+		if errors.Is(err, services.ErrProductOutletNotFound) || errors.Is(err, services.ErrProductOrderTypeNotFound) {
+			writeError(w, http.StatusNotFound, "NOT_FOUND", err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrProductOrderTypeInactive) {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+			return
+		}
 		writeError(w, http.StatusInternalServerError, err.Error(), "Failed to get products")
 		return
 	}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"gowes/models"
+	"gowes/services"
+	"gowes/utils"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type PromoCodeHandler struct {
+	service services.PromoCodeService
+}
+
+func NewPromoCodeHandler(service services.PromoCodeService) *PromoCodeHandler {
+	return &PromoCodeHandler{service: service}
+}
+
+func (h *PromoCodeHandler) ListOrCreate(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		params := utils.ParsePaginationParams(r)
+		promoCodes, total, err := h.service.ListPromoCodes(*user.CompanyID, params)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to list promo codes")
+			return
+		}
+		meta := utils.CalculateMeta(total, params)
+		writeSuccess(w, http.StatusOK, promoCodes, "promo code list", meta)
+	case http.MethodPost:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "failed to read body")
+			return
+		}
+
+		var input models.PromoCodeInput
+		if err := json.Unmarshal(body, &input); err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+			return
+		}
+
+		promoCode, err := h.service.CreatePromoCode(*user.CompanyID, input)
+		if err != nil {
+			writePromoCodeWriteError(w, err, "failed to create promo code")
+			return
+		}
+		writeSuccess(w, http.StatusCreated, promoCode, "promo code created", nil)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+	}
+}
+
+func (h *PromoCodeHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	id := strings.TrimSpace(r.PathValue("id"))
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "id cannot be empty")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		promoCode, err := h.service.GetPromoCode(id, *user.CompanyID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				writeError(w, http.StatusNotFound, "NOT_FOUND", "promo code not found")
+				return
+			}
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to get promo code")
+			return
+		}
+		writeSuccess(w, http.StatusOK, promoCode, "promo code detail", nil)
+	case http.MethodPut:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "failed to read body")
+			return
+		}
+
+		var input models.PromoCodeInput
+		if err := json.Unmarshal(body, &input); err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+			return
+		}
+
+		promoCode, err := h.service.UpdatePromoCode(id, *user.CompanyID, input)
+		if err != nil {
+			writePromoCodeWriteError(w, err, "failed to update promo code")
+			return
+		}
+		writeSuccess(w, http.StatusOK, promoCode, "promo code updated", nil)
+	case http.MethodDelete:
+		if err := h.service.DeletePromoCode(id, *user.CompanyID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				writeError(w, http.StatusNotFound, "NOT_FOUND", "promo code not found")
+				return
+			}
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to delete promo code")
+			return
+		}
+		writeSuccess(w, http.StatusOK, nil, "promo code deleted", nil)
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+	}
+}
+
+func (h *PromoCodeHandler) Validate(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "failed to read body")
+		return
+	}
+
+	var input models.PromoCodeRedeemInput
+	if err := json.Unmarshal(body, &input); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+		return
+	}
+
+	if !scopeOutlet(w, user, &input.OutletID) {
+		return
+	}
+
+	result, err := h.service.ValidatePromoCode(*user.CompanyID, input)
+	if err != nil {
+		if errors.Is(err, services.ErrPromoCodeRequired) {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to validate promo code")
+		return
+	}
+	writeSuccess(w, http.StatusOK, result, "promo code validation", nil)
+}
+
+func (h *PromoCodeHandler) Redeem(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "failed to read body")
+		return
+	}
+
+	var input models.PromoCodeRedeemInput
+	if err := json.Unmarshal(body, &input); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+		return
+	}
+
+	if !scopeOutlet(w, user, &input.OutletID) {
+		return
+	}
+
+	result, err := h.service.RedeemPromoCode(*user.CompanyID, user.ID, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			writeError(w, http.StatusNotFound, "NOT_FOUND", "promo code not found")
+		case errors.Is(err, services.ErrPromoCodeRequired),
+			errors.Is(err, services.ErrPromoCodeCustomerRequired),
+			errors.Is(err, services.ErrPromoCodeSaleNotFound),
+			errors.Is(err, services.ErrPromoCodeCustomerNotFound):
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		case errors.Is(err, services.ErrPromoCodeInactive),
+			errors.Is(err, services.ErrPromoCodeDiscountUnavailable),
+			errors.Is(err, services.ErrPromoCodeOutletUnavailable),
+			errors.Is(err, services.ErrPromoCodeUsageLimitReached),
+			errors.Is(err, services.ErrPromoCodeCustomerLimitReached):
+			writeError(w, http.StatusConflict, "CONFLICT", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to redeem promo code")
+		}
+		return
+	}
+	writeSuccess(w, http.StatusCreated, result, "promo code redeemed", nil)
+}
+
+func writePromoCodeWriteError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "promo code not found")
+	case errors.Is(err, services.ErrPromoCodeRequired),
+		errors.Is(err, services.ErrPromoCodeTooLong),
+		errors.Is(err, services.ErrPromoCodeDiscountRequired),
+		errors.Is(err, services.ErrPromoCodeDiscountNotFound),
+		errors.Is(err, services.ErrPromoCodeMaxUsesInvalid),
+		errors.Is(err, services.ErrPromoCodeMaxPerCustomer):
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	case errors.Is(err, services.ErrPromoCodeDuplicate):
+		writeError(w, http.StatusConflict, "CONFLICT", err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", fallback)
+	}
+}
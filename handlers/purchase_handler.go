@@ -29,10 +29,28 @@ func (h *PurchaseHandler) ListOrCreate(w http.ResponseWriter, r *http.Request) {
 
 	switch r.Method {
 	case http.MethodGet:
-		params := utils.ParsePaginationParams(r)
-		purchases, total, err := h.service.ListPurchases(*user.CompanyID, params)
+		params, err := utils.ParseCursorPaginationParams(r)
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to list purchases")
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+			return
+		}
+		filter, err := utils.ParseFilterParams(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+			return
+		}
+		if params.CursorMode {
+			purchases, page, err := h.service.ListPurchasePage(*user.CompanyID, params, filter)
+			if err != nil {
+				writePurchaseListError(w, err)
+				return
+			}
+			writeSuccess(w, http.StatusOK, purchases, "purchase list", utils.CalculateCursorMeta(page, params))
+			return
+		}
+		purchases, total, err := h.service.ListPurchases(*user.CompanyID, params, filter)
+		if err != nil {
+			writePurchaseListError(w, err)
 			return
 		}
 		meta := utils.CalculateMeta(total, params)
@@ -52,16 +70,7 @@ func (h *PurchaseHandler) ListOrCreate(w http.ResponseWriter, r *http.Request) {
 
 		purchase, err := h.service.CreatePurchase(*user.CompanyID, user.ID, input)
 		if err != nil {
-			switch {
-			case errors.Is(err, services.ErrPurchaseOutletRequired),
-				errors.Is(err, services.ErrPurchaseDetailsRequired),
-				errors.Is(err, services.ErrPurchaseDetailProductRequired),
-				errors.Is(err, services.ErrPurchaseDetailQtyInvalid),
-				errors.Is(err, services.ErrPurchaseDetailPriceInvalid):
-				writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
-			default:
-				writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to create purchase")
-			}
+			writePurchaseError(w, err, "failed to create purchase")
 			return
 		}
 
@@ -89,16 +98,255 @@ func (h *PurchaseHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
 	case http.MethodGet:
 		purchase, err := h.service.GetPurchase(id, *user.CompanyID)
 		if err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-				writeError(w, http.StatusNotFound, "NOT_FOUND", "purchase not found")
-				return
-			}
-			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to get purchase")
+			writePurchaseError(w, err, "failed to get purchase")
 			return
 		}
 		writeSuccess(w, http.StatusOK, purchase, "purchase detail", nil)
+	case http.MethodPut:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "failed to read body")
+			return
+		}
+
+		var input models.PurchaseInput
+		if err := json.Unmarshal(body, &input); err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+			return
+		}
+
+		purchase, err := h.service.UpdatePurchase(id, *user.CompanyID, input)
+		if err != nil {
+			writePurchaseError(w, err, "failed to update purchase")
+			return
+		}
+		writeSuccess(w, http.StatusOK, purchase, "purchase updated", nil)
+	case http.MethodDelete:
+		if err := h.service.DeletePurchase(id, *user.CompanyID); err != nil {
+			writePurchaseError(w, err, "failed to delete purchase")
+			return
+		}
+		writeSuccess(w, http.StatusOK, nil, "purchase deleted", nil)
 	default:
-		w.Header().Set("Allow", "GET")
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+	}
+}
+
+func (h *PurchaseHandler) Order(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	id := strings.TrimSpace(r.PathValue("id"))
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "id cannot be empty")
+		return
+	}
+
+	purchase, err := h.service.OrderPurchase(id, *user.CompanyID, user.ID)
+	if err != nil {
+		writePurchaseError(w, err, "failed to order purchase")
+		return
+	}
+	writeSuccess(w, http.StatusOK, purchase, "purchase ordered", nil)
+}
+
+func (h *PurchaseHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	id := strings.TrimSpace(r.PathValue("id"))
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "id cannot be empty")
+		return
+	}
+
+	purchase, err := h.service.CancelPurchase(id, *user.CompanyID, user.ID)
+	if err != nil {
+		writePurchaseError(w, err, "failed to cancel purchase")
+		return
+	}
+	writeSuccess(w, http.StatusOK, purchase, "purchase cancelled", nil)
+}
+
+func (h *PurchaseHandler) Receive(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
 		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	id := strings.TrimSpace(r.PathValue("id"))
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "id cannot be empty")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "failed to read body")
+		return
+	}
+
+	var input models.PurchaseReceiveInput
+	if err := json.Unmarshal(body, &input); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+		return
+	}
+
+	purchase, err := h.service.ReceivePurchase(id, *user.CompanyID, user.ID, input)
+	if err != nil {
+		writePurchaseError(w, err, "failed to receive purchase")
+		return
+	}
+	writeSuccess(w, http.StatusOK, purchase, "purchase received", nil)
+}
+
+func (h *PurchaseHandler) Return(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeError(w, http.StatusMethodNotAllowed, "ReturnPurchase_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	id := strings.TrimSpace(r.PathValue("id"))
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "id cannot be empty")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "failed to read body")
+		return
+	}
+
+	var input models.PurchaseReturnInput
+	if err := json.Unmarshal(body, &input); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+		return
+	}
+
+	purchase, err := h.service.ReturnPurchase(id, *user.CompanyID, user.ID, input)
+	if err != nil {
+		writePurchaseError(w, err, "failed to return purchase")
+		return
+	}
+	writeSuccess(w, http.StatusOK, purchase, "purchase returned", nil)
+}
+
+func (h *PurchaseHandler) Void(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeError(w, http.StatusMethodNotAllowed, "VoidPurchase_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	id := strings.TrimSpace(r.PathValue("id"))
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "id cannot be empty")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "failed to read body")
+		return
+	}
+
+	var input models.PurchaseVoidInput
+	if err := json.Unmarshal(body, &input); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+		return
+	}
+
+	purchase, err := h.service.VoidPurchase(id, *user.CompanyID, user.ID, input)
+	if err != nil {
+		writePurchaseError(w, err, "failed to void purchase")
+		return
+	}
+	writeSuccess(w, http.StatusOK, purchase, "purchase voided", nil)
+}
+
+func writePurchaseError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "purchase not found")
+	case errors.Is(err, services.ErrPurchaseOutletRequired),
+		errors.Is(err, services.ErrPurchaseSupplierRequired),
+		errors.Is(err, services.ErrPurchaseOutletNotFound),
+		errors.Is(err, services.ErrPurchaseSupplierNotFound),
+		errors.Is(err, services.ErrPurchaseSupplierInactive),
+		errors.Is(err, services.ErrPurchaseDetailsRequired),
+		errors.Is(err, services.ErrPurchaseDetailProductRequired),
+		errors.Is(err, services.ErrPurchaseDetailQtyInvalid),
+		errors.Is(err, services.ErrPurchaseDetailPriceInvalid),
+		errors.Is(err, services.ErrPurchaseProductNotFound),
+		errors.Is(err, services.ErrPurchaseDuplicateProduct),
+		errors.Is(err, services.ErrPurchaseDiscountInvalid),
+		errors.Is(err, services.ErrPurchaseCreateStatusInvalid),
+		errors.Is(err, services.ErrPurchaseReceiveItemsRequired),
+		errors.Is(err, services.ErrPurchaseReceiveQtyInvalid),
+		errors.Is(err, services.ErrPurchaseItemNotFound),
+		errors.Is(err, services.ErrPurchaseReceiveExceeds),
+		errors.Is(err, services.ErrPurchaseReturnExceeds),
+		errors.Is(err, services.ErrPurchaseVoidReasonRequired),
+		errors.Is(err, services.ErrUnitConversionUnitNotFound),
+		errors.Is(err, services.ErrUnitConversionIncompatible),
+		errors.Is(err, services.ErrUnitQtyPrecision):
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	case errors.Is(err, services.ErrPurchaseNotDraft),
+		errors.Is(err, services.ErrPurchaseNotReceivable),
+		errors.Is(err, services.ErrPurchaseNotCancellable),
+		errors.Is(err, services.ErrPurchaseNotReturnable),
+		errors.Is(err, services.ErrPurchaseNotVoidable),
+		errors.Is(err, services.ErrPurchaseInsufficientStock):
+		writeError(w, http.StatusConflict, "CONFLICT", err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", fallback)
+	}
+}
+
+func writePurchaseListError(w http.ResponseWriter, err error) {
+	if errors.Is(err, services.ErrPurchaseStatusInvalid) || errors.Is(err, services.ErrPurchasePaymentStatusInvalid) {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		return
 	}
+	writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to list purchases")
 }
@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"gowes/models"
 	"gowes/services"
 	"gowes/utils"
@@ -57,7 +59,7 @@ func (h *RecipeHandler) ListOrCreate(w http.ResponseWriter, r *http.Request) {
 
 		recipe, err := h.service.CreateRecipe(*user.CompanyID, user.ID, input)
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to create recipe")
+			writeRecipeError(w, err, "failed to create recipe")
 			return
 		}
 		writeSuccess(w, http.StatusCreated, recipe, "recipe created", nil)
@@ -110,7 +112,7 @@ func (h *RecipeHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
 
 		recipe, err := h.service.UpdateRecipe(id, *user.CompanyID, user.ID, input)
 		if err != nil {
-			writeError(w, http.StatusNotFound, "NOT_FOUND", "recipe not found")
+			writeRecipeError(w, err, "failed to update recipe")
 			return
 		}
 		writeSuccess(w, http.StatusOK, recipe, "recipe updated", nil)
@@ -125,3 +127,72 @@ func (h *RecipeHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
 	}
 }
+
+func (h *RecipeHandler) HandleBOM(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	productID := strings.TrimSpace(r.PathValue("product_id"))
+	if productID == "" {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "product_id cannot be empty")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		bom, err := h.service.GetBOM(*user.CompanyID, productID)
+		if err != nil {
+			writeRecipeError(w, err, "failed to get recipe")
+			return
+		}
+		writeSuccess(w, http.StatusOK, bom, "recipe bill of materials", nil)
+	case http.MethodPut:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "failed to read body")
+			return
+		}
+		var input models.RecipeBOMInput
+		if err := json.Unmarshal(body, &input); err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+			return
+		}
+
+		bom, err := h.service.SaveBOM(*user.CompanyID, user.ID, productID, input)
+		if err != nil {
+			writeRecipeError(w, err, "failed to save recipe")
+			return
+		}
+		writeSuccess(w, http.StatusOK, bom, "recipe bill of materials saved", nil)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+	}
+}
+
+func writeRecipeError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "recipe not found")
+	case errors.Is(err, services.ErrRecipeProductNotFound):
+		writeError(w, http.StatusNotFound, "NOT_FOUND", err.Error())
+	case errors.Is(err, services.ErrRecipeProductRequired),
+		errors.Is(err, services.ErrRecipeIngredientRequired),
+		errors.Is(err, services.ErrRecipeQuantityInvalid),
+		errors.Is(err, services.ErrRecipeIngredientNotFound),
+		errors.Is(err, services.ErrUnitConversionUnitNotFound),
+		errors.Is(err, services.ErrUnitConversionIncompatible),
+		errors.Is(err, services.ErrUnitQtyPrecision),
+		errors.Is(err, services.ErrRecipeProductNotFinished),
+		errors.Is(err, services.ErrRecipeIngredientNotRaw),
+		errors.Is(err, services.ErrRecipeSelfReference):
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	case errors.Is(err, services.ErrRecipeDuplicateIngredient):
+		writeError(w, http.StatusConflict, "CONFLICT", err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", fallback)
+	}
+}
@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"gowes/models"
 	"gowes/services"
 	"gowes/utils"
@@ -36,6 +38,9 @@ func (h *RoleHandler) ListOrCreate(w http.ResponseWriter, r *http.Request) {
 		meta := utils.CalculateMeta(total, params)
 		writeSuccess(w, http.StatusOK, roles, "role list", meta)
 	case http.MethodPost:
+		if !roleAdmin(w, user) {
+			return
+		}
 		body, err := io.ReadAll(r.Body)
 		if err != nil {
 			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "failed to read body")
@@ -55,7 +60,7 @@ func (h *RoleHandler) ListOrCreate(w http.ResponseWriter, r *http.Request) {
 
 		role, err := h.service.CreateRole(*user.CompanyID, user.ID, input)
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+			writeRoleError(w, err, "failed to create role")
 			return
 		}
 
@@ -88,6 +93,9 @@ func (h *RoleHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
 		}
 		writeSuccess(w, http.StatusOK, role, "role detail", nil)
 	case http.MethodPut:
+		if !roleAdmin(w, user) {
+			return
+		}
 		body, err := io.ReadAll(r.Body)
 		if err != nil {
 			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "failed to read body")
@@ -107,11 +115,14 @@ func (h *RoleHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
 
 		role, err := h.service.UpdateRole(id, *user.CompanyID, user.ID, input)
 		if err != nil {
-			writeError(w, http.StatusNotFound, "NOT_FOUND", "role not found")
+			writeRoleError(w, err, "failed to update role")
 			return
 		}
 		writeSuccess(w, http.StatusOK, role, "role updated", nil)
 	case http.MethodDelete:
+		if !roleAdmin(w, user) {
+			return
+		}
 		if err := h.service.DeleteRole(id, *user.CompanyID); err != nil {
 			writeError(w, http.StatusNotFound, "NOT_FOUND", "role not found")
 			return
@@ -122,3 +133,35 @@ func (h *RoleHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
 	}
 }
+
+// roleAdmin: role dan permission-nya hanya boleh diubah admin, supaya staff dengan user.manage
+// tidak bisa menambah permission ke role-nya sendiri.
+func roleAdmin(w http.ResponseWriter, user models.User) bool {
+	if user.Role != models.RoleAdmin {
+		writeError(w, http.StatusForbidden, "FORBIDDEN", "only admin can change roles")
+		return false
+	}
+	return true
+}
+
+// ListPermissions mengembalikan katalog permission yang bisa dipasang ke role
+func (h *RoleHandler) ListPermissions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+	writeSuccess(w, http.StatusOK, h.service.ListPermissions(), "permission list", nil)
+}
+
+func writeRoleError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "role not found")
+	case errors.Is(err, services.ErrRoleNameRequired),
+		errors.Is(err, services.ErrRolePermissionInvalid):
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", fallback)
+	}
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"gowes/models"
+	"gowes/services"
+	"gowes/utils"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type SaleHandler struct {
+	service services.SaleService
+}
+
+func NewSaleHandler(service services.SaleService) *SaleHandler {
+	return &SaleHandler{service: service}
+}
+
+func (h *SaleHandler) ListOrCreate(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		params := utils.ParsePaginationParams(r)
+		// Token PIN hanya melihat penjualan outlet terminal-nya
+		sales, total, err := h.service.ListSales(*user.CompanyID, user.OutletID, params)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to list sales")
+			return
+		}
+		meta := utils.CalculateMeta(total, params)
+		writeSuccess(w, http.StatusOK, sales, "sale list", meta)
+	case http.MethodPost:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "failed to read body")
+			return
+		}
+
+		var input models.SaleInput
+		if err := json.Unmarshal(body, &input); err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+			return
+		}
+		if !scopeOutlet(w, user, &input.OutletID) {
+			return
+		}
+
+		sale, err := h.service.CreateSale(*user.CompanyID, user.ID, input)
+		if err != nil {
+			switch {
+			case errors.Is(err, services.ErrSaleOutletRequired),
+				errors.Is(err, services.ErrSaleDetailsRequired),
+				errors.Is(err, services.ErrSaleDetailProductRequired),
+				errors.Is(err, services.ErrSaleDetailQtyInvalid),
+				errors.Is(err, services.ErrSaleAddOnRequired),
+				errors.Is(err, services.ErrSaleAddOnQtyInvalid),
+				errors.Is(err, services.ErrSaleAddOnNotFound),
+				errors.Is(err, services.ErrSaleOrderTypeNotFound),
+				errors.Is(err, services.ErrSaleOrderTypeInactive),
+				errors.Is(err, services.ErrSalePaidAmountInsufficient),
+				errors.Is(err, services.ErrSaleProductNotFound),
+				errors.Is(err, services.ErrSaleProductUnavailable),
+				errors.Is(err, services.ErrSaleDiscountInvalid),
+				errors.Is(err, services.ErrSaleOutletNotFound),
+				errors.Is(err, services.ErrSaleCustomerNotFound),
+				errors.Is(err, services.ErrSaleStatusInvalid),
+				errors.Is(err, services.ErrSaleInsufficientStock),
+				errors.Is(err, services.ErrUnitConversionUnitNotFound),
+				errors.Is(err, services.ErrUnitConversionIncompatible),
+				errors.Is(err, services.ErrUnitQtyPrecision):
+				writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+			default:
+				writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to create sale")
+			}
+			return
+		}
+
+		writeSuccess(w, http.StatusCreated, sale, "sale created", nil)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+	}
+}
+
+func (h *SaleHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	id := strings.TrimSpace(r.PathValue("id"))
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "id cannot be empty")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		sale, err := h.service.GetSale(id, *user.CompanyID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				writeError(w, http.StatusNotFound, "NOT_FOUND", "sale not found")
+				return
+			}
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to get sale")
+			return
+		}
+		if !outletAllowed(w, user, sale.OutletID) {
+			return
+		}
+		writeSuccess(w, http.StatusOK, sale, "sale detail", nil)
+	default:
+		w.Header().Set("Allow", "GET")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+	}
+}
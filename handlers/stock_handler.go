@@ -32,10 +32,13 @@ func (h *StockHandler) List(w http.ResponseWriter, r *http.Request) {
 	}
 
 	params := utils.ParsePaginationParams(r)
-	outletID := strings.TrimSpace(r.URL.Query().Get("outlet_id"))
-	productID := strings.TrimSpace(r.URL.Query().Get("product_id"))
+	filter, err := utils.ParseFilterParams(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		return
+	}
 
-	stocks, total, err := h.service.ListStocks(*user.CompanyID, params, outletID, productID)
+	stocks, total, err := h.service.ListStocks(*user.CompanyID, params, filter)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to list stocks")
 		return
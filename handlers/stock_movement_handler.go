@@ -8,6 +8,7 @@ import (
 	"gowes/utils"
 	"net/http"
 	"strings"
+	"time"
 )
 
 type StockMovementHandler struct {
@@ -31,13 +32,28 @@ func (h *StockMovementHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	params := utils.ParsePaginationParams(r)
-	outletID := strings.TrimSpace(r.URL.Query().Get("outlet_id"))
-	productID := strings.TrimSpace(r.URL.Query().Get("product_id"))
-	movementType := strings.TrimSpace(r.URL.Query().Get("type"))
-	referenceType := strings.TrimSpace(r.URL.Query().Get("reference_type"))
+	params, err := utils.ParseCursorPaginationParams(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		return
+	}
+	filter, err := utils.ParseFilterParams(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		return
+	}
+
+	if params.CursorMode {
+		movements, page, err := h.service.ListStockMovementPage(*user.CompanyID, params, filter)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to list stock movements")
+			return
+		}
+		writeSuccess(w, http.StatusOK, movements, "stock movement list", utils.CalculateCursorMeta(page, params))
+		return
+	}
 
-	movements, total, err := h.service.ListStockMovements(*user.CompanyID, params, outletID, productID, movementType, referenceType)
+	movements, total, err := h.service.ListStockMovements(*user.CompanyID, params, filter)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to list stock movements")
 		return
@@ -78,3 +94,96 @@ func (h *StockMovementHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 
 	writeSuccess(w, http.StatusOK, movement, "stock movement detail", nil)
 }
+
+// StockCard: GET kartu stok satu produk di satu outlet (saldo awal, movement + saldo berjalan, saldo akhir).
+func (h *StockMovementHandler) StockCard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	filter, ok := parseStockLedgerFilter(w, r)
+	if !ok {
+		return
+	}
+
+	card, err := h.service.GetStockCard(*user.CompanyID, filter)
+	if err != nil {
+		writeStockLedgerError(w, err, "failed to get stock card")
+		return
+	}
+	writeSuccess(w, http.StatusOK, card, "stock card", nil)
+}
+
+// Summary: GET rekap mutasi stok seluruh produk pada periode.
+func (h *StockMovementHandler) Summary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	filter, ok := parseStockLedgerFilter(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.GetStockSummary(*user.CompanyID, filter)
+	if err != nil {
+		writeStockLedgerError(w, err, "failed to get stock summary")
+		return
+	}
+	writeSuccess(w, http.StatusOK, report, "stock summary", nil)
+}
+
+// parseStockLedgerFilter membaca product_id, outlet_id, date_from, dan date_to (YYYY-MM-DD).
+func parseStockLedgerFilter(w http.ResponseWriter, r *http.Request) (models.StockLedgerFilter, bool) {
+	query := r.URL.Query()
+	filter := models.StockLedgerFilter{
+		ProductID: strings.TrimSpace(query.Get("product_id")),
+		OutletID:  strings.TrimSpace(query.Get("outlet_id")),
+	}
+	if raw := strings.TrimSpace(query.Get("date_from")); raw != "" {
+		dateFrom, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "date_from must use the YYYY-MM-DD format")
+			return models.StockLedgerFilter{}, false
+		}
+		filter.DateFrom = dateFrom
+	}
+	if raw := strings.TrimSpace(query.Get("date_to")); raw != "" {
+		dateTo, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "date_to must use the YYYY-MM-DD format")
+			return models.StockLedgerFilter{}, false
+		}
+		filter.DateTo = dateTo
+	}
+	return filter, true
+}
+
+func writeStockLedgerError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "product or outlet not found")
+	case errors.Is(err, services.ErrStockLedgerProductRequired),
+		errors.Is(err, services.ErrStockLedgerOutletRequired),
+		errors.Is(err, services.ErrStockLedgerDateRange):
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", fallback)
+	}
+}
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"gowes/models"
+	"gowes/services"
+	"gowes/utils"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type StockOpnameHandler struct {
+	service services.StockOpnameService
+}
+
+func NewStockOpnameHandler(service services.StockOpnameService) *StockOpnameHandler {
+	return &StockOpnameHandler{service: service}
+}
+
+func (h *StockOpnameHandler) ListOrCreate(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		params := utils.ParsePaginationParams(r)
+		filter := models.StockOpnameFilter{
+			OutletID: strings.TrimSpace(r.URL.Query().Get("outlet_id")),
+			Status:   strings.TrimSpace(r.URL.Query().Get("status")),
+		}
+		opnames, total, err := h.service.ListStockOpnames(*user.CompanyID, params, filter)
+		if err != nil {
+			if errors.Is(err, services.ErrStockOpnameStatusInvalid) {
+				writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+				return
+			}
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to list stock opnames")
+			return
+		}
+		meta := utils.CalculateMeta(total, params)
+		writeSuccess(w, http.StatusOK, opnames, "stock opname list", meta)
+	case http.MethodPost:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "failed to read body")
+			return
+		}
+
+		var input models.StockOpnameInput
+		if err := json.Unmarshal(body, &input); err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+			return
+		}
+
+		opname, err := h.service.CreateStockOpname(*user.CompanyID, user.ID, input)
+		if err != nil {
+			writeStockOpnameError(w, err, "failed to create stock opname")
+			return
+		}
+		writeSuccess(w, http.StatusCreated, opname, "stock opname created", nil)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+	}
+}
+
+func (h *StockOpnameHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	id := strings.TrimSpace(r.PathValue("id"))
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "id cannot be empty")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		opname, err := h.service.GetStockOpname(id, *user.CompanyID)
+		if err != nil {
+			writeStockOpnameError(w, err, "failed to get stock opname")
+			return
+		}
+		writeSuccess(w, http.StatusOK, opname, "stock opname detail", nil)
+	case http.MethodDelete:
+		if err := h.service.DeleteStockOpname(id, *user.CompanyID); err != nil {
+			writeStockOpnameError(w, err, "failed to delete stock opname")
+			return
+		}
+		writeSuccess(w, http.StatusOK, nil, "stock opname deleted", nil)
+	default:
+		w.Header().Set("Allow", "GET, DELETE")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+	}
+}
+
+func (h *StockOpnameHandler) SaveItems(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	if r.Method != http.MethodPut {
+		w.Header().Set("Allow", "PUT")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	id := strings.TrimSpace(r.PathValue("id"))
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "id cannot be empty")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "failed to read body")
+		return
+	}
+
+	var input models.StockOpnameItemsInput
+	if err := json.Unmarshal(body, &input); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+		return
+	}
+
+	opname, err := h.service.SaveItems(id, *user.CompanyID, input)
+	if err != nil {
+		writeStockOpnameError(w, err, "failed to save stock opname items")
+		return
+	}
+	writeSuccess(w, http.StatusOK, opname, "stock opname items saved", nil)
+}
+
+func (h *StockOpnameHandler) DeleteItem(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	if r.Method != http.MethodDelete {
+		w.Header().Set("Allow", "DELETE")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	id := strings.TrimSpace(r.PathValue("id"))
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "id cannot be empty")
+		return
+	}
+
+	opname, err := h.service.DeleteItem(id, *user.CompanyID, r.PathValue("product_id"))
+	if err != nil {
+		writeStockOpnameError(w, err, "failed to delete stock opname item")
+		return
+	}
+	writeSuccess(w, http.StatusOK, opname, "stock opname item deleted", nil)
+}
+
+func (h *StockOpnameHandler) Post(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	id := strings.TrimSpace(r.PathValue("id"))
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "id cannot be empty")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "failed to read body")
+		return
+	}
+
+	var input models.StockOpnamePostInput
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &input); err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+			return
+		}
+	}
+
+	opname, err := h.service.PostStockOpname(id, *user.CompanyID, user.ID, input)
+	if err != nil {
+		writeStockOpnameError(w, err, "failed to post stock opname")
+		return
+	}
+	writeSuccess(w, http.StatusOK, opname, "stock opname posted", nil)
+}
+
+func writeStockOpnameError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "stock opname not found")
+	case errors.Is(err, services.ErrStockOpnameOutletRequired),
+		errors.Is(err, services.ErrStockOpnameOutletNotFound),
+		errors.Is(err, services.ErrStockOpnameItemsRequired),
+		errors.Is(err, services.ErrStockOpnameProductRequired),
+		errors.Is(err, services.ErrStockOpnameProductNotFound),
+		errors.Is(err, services.ErrStockOpnameQtyInvalid),
+		errors.Is(err, services.ErrStockOpnameNoItems),
+		errors.Is(err, services.ErrStockOpnameReasonRequired),
+		errors.Is(err, services.ErrUnitConversionUnitNotFound),
+		errors.Is(err, services.ErrUnitConversionIncompatible),
+		errors.Is(err, services.ErrUnitQtyPrecision):
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	case errors.Is(err, services.ErrStockOpnameNotDraft):
+		writeError(w, http.StatusConflict, "CONFLICT", err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", fallback)
+	}
+}
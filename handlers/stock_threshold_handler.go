@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"gowes/models"
+	"gowes/services"
+	"gowes/utils"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type StockThresholdHandler struct {
+	service services.StockThresholdService
+}
+
+func NewStockThresholdHandler(service services.StockThresholdService) *StockThresholdHandler {
+	return &StockThresholdHandler{service: service}
+}
+
+// ListOrSave: GET daftar batas stok, POST menyimpan (membuat atau menimpa) batas stok produk di outlet.
+func (h *StockThresholdHandler) ListOrSave(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		params := utils.ParsePaginationParams(r)
+		filter := models.StockThresholdFilter{
+			OutletID:  strings.TrimSpace(r.URL.Query().Get("outlet_id")),
+			ProductID: strings.TrimSpace(r.URL.Query().Get("product_id")),
+		}
+		thresholds, total, err := h.service.ListThresholds(*user.CompanyID, params, filter)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to list stock thresholds")
+			return
+		}
+		meta := utils.CalculateMeta(total, params)
+		writeSuccess(w, http.StatusOK, thresholds, "stock threshold list", meta)
+	case http.MethodPost:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "failed to read body")
+			return
+		}
+
+		var input models.StockThresholdInput
+		if err := json.Unmarshal(body, &input); err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+			return
+		}
+
+		threshold, err := h.service.SaveThreshold(*user.CompanyID, input)
+		if err != nil {
+			writeStockThresholdError(w, err, "failed to save stock threshold")
+			return
+		}
+		writeSuccess(w, http.StatusOK, threshold, "stock threshold saved", nil)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+	}
+}
+
+func (h *StockThresholdHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	id := strings.TrimSpace(r.PathValue("id"))
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "id cannot be empty")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		threshold, err := h.service.GetThreshold(id, *user.CompanyID)
+		if err != nil {
+			writeStockThresholdError(w, err, "failed to get stock threshold")
+			return
+		}
+		writeSuccess(w, http.StatusOK, threshold, "stock threshold detail", nil)
+	case http.MethodDelete:
+		if err := h.service.DeleteThreshold(id, *user.CompanyID); err != nil {
+			writeStockThresholdError(w, err, "failed to delete stock threshold")
+			return
+		}
+		writeSuccess(w, http.StatusOK, nil, "stock threshold deleted", nil)
+	default:
+		w.Header().Set("Allow", "GET, DELETE")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+	}
+}
+
+func (h *StockThresholdHandler) LowStock(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	filter, ok := parseLowStockFilter(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := h.service.ListLowStock(*user.CompanyID, filter)
+	if err != nil {
+		writeStockThresholdError(w, err, "failed to list low stock")
+		return
+	}
+	writeSuccess(w, http.StatusOK, items, "low stock list", nil)
+}
+
+func (h *StockThresholdHandler) ReorderSuggestions(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	filter, ok := parseLowStockFilter(w, r)
+	if !ok {
+		return
+	}
+
+	suggestions, err := h.service.ReorderSuggestions(*user.CompanyID, filter)
+	if err != nil {
+		writeStockThresholdError(w, err, "failed to build reorder suggestions")
+		return
+	}
+	writeSuccess(w, http.StatusOK, suggestions, "reorder suggestions", nil)
+}
+
+// parseLowStockFilter membaca outlet_id, days, dan cover_days; menulis error 400 jika tidak valid.
+func parseLowStockFilter(w http.ResponseWriter, r *http.Request) (models.LowStockFilter, bool) {
+	query := r.URL.Query()
+	filter := models.LowStockFilter{OutletID: strings.TrimSpace(query.Get("outlet_id"))}
+	if raw := strings.TrimSpace(query.Get("days")); raw != "" {
+		days, err := strconv.Atoi(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "days must be a number")
+			return models.LowStockFilter{}, false
+		}
+		filter.Days = days
+	}
+	if raw := strings.TrimSpace(query.Get("cover_days")); raw != "" {
+		coverDays, err := strconv.Atoi(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "cover_days must be a number")
+			return models.LowStockFilter{}, false
+		}
+		filter.CoverDays = coverDays
+	}
+	return filter, true
+}
+
+func writeStockThresholdError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "stock threshold not found")
+	case errors.Is(err, services.ErrStockThresholdProductRequired),
+		errors.Is(err, services.ErrStockThresholdProductNotFound),
+		errors.Is(err, services.ErrStockThresholdOutletRequired),
+		errors.Is(err, services.ErrStockThresholdOutletNotFound),
+		errors.Is(err, services.ErrStockThresholdQtyInvalid),
+		errors.Is(err, services.ErrStockThresholdMaxBelowMin),
+		errors.Is(err, services.ErrStockThresholdSupplierNotFound),
+		errors.Is(err, services.ErrStockThresholdSupplierInactive),
+		errors.Is(err, services.ErrStockThresholdDaysInvalid):
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", fallback)
+	}
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"gowes/models"
+	"gowes/services"
+	"gowes/utils"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type StockTransferHandler struct {
+	service services.StockTransferService
+}
+
+func NewStockTransferHandler(service services.StockTransferService) *StockTransferHandler {
+	return &StockTransferHandler{service: service}
+}
+
+func (h *StockTransferHandler) ListOrCreate(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		params := utils.ParsePaginationParams(r)
+		filter := models.StockTransferFilter{
+			OutletID: strings.TrimSpace(r.URL.Query().Get("outlet_id")),
+			Status:   strings.TrimSpace(r.URL.Query().Get("status")),
+		}
+		transfers, total, err := h.service.ListStockTransfers(*user.CompanyID, params, filter)
+		if err != nil {
+			if errors.Is(err, services.ErrStockTransferStatusInvalid) {
+				writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+				return
+			}
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to list stock transfers")
+			return
+		}
+		meta := utils.CalculateMeta(total, params)
+		writeSuccess(w, http.StatusOK, transfers, "stock transfer list", meta)
+	case http.MethodPost:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "failed to read body")
+			return
+		}
+
+		var input models.StockTransferInput
+		if err := json.Unmarshal(body, &input); err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+			return
+		}
+
+		transfer, err := h.service.CreateStockTransfer(*user.CompanyID, user.ID, input)
+		if err != nil {
+			writeStockTransferError(w, err, "failed to create stock transfer")
+			return
+		}
+		writeSuccess(w, http.StatusCreated, transfer, "stock transfer created", nil)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+	}
+}
+
+func (h *StockTransferHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	id := strings.TrimSpace(r.PathValue("id"))
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "id cannot be empty")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		transfer, err := h.service.GetStockTransfer(id, *user.CompanyID)
+		if err != nil {
+			writeStockTransferError(w, err, "failed to get stock transfer")
+			return
+		}
+		writeSuccess(w, http.StatusOK, transfer, "stock transfer detail", nil)
+	case http.MethodPut:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "failed to read body")
+			return
+		}
+
+		var input models.StockTransferInput
+		if err := json.Unmarshal(body, &input); err != nil {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+			return
+		}
+
+		transfer, err := h.service.UpdateStockTransfer(id, *user.CompanyID, input)
+		if err != nil {
+			writeStockTransferError(w, err, "failed to update stock transfer")
+			return
+		}
+		writeSuccess(w, http.StatusOK, transfer, "stock transfer updated", nil)
+	case http.MethodDelete:
+		if err := h.service.DeleteStockTransfer(id, *user.CompanyID); err != nil {
+			writeStockTransferError(w, err, "failed to delete stock transfer")
+			return
+		}
+		writeSuccess(w, http.StatusOK, nil, "stock transfer deleted", nil)
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+	}
+}
+
+func (h *StockTransferHandler) Send(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	id := strings.TrimSpace(r.PathValue("id"))
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "id cannot be empty")
+		return
+	}
+
+	transfer, err := h.service.SendStockTransfer(id, *user.CompanyID, user.ID)
+	if err != nil {
+		writeStockTransferError(w, err, "failed to send stock transfer")
+		return
+	}
+	writeSuccess(w, http.StatusOK, transfer, "stock transfer sent", nil)
+}
+
+func (h *StockTransferHandler) Receive(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	id := strings.TrimSpace(r.PathValue("id"))
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "id cannot be empty")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "failed to read body")
+		return
+	}
+
+	var input models.StockTransferReceiveInput
+	if err := json.Unmarshal(body, &input); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+		return
+	}
+
+	transfer, err := h.service.ReceiveStockTransfer(id, *user.CompanyID, user.ID, input)
+	if err != nil {
+		writeStockTransferError(w, err, "failed to receive stock transfer")
+		return
+	}
+	writeSuccess(w, http.StatusOK, transfer, "stock transfer received", nil)
+}
+
+func writeStockTransferError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "stock transfer not found")
+	case errors.Is(err, services.ErrStockTransferSourceRequired),
+		errors.Is(err, services.ErrStockTransferDestinationRequired),
+		errors.Is(err, services.ErrStockTransferSameOutlet),
+		errors.Is(err, services.ErrStockTransferOutletNotFound),
+		errors.Is(err, services.ErrStockTransferItemsRequired),
+		errors.Is(err, services.ErrStockTransferProductRequired),
+		errors.Is(err, services.ErrStockTransferProductNotFound),
+		errors.Is(err, services.ErrStockTransferQtyInvalid),
+		errors.Is(err, services.ErrStockTransferReceiveQtyInvalid),
+		errors.Is(err, services.ErrStockTransferReceiveEmpty),
+		errors.Is(err, services.ErrStockTransferItemNotFound),
+		errors.Is(err, services.ErrStockTransferReceiveExceeds),
+		errors.Is(err, services.ErrStockTransferDiscrepancyNote),
+		errors.Is(err, services.ErrUnitConversionUnitNotFound),
+		errors.Is(err, services.ErrUnitConversionIncompatible),
+		errors.Is(err, services.ErrUnitQtyPrecision):
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	case errors.Is(err, services.ErrStockTransferNotDraft),
+		errors.Is(err, services.ErrStockTransferNotInTransit),
+		errors.Is(err, services.ErrStockTransferInsufficientStock):
+		writeError(w, http.StatusConflict, "CONFLICT", err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", fallback)
+	}
+}
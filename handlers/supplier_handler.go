@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"gowes/models"
 	"gowes/services"
 	"gowes/utils"
@@ -53,6 +54,10 @@ func (h *SupplierHandler) ListOrCreate(w http.ResponseWriter, r *http.Request) {
 
 		supplier, err := h.service.CreateSupplier(*user.CompanyID, user.ID, input)
 		if err != nil {
+			if errors.Is(err, services.ErrSupplierPaymentTermInvalid) {
+				writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+				return
+			}
 			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to create supplier")
 			return
 		}
@@ -102,6 +107,10 @@ func (h *SupplierHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
 
 		supplier, err := h.service.UpdateSupplier(id, *user.CompanyID, user.ID, input)
 		if err != nil {
+			if errors.Is(err, services.ErrSupplierPaymentTermInvalid) {
+				writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+				return
+			}
 			writeError(w, http.StatusNotFound, "NOT_FOUND", "supplier not found")
 			return
 		}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"strings"
@@ -64,6 +65,10 @@ func (h *TaxHandler) ListOrCreate(w http.ResponseWriter, r *http.Request) {
 
 		tax, err := h.service.CreateTax(*user.CompanyID, input)
 		if err != nil {
+			if isTaxValidationError(err) {
+				writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+				return
+			}
 			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to create tax")
 			return
 		}
@@ -76,6 +81,12 @@ func (h *TaxHandler) ListOrCreate(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *TaxHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.CompanyID == nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "company info missing")
+		return
+	}
+
 	id := strings.TrimSpace(r.PathValue("id"))
 	if id == "" {
 		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "id cannot be empty")
@@ -116,8 +127,12 @@ func (h *TaxHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		updated, err := h.service.UpdateTax(id, input)
+		updated, err := h.service.UpdateTax(id, *user.CompanyID, input)
 		if err != nil {
+			if isTaxValidationError(err) {
+				writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+				return
+			}
 			writeError(w, http.StatusNotFound, "NOT_FOUND", "tax not found")
 			return
 		}
@@ -135,3 +150,11 @@ func (h *TaxHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
 	}
 }
+
+func isTaxValidationError(err error) bool {
+	return errors.Is(err, services.ErrTaxTypeInvalid) ||
+		errors.Is(err, services.ErrTaxTargetInvalid) ||
+		errors.Is(err, services.ErrTaxOutletNotFound) ||
+		errors.Is(err, services.ErrTaxProductNotFound) ||
+		errors.Is(err, services.ErrTaxCategoryNotFound)
+}
@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"gowes/models"
 	"gowes/services"
 	"gowes/utils"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
@@ -63,7 +66,7 @@ func (h *UnitHandler) ListOrCreate(w http.ResponseWriter, r *http.Request) {
 
 		unit, err := h.service.CreateUnit(*user.CompanyID, user.ID, input)
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+			writeUnitError(w, err, "failed to create unit")
 			return
 		}
 
@@ -123,7 +126,7 @@ func (h *UnitHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
 
 		unit, err := h.service.UpdateUnit(id, *user.CompanyID, user.ID, input)
 		if err != nil {
-			writeError(w, http.StatusNotFound, "NOT_FOUND", "unit not found")
+			writeUnitError(w, err, "failed to update unit")
 			return
 		}
 		writeSuccess(w, http.StatusOK, unit, "unit updated", nil)
@@ -138,3 +141,53 @@ func (h *UnitHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
 	}
 }
+
+func (h *UnitHandler) Convert(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	qty, err := strconv.ParseFloat(strings.TrimSpace(query.Get("qty")), 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "qty must be a number")
+		return
+	}
+
+	conversion, err := h.service.ConvertUnit(*user.CompanyID, query.Get("from_unit_id"), query.Get("to_unit_id"), qty)
+	if err != nil {
+		writeUnitError(w, err, "failed to convert unit")
+		return
+	}
+	writeSuccess(w, http.StatusOK, conversion, "unit conversion", nil)
+}
+
+func writeUnitError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "unit not found")
+	case errors.Is(err, services.ErrUnitConversionUnitNotFound):
+		writeError(w, http.StatusNotFound, "NOT_FOUND", err.Error())
+	case errors.Is(err, services.ErrUnitNameRequired),
+		errors.Is(err, services.ErrUnitSymbolRequired),
+		errors.Is(err, services.ErrUnitTypeRequired),
+		errors.Is(err, services.ErrUnitTypeInvalid),
+		errors.Is(err, services.ErrUnitFactorInvalid),
+		errors.Is(err, services.ErrUnitDecimalInvalid),
+		errors.Is(err, services.ErrUnitConvertQty),
+		errors.Is(err, services.ErrUnitConversionIncompatible):
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	case errors.Is(err, services.ErrUnitInUse):
+		writeError(w, http.StatusConflict, "CONFLICT", err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", fallback)
+	}
+}
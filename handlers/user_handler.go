@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"gowes/models"
+	"gowes/services"
+	"gowes/utils"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type UserHandler struct {
+	service services.UserService
+}
+
+func NewUserHandler(service services.UserService) *UserHandler {
+	return &UserHandler{service: service}
+}
+
+// ListOrCreate: GET daftar user company, POST menambah staff (cashier / waiter). Butuh permission user.manage.
+func (h *UserHandler) ListOrCreate(w http.ResponseWriter, r *http.Request) {
+	user, ok := companyUser(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		params := utils.ParsePaginationParams(r)
+		filter, err := utils.ParseFilterParams(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+			return
+		}
+		users, total, err := h.service.ListUsers(*user.CompanyID, params, filter)
+		if err != nil {
+			writeUserError(w, err, "failed to list users")
+			return
+		}
+		meta := utils.CalculateMeta(total, params)
+		writeSuccess(w, http.StatusOK, users, "user list", meta)
+	case http.MethodPost:
+		var input models.UserInput
+		if !decodeJSONBody(w, r, &input) {
+			return
+		}
+		created, err := h.service.CreateUser(*user.CompanyID, user, input)
+		if err != nil {
+			writeUserError(w, err, "failed to create user")
+			return
+		}
+		writeSuccess(w, http.StatusCreated, created, "user created", nil)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+	}
+}
+
+func (h *UserHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
+	user, ok := companyUser(w, r)
+	if !ok {
+		return
+	}
+
+	id := strings.TrimSpace(r.PathValue("id"))
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "id cannot be empty")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		found, err := h.service.GetUser(id, *user.CompanyID)
+		if err != nil {
+			writeUserError(w, err, "failed to get user")
+			return
+		}
+		writeSuccess(w, http.StatusOK, found, "user detail", nil)
+	case http.MethodPut:
+		var input models.UserUpdateInput
+		if !decodeJSONBody(w, r, &input) {
+			return
+		}
+		updated, err := h.service.UpdateUser(id, *user.CompanyID, user, input)
+		if err != nil {
+			writeUserError(w, err, "failed to update user")
+			return
+		}
+		writeSuccess(w, http.StatusOK, updated, "user updated", nil)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+	}
+}
+
+func (h *UserHandler) Activate(w http.ResponseWriter, r *http.Request) {
+	h.setActive(w, r, true)
+}
+
+func (h *UserHandler) Deactivate(w http.ResponseWriter, r *http.Request) {
+	h.setActive(w, r, false)
+}
+
+func (h *UserHandler) setActive(w http.ResponseWriter, r *http.Request, active bool) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+	user, ok := companyUser(w, r)
+	if !ok {
+		return
+	}
+
+	updated, err := h.service.SetUserActive(strings.TrimSpace(r.PathValue("id")), *user.CompanyID, user.ID, active)
+	if err != nil {
+		writeUserError(w, err, "failed to update user status")
+		return
+	}
+	message := "user deactivated"
+	if active {
+		message = "user activated"
+	}
+	writeSuccess(w, http.StatusOK, updated, message, nil)
+}
+
+func (h *UserHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+	user, ok := companyUser(w, r)
+	if !ok {
+		return
+	}
+
+	var input models.UserPasswordInput
+	if !decodeJSONBody(w, r, &input) {
+		return
+	}
+	if err := h.service.ResetUserPassword(strings.TrimSpace(r.PathValue("id")), *user.CompanyID, input); err != nil {
+		writeUserError(w, err, "failed to reset user password")
+		return
+	}
+	writeSuccess(w, http.StatusOK, nil, "user password reset", nil)
+}
+
+func (h *UserHandler) ResetPIN(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+	user, ok := companyUser(w, r)
+	if !ok {
+		return
+	}
+
+	var input models.UserPINInput
+	if !decodeJSONBody(w, r, &input) {
+		return
+	}
+	if err := h.service.ResetUserPIN(strings.TrimSpace(r.PathValue("id")), *user.CompanyID, input); err != nil {
+		writeUserError(w, err, "failed to reset user pin")
+		return
+	}
+	writeSuccess(w, http.StatusOK, nil, "user pin reset", nil)
+}
+
+// companyUser mengambil user dari context; hak akses dicek di route lewat Middleware.RequirePermission.
+func companyUser(w http.ResponseWriter, r *http.Request) (models.User, bool) {
+	user, ok := r.Context().Value(UserContextKey).(models.User)
+	if !ok || user.ID == "" || user.CompanyID == nil || *user.CompanyID == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user or company info missing")
+		return models.User{}, false
+	}
+	return user, true
+}
+
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, input interface{}) bool {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "failed to read body")
+		return false
+	}
+	if err := json.Unmarshal(body, input); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON format")
+		return false
+	}
+	return true
+}
+
+func writeUserError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "user not found")
+	case errors.Is(err, services.ErrUserUsernameRequired),
+		errors.Is(err, services.ErrUserEmailRequired),
+		errors.Is(err, services.ErrUserRoleInvalid),
+		errors.Is(err, services.ErrUserPINInvalid),
+		errors.Is(err, services.ErrUserRoleNotFound),
+		errors.Is(err, services.ErrPasswordTooShort):
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	case errors.Is(err, services.ErrUserEmailTaken),
+		errors.Is(err, services.ErrUserUsernameTaken):
+		writeError(w, http.StatusConflict, "CONFLICT", err.Error())
+	case errors.Is(err, services.ErrUserOwnerLocked),
+		errors.Is(err, services.ErrUserSelfDeactivate),
+		errors.Is(err, services.ErrUserRoleAdminOnly),
+		errors.Is(err, services.ErrUserSelfRoleChange):
+		writeError(w, http.StatusForbidden, "FORBIDDEN", err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", fallback)
+	}
+}
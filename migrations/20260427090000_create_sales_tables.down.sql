@@ -0,0 +1,16 @@
+DROP INDEX IF EXISTS idx_sale_detail_add_ons_add_on_id;
+DROP INDEX IF EXISTS idx_sale_detail_add_ons_sale_detail_id;
+DROP TABLE IF EXISTS sale_detail_add_ons;
+
+DROP INDEX IF EXISTS idx_sale_details_product_id;
+DROP INDEX IF EXISTS idx_sale_details_sale_id;
+DROP TABLE IF EXISTS sale_details;
+
+DROP INDEX IF EXISTS idx_sales_created_at;
+DROP INDEX IF EXISTS idx_sales_status;
+DROP INDEX IF EXISTS idx_sales_customer_id;
+DROP INDEX IF EXISTS idx_sales_order_type_id;
+DROP INDEX IF EXISTS idx_sales_outlet_id;
+DROP INDEX IF EXISTS idx_sales_user_id;
+DROP INDEX IF EXISTS idx_sales_company_id;
+DROP TABLE IF EXISTS sales;
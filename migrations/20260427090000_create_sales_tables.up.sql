@@ -0,0 +1,53 @@
+CREATE TABLE IF NOT EXISTS sales (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id UUID NOT NULL REFERENCES company(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    outlet_id UUID NOT NULL REFERENCES outlets(id) ON DELETE RESTRICT,
+    order_type_id UUID REFERENCES order_types(id) ON DELETE SET NULL,
+    customer_id UUID REFERENCES customers(id) ON DELETE SET NULL,
+    payment_method VARCHAR(50) NOT NULL,
+    subtotal NUMERIC(15,2) NOT NULL DEFAULT 0,
+    discount_bill NUMERIC(15,2) NOT NULL DEFAULT 0,
+    tax_value NUMERIC(15,2) NOT NULL DEFAULT 0,
+    grand_total NUMERIC(15,2) NOT NULL DEFAULT 0,
+    paid_amount NUMERIC(15,2) NOT NULL DEFAULT 0,
+    change_amount NUMERIC(15,2) NOT NULL DEFAULT 0,
+    status VARCHAR(50) NOT NULL,
+    note TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_sales_company_id ON sales(company_id);
+CREATE INDEX IF NOT EXISTS idx_sales_user_id ON sales(user_id);
+CREATE INDEX IF NOT EXISTS idx_sales_outlet_id ON sales(outlet_id);
+CREATE INDEX IF NOT EXISTS idx_sales_order_type_id ON sales(order_type_id);
+CREATE INDEX IF NOT EXISTS idx_sales_customer_id ON sales(customer_id);
+CREATE INDEX IF NOT EXISTS idx_sales_status ON sales(status);
+CREATE INDEX IF NOT EXISTS idx_sales_created_at ON sales(created_at);
+
+CREATE TABLE IF NOT EXISTS sale_details (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    sale_id UUID NOT NULL REFERENCES sales(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    price NUMERIC(15,2) NOT NULL DEFAULT 0,
+    add_on_total NUMERIC(15,2) NOT NULL DEFAULT 0,
+    total NUMERIC(15,2) NOT NULL DEFAULT 0,
+    note TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_sale_details_sale_id ON sale_details(sale_id);
+CREATE INDEX IF NOT EXISTS idx_sale_details_product_id ON sale_details(product_id);
+
+CREATE TABLE IF NOT EXISTS sale_detail_add_ons (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    sale_detail_id UUID NOT NULL REFERENCES sale_details(id) ON DELETE CASCADE,
+    add_on_id UUID NOT NULL REFERENCES add_ons(id) ON DELETE RESTRICT,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    price NUMERIC(15,2) NOT NULL DEFAULT 0,
+    total NUMERIC(15,2) NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_sale_detail_add_ons_sale_detail_id ON sale_detail_add_ons(sale_detail_id);
+CREATE INDEX IF NOT EXISTS idx_sale_detail_add_ons_add_on_id ON sale_detail_add_ons(add_on_id);
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_discount_schedules_discount_id;
+DROP TABLE IF EXISTS discount_schedules;
+
+DROP INDEX IF EXISTS idx_discounts_is_active;
+
+ALTER TABLE discounts
+DROP CONSTRAINT IF EXISTS chk_discounts_period;
+
+ALTER TABLE discounts
+DROP COLUMN IF EXISTS is_active,
+DROP COLUMN IF EXISTS end_at,
+DROP COLUMN IF EXISTS start_at;
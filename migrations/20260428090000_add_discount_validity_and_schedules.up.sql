@@ -0,0 +1,30 @@
+-- ============================================================
+-- Masa berlaku & status aktif diskon
+-- ============================================================
+ALTER TABLE discounts
+ADD COLUMN IF NOT EXISTS start_at TIMESTAMP WITH TIME ZONE NULL,
+ADD COLUMN IF NOT EXISTS end_at TIMESTAMP WITH TIME ZONE NULL,
+ADD COLUMN IF NOT EXISTS is_active BOOLEAN NOT NULL DEFAULT TRUE;
+
+ALTER TABLE discounts
+ADD CONSTRAINT chk_discounts_period CHECK (start_at IS NULL OR end_at IS NULL OR end_at > start_at);
+
+CREATE INDEX IF NOT EXISTS idx_discounts_is_active ON discounts(is_active);
+
+
+-- ============================================================
+-- Jadwal mingguan diskon (contoh: happy hour)
+--    day_of_week: 0 = Minggu ... 6 = Sabtu
+--    end_time < start_time berarti jadwal melewati tengah malam
+-- ============================================================
+CREATE TABLE IF NOT EXISTS discount_schedules (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    discount_id UUID NOT NULL REFERENCES discounts(id) ON DELETE CASCADE,
+    day_of_week SMALLINT NOT NULL CHECK (day_of_week BETWEEN 0 AND 6),
+    start_time  TIME NOT NULL,
+    end_time    TIME NOT NULL,
+
+    CONSTRAINT chk_discount_schedules_time CHECK (start_time <> end_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_discount_schedules_discount_id ON discount_schedules(discount_id);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_promo_code_redemptions_customer_id;
+DROP INDEX IF EXISTS idx_promo_code_redemptions_promo_code_id;
+DROP TABLE IF EXISTS promo_code_redemptions;
+
+DROP INDEX IF EXISTS idx_promo_codes_discount_id;
+DROP INDEX IF EXISTS idx_promo_codes_company_id;
+DROP TABLE IF EXISTS promo_codes;
@@ -0,0 +1,38 @@
+-- ============================================================
+-- 1. Kode promo / voucher yang terhubung ke diskon
+--    max_uses NULL = tanpa batas, 1 = sekali pakai
+-- ============================================================
+CREATE TABLE IF NOT EXISTS promo_codes (
+    id                    UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id            UUID NOT NULL REFERENCES company(id) ON DELETE CASCADE,
+    discount_id           UUID NOT NULL REFERENCES discounts(id) ON DELETE CASCADE,
+    code                  VARCHAR(50) NOT NULL,
+    max_uses              INTEGER NULL CHECK (max_uses IS NULL OR max_uses > 0),
+    max_uses_per_customer INTEGER NULL CHECK (max_uses_per_customer IS NULL OR max_uses_per_customer > 0),
+    used_count            INTEGER NOT NULL DEFAULT 0 CHECK (used_count >= 0),
+    is_active             BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at            TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at            TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uq_promo_codes_company_code UNIQUE (company_id, code)
+);
+
+CREATE INDEX IF NOT EXISTS idx_promo_codes_company_id ON promo_codes(company_id);
+CREATE INDEX IF NOT EXISTS idx_promo_codes_discount_id ON promo_codes(discount_id);
+
+
+-- ============================================================
+-- 2. Ledger pemakaian kode promo
+-- ============================================================
+CREATE TABLE IF NOT EXISTS promo_code_redemptions (
+    id            UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    promo_code_id UUID NOT NULL REFERENCES promo_codes(id) ON DELETE CASCADE,
+    company_id    UUID NOT NULL REFERENCES company(id) ON DELETE CASCADE,
+    user_id       UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    customer_id   UUID NULL REFERENCES customers(id) ON DELETE SET NULL,
+    sale_id       UUID NULL REFERENCES sales(id) ON DELETE SET NULL,
+    redeemed_at   TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_promo_code_redemptions_promo_code_id ON promo_code_redemptions(promo_code_id);
+CREATE INDEX IF NOT EXISTS idx_promo_code_redemptions_customer_id ON promo_code_redemptions(customer_id);
@@ -0,0 +1,25 @@
+DROP INDEX IF EXISTS idx_sale_taxes_sale_id;
+DROP TABLE IF EXISTS sale_taxes;
+
+DROP INDEX IF EXISTS idx_purchase_taxes_purchase_id;
+DROP TABLE IF EXISTS purchase_taxes;
+
+ALTER TABLE purchases
+DROP COLUMN IF EXISTS subtotal;
+
+DROP INDEX IF EXISTS idx_tax_categories_tax_id;
+DROP TABLE IF EXISTS tax_categories;
+
+DROP INDEX IF EXISTS idx_tax_products_tax_id;
+DROP TABLE IF EXISTS tax_products;
+
+DROP INDEX IF EXISTS idx_tax_outlets_tax_id;
+DROP TABLE IF EXISTS tax_outlets;
+
+ALTER TABLE taxes
+DROP COLUMN IF EXISTS is_active,
+DROP COLUMN IF EXISTS target_type,
+DROP COLUMN IF EXISTS is_compound,
+DROP COLUMN IF EXISTS sequence,
+DROP COLUMN IF EXISTS is_inclusive,
+DROP COLUMN IF EXISTS type;
@@ -0,0 +1,87 @@
+-- ============================================================
+-- 1. Aturan pajak
+--    type        : vat (PPN/PB1) | service_charge
+--    is_inclusive: harga jual sudah termasuk pajak
+--    sequence    : urutan perhitungan (kecil dihitung lebih dulu)
+--    is_compound : dihitung dari subtotal + pajak eksklusif sebelumnya
+--    target_type : all | product | category
+-- ============================================================
+ALTER TABLE taxes
+ADD COLUMN IF NOT EXISTS type VARCHAR(30) NOT NULL DEFAULT 'vat'
+    CHECK (type IN ('vat', 'service_charge')),
+ADD COLUMN IF NOT EXISTS is_inclusive BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN IF NOT EXISTS sequence INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS is_compound BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN IF NOT EXISTS target_type VARCHAR(20) NOT NULL DEFAULT 'all'
+    CHECK (target_type IN ('all', 'product', 'category')),
+ADD COLUMN IF NOT EXISTS is_active BOOLEAN NOT NULL DEFAULT TRUE;
+
+
+-- ============================================================
+-- 2. Junction table pajak ↔ outlets / products / categories
+--    Tanpa outlet berarti berlaku di semua outlet
+-- ============================================================
+CREATE TABLE IF NOT EXISTS tax_outlets (
+    id        UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tax_id    UUID NOT NULL REFERENCES taxes(id)   ON DELETE CASCADE,
+    outlet_id UUID NOT NULL REFERENCES outlets(id) ON DELETE CASCADE,
+
+    CONSTRAINT uq_tax_outlet UNIQUE (tax_id, outlet_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tax_outlets_tax_id ON tax_outlets(tax_id);
+
+CREATE TABLE IF NOT EXISTS tax_products (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tax_id     UUID NOT NULL REFERENCES taxes(id)    ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+
+    CONSTRAINT uq_tax_product UNIQUE (tax_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tax_products_tax_id ON tax_products(tax_id);
+
+CREATE TABLE IF NOT EXISTS tax_categories (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tax_id      UUID NOT NULL REFERENCES taxes(id)      ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+
+    CONSTRAINT uq_tax_category UNIQUE (tax_id, category_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tax_categories_tax_id ON tax_categories(tax_id);
+
+
+-- ============================================================
+-- 3. Rincian pajak per transaksi
+-- ============================================================
+ALTER TABLE purchases
+ADD COLUMN IF NOT EXISTS subtotal NUMERIC(15, 2) NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS purchase_taxes (
+    id             UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    purchase_id    UUID NOT NULL REFERENCES purchases(id) ON DELETE CASCADE,
+    tax_id         UUID NULL REFERENCES taxes(id) ON DELETE SET NULL,
+    name           VARCHAR(255) NOT NULL,
+    type           VARCHAR(30) NOT NULL,
+    rate           NUMERIC(5, 2) NOT NULL DEFAULT 0,
+    is_inclusive   BOOLEAN NOT NULL DEFAULT FALSE,
+    taxable_amount NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    amount         NUMERIC(15, 2) NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_taxes_purchase_id ON purchase_taxes(purchase_id);
+
+CREATE TABLE IF NOT EXISTS sale_taxes (
+    id             UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    sale_id        UUID NOT NULL REFERENCES sales(id) ON DELETE CASCADE,
+    tax_id         UUID NULL REFERENCES taxes(id) ON DELETE SET NULL,
+    name           VARCHAR(255) NOT NULL,
+    type           VARCHAR(30) NOT NULL,
+    rate           NUMERIC(5, 2) NOT NULL DEFAULT 0,
+    is_inclusive   BOOLEAN NOT NULL DEFAULT FALSE,
+    taxable_amount NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    amount         NUMERIC(15, 2) NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_sale_taxes_sale_id ON sale_taxes(sale_id);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_outlet_products_product_id;
+DROP INDEX IF EXISTS idx_outlet_products_company_id;
+
+ALTER TABLE outlet_products DROP CONSTRAINT IF EXISTS uq_outlet_products_outlet_product;
+
+ALTER TABLE outlet_products ALTER COLUMN is_active DROP NOT NULL;
+UPDATE outlet_products SET price = 0 WHERE price IS NULL;
+UPDATE outlet_products SET cost = 0 WHERE cost IS NULL;
+ALTER TABLE outlet_products ALTER COLUMN price SET DEFAULT 0;
+ALTER TABLE outlet_products ALTER COLUMN cost SET DEFAULT 0;
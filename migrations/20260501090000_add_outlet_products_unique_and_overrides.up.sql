@@ -0,0 +1,22 @@
+-- ============================================================
+-- Harga / HPP per outlet bersifat override:
+-- NULL = memakai harga / HPP master produk
+-- ============================================================
+ALTER TABLE outlet_products ALTER COLUMN price DROP DEFAULT;
+ALTER TABLE outlet_products ALTER COLUMN cost DROP DEFAULT;
+UPDATE outlet_products SET price = NULL WHERE price = 0;
+UPDATE outlet_products SET cost = NULL WHERE cost = 0;
+ALTER TABLE outlet_products ALTER COLUMN is_active SET NOT NULL;
+
+-- Satu produk hanya punya satu pengaturan per outlet
+DELETE FROM outlet_products a
+USING outlet_products b
+WHERE a.outlet_id = b.outlet_id
+  AND a.product_id = b.product_id
+  AND (a.updated_at, a.id) < (b.updated_at, b.id);
+
+ALTER TABLE outlet_products
+    ADD CONSTRAINT uq_outlet_products_outlet_product UNIQUE (outlet_id, product_id);
+
+CREATE INDEX IF NOT EXISTS idx_outlet_products_company_id ON outlet_products(company_id);
+CREATE INDEX IF NOT EXISTS idx_outlet_products_product_id ON outlet_products(product_id);
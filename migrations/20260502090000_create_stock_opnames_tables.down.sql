@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_stock_opname_items_product_id;
+DROP TABLE IF EXISTS stock_opname_items;
+
+DROP INDEX IF EXISTS idx_stock_opnames_status;
+DROP INDEX IF EXISTS idx_stock_opnames_outlet_id;
+DROP INDEX IF EXISTS idx_stock_opnames_company_id;
+DROP TABLE IF EXISTS stock_opnames;
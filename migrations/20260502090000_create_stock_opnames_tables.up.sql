@@ -0,0 +1,39 @@
+-- ============================================================
+-- 1. Sesi stock opname (hitung fisik) per outlet
+--    status: draft -> posted
+-- ============================================================
+CREATE TABLE IF NOT EXISTS stock_opnames (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id UUID NOT NULL REFERENCES company(id) ON DELETE CASCADE,
+    outlet_id  UUID NOT NULL REFERENCES outlets(id) ON DELETE RESTRICT,
+    user_id    UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    status     VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'posted')),
+    note       TEXT,
+    posted_by  UUID NULL REFERENCES users(id) ON DELETE RESTRICT,
+    posted_at  TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_opnames_company_id ON stock_opnames(company_id);
+CREATE INDEX IF NOT EXISTS idx_stock_opnames_outlet_id ON stock_opnames(outlet_id);
+CREATE INDEX IF NOT EXISTS idx_stock_opnames_status ON stock_opnames(status);
+
+
+-- ============================================================
+-- 2. Hasil hitung per produk
+--    system_qty & variance diisi saat posting
+-- ============================================================
+CREATE TABLE IF NOT EXISTS stock_opname_items (
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    stock_opname_id UUID NOT NULL REFERENCES stock_opnames(id) ON DELETE CASCADE,
+    product_id      UUID NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    counted_qty     INT NOT NULL CHECK (counted_qty >= 0),
+    system_qty      INT,
+    variance        INT,
+    note            TEXT,
+
+    CONSTRAINT uq_stock_opname_items_product UNIQUE (stock_opname_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_opname_items_product_id ON stock_opname_items(product_id);
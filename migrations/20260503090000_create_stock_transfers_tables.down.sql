@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_stock_transfer_items_product_id;
+DROP TABLE IF EXISTS stock_transfer_items;
+
+DROP INDEX IF EXISTS idx_stock_transfers_status;
+DROP INDEX IF EXISTS idx_stock_transfers_destination_outlet_id;
+DROP INDEX IF EXISTS idx_stock_transfers_source_outlet_id;
+DROP INDEX IF EXISTS idx_stock_transfers_company_id;
+DROP TABLE IF EXISTS stock_transfers;
@@ -0,0 +1,46 @@
+-- ============================================================
+-- 1. Dokumen transfer stok antar outlet
+--    status: draft -> sent -> partially_received -> received
+-- ============================================================
+CREATE TABLE IF NOT EXISTS stock_transfers (
+    id                    UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id            UUID NOT NULL REFERENCES company(id) ON DELETE CASCADE,
+    source_outlet_id      UUID NOT NULL REFERENCES outlets(id) ON DELETE RESTRICT,
+    destination_outlet_id UUID NOT NULL REFERENCES outlets(id) ON DELETE RESTRICT,
+    user_id               UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    status                VARCHAR(20) NOT NULL DEFAULT 'draft'
+                          CHECK (status IN ('draft', 'sent', 'partially_received', 'received')),
+    note                  TEXT,
+    receive_note          TEXT,
+    sent_by               UUID NULL REFERENCES users(id) ON DELETE RESTRICT,
+    sent_at               TIMESTAMP WITH TIME ZONE,
+    received_by           UUID NULL REFERENCES users(id) ON DELETE RESTRICT,
+    received_at           TIMESTAMP WITH TIME ZONE,
+    created_at            TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at            TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_stock_transfers_outlets CHECK (source_outlet_id <> destination_outlet_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_company_id ON stock_transfers(company_id);
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_source_outlet_id ON stock_transfers(source_outlet_id);
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_destination_outlet_id ON stock_transfers(destination_outlet_id);
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_status ON stock_transfers(status);
+
+
+-- ============================================================
+-- 2. Baris transfer
+--    received_qty bertambah setiap penerimaan (parsial)
+-- ============================================================
+CREATE TABLE IF NOT EXISTS stock_transfer_items (
+    id                UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    stock_transfer_id UUID NOT NULL REFERENCES stock_transfers(id) ON DELETE CASCADE,
+    product_id        UUID NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    qty               INT NOT NULL CHECK (qty > 0),
+    received_qty      INT NOT NULL DEFAULT 0 CHECK (received_qty >= 0 AND received_qty <= qty),
+    discrepancy_note  TEXT,
+
+    CONSTRAINT uq_stock_transfer_items_product UNIQUE (stock_transfer_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_transfer_items_product_id ON stock_transfer_items(product_id);
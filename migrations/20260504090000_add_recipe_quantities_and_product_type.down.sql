@@ -0,0 +1,7 @@
+ALTER TABLE recipes DROP CONSTRAINT IF EXISTS uq_recipes_product_ingredient;
+ALTER TABLE recipes DROP CONSTRAINT IF EXISTS fk_recipes_ingredient;
+ALTER TABLE recipes DROP COLUMN IF EXISTS unit_id;
+ALTER TABLE recipes DROP COLUMN IF EXISTS quantity;
+
+DROP INDEX IF EXISTS idx_products_type;
+ALTER TABLE products DROP COLUMN IF EXISTS type;
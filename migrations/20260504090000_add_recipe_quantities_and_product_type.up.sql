@@ -0,0 +1,29 @@
+-- ============================================================
+-- 1. Jenis produk: bahan baku atau barang jadi
+-- ============================================================
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS type VARCHAR(20) NOT NULL DEFAULT 'finished_goods'
+    CHECK (type IN ('raw_material', 'finished_goods'));
+
+CREATE INDEX IF NOT EXISTS idx_products_type ON products(type);
+
+
+-- ============================================================
+-- 2. Resep (bill of materials): jumlah bahan per 1 produk jadi
+-- ============================================================
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS quantity INT NOT NULL DEFAULT 1 CHECK (quantity > 0);
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS unit_id UUID NULL REFERENCES units(id) ON DELETE SET NULL;
+
+-- Data lama belum tentu valid, jadi constraint tidak divalidasi ulang
+ALTER TABLE recipes
+    ADD CONSTRAINT fk_recipes_ingredient FOREIGN KEY (ingredient_id) REFERENCES products(id) ON DELETE RESTRICT NOT VALID;
+
+-- Satu bahan hanya muncul sekali di resep sebuah produk
+DELETE FROM recipes a
+USING recipes b
+WHERE a.product_id = b.product_id
+  AND a.ingredient_id = b.ingredient_id
+  AND (a.updated_at, a.id) < (b.updated_at, b.id);
+
+ALTER TABLE recipes
+    ADD CONSTRAINT uq_recipes_product_ingredient UNIQUE (product_id, ingredient_id);
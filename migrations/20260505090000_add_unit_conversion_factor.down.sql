@@ -0,0 +1,2 @@
+ALTER TABLE units DROP CONSTRAINT IF EXISTS chk_units_type;
+ALTER TABLE units DROP COLUMN IF EXISTS conversion_factor;
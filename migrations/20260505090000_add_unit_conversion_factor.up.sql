@@ -0,0 +1,19 @@
+-- ============================================================
+-- Faktor konversi satuan terhadap satuan dasar per tipe
+--   weight -> gram, volume -> mililiter, qty -> pcs
+--   contoh: kg = 1000, g = 1, l = 1000, ml = 1, lusin = 12
+-- ============================================================
+ALTER TABLE units
+    ADD COLUMN IF NOT EXISTS conversion_factor NUMERIC(18,6) NOT NULL DEFAULT 1
+    CHECK (conversion_factor > 0);
+
+UPDATE units SET type = LOWER(TRIM(type));
+
+ALTER TABLE units
+    ADD CONSTRAINT chk_units_type CHECK (type IN ('weight', 'volume', 'qty')) NOT VALID;
+
+-- Isi faktor untuk satuan umum yang sudah ada
+UPDATE units SET conversion_factor = 1000  WHERE type = 'weight' AND LOWER(symbol) = 'kg';
+UPDATE units SET conversion_factor = 0.001 WHERE type = 'weight' AND LOWER(symbol) = 'mg';
+UPDATE units SET conversion_factor = 1000  WHERE type = 'volume' AND LOWER(symbol) IN ('l', 'ltr', 'liter');
+UPDATE units SET conversion_factor = 12    WHERE type = 'qty' AND LOWER(symbol) IN ('lusin', 'dozen', 'dz');
@@ -0,0 +1,14 @@
+-- Qty pecahan dibulatkan saat kembali ke INT
+ALTER TABLE stock_transfer_items ALTER COLUMN received_qty TYPE INT USING ROUND(received_qty);
+ALTER TABLE stock_transfer_items ALTER COLUMN qty TYPE INT USING ROUND(qty);
+
+ALTER TABLE stock_opname_items ALTER COLUMN variance TYPE INT USING ROUND(variance);
+ALTER TABLE stock_opname_items ALTER COLUMN system_qty TYPE INT USING ROUND(system_qty);
+ALTER TABLE stock_opname_items ALTER COLUMN counted_qty TYPE INT USING ROUND(counted_qty);
+
+ALTER TABLE recipes ALTER COLUMN quantity TYPE INT USING GREATEST(ROUND(quantity), 1);
+ALTER TABLE purchase_details ALTER COLUMN quantity TYPE INT USING GREATEST(ROUND(quantity), 1);
+ALTER TABLE stock_movements ALTER COLUMN qty TYPE INT USING ROUND(qty);
+ALTER TABLE stocks ALTER COLUMN qty TYPE INT USING ROUND(qty);
+
+ALTER TABLE units DROP COLUMN IF EXISTS decimal_places;
@@ -0,0 +1,25 @@
+-- ============================================================
+-- 1. Presisi desimal per satuan (0 = bilangan bulat, maks 4)
+-- ============================================================
+ALTER TABLE units
+    ADD COLUMN IF NOT EXISTS decimal_places SMALLINT NOT NULL DEFAULT 0
+    CHECK (decimal_places BETWEEN 0 AND 4);
+
+-- Satuan berat/volume besar (kg, liter) biasanya dipakai pecahan
+UPDATE units SET decimal_places = 3 WHERE type IN ('weight', 'volume') AND conversion_factor >= 1000;
+
+
+-- ============================================================
+-- 2. Qty stok, pergerakan stok dan pembelian menjadi desimal
+-- ============================================================
+ALTER TABLE stocks ALTER COLUMN qty TYPE NUMERIC(18,4);
+ALTER TABLE stock_movements ALTER COLUMN qty TYPE NUMERIC(18,4);
+ALTER TABLE purchase_details ALTER COLUMN quantity TYPE NUMERIC(18,4);
+ALTER TABLE recipes ALTER COLUMN quantity TYPE NUMERIC(18,4);
+
+ALTER TABLE stock_opname_items ALTER COLUMN counted_qty TYPE NUMERIC(18,4);
+ALTER TABLE stock_opname_items ALTER COLUMN system_qty TYPE NUMERIC(18,4);
+ALTER TABLE stock_opname_items ALTER COLUMN variance TYPE NUMERIC(18,4);
+
+ALTER TABLE stock_transfer_items ALTER COLUMN qty TYPE NUMERIC(18,4);
+ALTER TABLE stock_transfer_items ALTER COLUMN received_qty TYPE NUMERIC(18,4);
@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS purchase_receipt_items;
+DROP TABLE IF EXISTS purchase_receipts;
+
+ALTER TABLE purchase_details DROP CONSTRAINT IF EXISTS chk_purchase_details_received_qty;
+ALTER TABLE purchase_details DROP COLUMN IF EXISTS received_qty;
+
+DROP INDEX IF EXISTS idx_purchases_supplier_id;
+ALTER TABLE purchases DROP CONSTRAINT IF EXISTS chk_purchases_status;
+ALTER TABLE purchases DROP COLUMN IF EXISTS cancelled_at;
+ALTER TABLE purchases DROP COLUMN IF EXISTS cancelled_by;
+ALTER TABLE purchases DROP COLUMN IF EXISTS ordered_at;
+ALTER TABLE purchases DROP COLUMN IF EXISTS ordered_by;
+ALTER TABLE purchases DROP COLUMN IF EXISTS note;
+ALTER TABLE purchases DROP COLUMN IF EXISTS supplier_id;
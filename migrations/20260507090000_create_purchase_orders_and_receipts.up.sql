@@ -0,0 +1,60 @@
+-- ============================================================
+-- 1. Purchase order: supplier dan siklus status
+--    status: draft -> ordered -> partially_received -> received
+--            draft/ordered -> cancelled
+-- ============================================================
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS supplier_id UUID NULL REFERENCES suppliers(id) ON DELETE RESTRICT;
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS note TEXT;
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS ordered_by UUID NULL REFERENCES users(id) ON DELETE RESTRICT;
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS ordered_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS cancelled_by UUID NULL REFERENCES users(id) ON DELETE RESTRICT;
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP WITH TIME ZONE;
+
+-- Pembelian lama sudah langsung menambah stok, jadi dianggap sudah diterima
+UPDATE purchases
+SET status = 'received'
+WHERE status NOT IN ('draft', 'ordered', 'partially_received', 'received', 'cancelled');
+
+ALTER TABLE purchases
+    ADD CONSTRAINT chk_purchases_status
+    CHECK (status IN ('draft', 'ordered', 'partially_received', 'received', 'cancelled'));
+
+CREATE INDEX IF NOT EXISTS idx_purchases_supplier_id ON purchases(supplier_id);
+
+ALTER TABLE purchase_details ADD COLUMN IF NOT EXISTS received_qty NUMERIC(18,4) NOT NULL DEFAULT 0;
+
+UPDATE purchase_details d
+SET received_qty = d.quantity
+FROM purchases p
+WHERE d.purchase_id = p.id AND p.status = 'received';
+
+ALTER TABLE purchase_details
+    ADD CONSTRAINT chk_purchase_details_received_qty CHECK (received_qty >= 0 AND received_qty <= quantity);
+
+
+-- ============================================================
+-- 2. Goods received note: setiap penerimaan barang dari supplier
+--    Stok baru bertambah saat penerimaan dicatat
+-- ============================================================
+CREATE TABLE IF NOT EXISTS purchase_receipts (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id  UUID NOT NULL REFERENCES company(id) ON DELETE CASCADE,
+    purchase_id UUID NOT NULL REFERENCES purchases(id) ON DELETE CASCADE,
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    note        TEXT,
+    received_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_receipts_company_id ON purchase_receipts(company_id);
+CREATE INDEX IF NOT EXISTS idx_purchase_receipts_purchase_id ON purchase_receipts(purchase_id);
+
+CREATE TABLE IF NOT EXISTS purchase_receipt_items (
+    id                  UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    purchase_receipt_id UUID NOT NULL REFERENCES purchase_receipts(id) ON DELETE CASCADE,
+    purchase_detail_id  UUID NOT NULL REFERENCES purchase_details(id) ON DELETE CASCADE,
+    product_id          UUID NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    qty                 NUMERIC(18,4) NOT NULL CHECK (qty > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_receipt_items_receipt_id ON purchase_receipt_items(purchase_receipt_id);
+CREATE INDEX IF NOT EXISTS idx_purchase_receipt_items_product_id ON purchase_receipt_items(product_id);
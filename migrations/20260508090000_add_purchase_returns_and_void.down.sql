@@ -0,0 +1,18 @@
+DROP TABLE IF EXISTS purchase_return_items;
+DROP TABLE IF EXISTS purchase_returns;
+
+ALTER TABLE purchase_details DROP CONSTRAINT IF EXISTS chk_purchase_details_returned_qty;
+ALTER TABLE purchase_details DROP COLUMN IF EXISTS returned_qty;
+
+UPDATE purchases SET status = 'received' WHERE status IN ('partially_returned', 'returned', 'voided');
+
+ALTER TABLE purchases DROP CONSTRAINT IF EXISTS chk_purchases_status;
+ALTER TABLE purchases
+    ADD CONSTRAINT chk_purchases_status
+    CHECK (status IN ('draft', 'ordered', 'partially_received', 'received', 'cancelled'));
+
+ALTER TABLE purchases DROP COLUMN IF EXISTS void_reason;
+ALTER TABLE purchases DROP COLUMN IF EXISTS voided_at;
+ALTER TABLE purchases DROP COLUMN IF EXISTS voided_by;
+
+ALTER TABLE outlets DROP COLUMN IF EXISTS allow_negative_stock;
@@ -0,0 +1,52 @@
+-- ============================================================
+-- 1. Izin stok minus per outlet
+--    Dipakai saat void / retur pembelian yang stoknya sudah terpakai
+-- ============================================================
+ALTER TABLE outlets ADD COLUMN IF NOT EXISTS allow_negative_stock BOOLEAN NOT NULL DEFAULT false;
+
+
+-- ============================================================
+-- 2. Void dan retur pembelian
+--    status tambahan: partially_returned, returned, voided
+-- ============================================================
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS voided_by UUID NULL REFERENCES users(id) ON DELETE RESTRICT;
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS voided_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS void_reason TEXT;
+
+ALTER TABLE purchases DROP CONSTRAINT IF EXISTS chk_purchases_status;
+ALTER TABLE purchases
+    ADD CONSTRAINT chk_purchases_status
+    CHECK (status IN ('draft', 'ordered', 'partially_received', 'received', 'partially_returned', 'returned', 'cancelled', 'voided'));
+
+ALTER TABLE purchase_details ADD COLUMN IF NOT EXISTS returned_qty NUMERIC(18,4) NOT NULL DEFAULT 0;
+ALTER TABLE purchase_details
+    ADD CONSTRAINT chk_purchase_details_returned_qty CHECK (returned_qty >= 0 AND returned_qty <= received_qty);
+
+
+-- ============================================================
+-- 3. Dokumen retur ke supplier (type = return) atau void (type = void)
+--    Setiap baris menulis movement OUT yang mereferensikan purchase asal
+-- ============================================================
+CREATE TABLE IF NOT EXISTS purchase_returns (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id  UUID NOT NULL REFERENCES company(id) ON DELETE CASCADE,
+    purchase_id UUID NOT NULL REFERENCES purchases(id) ON DELETE CASCADE,
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    type        VARCHAR(20) NOT NULL CHECK (type IN ('return', 'void')),
+    note        TEXT,
+    returned_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_returns_company_id ON purchase_returns(company_id);
+CREATE INDEX IF NOT EXISTS idx_purchase_returns_purchase_id ON purchase_returns(purchase_id);
+
+CREATE TABLE IF NOT EXISTS purchase_return_items (
+    id                 UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    purchase_return_id UUID NOT NULL REFERENCES purchase_returns(id) ON DELETE CASCADE,
+    purchase_detail_id UUID NOT NULL REFERENCES purchase_details(id) ON DELETE CASCADE,
+    product_id         UUID NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    qty                NUMERIC(18,4) NOT NULL CHECK (qty > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_return_items_return_id ON purchase_return_items(purchase_return_id);
+CREATE INDEX IF NOT EXISTS idx_purchase_return_items_product_id ON purchase_return_items(product_id);
@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS purchase_payments;
+
+DROP INDEX IF EXISTS idx_purchases_due_date;
+DROP INDEX IF EXISTS idx_purchases_payment_status;
+ALTER TABLE purchases DROP CONSTRAINT IF EXISTS chk_purchases_payment_status;
+ALTER TABLE purchases DROP CONSTRAINT IF EXISTS chk_purchases_payment_term_days;
+ALTER TABLE purchases DROP COLUMN IF EXISTS payment_status;
+ALTER TABLE purchases DROP COLUMN IF EXISTS due_date;
+ALTER TABLE purchases DROP COLUMN IF EXISTS payment_term_days;
+ALTER TABLE purchases DROP COLUMN IF EXISTS invoice_date;
+ALTER TABLE purchases DROP COLUMN IF EXISTS invoice_number;
+
+ALTER TABLE suppliers DROP CONSTRAINT IF EXISTS chk_suppliers_payment_term_days;
+ALTER TABLE suppliers DROP COLUMN IF EXISTS payment_term_days;
@@ -0,0 +1,63 @@
+-- ============================================================
+-- 1. Termin pembayaran default per supplier (hari)
+-- ============================================================
+ALTER TABLE suppliers ADD COLUMN IF NOT EXISTS payment_term_days INT NOT NULL DEFAULT 0;
+ALTER TABLE suppliers
+    ADD CONSTRAINT chk_suppliers_payment_term_days CHECK (payment_term_days >= 0);
+
+
+-- ============================================================
+-- 2. Invoice supplier dan status hutang pada purchase
+--    payment_status: unpaid -> partially_paid -> paid
+--    Jatuh tempo = due_date, atau tanggal invoice (tanggal purchase jika kosong) + payment_term_days
+-- ============================================================
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS invoice_number VARCHAR(100);
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS invoice_date DATE;
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS payment_term_days INT NOT NULL DEFAULT 0;
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS due_date DATE;
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS payment_status VARCHAR(20) NOT NULL DEFAULT 'unpaid';
+
+ALTER TABLE purchases
+    ADD CONSTRAINT chk_purchases_payment_term_days CHECK (payment_term_days >= 0);
+ALTER TABLE purchases
+    ADD CONSTRAINT chk_purchases_payment_status CHECK (payment_status IN ('unpaid', 'partially_paid', 'paid'));
+
+CREATE INDEX IF NOT EXISTS idx_purchases_payment_status ON purchases(payment_status);
+CREATE INDEX IF NOT EXISTS idx_purchases_due_date ON purchases(due_date);
+
+
+-- ============================================================
+-- 3. Pembayaran ke supplier (boleh beberapa kali per purchase)
+-- ============================================================
+CREATE TABLE IF NOT EXISTS purchase_payments (
+    id             UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id     UUID NOT NULL REFERENCES company(id) ON DELETE CASCADE,
+    purchase_id    UUID NOT NULL REFERENCES purchases(id) ON DELETE CASCADE,
+    user_id        UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    amount         NUMERIC(15,2) NOT NULL CHECK (amount > 0),
+    payment_method VARCHAR(50) NOT NULL,
+    reference      VARCHAR(100),
+    note           TEXT,
+    paid_at        TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at     TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_payments_company_id ON purchase_payments(company_id);
+CREATE INDEX IF NOT EXISTS idx_purchase_payments_purchase_id ON purchase_payments(purchase_id);
+
+-- Pembayaran lama (paid_amount dikurangi kembalian) dicatat sebagai satu pembayaran
+INSERT INTO purchase_payments (company_id, purchase_id, user_id, amount, payment_method, note, paid_at, created_at)
+SELECT company_id, id, user_id, LEAST(paid_amount - change_amount, grand_total), payment_method, 'migrated payment', created_at, created_at
+FROM purchases
+WHERE LEAST(paid_amount - change_amount, grand_total) > 0;
+
+UPDATE purchases
+SET paid_amount = GREATEST(LEAST(paid_amount - change_amount, grand_total), 0),
+    change_amount = 0;
+
+UPDATE purchases
+SET payment_status = CASE
+        WHEN paid_amount >= grand_total THEN 'paid'
+        WHEN paid_amount > 0 THEN 'partially_paid'
+        ELSE 'unpaid'
+    END;
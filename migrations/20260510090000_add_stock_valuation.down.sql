@@ -0,0 +1,12 @@
+ALTER TABLE sales DROP COLUMN IF EXISTS cogs;
+ALTER TABLE sale_details DROP COLUMN IF EXISTS cogs;
+
+ALTER TABLE stock_transfer_items DROP COLUMN IF EXISTS unit_cost;
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS unit_cost;
+
+DROP TABLE IF EXISTS stock_cost_layers;
+
+ALTER TABLE stocks DROP COLUMN IF EXISTS avg_cost;
+
+ALTER TABLE products DROP CONSTRAINT IF EXISTS chk_products_costing_method;
+ALTER TABLE products DROP COLUMN IF EXISTS costing_method;
@@ -0,0 +1,55 @@
+-- ============================================================
+-- 1. Metode costing per produk
+--    average = rata-rata bergerak, fifo = layer pembelian terlama keluar lebih dulu
+-- ============================================================
+ALTER TABLE products ADD COLUMN IF NOT EXISTS costing_method VARCHAR(20) NOT NULL DEFAULT 'average';
+ALTER TABLE products DROP CONSTRAINT IF EXISTS chk_products_costing_method;
+ALTER TABLE products
+    ADD CONSTRAINT chk_products_costing_method CHECK (costing_method IN ('average', 'fifo'));
+
+
+-- ============================================================
+-- 2. Nilai persediaan per produk per outlet
+--    avg_cost NULL berarti belum pernah dinilai (fallback ke products.cost)
+-- ============================================================
+ALTER TABLE stocks ADD COLUMN IF NOT EXISTS avg_cost NUMERIC(18,4);
+
+UPDATE stocks s
+SET avg_cost = p.cost
+FROM products p
+WHERE s.product_id = p.id AND s.avg_cost IS NULL;
+
+
+-- ============================================================
+-- 3. Layer biaya (FIFO)
+--    Setiap stok masuk membuat satu layer; stok keluar mengurangi layer terlama
+-- ============================================================
+CREATE TABLE IF NOT EXISTS stock_cost_layers (
+    id             UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    product_id     UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    outlet_id      UUID NOT NULL REFERENCES outlets(id) ON DELETE CASCADE,
+    reference_type VARCHAR(50),
+    reference_id   UUID,
+    unit_cost      NUMERIC(18,4) NOT NULL DEFAULT 0,
+    qty_in         NUMERIC(18,4) NOT NULL CHECK (qty_in >= 0),
+    qty_remaining  NUMERIC(18,4) NOT NULL CHECK (qty_remaining >= 0),
+    created_at     TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_cost_layers_product_outlet ON stock_cost_layers(product_id, outlet_id, created_at);
+
+-- Layer pembuka untuk stok yang sudah ada
+INSERT INTO stock_cost_layers (product_id, outlet_id, reference_type, unit_cost, qty_in, qty_remaining)
+SELECT s.product_id, s.outlet_id, 'opening', COALESCE(s.avg_cost, 0), s.qty, s.qty
+FROM stocks s
+WHERE s.qty > 0;
+
+
+-- ============================================================
+-- 4. Biaya per pergerakan stok dan HPP penjualan
+-- ============================================================
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS unit_cost NUMERIC(18,4) NOT NULL DEFAULT 0;
+ALTER TABLE stock_transfer_items ADD COLUMN IF NOT EXISTS unit_cost NUMERIC(18,4) NOT NULL DEFAULT 0;
+
+ALTER TABLE sale_details ADD COLUMN IF NOT EXISTS cogs NUMERIC(15,2) NOT NULL DEFAULT 0;
+ALTER TABLE sales ADD COLUMN IF NOT EXISTS cogs NUMERIC(15,2) NOT NULL DEFAULT 0;
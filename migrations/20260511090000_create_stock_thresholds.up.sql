@@ -0,0 +1,22 @@
+-- ============================================================
+-- Batas stok minimum / maksimum per produk per outlet
+--   min_qty  : titik pesan ulang, stok <= min_qty dianggap menipis
+--   max_qty  : level stok yang dituju saat pesan ulang (0 = tidak diatur)
+--   preferred_supplier_id : supplier untuk saran pembelian
+--                           (kosong = supplier pembelian terakhir produk)
+-- ============================================================
+CREATE TABLE IF NOT EXISTS stock_thresholds (
+    id                    UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id            UUID NOT NULL REFERENCES company(id) ON DELETE CASCADE,
+    product_id            UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    outlet_id             UUID NOT NULL REFERENCES outlets(id) ON DELETE CASCADE,
+    min_qty               NUMERIC(18,4) NOT NULL DEFAULT 0 CHECK (min_qty >= 0),
+    max_qty               NUMERIC(18,4) NOT NULL DEFAULT 0 CHECK (max_qty >= 0),
+    preferred_supplier_id UUID NULL REFERENCES suppliers(id) ON DELETE SET NULL,
+    created_at            TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at            TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_stock_thresholds_product_outlet UNIQUE (product_id, outlet_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_thresholds_company_id ON stock_thresholds(company_id);
+CREATE INDEX IF NOT EXISTS idx_stock_thresholds_outlet_id ON stock_thresholds(outlet_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_stock_movements_created_at_id;
+DROP INDEX IF EXISTS idx_purchases_company_created_at_id;
@@ -0,0 +1,5 @@
+-- ============================================================
+-- Index keyset untuk pagination cursor (created_at, id)
+-- ============================================================
+CREATE INDEX IF NOT EXISTS idx_purchases_company_created_at_id ON purchases(company_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_created_at_id ON stock_movements(created_at, id);
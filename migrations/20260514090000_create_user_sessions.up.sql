@@ -0,0 +1,21 @@
+-- ============================================================
+-- Sesi login (refresh token)
+--   refresh_token_hash          : SHA-256 (hex) refresh token aktif, diganti setiap /api/auth/refresh
+--   previous_refresh_token_hash : refresh token sebelumnya; jika dipakai lagi, sesi dicabut
+--   revoked_at                  : diisi saat logout / reset password; access token dengan sid sesi ini ditolak
+-- ============================================================
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    refresh_token_hash VARCHAR(64) NOT NULL UNIQUE,
+    previous_refresh_token_hash VARCHAR(64),
+    user_agent TEXT NOT NULL DEFAULT '',
+    ip_address VARCHAR(64) NOT NULL DEFAULT '',
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_used_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user_id ON user_sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_previous_refresh_token_hash ON user_sessions(previous_refresh_token_hash);
@@ -0,0 +1,2 @@
+-- Hash PIN tidak bisa dikembalikan ke teks biasa; PIN perlu di-reset ulang oleh admin.
+SELECT 1;
@@ -0,0 +1,11 @@
+-- ============================================================
+-- PIN POS disimpan sebagai hash bcrypt (sama seperti password).
+-- PIN lama yang masih berupa teks biasa di-hash dengan pgcrypto ($2a$, kompatibel dengan bcrypt Go).
+-- ============================================================
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+
+UPDATE users
+SET pos_pin = crypt(pos_pin, gen_salt('bf', 10))
+WHERE pos_pin IS NOT NULL AND pos_pin <> '' AND pos_pin NOT LIKE '$2%';
+
+UPDATE users SET pos_pin = NULL WHERE pos_pin = '';
@@ -0,0 +1,9 @@
+ALTER TABLE user_sessions
+DROP COLUMN IF EXISTS device_id,
+DROP COLUMN IF EXISTS outlet_id;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS pin_locked_until,
+DROP COLUMN IF EXISTS pin_failed_attempts;
+
+DROP TABLE IF EXISTS pos_devices;
@@ -0,0 +1,32 @@
+-- ============================================================
+-- Perangkat POS dan login PIN kasir
+--   pos_devices.device_key_hash : SHA-256 (hex) device key yang disimpan di terminal
+--   users.pin_failed_attempts   : jumlah PIN salah berturut-turut
+--   users.pin_locked_until      : login PIN ditolak sampai waktu ini
+--   user_sessions.outlet_id / device_id : sesi hasil login PIN hanya berlaku di outlet & terminal tsb
+-- ============================================================
+CREATE TABLE IF NOT EXISTS pos_devices (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id UUID NOT NULL REFERENCES company(id) ON DELETE CASCADE,
+    outlet_id UUID NOT NULL REFERENCES outlets(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    device_key_hash VARCHAR(64) NOT NULL UNIQUE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    last_seen_at TIMESTAMP WITH TIME ZONE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pos_devices_company_id ON pos_devices(company_id);
+CREATE INDEX IF NOT EXISTS idx_pos_devices_outlet_id ON pos_devices(outlet_id);
+
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS pin_failed_attempts INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS pin_locked_until TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE user_sessions
+ADD COLUMN IF NOT EXISTS outlet_id UUID REFERENCES outlets(id) ON DELETE CASCADE,
+ADD COLUMN IF NOT EXISTS device_id UUID REFERENCES pos_devices(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_device_id ON user_sessions(device_id);
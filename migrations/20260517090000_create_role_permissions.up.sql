@@ -0,0 +1,16 @@
+-- ============================================================
+-- Permission per role dan role kustom untuk user
+--   role_permissions : daftar permission (mis. product.write, stock.adjust) milik role
+--   users.role_id    : role kustom; NULL = permission default sesuai users.role
+--                      (admin selalu memiliki semua permission)
+-- ============================================================
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission VARCHAR(64) NOT NULL,
+    PRIMARY KEY (role_id, permission)
+);
+
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS role_id UUID REFERENCES roles(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_role_id ON users(role_id);
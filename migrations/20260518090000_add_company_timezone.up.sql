@@ -0,0 +1,6 @@
+-- ============================================================
+-- Zona waktu company (nama IANA, mis. Asia/Jakarta)
+--   dipakai untuk jadwal mingguan diskon dan batas hari filter tanggal
+-- ============================================================
+ALTER TABLE company
+ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'Asia/Jakarta';
@@ -0,0 +1 @@
+ALTER TABLE purchases DROP COLUMN IF EXISTS returned_amount;
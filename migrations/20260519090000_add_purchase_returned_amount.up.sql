@@ -0,0 +1,24 @@
+-- ============================================================
+-- Nilai barang yang diretur ke supplier
+--   returned_amount mengurangi hutang: sisa = grand_total - returned_amount - paid_amount
+--   nilai retur = qty x harga x (grand_total / subtotal), sehingga diskon bill dan pajak ikut proporsional
+-- ============================================================
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS returned_amount NUMERIC(15,2) NOT NULL DEFAULT 0;
+
+UPDATE purchases p
+SET returned_amount = x.amount
+FROM (
+    SELECT pr.purchase_id,
+        ROUND(SUM(pri.qty * pd.price * CASE WHEN pu.subtotal > 0 THEN pu.grand_total / pu.subtotal ELSE 1 END), 2) AS amount
+    FROM purchase_return_items pri
+    JOIN purchase_returns pr ON pri.purchase_return_id = pr.id
+    JOIN purchase_details pd ON pri.purchase_detail_id = pd.id
+    JOIN purchases pu ON pr.purchase_id = pu.id
+    WHERE pr.type = 'return'
+    GROUP BY pr.purchase_id
+) x
+WHERE p.id = x.purchase_id;
+
+UPDATE purchases
+SET payment_status = 'paid'
+WHERE paid_amount > 0 AND paid_amount >= grand_total - returned_amount;
@@ -0,0 +1,9 @@
+UPDATE purchases p
+SET returned_amount = COALESCE((
+    SELECT ROUND(SUM(pri.qty * pd.price * CASE WHEN p.subtotal > 0 THEN p.grand_total / p.subtotal ELSE 1 END), 2)
+    FROM purchase_return_items pri
+    JOIN purchase_returns pr ON pri.purchase_return_id = pr.id
+    JOIN purchase_details pd ON pri.purchase_detail_id = pd.id
+    WHERE pr.purchase_id = p.id AND pr.type = 'return'
+), 0)
+WHERE p.status = 'voided';
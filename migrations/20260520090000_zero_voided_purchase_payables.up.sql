@@ -0,0 +1,7 @@
+-- ============================================================
+-- Purchase yang di-void tidak lagi punya hutang
+--   returned_amount = grand_total, sehingga sisa hutang (grand_total - returned_amount - paid_amount) <= 0
+-- ============================================================
+UPDATE purchases
+SET returned_amount = grand_total
+WHERE status = 'voided';
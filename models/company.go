@@ -4,6 +4,9 @@ import (
 	"time"
 )
 
+// DefaultCompanyTimezone dipakai jika company belum mengatur zona waktu
+const DefaultCompanyTimezone = "Asia/Jakarta"
+
 type Company struct {
 	ID        string    `json:"id"`
 	Logo      string    `json:"logo"`
@@ -11,6 +14,7 @@ type Company struct {
 	Phone     string    `json:"phone"`
 	Owner     string    `json:"owner"`
 	Address   string    `json:"address"`
+	Timezone  string    `json:"timezone"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -87,6 +87,15 @@ type Discount struct {
 	ApplyToOrderTypes bool                      `json:"apply_to_order_types"`
 	OrderTypeIDs      []DiscountTargetOrderType `json:"order_type_ids,omitempty"`
 
+	// Masa berlaku (opsional) — NULL berarti tidak dibatasi
+	StartAt *time.Time `json:"start_at,omitempty"`
+	EndAt   *time.Time `json:"end_at,omitempty"`
+
+	// Jadwal mingguan (opsional) — kosong berarti berlaku sepanjang hari
+	Schedules []DiscountSchedule `json:"schedules"`
+
+	IsActive bool `json:"is_active"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -111,4 +120,98 @@ type DiscountInput struct {
 	// Target Diskon Lainnya — opsional untuk semua tipe
 	ApplyToOrderTypes bool     `json:"apply_to_order_types"`
 	OrderTypeIDs      []string `json:"order_type_ids,omitempty"`
+
+	// Masa berlaku & jadwal — opsional untuk semua tipe
+	StartAt   *time.Time              `json:"start_at,omitempty"`
+	EndAt     *time.Time              `json:"end_at,omitempty"`
+	Schedules []DiscountScheduleInput `json:"schedules,omitempty"`
+
+	// Default aktif jika tidak dikirim
+	IsActive *bool `json:"is_active,omitempty"`
+}
+
+// DiscountSchedule adalah jadwal mingguan diskon, misalnya happy hour.
+// DayOfWeek: 0 = Minggu ... 6 = Sabtu. Jam berformat "HH:MM".
+// Jika EndTime lebih kecil dari StartTime, jadwal berlanjut sampai hari berikutnya.
+type DiscountSchedule struct {
+	ID         string `json:"id"`
+	DiscountID string `json:"discount_id"`
+	DayOfWeek  int    `json:"day_of_week"`
+	StartTime  string `json:"start_time"`
+	EndTime    string `json:"end_time"`
+}
+
+type DiscountScheduleInput struct {
+	DayOfWeek int    `json:"day_of_week"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+// DiscountFilter adalah filter tambahan untuk daftar diskon
+type DiscountFilter struct {
+	// Jika diisi, hanya diskon yang aktif pada waktu tersebut yang dikembalikan
+	ActiveAt *time.Time
+	// Jika diisi, hanya diskon yang berlaku di outlet tersebut
+	OutletID string
+}
+
+// DiscountCartLine adalah satu baris item di keranjang yang akan dievaluasi
+type DiscountCartLine struct {
+	ProductID  string  `json:"product_id"`
+	CategoryID string  `json:"category_id"`
+	Quantity   int     `json:"quantity"`
+	Price      float64 `json:"price"`
+}
+
+// DiscountCartInput adalah payload untuk evaluasi diskon keranjang (POST /api/discounts/evaluate).
+// Diskon yang punya kode promo hanya ikut dievaluasi jika PromoCode-nya dikirim dan lolos validasi;
+// CustomerID dipakai untuk batas pemakaian per customer kode promo.
+type DiscountCartInput struct {
+	OutletID    string             `json:"outlet_id"`
+	OrderTypeID string             `json:"order_type_id"`
+	PromoCode   string             `json:"promo_code"`
+	CustomerID  string             `json:"customer_id"`
+	Lines       []DiscountCartLine `json:"lines"`
+}
+
+// AppliedDiscount adalah diskon yang berhasil diterapkan beserta nominalnya
+type AppliedDiscount struct {
+	DiscountID    string       `json:"discount_id"`
+	Name          string       `json:"name"`
+	Type          DiscountType `json:"type"`
+	Priority      int          `json:"priority"`
+	DiscountValue float64      `json:"discount_value"`
+	Amount        float64      `json:"amount"`
+	Capped        bool         `json:"capped"`
+}
+
+// RejectedDiscount menjelaskan alasan sebuah diskon tidak diterapkan
+type RejectedDiscount struct {
+	DiscountID string       `json:"discount_id"`
+	Name       string       `json:"name"`
+	Type       DiscountType `json:"type"`
+	Reason     string       `json:"reason"`
+	Message    string       `json:"message"`
+}
+
+// DiscountLineResult adalah hasil evaluasi diskon untuk satu baris keranjang
+type DiscountLineResult struct {
+	Index     int               `json:"index"`
+	ProductID string            `json:"product_id"`
+	Subtotal  float64           `json:"subtotal"`
+	Discounts []AppliedDiscount `json:"discounts"`
+	Discount  float64           `json:"discount"`
+	Total     float64           `json:"total"`
+}
+
+// DiscountEvaluation adalah hasil lengkap evaluasi diskon untuk satu keranjang
+type DiscountEvaluation struct {
+	Lines                []DiscountLineResult `json:"lines"`
+	ReceiptDiscounts     []AppliedDiscount    `json:"receipt_discounts"`
+	Rejected             []RejectedDiscount   `json:"rejected"`
+	Subtotal             float64              `json:"subtotal"`
+	LineDiscountTotal    float64              `json:"line_discount_total"`
+	ReceiptDiscountTotal float64              `json:"receipt_discount_total"`
+	DiscountTotal        float64              `json:"discount_total"`
+	GrandTotal           float64              `json:"grand_total"`
 }
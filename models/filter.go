@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// FilterParams adalah filter list endpoint yang dibaca dari query string:
+//
+//	field=a        -> field = a
+//	field=a,b,c    -> field IN (a, b, c); nilai field berakhiran _id harus UUID
+//	field_from / field_to -> rentang tanggal YYYY-MM-DD (inklusif, hari di zona waktu company)
+//	field_min / field_max -> rentang angka (inklusif)
+//
+// Repository hanya memakai field yang ada di whitelist miliknya; field lain diabaikan.
+type FilterParams struct {
+	Values  map[string][]string
+	Dates   map[string]DateRange
+	Numbers map[string]NumberRange
+}
+
+type DateRange struct {
+	From *time.Time
+	To   *time.Time
+}
+
+type NumberRange struct {
+	Min *float64
+	Max *float64
+}
+
+// Value mengembalikan nilai pertama field (kosong jika tidak dikirim).
+func (f FilterParams) Value(field string) string {
+	if values := f.Values[field]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// InLocation memindahkan batas hari rentang tanggal ke zona waktu loc (tanggal yang sama, jam 00:00 di loc).
+// Tanggal kalendernya tidak berubah, sehingga filter kolom DATE tetap membandingkan tanggal yang dikirim.
+func (f FilterParams) InLocation(loc *time.Location) FilterParams {
+	dates := make(map[string]DateRange, len(f.Dates))
+	for field, dr := range f.Dates {
+		dates[field] = DateRange{From: dateInLocation(dr.From, loc), To: dateInLocation(dr.To, loc)}
+	}
+	f.Dates = dates
+	return f
+}
+
+func dateInLocation(t *time.Time, loc *time.Location) *time.Time {
+	if t == nil {
+		return nil
+	}
+	local := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	return &local
+}
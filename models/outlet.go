@@ -4,26 +4,29 @@ import (
 	"time"
 )
 
+// Outlet: AllowNegativeStock mengizinkan stok outlet menjadi minus saat void / retur pembelian
 type Outlet struct {
-	ID         string    `json:"id"`
-	CompanyID  string    `json:"company_id"`
-	Code       string    `json:"code"`
-	Name       string    `json:"name"`
-	Supervisor string    `json:"supervisor"`
-	Address    string    `json:"address"`
-	Phone      string    `json:"phone"`
-	Email      string    `json:"email"`
-	IsActive   bool      `json:"is_active"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID                 string    `json:"id"`
+	CompanyID          string    `json:"company_id"`
+	Code               string    `json:"code"`
+	Name               string    `json:"name"`
+	Supervisor         string    `json:"supervisor"`
+	Address            string    `json:"address"`
+	Phone              string    `json:"phone"`
+	Email              string    `json:"email"`
+	IsActive           bool      `json:"is_active"`
+	AllowNegativeStock bool      `json:"allow_negative_stock"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
 }
 
 type OutletInput struct {
-	Code       string `json:"code"`
-	Name       string `json:"name"`
-	Supervisor string `json:"supervisor"`
-	Address    string `json:"address"`
-	Phone      string `json:"phone"`
-	Email      string `json:"email"`
-	IsActive   bool   `json:"is_active"`
+	Code               string `json:"code"`
+	Name               string `json:"name"`
+	Supervisor         string `json:"supervisor"`
+	Address            string `json:"address"`
+	Phone              string `json:"phone"`
+	Email              string `json:"email"`
+	IsActive           bool   `json:"is_active"`
+	AllowNegativeStock bool   `json:"allow_negative_stock"`
 }
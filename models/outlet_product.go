@@ -4,15 +4,42 @@ import (
 	"time"
 )
 
+// OutletProduct adalah pengaturan produk di satu outlet.
+// Price / Cost nil berarti memakai harga / HPP master produk.
 type OutletProduct struct {
-	ID        string    `json:"id"`
-	CompanyID string    `json:"company_id"`
-	OutletID  string    `json:"outlet_id"`
-	ProductID string    `json:"product_id"`
-	Stock     int       `json:"stock"`
-	Price     float64   `json:"price"`
-	Cost      float64   `json:"cost"`
-	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          string    `json:"id"`
+	CompanyID   string    `json:"company_id"`
+	OutletID    string    `json:"outlet_id"`
+	OutletName  string    `json:"outlet_name,omitempty"`
+	ProductID   string    `json:"product_id"`
+	ProductName string    `json:"product_name,omitempty"`
+	Stock       int       `json:"stock"`
+	Price       *float64  `json:"price"`
+	Cost        *float64  `json:"cost"`
+	IsActive    bool      `json:"is_active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type OutletProductInput struct {
+	OutletID  string   `json:"outlet_id"`
+	ProductID string   `json:"product_id"`
+	Price     *float64 `json:"price"`
+	Cost      *float64 `json:"cost"`
+	IsActive  *bool    `json:"is_active,omitempty"`
+}
+
+// OutletProductBulkInput memasang pengaturan yang sama untuk setiap kombinasi outlet dan produk.
+// Kombinasi yang sudah ada akan ditimpa.
+type OutletProductBulkInput struct {
+	OutletIDs  []string `json:"outlet_ids"`
+	ProductIDs []string `json:"product_ids"`
+	Price      *float64 `json:"price"`
+	Cost       *float64 `json:"cost"`
+	IsActive   *bool    `json:"is_active,omitempty"`
+}
+
+type OutletProductFilter struct {
+	OutletID  string
+	ProductID string
 }
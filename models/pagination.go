@@ -1,11 +1,25 @@
 package models
 
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
 type PaginationParams struct {
 	Page      int    `json:"page"`
 	Limit     int    `json:"limit"`
 	SortBy    string `json:"sort_by"`
 	SortOrder string `json:"sort_order"`
 	Search    string `json:"search"`
+	// CursorMode aktif jika query string memuat cursor (kosong = halaman pertama).
+	// Di mode ini Page diabaikan dan total data hanya dihitung jika WithTotal.
+	CursorMode bool        `json:"cursor_mode"`
+	Cursor     *PageCursor `json:"-"`
+	WithTotal  bool        `json:"with_total"`
 }
 
 type PaginationMeta struct {
@@ -14,3 +28,50 @@ type PaginationMeta struct {
 	TotalData   int `json:"total_data"`
 	Limit       int `json:"limit"`
 }
+
+// CursorPaginationMeta adalah meta list mode cursor; total_data hanya ada jika with_total=true
+type CursorPaginationMeta struct {
+	TotalData  *int   `json:"total_data,omitempty"`
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor"`
+}
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// PageCursor adalah posisi baris terakhir di halaman sebelumnya (keyset created_at, id)
+type PageCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// CursorPage adalah hasil list mode cursor. NextCursor kosong berarti halaman terakhir,
+// Total nil berarti total data tidak dihitung.
+type CursorPage struct {
+	NextCursor string
+	Total      *int
+}
+
+// Encode menghasilkan cursor opaque yang dikirim ke client sebagai next_cursor
+func (c PageCursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodePageCursor(cursor string) (PageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return PageCursor{}, ErrInvalidCursor
+	}
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return PageCursor{}, ErrInvalidCursor
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return PageCursor{}, ErrInvalidCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return PageCursor{}, ErrInvalidCursor
+	}
+	return PageCursor{CreatedAt: t, ID: id}, nil
+}
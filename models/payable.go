@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+type PurchasePaymentStatus string
+
+const (
+	PurchasePaymentStatusUnpaid        PurchasePaymentStatus = "unpaid"
+	PurchasePaymentStatusPartiallyPaid PurchasePaymentStatus = "partially_paid"
+	PurchasePaymentStatusPaid          PurchasePaymentStatus = "paid"
+)
+
+// PurchasePayment adalah satu kali pembayaran hutang ke supplier atas sebuah purchase
+type PurchasePayment struct {
+	ID            string    `json:"id"`
+	CompanyID     string    `json:"company_id"`
+	PurchaseID    string    `json:"purchase_id"`
+	UserID        string    `json:"user_id"`
+	UserName      string    `json:"user_name,omitempty"`
+	Amount        float64   `json:"amount"`
+	PaymentMethod string    `json:"payment_method"`
+	Reference     string    `json:"reference"`
+	Note          string    `json:"note"`
+	PaidAt        time.Time `json:"paid_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// PurchasePaymentInput: PaidAt format YYYY-MM-DD atau RFC3339, kosong = sekarang
+type PurchasePaymentInput struct {
+	Amount        float64 `json:"amount"`
+	PaymentMethod string  `json:"payment_method"`
+	Reference     string  `json:"reference"`
+	Note          string  `json:"note"`
+	PaidAt        string  `json:"paid_at"`
+}
+
+// PurchaseInvoiceInput mencatat invoice supplier. Tanggal format YYYY-MM-DD.
+// PaymentTermDays kosong = termin default supplier; DueDate kosong = InvoiceDate + termin.
+type PurchaseInvoiceInput struct {
+	InvoiceNumber   string `json:"invoice_number"`
+	InvoiceDate     string `json:"invoice_date"`
+	PaymentTermDays *int   `json:"payment_term_days"`
+	DueDate         string `json:"due_date"`
+}
+
+// PayableAging adalah sisa hutang satu supplier yang dikelompokkan menurut umur jatuh tempo
+type PayableAging struct {
+	SupplierID   string  `json:"supplier_id"`
+	SupplierName string  `json:"supplier_name"`
+	Current      float64 `json:"current"`
+	Days1To30    float64 `json:"days_1_30"`
+	Days31To60   float64 `json:"days_31_60"`
+	Days61To90   float64 `json:"days_61_90"`
+	Over90       float64 `json:"over_90"`
+	Total        float64 `json:"total"`
+}
+
+type PayableAgingReport struct {
+	AsOf      time.Time      `json:"as_of"`
+	Suppliers []PayableAging `json:"suppliers"`
+	Totals    PayableAging   `json:"totals"`
+}
+
+type PayableAgingFilter struct {
+	SupplierID string
+	OutletID   string
+	AsOf       time.Time
+}
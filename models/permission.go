@@ -0,0 +1,105 @@
+package models
+
+// Permission adalah hak akses yang bisa diberikan ke role. Route dilindungi dengan
+// Middleware.RequirePermission / Middleware.RequireReadWrite di package handlers.
+type Permission string
+
+const (
+	PermissionProductView    Permission = "product.view"
+	PermissionProductWrite   Permission = "product.write"
+	PermissionOutletView     Permission = "outlet.view"
+	PermissionOutletManage   Permission = "outlet.manage"
+	PermissionCustomerView   Permission = "customer.view"
+	PermissionCustomerManage Permission = "customer.manage"
+	PermissionDiscountView   Permission = "discount.view"
+	PermissionDiscountManage Permission = "discount.manage"
+	PermissionSaleView       Permission = "sale.view"
+	PermissionSaleCreate     Permission = "sale.create"
+	PermissionShiftManage    Permission = "shift.manage"
+	PermissionStockView      Permission = "stock.view"
+	PermissionStockAdjust    Permission = "stock.adjust"
+	PermissionPurchaseView   Permission = "purchase.view"
+	PermissionPurchaseManage Permission = "purchase.manage"
+	PermissionReportView     Permission = "report.view"
+	PermissionUserManage     Permission = "user.manage"
+	PermissionSystemView     Permission = "system.view"
+)
+
+type PermissionInfo struct {
+	Permission  Permission `json:"permission"`
+	Description string     `json:"description"`
+}
+
+// PermissionCatalog adalah daftar semua permission yang dikenal, urut sesuai tampilan di UI
+var PermissionCatalog = []PermissionInfo{
+	{PermissionProductView, "lihat produk, kategori, add-on, resep, satuan, dan tipe order"},
+	{PermissionProductWrite, "kelola produk, kategori, add-on, resep, satuan, dan tipe order"},
+	{PermissionOutletView, "lihat outlet"},
+	{PermissionOutletManage, "kelola outlet"},
+	{PermissionCustomerView, "lihat customer"},
+	{PermissionCustomerManage, "kelola customer"},
+	{PermissionDiscountView, "lihat diskon, pajak, dan kode promo"},
+	{PermissionDiscountManage, "kelola diskon, pajak, dan kode promo"},
+	{PermissionSaleView, "lihat penjualan"},
+	{PermissionSaleCreate, "buat penjualan dan pakai diskon / kode promo di kasir"},
+	{PermissionShiftManage, "buka, tutup, dan lihat shift kasir"},
+	{PermissionStockView, "lihat stok, mutasi stok, opname, dan transfer"},
+	{PermissionStockAdjust, "kelola batas stok, stock opname, dan transfer stok"},
+	{PermissionPurchaseView, "lihat supplier, pembelian, dan hutang"},
+	{PermissionPurchaseManage, "kelola supplier, pembelian, invoice, dan pembayaran hutang"},
+	{PermissionReportView, "lihat laporan (kartu stok, ringkasan stok, umur hutang, saran pembelian)"},
+	{PermissionUserManage, "kelola staff dan perangkat POS; ubah role hanya oleh admin"},
+	{PermissionSystemView, "lihat struktur database dan endpoint todo (debug)"},
+}
+
+// DefaultRolePermissions dipakai user cashier / waiter yang belum punya role kustom.
+// Admin selalu memiliki semua permission.
+var DefaultRolePermissions = map[UserRole][]Permission{
+	RoleCashier: {
+		PermissionProductView,
+		PermissionOutletView,
+		PermissionCustomerView,
+		PermissionCustomerManage,
+		PermissionDiscountView,
+		PermissionSaleView,
+		PermissionSaleCreate,
+		PermissionShiftManage,
+		PermissionStockView,
+	},
+	RoleWaiter: {
+		PermissionProductView,
+		PermissionOutletView,
+		PermissionCustomerView,
+		PermissionDiscountView,
+		PermissionSaleView,
+		PermissionSaleCreate,
+	},
+}
+
+// PosSessionPermissions adalah batas atas permission token hasil login PIN di terminal POS,
+// apa pun role user-nya (admin yang login lewat PIN pun hanya mendapat permission kasir ini).
+var PosSessionPermissions = []Permission{
+	PermissionProductView,
+	PermissionCustomerView,
+	PermissionCustomerManage,
+	PermissionDiscountView,
+	PermissionSaleView,
+	PermissionSaleCreate,
+	PermissionShiftManage,
+}
+
+// UserAccess adalah data yang dibutuhkan untuk menghitung permission user
+type UserAccess struct {
+	Role        UserRole
+	RoleID      *string
+	Permissions []Permission
+}
+
+func IsValidPermission(permission Permission) bool {
+	for _, info := range PermissionCatalog {
+		if info.Permission == permission {
+			return true
+		}
+	}
+	return false
+}
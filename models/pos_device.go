@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// PosDevice adalah terminal POS yang terdaftar di satu outlet. DeviceKey hanya dikembalikan sekali
+// saat perangkat didaftarkan; yang disimpan di DB hanya hash-nya.
+type PosDevice struct {
+	ID         string     `json:"id"`
+	CompanyID  string     `json:"company_id"`
+	OutletID   string     `json:"outlet_id"`
+	OutletName string     `json:"outlet_name,omitempty"`
+	Name       string     `json:"name"`
+	IsActive   bool       `json:"is_active"`
+	LastSeenAt *time.Time `json:"last_seen_at"`
+	CreatedBy  *string    `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	DeviceKey  string     `json:"device_key,omitempty"`
+}
+
+type PosDeviceInput struct {
+	OutletID string `json:"outlet_id"`
+	Name     string `json:"name"`
+}
+
+// PinLoginInput: login cepat kasir di terminal POS yang terdaftar
+type PinLoginInput struct {
+	DeviceKey string `json:"device_key"`
+	Username  string `json:"username"`
+	PIN       string `json:"pin"`
+	UserAgent string `json:"-"`
+	IPAddress string `json:"-"`
+}
@@ -11,20 +11,29 @@ const (
 	ProductTypeFinishedGoods ProductType = "finished_goods"
 )
 
+// CostingMethod menentukan cara HPP dihitung saat stok keluar
+type CostingMethod string
+
+const (
+	CostingMethodAverage CostingMethod = "average"
+	CostingMethodFIFO    CostingMethod = "fifo"
+)
+
 type Product struct {
-	ID         string      `json:"id"`
-	Name       string      `json:"name"`
-	SKU        string      `json:"sku"`
-	Unit       string      `json:"unit"`
-	UnitID     string      `json:"unit_id"`
-	Cost       float64     `json:"cost"`
-	Price      float64     `json:"price"`
-	ImageURL   string      `json:"image_url"`
-	CompanyID  string      `json:"company_id"`
-	CategoryID string      `json:"category_id"`
-	Type       ProductType `json:"type"`
-	CreatedAt  time.Time   `json:"created_at"`
-	UpdatedAt  time.Time   `json:"updated_at"`
+	ID            string        `json:"id"`
+	Name          string        `json:"name"`
+	SKU           string        `json:"sku"`
+	Unit          string        `json:"unit"`
+	UnitID        string        `json:"unit_id"`
+	Cost          float64       `json:"cost"`
+	Price         float64       `json:"price"`
+	ImageURL      string        `json:"image_url"`
+	CompanyID     string        `json:"company_id"`
+	CategoryID    string        `json:"category_id"`
+	Type          ProductType   `json:"type"`
+	CostingMethod CostingMethod `json:"costing_method"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
 }
 
 // raw_material
@@ -39,6 +48,9 @@ type ProductList struct {
 	Price    float64 `json:"price"`
 	ImageURL string  `json:"image_url"`
 	Category string  `json:"category"`
+
+	// Harga produk sebelum penyesuaian tipe order
+	BasePrice float64 `json:"base_price,omitempty"`
 }
 type ProductInput struct {
 	Name       string  `json:"name"`
@@ -50,4 +62,10 @@ type ProductInput struct {
 	ImageURL   string  `json:"image_url"`
 	CompanyID  string  `json:"company_id"`
 	CategoryID string  `json:"category_id"`
+
+	// Kosong berarti finished_goods
+	Type ProductType `json:"type"`
+
+	// Kosong berarti average
+	CostingMethod CostingMethod `json:"costing_method"`
 }
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// PromoCode adalah kode voucher yang terhubung ke sebuah diskon.
+// MaxUses nil berarti tanpa batas; MaxUses = 1 berarti sekali pakai.
+type PromoCode struct {
+	ID                 string    `json:"id"`
+	CompanyID          string    `json:"company_id"`
+	DiscountID         string    `json:"discount_id"`
+	DiscountName       string    `json:"discount_name,omitempty"`
+	Code               string    `json:"code"`
+	MaxUses            *int      `json:"max_uses,omitempty"`
+	MaxUsesPerCustomer *int      `json:"max_uses_per_customer,omitempty"`
+	UsedCount          int       `json:"used_count"`
+	IsActive           bool      `json:"is_active"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+type PromoCodeInput struct {
+	DiscountID         string `json:"discount_id"`
+	Code               string `json:"code"`
+	MaxUses            *int   `json:"max_uses,omitempty"`
+	MaxUsesPerCustomer *int   `json:"max_uses_per_customer,omitempty"`
+	IsActive           *bool  `json:"is_active,omitempty"`
+}
+
+// PromoCodeRedemption adalah satu baris ledger pemakaian kode promo
+type PromoCodeRedemption struct {
+	ID          string    `json:"id"`
+	PromoCodeID string    `json:"promo_code_id"`
+	CompanyID   string    `json:"company_id"`
+	UserID      string    `json:"user_id"`
+	CustomerID  *string   `json:"customer_id,omitempty"`
+	SaleID      *string   `json:"sale_id,omitempty"`
+	RedeemedAt  time.Time `json:"redeemed_at"`
+}
+
+// PromoCodeRedeemInput dipakai untuk validasi maupun redeem kode promo.
+// OutletID opsional: jika diisi, diskon harus berlaku di outlet tersebut dan sale_id harus milik outlet itu.
+type PromoCodeRedeemInput struct {
+	Code       string `json:"code"`
+	CustomerID string `json:"customer_id"`
+	SaleID     string `json:"sale_id"`
+	OutletID   string `json:"outlet_id"`
+}
+
+// PromoCodeValidation adalah hasil pengecekan kode promo tanpa memakainya
+type PromoCodeValidation struct {
+	Valid         bool       `json:"valid"`
+	Reason        string     `json:"reason,omitempty"`
+	Message       string     `json:"message,omitempty"`
+	RemainingUses *int       `json:"remaining_uses,omitempty"`
+	PromoCode     *PromoCode `json:"promo_code,omitempty"`
+	Discount      *Discount  `json:"discount,omitempty"`
+}
+
+// PromoCodeRedeemResult adalah hasil redeem kode promo
+type PromoCodeRedeemResult struct {
+	Redemption PromoCodeRedemption `json:"redemption"`
+	PromoCode  PromoCode           `json:"promo_code"`
+	Discount   Discount            `json:"discount"`
+}
@@ -2,46 +2,167 @@ package models
 
 import "time"
 
+type PurchaseStatus string
+
+const (
+	PurchaseStatusDraft             PurchaseStatus = "draft"
+	PurchaseStatusOrdered           PurchaseStatus = "ordered"
+	PurchaseStatusPartiallyReceived PurchaseStatus = "partially_received"
+	PurchaseStatusReceived          PurchaseStatus = "received"
+	PurchaseStatusPartiallyReturned PurchaseStatus = "partially_returned"
+	PurchaseStatusReturned          PurchaseStatus = "returned"
+	PurchaseStatusCancelled         PurchaseStatus = "cancelled"
+	PurchaseStatusVoided            PurchaseStatus = "voided"
+)
+
+type PurchaseReturnType string
+
+const (
+	PurchaseReturnTypeReturn PurchaseReturnType = "return"
+	PurchaseReturnTypeVoid   PurchaseReturnType = "void"
+)
+
+// Purchase adalah purchase order ke supplier; stok baru bertambah saat barang diterima
 type Purchase struct {
-	ID            string           `json:"id"`
-	CompanyID     string           `json:"company_id"`
-	UserID        string           `json:"user_id"`
-	UserName      string           `json:"user_name,omitempty"`
-	OutletID      string           `json:"outlet_id"`
-	OutletName    string           `json:"outlet_name,omitempty"`
-	PaymentMethod string           `json:"payment_method"`
-	GrandTotal    float64          `json:"grand_total"`
-	TaxValue      float64          `json:"tax_value"`
-	PaidAmount    float64          `json:"paid_amount"`
-	ChangeAmount  float64          `json:"change_amount"`
-	Status        string           `json:"status"`
-	DiscountBill  float64          `json:"discount_bill"`
-	Details       []PurchaseDetail `json:"details,omitempty"`
-	CreatedAt     time.Time        `json:"created_at"`
-	UpdatedAt     time.Time        `json:"updated_at"`
+	ID              string                `json:"id"`
+	CompanyID       string                `json:"company_id"`
+	UserID          string                `json:"user_id"`
+	UserName        string                `json:"user_name,omitempty"`
+	OutletID        string                `json:"outlet_id"`
+	OutletName      string                `json:"outlet_name,omitempty"`
+	SupplierID      *string               `json:"supplier_id"`
+	SupplierName    string                `json:"supplier_name,omitempty"`
+	PaymentMethod   string                `json:"payment_method"`
+	Subtotal        float64               `json:"subtotal"`
+	GrandTotal      float64               `json:"grand_total"`
+	TaxValue        float64               `json:"tax_value"`
+	PaidAmount      float64               `json:"paid_amount"`
+	ReturnedAmount  float64               `json:"returned_amount"` // nilai barang yang diretur, mengurangi hutang
+	Outstanding     float64               `json:"outstanding"`
+	Status          PurchaseStatus        `json:"status"`
+	DiscountBill    float64               `json:"discount_bill"`
+	InvoiceNumber   string                `json:"invoice_number"`
+	InvoiceDate     *time.Time            `json:"invoice_date,omitempty"`
+	PaymentTermDays int                   `json:"payment_term_days"`
+	DueDate         *time.Time            `json:"due_date,omitempty"`
+	PaymentStatus   PurchasePaymentStatus `json:"payment_status"`
+	Note            string                `json:"note"`
+	OrderedBy       *string               `json:"ordered_by,omitempty"`
+	OrderedAt       *time.Time            `json:"ordered_at,omitempty"`
+	CancelledBy     *string               `json:"cancelled_by,omitempty"`
+	CancelledAt     *time.Time            `json:"cancelled_at,omitempty"`
+	VoidedBy        *string               `json:"voided_by,omitempty"`
+	VoidedAt        *time.Time            `json:"voided_at,omitempty"`
+	VoidReason      string                `json:"void_reason,omitempty"`
+	Details         []PurchaseDetail      `json:"details,omitempty"`
+	Taxes           []TaxBreakdown        `json:"taxes,omitempty"`
+	Receipts        []PurchaseReceipt     `json:"receipts,omitempty"`
+	Returns         []PurchaseReturn      `json:"returns,omitempty"`
+	Payments        []PurchasePayment     `json:"payments,omitempty"`
+	CreatedAt       time.Time             `json:"created_at"`
+	UpdatedAt       time.Time             `json:"updated_at"`
 }
 
 type PurchaseDetail struct {
-	ID         string  `json:"id"`
-	PurchaseID string  `json:"purchase_id"`
-	ProductID  string  `json:"product_id"`
-	Quantity   int     `json:"quantity"`
-	Price      float64 `json:"price"`
-	Total      float64 `json:"total"`
+	ID          string  `json:"id"`
+	PurchaseID  string  `json:"purchase_id"`
+	ProductID   string  `json:"product_id"`
+	ProductName string  `json:"product_name,omitempty"`
+	Quantity    float64 `json:"quantity"`
+	ReceivedQty float64 `json:"received_qty"`
+	ReturnedQty float64 `json:"returned_qty"`
+	Price       float64 `json:"price"`
+	Total       float64 `json:"total"`
+}
+
+// PurchaseReceipt adalah goods received note: satu kali penerimaan barang dari supplier
+type PurchaseReceipt struct {
+	ID         string                `json:"id"`
+	PurchaseID string                `json:"purchase_id"`
+	UserID     string                `json:"user_id"`
+	UserName   string                `json:"user_name,omitempty"`
+	Note       string                `json:"note"`
+	Items      []PurchaseReceiptItem `json:"items,omitempty"`
+	ReceivedAt time.Time             `json:"received_at"`
+}
+
+type PurchaseReceiptItem struct {
+	ID                string  `json:"id"`
+	PurchaseReceiptID string  `json:"purchase_receipt_id"`
+	PurchaseDetailID  string  `json:"purchase_detail_id"`
+	ProductID         string  `json:"product_id"`
+	ProductName       string  `json:"product_name,omitempty"`
+	Qty               float64 `json:"qty"`
+}
+
+// PurchaseReturn adalah barang yang dikeluarkan kembali ke supplier, baik retur parsial
+// maupun void seluruh sisa barang yang sudah diterima
+type PurchaseReturn struct {
+	ID         string               `json:"id"`
+	PurchaseID string               `json:"purchase_id"`
+	UserID     string               `json:"user_id"`
+	UserName   string               `json:"user_name,omitempty"`
+	Type       PurchaseReturnType   `json:"type"`
+	Note       string               `json:"note"`
+	Items      []PurchaseReturnItem `json:"items,omitempty"`
+	ReturnedAt time.Time            `json:"returned_at"`
+}
+
+type PurchaseReturnItem struct {
+	ID               string  `json:"id"`
+	PurchaseReturnID string  `json:"purchase_return_id"`
+	PurchaseDetailID string  `json:"purchase_detail_id"`
+	ProductID        string  `json:"product_id"`
+	ProductName      string  `json:"product_name,omitempty"`
+	Qty              float64 `json:"qty"`
 }
 
+// PurchaseDetailInput: Quantity dan Price dalam satuan UnitID (kosong = satuan stok produk)
 type PurchaseDetailInput struct {
 	ProductID string  `json:"product_id"`
-	Quantity  int     `json:"quantity"`
+	Quantity  float64 `json:"quantity"`
 	Price     float64 `json:"price"`
+	UnitID    string  `json:"unit_id"`
 }
 
+// PurchaseInput: Status hanya boleh draft (default) atau ordered saat dibuat
 type PurchaseInput struct {
 	OutletID      string                `json:"outlet_id"`
+	SupplierID    string                `json:"supplier_id"`
 	PaymentMethod string                `json:"payment_method"`
-	TaxValue      float64               `json:"tax_value"`
-	PaidAmount    float64               `json:"paid_amount"`
 	Status        string                `json:"status"`
 	DiscountBill  float64               `json:"discount_bill"`
+	Note          string                `json:"note"`
 	Details       []PurchaseDetailInput `json:"details"`
 }
+
+// PurchaseReceiveItemInput: Qty dalam satuan UnitID (kosong = satuan stok produk)
+type PurchaseReceiveItemInput struct {
+	ProductID string  `json:"product_id"`
+	Qty       float64 `json:"qty"`
+	UnitID    string  `json:"unit_id"`
+}
+
+// PurchaseReceiveInput mencatat satu penerimaan barang (boleh parsial)
+type PurchaseReceiveInput struct {
+	Items []PurchaseReceiveItemInput `json:"items"`
+	Note  string                     `json:"note"`
+}
+
+// PurchaseReturnItemInput: Qty dalam satuan UnitID (kosong = satuan stok produk)
+type PurchaseReturnItemInput struct {
+	ProductID string  `json:"product_id"`
+	Qty       float64 `json:"qty"`
+	UnitID    string  `json:"unit_id"`
+}
+
+// PurchaseReturnInput mencatat retur barang ke supplier (boleh parsial)
+type PurchaseReturnInput struct {
+	Items []PurchaseReturnItemInput `json:"items"`
+	Note  string                    `json:"note"`
+}
+
+// PurchaseVoidInput membatalkan purchase yang barangnya sudah diterima; Reason wajib diisi
+type PurchaseVoidInput struct {
+	Reason string `json:"reason"`
+}
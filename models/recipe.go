@@ -2,21 +2,48 @@ package models
 
 import "time"
 
+// Recipe adalah satu baris bill of materials: Quantity bahan (IngredientID)
+// yang dipakai untuk membuat 1 produk jadi (ProductID).
 type Recipe struct {
-	ID           string    `json:"id"`
-	CompanyID    string    `json:"company_id"`
-	ProductID    string    `json:"product_id"`
-	IngredientID string    `json:"ingredient_id"`
-	IsActive     bool      `json:"is_active"`
-	CreatedBy    string    `json:"created_by"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	UpdatedBy    string    `json:"updated_by"`
+	ID             string    `json:"id"`
+	CompanyID      string    `json:"company_id"`
+	ProductID      string    `json:"product_id"`
+	IngredientID   string    `json:"ingredient_id"`
+	IngredientName string    `json:"ingredient_name,omitempty"`
+	Quantity       float64   `json:"quantity"`
+	UnitID         *string   `json:"unit_id,omitempty"`
+	UnitName       string    `json:"unit_name,omitempty"`
+	IsActive       bool      `json:"is_active"`
+	CreatedBy      string    `json:"created_by"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	UpdatedBy      string    `json:"updated_by"`
 }
 
 type RecipeInput struct {
-	CompanyID    string `json:"company_id"`
-	ProductID    string `json:"product_id"`
-	IngredientID string `json:"ingredient_id"`
-	IsActive     *bool  `json:"is_active"`
+	CompanyID    string  `json:"company_id"`
+	ProductID    string  `json:"product_id"`
+	IngredientID string  `json:"ingredient_id"`
+	Quantity     float64 `json:"quantity"`
+	UnitID       string  `json:"unit_id"`
+	IsActive     *bool   `json:"is_active"`
+}
+
+// RecipeBOM adalah seluruh resep untuk satu produk jadi
+type RecipeBOM struct {
+	ProductID   string   `json:"product_id"`
+	ProductName string   `json:"product_name"`
+	Items       []Recipe `json:"items"`
+}
+
+type RecipeBOMItemInput struct {
+	IngredientID string  `json:"ingredient_id"`
+	Quantity     float64 `json:"quantity"`
+	UnitID       string  `json:"unit_id"`
+	IsActive     *bool   `json:"is_active"`
+}
+
+// RecipeBOMInput mengganti seluruh resep sebuah produk jadi
+type RecipeBOMInput struct {
+	Items []RecipeBOMItemInput `json:"items"`
 }
@@ -1 +1,99 @@
 package models
+
+import "time"
+
+// Status penjualan: completed wajib lunas, pending untuk bill yang belum dibayar penuh
+const (
+	SaleStatusCompleted = "completed"
+	SaleStatusPending   = "pending"
+)
+
+type Sale struct {
+	ID            string         `json:"id"`
+	CompanyID     string         `json:"company_id"`
+	UserID        string         `json:"user_id"`
+	UserName      string         `json:"user_name,omitempty"`
+	OutletID      string         `json:"outlet_id"`
+	OutletName    string         `json:"outlet_name,omitempty"`
+	OrderTypeID   *string        `json:"order_type_id,omitempty"`
+	OrderTypeName string         `json:"order_type_name,omitempty"`
+	CustomerID    *string        `json:"customer_id,omitempty"`
+	CustomerName  string         `json:"customer_name,omitempty"`
+	PaymentMethod string         `json:"payment_method"`
+	Subtotal      float64        `json:"subtotal"`
+	DiscountBill  float64        `json:"discount_bill"`
+	TaxValue      float64        `json:"tax_value"`
+	GrandTotal    float64        `json:"grand_total"`
+	PaidAmount    float64        `json:"paid_amount"`
+	ChangeAmount  float64        `json:"change_amount"`
+	COGS          float64        `json:"cogs"`
+	Status        string         `json:"status"`
+	Note          string         `json:"note"`
+	Details       []SaleDetail   `json:"details,omitempty"`
+	Taxes         []TaxBreakdown `json:"taxes,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+
+	// Pengurangan stok hasil perhitungan resep; diisi service, ditulis repository
+	StockDeductions []SaleStockDeduction `json:"-"`
+}
+
+// SaleStockDeduction adalah qty produk (atau bahan baku) yang keluar dari stok karena penjualan
+type SaleStockDeduction struct {
+	ProductID string
+	Qty       float64
+	Note      string
+}
+
+type SaleDetail struct {
+	ID          string            `json:"id"`
+	SaleID      string            `json:"sale_id"`
+	ProductID   string            `json:"product_id"`
+	ProductName string            `json:"product_name,omitempty"`
+	Quantity    int               `json:"quantity"`
+	Price       float64           `json:"price"`
+	AddOnTotal  float64           `json:"add_on_total"`
+	Total       float64           `json:"total"`
+	COGS        float64           `json:"cogs"`
+	Note        string            `json:"note"`
+	AddOns      []SaleDetailAddOn `json:"add_ons,omitempty"`
+
+	// Stok yang keluar untuk baris ini (produk sendiri atau bahan resep); dipakai menghitung HPP
+	StockComponents []SaleStockDeduction `json:"-"`
+}
+
+type SaleDetailAddOn struct {
+	ID           string  `json:"id"`
+	SaleDetailID string  `json:"sale_detail_id"`
+	AddOnID      string  `json:"add_on_id"`
+	AddOnName    string  `json:"add_on_name,omitempty"`
+	Quantity     int     `json:"quantity"`
+	Price        float64 `json:"price"`
+	Total        float64 `json:"total"`
+}
+
+// Harga produk dan add-on tidak dikirim client; server mengambilnya dari master data
+// dan menerapkan penyesuaian harga tipe order.
+type SaleDetailAddOnInput struct {
+	AddOnID  string `json:"add_on_id"`
+	Quantity int    `json:"quantity"`
+}
+
+type SaleDetailInput struct {
+	ProductID string                 `json:"product_id"`
+	Quantity  int                    `json:"quantity"`
+	Note      string                 `json:"note"`
+	AddOns    []SaleDetailAddOnInput `json:"add_ons"`
+}
+
+type SaleInput struct {
+	OutletID      string            `json:"outlet_id"`
+	OrderTypeID   string            `json:"order_type_id"`
+	CustomerID    string            `json:"customer_id"`
+	PaymentMethod string            `json:"payment_method"`
+	PaidAmount    float64           `json:"paid_amount"`
+	Status        string            `json:"status"`
+	DiscountBill  float64           `json:"discount_bill"`
+	Note          string            `json:"note"`
+	Details       []SaleDetailInput `json:"details"`
+}
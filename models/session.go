@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// UserSession adalah satu login (perangkat). Access token membawa id sesi di claim "sid".
+// OutletID dan DeviceID terisi untuk sesi hasil login PIN di terminal POS.
+type UserSession struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	OutletID   *string    `json:"outlet_id"`
+	DeviceID   *string    `json:"device_id"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type RefreshTokenInput struct {
+	RefreshToken string `json:"refresh_token"`
+	UserAgent    string `json:"-"`
+	IPAddress    string `json:"-"`
+}
+
+// SessionAuthState adalah data sesi yang dicek Middleware.Auth di setiap request
+type SessionAuthState struct {
+	UserID       string
+	TokenVersion int
+	Active       bool
+}
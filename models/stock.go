@@ -3,20 +3,22 @@ package models
 import "time"
 
 type Stock struct {
-	ID        string `json:"id"`
-	ProductID string `json:"product_id"`
-	OutletID  string `json:"outlet_id"`
-	Qty       int    `json:"qty"`
+	ID        string  `json:"id"`
+	ProductID string  `json:"product_id"`
+	OutletID  string  `json:"outlet_id"`
+	Qty       float64 `json:"qty"`
 }
 
 type StockPerOutlet struct {
-	ID          string `json:"id"`
-	ProductID   string `json:"product_id"`
-	ProductName string `json:"product_name"`
-	ProductSKU  string `json:"product_sku"`
-	OutletID    string `json:"outlet_id"`
-	OutletName  string `json:"outlet_name"`
-	Qty         int    `json:"qty"`
+	ID          string  `json:"id"`
+	ProductID   string  `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	ProductSKU  string  `json:"product_sku"`
+	OutletID    string  `json:"outlet_id"`
+	OutletName  string  `json:"outlet_name"`
+	Qty         float64 `json:"qty"`
+	AvgCost     float64 `json:"avg_cost"`
+	StockValue  float64 `json:"stock_value"`
 }
 
 type StockMovementType string
@@ -24,8 +26,8 @@ type StockMovementType string
 const (
 	StockMovementTypeIn         StockMovementType = "IN"
 	StockMovementTypeOut        StockMovementType = "OUT"
-	StockMovementTypeAdjustment StockMovementType = "ADJUSTMENT"
-	StockMovementTypeTransfer   StockMovementType = "TRANSFER"
+	StockMovementTypeAdjustment StockMovementType = "ADJUSTMENT" // qty bertanda: positif = selisih lebih, negatif = selisih kurang
+	StockMovementTypeTransfer   StockMovementType = "TRANSFER"   // qty bertanda: negatif di outlet asal, positif di outlet tujuan
 )
 
 type StockReferenceType string
@@ -45,9 +47,75 @@ type StockMovement struct {
 	OutletID      string             `json:"outlet_id"`
 	OutletName    string             `json:"outlet_name,omitempty"`
 	Type          StockMovementType  `json:"type"`
-	Qty           int                `json:"qty"`
+	Qty           float64            `json:"qty"`
+	UnitCost      float64            `json:"unit_cost"`
 	ReferenceType StockReferenceType `json:"reference_type"`
 	ReferenceID   string             `json:"reference_id"`
 	Note          string             `json:"note"`
 	CreatedAt     time.Time          `json:"created_at"`
 }
+
+// StockLedgerFilter: rentang tanggal inklusif (DateFrom s/d DateTo, per hari di zona waktu company)
+type StockLedgerFilter struct {
+	ProductID string
+	OutletID  string
+	DateFrom  time.Time
+	DateTo    time.Time
+}
+
+// StockCardEntry adalah satu movement di kartu stok. QtyIn/QtyOut sudah dipisah dari qty bertanda,
+// Balance adalah saldo berjalan setelah movement ini.
+type StockCardEntry struct {
+	StockMovement
+	QtyIn   float64 `json:"qty_in"`
+	QtyOut  float64 `json:"qty_out"`
+	Balance float64 `json:"balance"`
+}
+
+// StockCard adalah kartu stok satu produk di satu outlet untuk satu periode
+type StockCard struct {
+	ProductID   string           `json:"product_id"`
+	ProductName string           `json:"product_name"`
+	ProductSKU  string           `json:"product_sku"`
+	OutletID    string           `json:"outlet_id"`
+	OutletName  string           `json:"outlet_name"`
+	DateFrom    time.Time        `json:"date_from"`
+	DateTo      time.Time        `json:"date_to"`
+	OpeningQty  float64          `json:"opening_qty"`
+	TotalIn     float64          `json:"total_in"`
+	TotalOut    float64          `json:"total_out"`
+	ClosingQty  float64          `json:"closing_qty"`
+	Entries     []StockCardEntry `json:"entries"`
+}
+
+// StockSummaryItem adalah mutasi stok satu produk di satu outlet selama periode
+type StockSummaryItem struct {
+	ProductID     string  `json:"product_id"`
+	ProductName   string  `json:"product_name"`
+	ProductSKU    string  `json:"product_sku"`
+	OutletID      string  `json:"outlet_id"`
+	OutletName    string  `json:"outlet_name"`
+	OpeningQty    float64 `json:"opening_qty"`
+	InQty         float64 `json:"in_qty"`
+	OutQty        float64 `json:"out_qty"`
+	AdjustmentQty float64 `json:"adjustment_qty"`
+	TransferIn    float64 `json:"transfer_in"`
+	TransferOut   float64 `json:"transfer_out"`
+	ClosingQty    float64 `json:"closing_qty"`
+}
+
+// StockSummaryTotals: total qty per jenis movement untuk seluruh produk pada periode
+type StockSummaryTotals struct {
+	InQty         float64 `json:"in_qty"`
+	OutQty        float64 `json:"out_qty"`
+	AdjustmentQty float64 `json:"adjustment_qty"`
+	TransferIn    float64 `json:"transfer_in"`
+	TransferOut   float64 `json:"transfer_out"`
+}
+
+type StockSummaryReport struct {
+	DateFrom time.Time          `json:"date_from"`
+	DateTo   time.Time          `json:"date_to"`
+	Totals   StockSummaryTotals `json:"totals"`
+	Items    []StockSummaryItem `json:"items"`
+}
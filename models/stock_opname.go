@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+type StockOpnameStatus string
+
+const (
+	StockOpnameStatusDraft  StockOpnameStatus = "draft"
+	StockOpnameStatusPosted StockOpnameStatus = "posted"
+)
+
+// StockOpname adalah satu sesi hitung fisik stok di sebuah outlet
+type StockOpname struct {
+	ID         string              `json:"id"`
+	CompanyID  string              `json:"company_id"`
+	OutletID   string              `json:"outlet_id"`
+	OutletName string              `json:"outlet_name,omitempty"`
+	UserID     string              `json:"user_id"`
+	Status     StockOpnameStatus   `json:"status"`
+	Note       string              `json:"note"`
+	PostedBy   *string             `json:"posted_by,omitempty"`
+	PostedAt   *time.Time          `json:"posted_at,omitempty"`
+	Items      []StockOpnameItem   `json:"items,omitempty"`
+	Summary    *StockOpnameSummary `json:"summary,omitempty"`
+	CreatedAt  time.Time           `json:"created_at"`
+	UpdatedAt  time.Time           `json:"updated_at"`
+}
+
+// StockOpnameItem adalah hasil hitung satu produk.
+// Selama draft, SystemQty diambil dari stok saat ini (preview); setelah posting nilainya dibekukan.
+// Variance = CountedQty - SystemQty.
+type StockOpnameItem struct {
+	ID            string  `json:"id"`
+	StockOpnameID string  `json:"stock_opname_id"`
+	ProductID     string  `json:"product_id"`
+	ProductName   string  `json:"product_name,omitempty"`
+	ProductSKU    string  `json:"product_sku,omitempty"`
+	CountedQty    float64 `json:"counted_qty"`
+	SystemQty     float64 `json:"system_qty"`
+	Variance      float64 `json:"variance"`
+	Note          string  `json:"note"`
+}
+
+type StockOpnameSummary struct {
+	TotalItems    int     `json:"total_items"`
+	VarianceItems int     `json:"variance_items"`
+	TotalSurplus  float64 `json:"total_surplus"`
+	TotalShortage float64 `json:"total_shortage"`
+}
+
+type StockOpnameInput struct {
+	OutletID string `json:"outlet_id"`
+	Note     string `json:"note"`
+}
+
+// StockOpnameItemInput: CountedQty dalam satuan UnitID (kosong = satuan stok produk)
+type StockOpnameItemInput struct {
+	ProductID  string  `json:"product_id"`
+	CountedQty float64 `json:"counted_qty"`
+	UnitID     string  `json:"unit_id"`
+	Note       string  `json:"note"`
+}
+
+type StockOpnameItemsInput struct {
+	Items []StockOpnameItemInput `json:"items"`
+}
+
+// StockOpnamePostInput berisi alasan penyesuaian yang dicatat di setiap stock movement
+type StockOpnamePostInput struct {
+	Note string `json:"note"`
+}
+
+type StockOpnameFilter struct {
+	OutletID string
+	Status   string
+}
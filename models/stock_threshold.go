@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+// StockThreshold adalah batas stok minimum (titik pesan ulang) dan maksimum produk di satu outlet.
+// PreferredSupplierID kosong berarti saran pembelian memakai supplier pembelian terakhir.
+type StockThreshold struct {
+	ID                    string    `json:"id"`
+	CompanyID             string    `json:"company_id"`
+	ProductID             string    `json:"product_id"`
+	ProductName           string    `json:"product_name,omitempty"`
+	ProductSKU            string    `json:"product_sku,omitempty"`
+	OutletID              string    `json:"outlet_id"`
+	OutletName            string    `json:"outlet_name,omitempty"`
+	MinQty                float64   `json:"min_qty"`
+	MaxQty                float64   `json:"max_qty"`
+	PreferredSupplierID   *string   `json:"preferred_supplier_id"`
+	PreferredSupplierName string    `json:"preferred_supplier_name,omitempty"`
+	CreatedAt             time.Time `json:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at"`
+}
+
+// StockThresholdInput: satu produk hanya punya satu pengaturan per outlet; simpan ulang = update
+type StockThresholdInput struct {
+	ProductID           string  `json:"product_id"`
+	OutletID            string  `json:"outlet_id"`
+	MinQty              float64 `json:"min_qty"`
+	MaxQty              float64 `json:"max_qty"`
+	PreferredSupplierID string  `json:"preferred_supplier_id"`
+}
+
+type StockThresholdFilter struct {
+	OutletID  string
+	ProductID string
+}
+
+// LowStockItem adalah stok outlet yang sudah mencapai atau di bawah min_qty.
+// UsageQty adalah qty yang keluar karena penjualan sejak awal periode pemakaian.
+type LowStockItem struct {
+	ProductID    string  `json:"product_id"`
+	ProductName  string  `json:"product_name"`
+	ProductSKU   string  `json:"product_sku"`
+	Unit         string  `json:"unit"`
+	OutletID     string  `json:"outlet_id"`
+	OutletName   string  `json:"outlet_name"`
+	Qty          float64 `json:"qty"`
+	MinQty       float64 `json:"min_qty"`
+	MaxQty       float64 `json:"max_qty"`
+	UsageQty     float64 `json:"usage_qty"`
+	SupplierID   *string `json:"supplier_id"`
+	SupplierName string  `json:"supplier_name"`
+	UnitCost     float64 `json:"unit_cost"`
+}
+
+// LowStockFilter: Days = panjang periode pemakaian (hari), CoverDays = stok yang ingin
+// dijamin cukup untuk sekian hari ke depan saat menghitung saran pembelian.
+type LowStockFilter struct {
+	OutletID  string
+	Days      int
+	CoverDays int
+}
+
+// ReorderSuggestionItem: product_id, quantity, dan price bisa langsung dipakai sebagai detail purchase
+type ReorderSuggestionItem struct {
+	ProductID     string  `json:"product_id"`
+	ProductName   string  `json:"product_name"`
+	ProductSKU    string  `json:"product_sku"`
+	Unit          string  `json:"unit"`
+	Qty           float64 `json:"qty"`
+	MinQty        float64 `json:"min_qty"`
+	MaxQty        float64 `json:"max_qty"`
+	AvgDailyUsage float64 `json:"avg_daily_usage"`
+	Quantity      float64 `json:"quantity"`
+	Price         float64 `json:"price"`
+	Total         float64 `json:"total"`
+}
+
+// ReorderSuggestion adalah draft purchase order per supplier per outlet
+type ReorderSuggestion struct {
+	SupplierID   *string                 `json:"supplier_id"`
+	SupplierName string                  `json:"supplier_name"`
+	OutletID     string                  `json:"outlet_id"`
+	OutletName   string                  `json:"outlet_name"`
+	Subtotal     float64                 `json:"subtotal"`
+	Items        []ReorderSuggestionItem `json:"items"`
+}
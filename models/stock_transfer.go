@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+type StockTransferStatus string
+
+const (
+	StockTransferStatusDraft             StockTransferStatus = "draft"
+	StockTransferStatusSent              StockTransferStatus = "sent"
+	StockTransferStatusPartiallyReceived StockTransferStatus = "partially_received"
+	StockTransferStatusReceived          StockTransferStatus = "received"
+)
+
+// StockTransfer adalah dokumen pemindahan stok dari satu outlet ke outlet lain
+type StockTransfer struct {
+	ID                    string              `json:"id"`
+	CompanyID             string              `json:"company_id"`
+	SourceOutletID        string              `json:"source_outlet_id"`
+	SourceOutletName      string              `json:"source_outlet_name,omitempty"`
+	DestinationOutletID   string              `json:"destination_outlet_id"`
+	DestinationOutletName string              `json:"destination_outlet_name,omitempty"`
+	UserID                string              `json:"user_id"`
+	Status                StockTransferStatus `json:"status"`
+	Note                  string              `json:"note"`
+	ReceiveNote           string              `json:"receive_note"`
+	SentBy                *string             `json:"sent_by,omitempty"`
+	SentAt                *time.Time          `json:"sent_at,omitempty"`
+	ReceivedBy            *string             `json:"received_by,omitempty"`
+	ReceivedAt            *time.Time          `json:"received_at,omitempty"`
+	Items                 []StockTransferItem `json:"items,omitempty"`
+	CreatedAt             time.Time           `json:"created_at"`
+	UpdatedAt             time.Time           `json:"updated_at"`
+}
+
+type StockTransferItem struct {
+	ID              string  `json:"id"`
+	StockTransferID string  `json:"stock_transfer_id"`
+	ProductID       string  `json:"product_id"`
+	ProductName     string  `json:"product_name,omitempty"`
+	ProductSKU      string  `json:"product_sku,omitempty"`
+	Qty             float64 `json:"qty"`
+	ReceivedQty     float64 `json:"received_qty"`
+	DiscrepancyNote string  `json:"discrepancy_note"`
+	UnitCost        float64 `json:"unit_cost"`
+}
+
+// StockTransferItemInput: Qty dalam satuan UnitID (kosong = satuan stok produk)
+type StockTransferItemInput struct {
+	ProductID string  `json:"product_id"`
+	Qty       float64 `json:"qty"`
+	UnitID    string  `json:"unit_id"`
+}
+
+type StockTransferInput struct {
+	SourceOutletID      string                   `json:"source_outlet_id"`
+	DestinationOutletID string                   `json:"destination_outlet_id"`
+	Note                string                   `json:"note"`
+	Items               []StockTransferItemInput `json:"items"`
+}
+
+// StockTransferReceiveItemInput adalah jumlah yang diterima pada satu kali penerimaan
+type StockTransferReceiveItemInput struct {
+	ProductID   string  `json:"product_id"`
+	ReceivedQty float64 `json:"received_qty"`
+	UnitID      string  `json:"unit_id"`
+	Note        string  `json:"note"`
+}
+
+// StockTransferReceiveInput mencatat penerimaan (boleh parsial).
+// Complete = true menutup transfer walaupun masih ada selisih; selisih wajib diberi catatan.
+type StockTransferReceiveInput struct {
+	Items    []StockTransferReceiveItemInput `json:"items"`
+	Note     string                          `json:"note"`
+	Complete bool                            `json:"complete"`
+}
+
+type StockTransferFilter struct {
+	OutletID string
+	Status   string
+}
@@ -4,16 +4,90 @@ import (
 	"time"
 )
 
+// TaxType membedakan pajak (PPN/PB1) dan service charge
+type TaxType string
+
+const (
+	TaxTypeVAT           TaxType = "vat"
+	TaxTypeServiceCharge TaxType = "service_charge"
+)
+
+// TaxTarget menentukan item yang dikenai pajak
+type TaxTarget string
+
+const (
+	TaxTargetAll      TaxTarget = "all"      // Semua Barang
+	TaxTargetProduct  TaxTarget = "product"  // Barang Tertentu
+	TaxTargetCategory TaxTarget = "category" // Kategori Tertentu
+)
+
 type Tax struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	Rate        float64   `json:"rate"`
-	CompanyID   string    `json:"company_id"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Rate       float64   `json:"rate"`
+	CompanyID  string    `json:"company_id"`
+	Type       TaxType   `json:"type"`
+	TargetType TaxTarget `json:"target_type"`
+
+	// Harga jual sudah termasuk pajak ini
+	IsInclusive bool `json:"is_inclusive"`
+
+	// Urutan perhitungan (semakin kecil dihitung lebih dulu)
+	Sequence int `json:"sequence"`
+
+	// Dihitung dari subtotal + pajak eksklusif dengan urutan sebelumnya
+	IsCompound bool `json:"is_compound"`
+
+	IsActive bool `json:"is_active"`
+
+	// Kosong berarti berlaku di semua outlet
+	OutletIDs   []string `json:"outlet_ids"`
+	ProductIDs  []string `json:"product_ids"`
+	CategoryIDs []string `json:"category_ids"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 type TaxInput struct {
-	Name string  `json:"name"`
-	Rate float64 `json:"rate"`
+	Name        string    `json:"name"`
+	Rate        float64   `json:"rate"`
+	Type        TaxType   `json:"type"`
+	TargetType  TaxTarget `json:"target_type"`
+	IsInclusive bool      `json:"is_inclusive"`
+	Sequence    int       `json:"sequence"`
+	IsCompound  bool      `json:"is_compound"`
+	IsActive    *bool     `json:"is_active,omitempty"`
+	OutletIDs   []string  `json:"outlet_ids,omitempty"`
+	ProductIDs  []string  `json:"product_ids,omitempty"`
+	CategoryIDs []string  `json:"category_ids,omitempty"`
+}
+
+// TaxLine adalah satu baris transaksi yang akan dihitung pajaknya.
+// Amount adalah nilai baris setelah diskon.
+type TaxLine struct {
+	ProductID  string
+	CategoryID string
+	Amount     float64
+}
+
+// TaxBreakdown adalah rincian satu pajak pada sebuah transaksi
+type TaxBreakdown struct {
+	ID            string  `json:"id,omitempty"`
+	TaxID         *string `json:"tax_id,omitempty"`
+	Name          string  `json:"name"`
+	Type          TaxType `json:"type"`
+	Rate          float64 `json:"rate"`
+	IsInclusive   bool    `json:"is_inclusive"`
+	TaxableAmount float64 `json:"taxable_amount"`
+	Amount        float64 `json:"amount"`
+}
+
+// TaxCalculation adalah hasil perhitungan pajak untuk satu transaksi
+type TaxCalculation struct {
+	// Pajak eksklusif yang ditambahkan ke tagihan
+	TaxValue float64 `json:"tax_value"`
+	// Pajak inklusif yang sudah termasuk di harga (informasi)
+	InclusiveTaxValue float64        `json:"inclusive_tax_value"`
+	Breakdown         []TaxBreakdown `json:"breakdown"`
 }
@@ -3,11 +3,17 @@ package models
 import "time"
 
 type Unit struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Symbol    string    `json:"symbol"`
-	Type      string    `json:"type"`
-	CompanyID string    `json:"company_id"`
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Symbol    string `json:"symbol"`
+	Type      string `json:"type"`
+	CompanyID string `json:"company_id"`
+
+	// Jumlah satuan dasar tipe ini dalam 1 satuan (gram, mililiter, pcs)
+	ConversionFactor float64 `json:"conversion_factor"`
+	// Jumlah digit desimal yang diizinkan untuk qty dalam satuan ini (0-4)
+	DecimalPlaces int `json:"decimal_places"`
+
 	CreatedAt time.Time `json:"created_at"`
 	CreatedBy string    `json:"created_by"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -19,4 +25,23 @@ type UnitInput struct {
 	Symbol    string `json:"symbol"`
 	Type      string `json:"type"` // weight / volume / qty
 	CompanyID string `json:"company_id"`
+
+	// Kosong berarti 1 (satuan dasar)
+	ConversionFactor float64 `json:"conversion_factor"`
+	// Kosong: 0 saat create, nilai lama dipertahankan saat update
+	DecimalPlaces *int `json:"decimal_places"`
+}
+
+const (
+	UnitTypeWeight = "weight"
+	UnitTypeVolume = "volume"
+	UnitTypeQty    = "qty"
+)
+
+// UnitConversion adalah hasil konversi jumlah antar satuan bertipe sama
+type UnitConversion struct {
+	FromUnitID string  `json:"from_unit_id"`
+	ToUnitID   string  `json:"to_unit_id"`
+	Qty        float64 `json:"qty"`
+	Result     float64 `json:"result"`
 }
@@ -17,24 +17,47 @@ type User struct {
 	Username     string    `json:"username"`
 	Email        string    `json:"email"`
 	PasswordHash string    `json:"-"` // Never return password hash
-	PosPIN       *string   `json:"pos_pin,omitempty"`
+	PosPIN       *string   `json:"-"` // hash bcrypt PIN POS
+	HasPosPIN    bool      `json:"has_pos_pin"`
 	Phone        *string   `json:"phone,omitempty"`
 	CompanyID    *string   `json:"company_id,omitempty"`
 	Role         UserRole  `json:"role"`
+	RoleID       *string   `json:"role_id"` // role kustom (tabel roles); NULL = permission default role
 	Active       bool      `json:"active"`
 	IsOwner      bool      `json:"is_owner"`
+	TokenVersion int       `json:"-"` // naik setiap reset password, dicocokkan dengan claim "ver" di JWT
+	SessionID    string    `json:"-"` // claim "sid" access token, diisi Middleware.Auth
+	OutletID     string    `json:"-"` // claim "outlet_id", hanya untuk token hasil login PIN
+	DeviceID     string    `json:"-"` // claim "device_id", hanya untuk token hasil login PIN
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
 
+// UserInput dipakai admin untuk menambah staff (cashier / waiter) ke company-nya
 type UserInput struct {
 	Username string   `json:"username"`
 	Email    string   `json:"email"`
 	Password string   `json:"password"`
 	Role     UserRole `json:"role"`
+	RoleID   string   `json:"role_id"`
 	PosPIN   string   `json:"pos_pin"`
 }
 
+type UserUpdateInput struct {
+	Username string   `json:"username"`
+	Email    string   `json:"email"`
+	Role     UserRole `json:"role"`
+	RoleID   string   `json:"role_id"`
+}
+
+type UserPasswordInput struct {
+	Password string `json:"password"`
+}
+
+type UserPINInput struct {
+	PosPIN string `json:"pos_pin"`
+}
+
 type UserRegisterInput struct {
 	Username      string `json:"username"`
 	Email         string `json:"email"`
@@ -44,13 +67,32 @@ type UserRegisterInput struct {
 	PosPIN        string `json:"pos_pin"`
 }
 
+// PinLock adalah status percobaan PIN user; LockedUntil terisi saat PIN salah terlalu sering
+type PinLock struct {
+	FailedAttempts int
+	LockedUntil    *time.Time
+}
+
 type LoginInput struct {
 	Identifier string `json:"identifier"`
 	Password   string `json:"password"`
+	UserAgent  string `json:"-"`
+	IPAddress  string `json:"-"`
 }
 
 type AuthResponse struct {
-	AccessToken string `json:"access_token"`
-	ExpireTime  string `json:"expire_time"`
-	User        User   `json:"user"`
+	AccessToken       string `json:"access_token"`
+	ExpireTime        string `json:"expire_time"`
+	RefreshToken      string `json:"refresh_token"`
+	RefreshExpireTime string `json:"refresh_expire_time"`
+	User              User   `json:"user"`
+}
+
+type ForgotPasswordInput struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordInput struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
 }
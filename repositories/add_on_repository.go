@@ -9,6 +9,7 @@ import (
 type AddOnRepository interface {
 	FindAll(companyID string, params models.PaginationParams) ([]models.AddOn, int, error)
 	FindByID(id string) (models.AddOn, error)
+	FindActiveByIDs(companyID string, addOnIDs []string) ([]models.AddOn, error)
 	Create(addOn *models.AddOnInput, companyID string) (models.AddOn, error)
 	Update(addOn *models.AddOnInput, id string) (models.AddOn, error)
 	Delete(id string) error
@@ -98,6 +99,29 @@ func (r *addOnRepository) FindByID(id string) (models.AddOn, error) {
 	return addOn, nil
 }
 
+// FindActiveByIDs mengambil beberapa add-on aktif milik company sekaligus (untuk perhitungan transaksi).
+func (r *addOnRepository) FindActiveByIDs(companyID string, addOnIDs []string) ([]models.AddOn, error) {
+	query := "SELECT id, company_id, name, price, is_active, created_at, updated_at FROM add_ons WHERE company_id = $1 AND id = ANY($2) AND is_active = TRUE"
+	rows, err := r.db.Query(query, companyID, addOnIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	addOns := []models.AddOn{}
+	for rows.Next() {
+		var addOn models.AddOn
+		if err := rows.Scan(&addOn.ID, &addOn.CompanyID, &addOn.Name, &addOn.Price, &addOn.IsActive, &addOn.CreatedAt, &addOn.UpdatedAt); err != nil {
+			return nil, err
+		}
+		addOns = append(addOns, addOn)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return addOns, nil
+}
+
 func (r *addOnRepository) Update(input *models.AddOnInput, id string) (models.AddOn, error) {
 	query := "UPDATE add_ons SET name = $1, price = $2, is_active = $3 WHERE id = $4 RETURNING id, company_id, name, price, is_active, created_at, updated_at"
 	args := []interface{}{input.Name, input.Price, input.IsActive, id}
@@ -2,11 +2,13 @@ package repositories
 
 import (
 	"database/sql"
+	"fmt"
 	"gowes/models"
 	"time"
 )
 
 type CashierShiftRepository interface {
+	FindAll(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.CashierShift, int, error)
 	StartShift(shift models.CashierShift) (models.CashierShift, error)
 	FindActiveShiftByUser(companyID string, userID string) (models.CashierShift, error)
 	EndShift(shift models.CashierShift) (models.CashierShift, error)
@@ -20,6 +22,84 @@ func NewCashierShiftRepository(db *sql.DB) CashierShiftRepository {
 	return &cashierShiftRepository{db: db}
 }
 
+// cashierShiftFilterColumns: filter query string yang didukung list shift kasir
+var cashierShiftFilterColumns = map[string]filterColumn{
+	"outlet_id":    {column: "cs.outlet_id", kind: filterValue},
+	"user_id":      {column: "cs.user_id", kind: filterValue},
+	"status":       {column: "cs.status", kind: filterValue},
+	"start_time":   {column: "cs.start_time", kind: filterDate},
+	"end_time":     {column: "cs.end_time", kind: filterDate},
+	"created_at":   {column: "cs.created_at", kind: filterDate},
+	"closing_cash": {column: "cs.closing_cash", kind: filterNumber},
+}
+
+func (r *cashierShiftRepository) FindAll(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.CashierShift, int, error) {
+	baseQuery := `
+		FROM cashier_shifts cs
+		JOIN outlets o ON cs.outlet_id = o.id
+		JOIN users u ON cs.user_id = u.id
+		WHERE cs.company_id = $1
+	`
+	args := []interface{}{companyID}
+	argIdx := 2
+
+	baseQuery, args, argIdx = applyFilterParams(baseQuery, args, argIdx, filter, cashierShiftFilterColumns)
+	if params.Search != "" {
+		baseQuery += fmt.Sprintf(" AND (o.name ILIKE $%d OR u.username ILIKE $%d)", argIdx, argIdx)
+		args = append(args, "%"+params.Search+"%")
+		argIdx++
+	}
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*)"+baseQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	allowedSorts := map[string]string{
+		"created_at": "cs.created_at",
+		"start_time": "cs.start_time",
+		"end_time":   "cs.end_time",
+		"outlet":     "o.name",
+		"user":       "u.username",
+	}
+	sortBy := "cs.start_time"
+	if col, ok := allowedSorts[params.SortBy]; ok {
+		sortBy = col
+	}
+
+	sortOrder := "DESC"
+	if params.SortOrder == "ASC" {
+		sortOrder = "ASC"
+	}
+
+	query := `SELECT cs.id, cs.company_id, cs.outlet_id, cs.user_id, cs.start_time, cs.end_time, cs.status, cs.opening_cash, cs.closing_cash, cs.expected_cash, cs.created_at, cs.updated_at` + baseQuery
+	query += fmt.Sprintf(" ORDER BY %s %s", sortBy, sortOrder)
+
+	offset := (params.Page - 1) * params.Limit
+	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
+	args = append(args, params.Limit, offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	shifts := []models.CashierShift{}
+	for rows.Next() {
+		shift, err := scanCashierShiftRow(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		shifts = append(shifts, shift)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return shifts, total, nil
+}
+
 func (r *cashierShiftRepository) StartShift(shift models.CashierShift) (models.CashierShift, error) {
 	row := r.db.QueryRow(`
 		INSERT INTO cashier_shifts (
@@ -78,7 +158,11 @@ func (r *cashierShiftRepository) EndShift(shift models.CashierShift) (models.Cas
 	return scanCashierShiftRow(row)
 }
 
-func scanCashierShiftRow(row *sql.Row) (models.CashierShift, error) {
+type cashierShiftScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanCashierShiftRow(row cashierShiftScanner) (models.CashierShift, error) {
 	var shift models.CashierShift
 	var endTime sql.NullTime
 
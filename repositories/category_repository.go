@@ -10,6 +10,7 @@ import (
 type CategoryRepository interface {
 	FindAll(companyID string, params models.PaginationParams) ([]models.Category, int, error)
 	FindByID(id string) (models.Category, error)
+	FindByIDs(companyID string, ids []string) ([]models.Category, error)
 	Create(category models.Category) (models.Category, error)
 	Update(category models.Category) (models.Category, error)
 	Delete(id string) error
@@ -91,6 +92,32 @@ func (r *categoryRepository) FindByID(id string) (models.Category, error) {
 	return c, nil
 }
 
+// FindByIDs mengambil beberapa kategori milik company sekaligus.
+func (r *categoryRepository) FindByIDs(companyID string, ids []string) ([]models.Category, error) {
+	rows, err := r.db.Query(`
+		SELECT id, company_id, name, description, created_at, updated_at
+		FROM categories
+		WHERE company_id = $1 AND id = ANY($2)
+	`, companyID, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []models.Category{}
+	for rows.Next() {
+		var c models.Category
+		if err := rows.Scan(&c.ID, &c.CompanyID, &c.Name, &c.Description, &c.CreatedAt, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
 func (r *categoryRepository) Create(category models.Category) (models.Category, error) {
 	err := r.db.QueryRow(`
 		INSERT INTO categories (company_id, name, description, created_at, updated_at) 
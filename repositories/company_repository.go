@@ -8,6 +8,7 @@ import (
 
 type CompanyRepository interface {
 	Create(ctx context.Context, tx *sql.Tx, company models.Company) (models.Company, error)
+	FindTimezone(companyID string) (string, error)
 }
 
 type companyRepository struct {
@@ -38,3 +39,12 @@ func (r *companyRepository) Create(ctx context.Context, tx *sql.Tx, company mode
 	}
 	return company, nil
 }
+
+// FindTimezone mengambil nama zona waktu IANA company
+func (r *companyRepository) FindTimezone(companyID string) (string, error) {
+	var timezone string
+	if err := r.db.QueryRow(`SELECT timezone FROM company WHERE id = $1`, companyID).Scan(&timezone); err != nil {
+		return "", err
+	}
+	return timezone, nil
+}
@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"fmt"
+	"gowes/models"
+)
+
+// applyPageCursor menambahkan kondisi keyset (created_at, id), ORDER BY, dan LIMIT untuk list mode cursor.
+// Baris yang diambil limit+1 agar cursorPageItems tahu masih ada halaman berikutnya.
+func applyPageCursor(baseQuery string, args []interface{}, argIdx int, params models.PaginationParams, createdAtColumn string, idColumn string) (string, []interface{}) {
+	sortOrder, comparator := "DESC", "<"
+	if params.SortOrder == "ASC" {
+		sortOrder, comparator = "ASC", ">"
+	}
+
+	if params.Cursor != nil {
+		baseQuery += fmt.Sprintf(" AND (%s, %s) %s ($%d, $%d)", createdAtColumn, idColumn, comparator, argIdx, argIdx+1)
+		args = append(args, params.Cursor.CreatedAt, params.Cursor.ID)
+		argIdx += 2
+	}
+
+	baseQuery += fmt.Sprintf(" ORDER BY %s %s, %s %s LIMIT $%d", createdAtColumn, sortOrder, idColumn, sortOrder, argIdx)
+	args = append(args, params.Limit+1)
+	return baseQuery, args
+}
+
+// cursorPageItems membuang baris kelebihan dari applyPageCursor dan menyusun next_cursor dari baris terakhir.
+func cursorPageItems[T any](items []T, limit int, cursorOf func(T) models.PageCursor) ([]T, string) {
+	if len(items) <= limit {
+		return items, ""
+	}
+	items = items[:limit]
+	return items, cursorOf(items[len(items)-1]).Encode()
+}
@@ -9,6 +9,7 @@ import (
 type CustomerRepository interface {
 	FindAll(companyID string, params models.PaginationParams) ([]models.Customer, int, error)
 	FindByID(id string) (models.Customer, error)
+	FindByIDs(companyID string, ids []string) ([]models.Customer, error)
 	Create(customer models.Customer) (models.Customer, error)
 	Update(customer models.Customer) (models.Customer, error)
 	Delete(id string) error
@@ -91,6 +92,29 @@ func (r *customerRepository) FindByID(id string) (models.Customer, error) {
 	return c, nil
 }
 
+// FindByIDs mengambil beberapa customer milik company sekaligus.
+func (r *customerRepository) FindByIDs(companyID string, ids []string) ([]models.Customer, error) {
+	rows, err := r.db.Query(`
+		SELECT id, company_id, name, phone, email, created_at, updated_at
+		FROM customers
+		WHERE company_id = $1 AND id = ANY($2)
+	`, companyID, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	customers := []models.Customer{}
+	for rows.Next() {
+		var c models.Customer
+		if err := rows.Scan(&c.ID, &c.CompanyID, &c.Name, &c.Phone, &c.Email, &c.CreatedAt, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		customers = append(customers, c)
+	}
+	return customers, rows.Err()
+}
+
 func (r *customerRepository) Create(customer models.Customer) (models.Customer, error) {
 	err := r.db.QueryRow(`
 		INSERT INTO customers (company_id, name, phone, email, created_at, updated_at)
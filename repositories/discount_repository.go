@@ -7,8 +7,9 @@ import (
 )
 
 type DiscountRepository interface {
-	FindAll(companyID string, params models.PaginationParams) ([]models.Discount, int, error)
+	FindAll(companyID string, params models.PaginationParams, filter models.DiscountFilter) ([]models.Discount, int, error)
 	FindByID(id string) (models.Discount, error)
+	FindAllByCompany(companyID string, promoDiscountID string) ([]models.Discount, error)
 	Create(discount models.Discount, outletIDs, categoryIDs, productIDs, orderTypeIDs []string) (models.Discount, error)
 	Update(discount models.Discount, outletIDs, categoryIDs, productIDs, orderTypeIDs []string) (models.Discount, error)
 	Delete(id string) error
@@ -28,12 +29,14 @@ func scanDiscountFromRow(row *sql.Row) (models.Discount, error) {
 	var d models.Discount
 	var maxAmount, minPurchase sql.NullFloat64
 	var targetType sql.NullString
+	var startAt, endAt sql.NullTime
 
 	err := row.Scan(
 		&d.ID, &d.CompanyID, &d.Name, &d.Type, &d.DiscountValue,
 		&maxAmount, &minPurchase,
 		&targetType, &d.Priority,
 		&d.ApplyToOrderTypes,
+		&startAt, &endAt, &d.IsActive,
 		&d.CreatedAt, &d.UpdatedAt,
 	)
 	if err != nil {
@@ -50,6 +53,12 @@ func scanDiscountFromRow(row *sql.Row) (models.Discount, error) {
 		t := models.DiscountTarget(targetType.String)
 		d.TargetType = &t
 	}
+	if startAt.Valid {
+		d.StartAt = &startAt.Time
+	}
+	if endAt.Valid {
+		d.EndAt = &endAt.Time
+	}
 	return d, nil
 }
 
@@ -57,12 +66,14 @@ func scanDiscountFromRows(rows *sql.Rows) (models.Discount, error) {
 	var d models.Discount
 	var maxAmount, minPurchase sql.NullFloat64
 	var targetType sql.NullString
+	var startAt, endAt sql.NullTime
 
 	err := rows.Scan(
 		&d.ID, &d.CompanyID, &d.Name, &d.Type, &d.DiscountValue,
 		&maxAmount, &minPurchase,
 		&targetType, &d.Priority,
 		&d.ApplyToOrderTypes,
+		&startAt, &endAt, &d.IsActive,
 		&d.CreatedAt, &d.UpdatedAt,
 	)
 	if err != nil {
@@ -79,81 +90,155 @@ func scanDiscountFromRows(rows *sql.Rows) (models.Discount, error) {
 		t := models.DiscountTarget(targetType.String)
 		d.TargetType = &t
 	}
+	if startAt.Valid {
+		d.StartAt = &startAt.Time
+	}
+	if endAt.Valid {
+		d.EndAt = &endAt.Time
+	}
 	return d, nil
 }
 
 // ─── relation helpers ────────────────────────────────────────────────────────
 
-// loadRelations mengisi semua slice relasi (junction tables) ke dalam Discount.
-func (r *discountRepository) loadRelations(d *models.Discount) error {
+// loadRelations mengisi semua slice relasi (junction tables) ke dalam setiap Discount.
+// Relasi dimuat per tabel untuk seluruh diskon sekaligus (discount_id = ANY($1)), bukan per diskon.
+func (r *discountRepository) loadRelations(discounts []models.Discount) error {
+	if len(discounts) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(discounts))
+	index := make(map[string]*models.Discount, len(discounts))
+	for i := range discounts {
+		d := &discounts[i]
+		ids[i] = d.ID
+		index[d.ID] = d
+		d.OutletIDs = []models.DiscountTargetOutlet{}
+		d.TargetCategoryIDs = []models.DiscountTargetCategory{}
+		d.TargetProductIDs = []models.DiscountTargetProduct{}
+		d.OrderTypeIDs = []models.DiscountTargetOrderType{}
+		d.Schedules = []models.DiscountSchedule{}
+	}
+
 	// 1. Outlets
 	outletRows, err := r.db.Query(
-		`SELECT id, discount_id, outlet_id FROM discount_outlets WHERE discount_id = $1`, d.ID,
+		`SELECT id, discount_id, outlet_id FROM discount_outlets WHERE discount_id = ANY($1)`, ids,
 	)
 	if err != nil {
 		return fmt.Errorf("load discount_outlets: %w", err)
 	}
 	defer outletRows.Close()
-	d.OutletIDs = []models.DiscountTargetOutlet{}
 	for outletRows.Next() {
 		var o models.DiscountTargetOutlet
 		if err := outletRows.Scan(&o.ID, &o.ParentID, &o.OutletID); err != nil {
 			return err
 		}
-		d.OutletIDs = append(d.OutletIDs, o)
+		if d, ok := index[o.ParentID]; ok {
+			d.OutletIDs = append(d.OutletIDs, o)
+		}
+	}
+	if err := outletRows.Err(); err != nil {
+		return err
 	}
 
 	// 2. Target categories
 	catRows, err := r.db.Query(
-		`SELECT id, discount_id, category_id FROM discount_target_categories WHERE discount_id = $1`, d.ID,
+		`SELECT id, discount_id, category_id FROM discount_target_categories WHERE discount_id = ANY($1)`, ids,
 	)
 	if err != nil {
 		return fmt.Errorf("load discount_target_categories: %w", err)
 	}
 	defer catRows.Close()
-	d.TargetCategoryIDs = []models.DiscountTargetCategory{}
 	for catRows.Next() {
 		var c models.DiscountTargetCategory
 		if err := catRows.Scan(&c.ID, &c.ParentID, &c.CategoryId); err != nil {
 			return err
 		}
-		d.TargetCategoryIDs = append(d.TargetCategoryIDs, c)
+		if d, ok := index[c.ParentID]; ok {
+			d.TargetCategoryIDs = append(d.TargetCategoryIDs, c)
+		}
+	}
+	if err := catRows.Err(); err != nil {
+		return err
 	}
 
 	// 3. Target products
 	prodRows, err := r.db.Query(
-		`SELECT id, discount_id, product_id FROM discount_target_products WHERE discount_id = $1`, d.ID,
+		`SELECT id, discount_id, product_id FROM discount_target_products WHERE discount_id = ANY($1)`, ids,
 	)
 	if err != nil {
 		return fmt.Errorf("load discount_target_products: %w", err)
 	}
 	defer prodRows.Close()
-	d.TargetProductIDs = []models.DiscountTargetProduct{}
 	for prodRows.Next() {
 		var p models.DiscountTargetProduct
 		if err := prodRows.Scan(&p.ID, &p.ParentID, &p.ProductId); err != nil {
 			return err
 		}
-		d.TargetProductIDs = append(d.TargetProductIDs, p)
+		if d, ok := index[p.ParentID]; ok {
+			d.TargetProductIDs = append(d.TargetProductIDs, p)
+		}
+	}
+	if err := prodRows.Err(); err != nil {
+		return err
 	}
 
 	// 4. Order types
 	otRows, err := r.db.Query(
-		`SELECT id, discount_id, order_type_id FROM discount_order_types WHERE discount_id = $1`, d.ID,
+		`SELECT id, discount_id, order_type_id FROM discount_order_types WHERE discount_id = ANY($1)`, ids,
 	)
 	if err != nil {
 		return fmt.Errorf("load discount_order_types: %w", err)
 	}
 	defer otRows.Close()
-	d.OrderTypeIDs = []models.DiscountTargetOrderType{}
 	for otRows.Next() {
 		var o models.DiscountTargetOrderType
 		if err := otRows.Scan(&o.ID, &o.ParentID, &o.OrderTypeID); err != nil {
 			return err
 		}
-		d.OrderTypeIDs = append(d.OrderTypeIDs, o)
+		if d, ok := index[o.ParentID]; ok {
+			d.OrderTypeIDs = append(d.OrderTypeIDs, o)
+		}
+	}
+	if err := otRows.Err(); err != nil {
+		return err
+	}
+
+	// 5. Schedules
+	scheduleRows, err := r.db.Query(
+		`SELECT id, discount_id, day_of_week, to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI')
+		 FROM discount_schedules WHERE discount_id = ANY($1) ORDER BY day_of_week, start_time`, ids,
+	)
+	if err != nil {
+		return fmt.Errorf("load discount_schedules: %w", err)
+	}
+	defer scheduleRows.Close()
+	for scheduleRows.Next() {
+		var sc models.DiscountSchedule
+		if err := scheduleRows.Scan(&sc.ID, &sc.DiscountID, &sc.DayOfWeek, &sc.StartTime, &sc.EndTime); err != nil {
+			return err
+		}
+		if d, ok := index[sc.DiscountID]; ok {
+			d.Schedules = append(d.Schedules, sc)
+		}
 	}
 
+	return scheduleRows.Err()
+}
+
+// insertSchedules menyisipkan jadwal mingguan diskon dalam satu transaksi.
+func insertSchedules(tx *sql.Tx, discountID string, d *models.Discount, schedules []models.DiscountSchedule) error {
+	for _, sc := range schedules {
+		sc.DiscountID = discountID
+		if err := tx.QueryRow(
+			`INSERT INTO discount_schedules (discount_id, day_of_week, start_time, end_time) VALUES ($1, $2, $3, $4) RETURNING id`,
+			discountID, sc.DayOfWeek, sc.StartTime, sc.EndTime,
+		).Scan(&sc.ID); err != nil {
+			return fmt.Errorf("insert discount_schedules: %w", err)
+		}
+		d.Schedules = append(d.Schedules, sc)
+	}
 	return nil
 }
 
@@ -225,6 +310,7 @@ func deleteJunctions(tx *sql.Tx, discountID string) error {
 		"discount_target_categories",
 		"discount_target_products",
 		"discount_order_types",
+		"discount_schedules",
 	}
 	for _, table := range tables {
 		if _, err := tx.Exec(
@@ -238,7 +324,7 @@ func deleteJunctions(tx *sql.Tx, discountID string) error {
 
 // ─── FindAll ─────────────────────────────────────────────────────────────────
 
-func (r *discountRepository) FindAll(companyID string, params models.PaginationParams) ([]models.Discount, int, error) {
+func (r *discountRepository) FindAll(companyID string, params models.PaginationParams, filter models.DiscountFilter) ([]models.Discount, int, error) {
 	baseQuery := " FROM discounts WHERE company_id = $1"
 	args := []interface{}{companyID}
 	argIdx := 2
@@ -249,6 +335,43 @@ func (r *discountRepository) FindAll(companyID string, params models.PaginationP
 		argIdx++
 	}
 
+	// Hanya diskon yang berlaku di outlet tertentu (tanpa outlet = semua outlet)
+	if filter.OutletID != "" {
+		baseQuery += fmt.Sprintf(` AND (
+			NOT EXISTS (SELECT 1 FROM discount_outlets dout WHERE dout.discount_id = discounts.id)
+			OR EXISTS (SELECT 1 FROM discount_outlets dout WHERE dout.discount_id = discounts.id AND dout.outlet_id = $%d)
+		)`, argIdx)
+		args = append(args, filter.OutletID)
+		argIdx++
+	}
+
+	// Hanya diskon yang aktif pada waktu tertentu: status aktif, dalam masa berlaku,
+	// dan (jika punya jadwal) jam tersebut masuk salah satu jadwal mingguan.
+	// Hari & jam dihitung dari zona waktu activeAt (service mengonversinya ke zona waktu company).
+	if filter.ActiveAt != nil {
+		at := *filter.ActiveAt
+		day := int(at.Weekday())
+		prevDay := (day + 6) % 7
+		clock := at.Format("15:04:05")
+
+		baseQuery += fmt.Sprintf(` AND is_active = TRUE
+			AND (start_at IS NULL OR start_at <= $%d)
+			AND (end_at IS NULL OR end_at > $%d)
+			AND (
+				NOT EXISTS (SELECT 1 FROM discount_schedules ds WHERE ds.discount_id = discounts.id)
+				OR EXISTS (
+					SELECT 1 FROM discount_schedules ds
+					WHERE ds.discount_id = discounts.id AND (
+						(ds.start_time < ds.end_time AND ds.day_of_week = $%d AND $%d::time >= ds.start_time AND $%d::time < ds.end_time)
+						OR (ds.start_time > ds.end_time AND ds.day_of_week = $%d AND $%d::time >= ds.start_time)
+						OR (ds.start_time > ds.end_time AND ds.day_of_week = $%d AND $%d::time < ds.end_time)
+					)
+				)
+			)`, argIdx, argIdx, argIdx+1, argIdx+2, argIdx+2, argIdx+1, argIdx+2, argIdx+3, argIdx+2)
+		args = append(args, at, day, clock, prevDay)
+		argIdx += 4
+	}
+
 	// Count total
 	var total int
 	if err := r.db.QueryRow("SELECT COUNT(*)"+baseQuery, args...).Scan(&total); err != nil {
@@ -267,7 +390,7 @@ func (r *discountRepository) FindAll(companyID string, params models.PaginationP
 	}
 
 	selectClause := `SELECT id, company_id, name, type, discount_value, max_amount, min_purchase,
-		target_type, priority, apply_to_order_types, created_at, updated_at`
+		target_type, priority, apply_to_order_types, start_at, end_at, is_active, created_at, updated_at`
 
 	query := selectClause + baseQuery
 	query += fmt.Sprintf(" ORDER BY %s %s", sortBy, sortOrder)
@@ -288,14 +411,14 @@ func (r *discountRepository) FindAll(companyID string, params models.PaginationP
 		if err != nil {
 			return nil, 0, err
 		}
-		if err := r.loadRelations(&d); err != nil {
-			return nil, 0, err
-		}
 		discounts = append(discounts, d)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, 0, err
 	}
+	if err := r.loadRelations(discounts); err != nil {
+		return nil, 0, err
+	}
 
 	return discounts, total, nil
 }
@@ -305,7 +428,7 @@ func (r *discountRepository) FindAll(companyID string, params models.PaginationP
 func (r *discountRepository) FindByID(id string) (models.Discount, error) {
 	row := r.db.QueryRow(`
 		SELECT id, company_id, name, type, discount_value, max_amount, min_purchase,
-		       target_type, priority, apply_to_order_types, created_at, updated_at
+		       target_type, priority, apply_to_order_types, start_at, end_at, is_active, created_at, updated_at
 		FROM discounts
 		WHERE id = $1
 	`, id)
@@ -315,11 +438,53 @@ func (r *discountRepository) FindByID(id string) (models.Discount, error) {
 		return models.Discount{}, err
 	}
 
-	if err := r.loadRelations(&d); err != nil {
+	discounts := []models.Discount{d}
+	if err := r.loadRelations(discounts); err != nil {
 		return models.Discount{}, err
 	}
 
-	return d, nil
+	return discounts[0], nil
+}
+
+// ─── FindAllByCompany ────────────────────────────────────────────────────────
+
+// FindAllByCompany mengambil semua diskon aktif milik company (tanpa pagination) untuk evaluasi keranjang.
+// Diskon yang terhubung ke kode promo dilewati, kecuali promoDiscountID (diskon dari kode promo yang sudah divalidasi).
+func (r *discountRepository) FindAllByCompany(companyID string, promoDiscountID string) ([]models.Discount, error) {
+	var promoID *string
+	if promoDiscountID != "" {
+		promoID = &promoDiscountID
+	}
+	rows, err := r.db.Query(`
+		SELECT id, company_id, name, type, discount_value, max_amount, min_purchase,
+		       target_type, priority, apply_to_order_types, start_at, end_at, is_active, created_at, updated_at
+		FROM discounts d
+		WHERE company_id = $1 AND is_active = TRUE
+		  AND (d.id = $2 OR NOT EXISTS (SELECT 1 FROM promo_codes pc WHERE pc.discount_id = d.id))
+		ORDER BY priority ASC, created_at ASC, id ASC
+	`, companyID, promoID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	discounts := []models.Discount{}
+	for rows.Next() {
+		d, err := scanDiscountFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		discounts = append(discounts, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadRelations(discounts); err != nil {
+		return nil, err
+	}
+
+	return discounts, nil
 }
 
 // ─── Create ──────────────────────────────────────────────────────────────────
@@ -334,8 +499,8 @@ func (r *discountRepository) Create(discount models.Discount, outletIDs, categor
 	err = tx.QueryRow(`
 		INSERT INTO discounts
 			(company_id, name, type, discount_value, max_amount, min_purchase,
-			 target_type, priority, apply_to_order_types, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			 target_type, priority, apply_to_order_types, start_at, end_at, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 		RETURNING id
 	`,
 		discount.CompanyID,
@@ -347,6 +512,9 @@ func (r *discountRepository) Create(discount models.Discount, outletIDs, categor
 		discount.TargetType,
 		discount.Priority,
 		discount.ApplyToOrderTypes,
+		discount.StartAt,
+		discount.EndAt,
+		discount.IsActive,
 		discount.CreatedAt,
 		discount.UpdatedAt,
 	).Scan(&discount.ID)
@@ -360,12 +528,18 @@ func (r *discountRepository) Create(discount models.Discount, outletIDs, categor
 	discount.TargetCategoryIDs = []models.DiscountTargetCategory{}
 	discount.TargetProductIDs = []models.DiscountTargetProduct{}
 	discount.OrderTypeIDs = []models.DiscountTargetOrderType{}
+	schedules := discount.Schedules
+	discount.Schedules = []models.DiscountSchedule{}
 
 	if err := insertJunctions(tx, discount.ID, &discount, outletIDs, categoryIDs, productIDs, orderTypeIDs); err != nil {
 		fmt.Println(err, "err insert junctions")
 		return models.Discount{}, err
 	}
 
+	if err := insertSchedules(tx, discount.ID, &discount, schedules); err != nil {
+		return models.Discount{}, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		fmt.Println(err, "err commit transaction")
 		return models.Discount{}, err
@@ -386,8 +560,9 @@ func (r *discountRepository) Update(discount models.Discount, outletIDs, categor
 	_, err = tx.Exec(`
 		UPDATE discounts
 		SET name = $1, type = $2, discount_value = $3, max_amount = $4, min_purchase = $5,
-		    target_type = $6, priority = $7, apply_to_order_types = $8, updated_at = $9
-		WHERE id = $10
+		    target_type = $6, priority = $7, apply_to_order_types = $8,
+		    start_at = $9, end_at = $10, is_active = $11, updated_at = $12
+		WHERE id = $13
 	`,
 		discount.Name,
 		discount.Type,
@@ -397,6 +572,9 @@ func (r *discountRepository) Update(discount models.Discount, outletIDs, categor
 		discount.TargetType,
 		discount.Priority,
 		discount.ApplyToOrderTypes,
+		discount.StartAt,
+		discount.EndAt,
+		discount.IsActive,
 		discount.UpdatedAt,
 		discount.ID,
 	)
@@ -414,11 +592,17 @@ func (r *discountRepository) Update(discount models.Discount, outletIDs, categor
 	discount.TargetCategoryIDs = []models.DiscountTargetCategory{}
 	discount.TargetProductIDs = []models.DiscountTargetProduct{}
 	discount.OrderTypeIDs = []models.DiscountTargetOrderType{}
+	schedules := discount.Schedules
+	discount.Schedules = []models.DiscountSchedule{}
 
 	if err := insertJunctions(tx, discount.ID, &discount, outletIDs, categoryIDs, productIDs, orderTypeIDs); err != nil {
 		return models.Discount{}, err
 	}
 
+	if err := insertSchedules(tx, discount.ID, &discount, schedules); err != nil {
+		return models.Discount{}, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return models.Discount{}, err
 	}
@@ -48,9 +48,22 @@ func (r *emailRepository) SendVerificationEmail(ctx context.Context, to, name, v
 	return err
 }
 
+// SendResetPasswordEmail mengirim link reset password; resetLink sudah memuat token.
 func (r *emailRepository) SendResetPasswordEmail(ctx context.Context, to, name, resetLink string) error {
-	// Similar implementation
-	return nil
+	params := &resend.SendEmailRequest{
+		From: r.from,
+		To:   []string{to},
+		Template: &resend.EmailTemplate{
+			Id: "reset-password",
+			Variables: map[string]interface{}{
+				"NAME": name,
+				"LINK": resetLink,
+			},
+		},
+	}
+
+	_, err := r.client.Emails.SendWithContext(ctx, params)
+	return err
 }
 
 func (r *emailRepository) SendOrderConfirmation(ctx context.Context, to, orderID string, items []string) error {
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"fmt"
+	"gowes/models"
+	"sort"
+)
+
+type filterKind int
+
+const (
+	filterValue  filterKind = iota // field=a atau field=a,b,c
+	filterDate                     // field_from / field_to untuk kolom TIMESTAMP (batas hari di zona waktu company)
+	filterDay                      // field_from / field_to untuk kolom DATE (dibandingkan sebagai tanggal YYYY-MM-DD)
+	filterNumber                   // field_min / field_max
+)
+
+// filterColumn memetakan nama field di query string ke kolom SQL. Hanya field yang terdaftar
+// yang masuk ke query, sehingga nama kolom tidak pernah berasal dari input user.
+type filterColumn struct {
+	column string
+	kind   filterKind
+}
+
+// applyFilterParams menambahkan kondisi AND untuk setiap filter yang dikirim dan ada di columns.
+// Nilai selalu dikirim sebagai argumen query; urutan field diurutkan agar query deterministik.
+func applyFilterParams(baseQuery string, args []interface{}, argIdx int, filter models.FilterParams, columns map[string]filterColumn) (string, []interface{}, int) {
+	fields := make([]string, 0, len(columns))
+	for field := range columns {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		col := columns[field]
+		switch col.kind {
+		case filterValue:
+			values := filter.Values[field]
+			if len(values) == 1 {
+				baseQuery += fmt.Sprintf(" AND %s = $%d", col.column, argIdx)
+				args = append(args, values[0])
+				argIdx++
+			} else if len(values) > 1 {
+				baseQuery += fmt.Sprintf(" AND %s = ANY($%d)", col.column, argIdx)
+				args = append(args, values)
+				argIdx++
+			}
+		case filterDate:
+			dr := filter.Dates[field]
+			if dr.From != nil {
+				baseQuery += fmt.Sprintf(" AND %s >= $%d", col.column, argIdx)
+				args = append(args, *dr.From)
+				argIdx++
+			}
+			if dr.To != nil {
+				// tanggal akhir inklusif: ambil sampai sebelum hari berikutnya
+				baseQuery += fmt.Sprintf(" AND %s < $%d", col.column, argIdx)
+				args = append(args, dr.To.AddDate(0, 0, 1))
+				argIdx++
+			}
+		case filterDay:
+			// Kolom DATE tidak punya zona waktu: hanya tanggal kalender yang dipakai,
+			// sehingga hasil tidak bergantung pada zona waktu company maupun sesi DB
+			dr := filter.Dates[field]
+			if dr.From != nil {
+				baseQuery += fmt.Sprintf(" AND %s >= $%d::date", col.column, argIdx)
+				args = append(args, dr.From.Format("2006-01-02"))
+				argIdx++
+			}
+			if dr.To != nil {
+				baseQuery += fmt.Sprintf(" AND %s <= $%d::date", col.column, argIdx)
+				args = append(args, dr.To.Format("2006-01-02"))
+				argIdx++
+			}
+		case filterNumber:
+			nr := filter.Numbers[field]
+			if nr.Min != nil {
+				baseQuery += fmt.Sprintf(" AND %s >= $%d", col.column, argIdx)
+				args = append(args, *nr.Min)
+				argIdx++
+			}
+			if nr.Max != nil {
+				baseQuery += fmt.Sprintf(" AND %s <= $%d", col.column, argIdx)
+				args = append(args, *nr.Max)
+				argIdx++
+			}
+		}
+	}
+	return baseQuery, args, argIdx
+}
@@ -0,0 +1,246 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"gowes/models"
+)
+
+type OutletProductRepository interface {
+	FindAll(companyID string, params models.PaginationParams, filter models.OutletProductFilter) ([]models.OutletProduct, int, error)
+	FindByID(id string, companyID string) (models.OutletProduct, error)
+	FindByOutletAndProducts(companyID string, outletID string, productIDs []string) ([]models.OutletProduct, error)
+	Create(outletProduct models.OutletProduct) (models.OutletProduct, error)
+	Update(outletProduct models.OutletProduct) (models.OutletProduct, error)
+	Delete(id string, companyID string) error
+	BulkUpsert(outletProducts []models.OutletProduct) (int, error)
+}
+
+type outletProductRepository struct {
+	db *sql.DB
+}
+
+func NewOutletProductRepository(db *sql.DB) OutletProductRepository {
+	return &outletProductRepository{db: db}
+}
+
+const outletProductSelectQuery = `SELECT op.id, op.company_id, op.outlet_id, o.name, op.product_id, p.name,
+		COALESCE(op.stock, 0), op.price, op.cost, op.is_active, op.created_at, op.updated_at
+		FROM outlet_products op
+		JOIN outlets o ON op.outlet_id = o.id
+		JOIN products p ON op.product_id = p.id`
+
+type outletProductScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanOutletProduct(row outletProductScanner) (models.OutletProduct, error) {
+	var op models.OutletProduct
+	var price, cost sql.NullFloat64
+	if err := row.Scan(
+		&op.ID,
+		&op.CompanyID,
+		&op.OutletID,
+		&op.OutletName,
+		&op.ProductID,
+		&op.ProductName,
+		&op.Stock,
+		&price,
+		&cost,
+		&op.IsActive,
+		&op.CreatedAt,
+		&op.UpdatedAt,
+	); err != nil {
+		return models.OutletProduct{}, err
+	}
+
+	if price.Valid {
+		op.Price = &price.Float64
+	}
+	if cost.Valid {
+		op.Cost = &cost.Float64
+	}
+	return op, nil
+}
+
+func (r *outletProductRepository) FindAll(companyID string, params models.PaginationParams, filter models.OutletProductFilter) ([]models.OutletProduct, int, error) {
+	baseQuery := `
+		FROM outlet_products op
+		JOIN outlets o ON op.outlet_id = o.id
+		JOIN products p ON op.product_id = p.id
+		WHERE op.company_id = $1`
+	args := []interface{}{companyID}
+	argIdx := 2
+
+	if filter.OutletID != "" {
+		baseQuery += fmt.Sprintf(" AND op.outlet_id = $%d", argIdx)
+		args = append(args, filter.OutletID)
+		argIdx++
+	}
+	if filter.ProductID != "" {
+		baseQuery += fmt.Sprintf(" AND op.product_id = $%d", argIdx)
+		args = append(args, filter.ProductID)
+		argIdx++
+	}
+	if params.Search != "" {
+		baseQuery += fmt.Sprintf(" AND (p.name ILIKE $%d OR o.name ILIKE $%d)", argIdx, argIdx)
+		args = append(args, "%"+params.Search+"%")
+		argIdx++
+	}
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*)"+baseQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	allowedSorts := map[string]string{
+		"product_name": "p.name",
+		"outlet_name":  "o.name",
+		"price":        "op.price",
+		"created_at":   "op.created_at",
+		"updated_at":   "op.updated_at",
+	}
+	sortBy := "op.created_at"
+	if col, ok := allowedSorts[params.SortBy]; ok {
+		sortBy = col
+	}
+
+	sortOrder := "DESC"
+	if params.SortOrder == "ASC" {
+		sortOrder = "ASC"
+	}
+
+	query := `SELECT op.id, op.company_id, op.outlet_id, o.name, op.product_id, p.name,
+		COALESCE(op.stock, 0), op.price, op.cost, op.is_active, op.created_at, op.updated_at` + baseQuery
+	query += fmt.Sprintf(" ORDER BY %s %s", sortBy, sortOrder)
+
+	offset := (params.Page - 1) * params.Limit
+	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
+	args = append(args, params.Limit, offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	outletProducts := []models.OutletProduct{}
+	for rows.Next() {
+		op, err := scanOutletProduct(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		outletProducts = append(outletProducts, op)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return outletProducts, total, nil
+}
+
+func (r *outletProductRepository) FindByID(id string, companyID string) (models.OutletProduct, error) {
+	return scanOutletProduct(r.db.QueryRow(outletProductSelectQuery+" WHERE op.id = $1 AND op.company_id = $2", id, companyID))
+}
+
+// FindByOutletAndProducts mengambil pengaturan beberapa produk di satu outlet (untuk perhitungan transaksi).
+// Produk tanpa pengaturan tidak ikut dikembalikan.
+func (r *outletProductRepository) FindByOutletAndProducts(companyID string, outletID string, productIDs []string) ([]models.OutletProduct, error) {
+	rows, err := r.db.Query(
+		outletProductSelectQuery+" WHERE op.company_id = $1 AND op.outlet_id = $2 AND op.product_id = ANY($3)",
+		companyID, outletID, productIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	outletProducts := []models.OutletProduct{}
+	for rows.Next() {
+		op, err := scanOutletProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		outletProducts = append(outletProducts, op)
+	}
+	return outletProducts, rows.Err()
+}
+
+func (r *outletProductRepository) Create(outletProduct models.OutletProduct) (models.OutletProduct, error) {
+	err := r.db.QueryRow(`
+		INSERT INTO outlet_products (company_id, outlet_id, product_id, price, cost, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`,
+		outletProduct.CompanyID, outletProduct.OutletID, outletProduct.ProductID,
+		outletProduct.Price, outletProduct.Cost, outletProduct.IsActive,
+		outletProduct.CreatedAt, outletProduct.UpdatedAt,
+	).Scan(&outletProduct.ID)
+	if err != nil {
+		return models.OutletProduct{}, err
+	}
+	return r.FindByID(outletProduct.ID, outletProduct.CompanyID)
+}
+
+func (r *outletProductRepository) Update(outletProduct models.OutletProduct) (models.OutletProduct, error) {
+	err := r.db.QueryRow(`
+		UPDATE outlet_products
+		SET price = $1, cost = $2, is_active = $3, updated_at = $4
+		WHERE id = $5 AND company_id = $6
+		RETURNING id
+	`,
+		outletProduct.Price, outletProduct.Cost, outletProduct.IsActive, outletProduct.UpdatedAt,
+		outletProduct.ID, outletProduct.CompanyID,
+	).Scan(&outletProduct.ID)
+	if err != nil {
+		return models.OutletProduct{}, err
+	}
+	return r.FindByID(outletProduct.ID, outletProduct.CompanyID)
+}
+
+func (r *outletProductRepository) Delete(id string, companyID string) error {
+	res, err := r.db.Exec(`DELETE FROM outlet_products WHERE id = $1 AND company_id = $2`, id, companyID)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// BulkUpsert menyimpan banyak pengaturan sekaligus dalam satu transaksi.
+// Kombinasi outlet + produk yang sudah ada akan diperbarui.
+func (r *outletProductRepository) BulkUpsert(outletProducts []models.OutletProduct) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	for _, op := range outletProducts {
+		if _, err := tx.Exec(`
+			INSERT INTO outlet_products (company_id, outlet_id, product_id, price, cost, is_active, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (outlet_id, product_id) DO UPDATE
+			SET price = EXCLUDED.price,
+			    cost = EXCLUDED.cost,
+			    is_active = EXCLUDED.is_active,
+			    updated_at = EXCLUDED.updated_at
+		`,
+			op.CompanyID, op.OutletID, op.ProductID, op.Price, op.Cost, op.IsActive,
+			op.CreatedAt, op.UpdatedAt,
+		); err != nil {
+			return 0, fmt.Errorf("upsert outlet product: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(outletProducts), nil
+}
@@ -11,6 +11,7 @@ import (
 type OutletRepository interface {
 	FindAll(companyID string, params models.PaginationParams) ([]models.Outlet, int, error)
 	FindByID(id string) (models.Outlet, error)
+	FindByIDs(companyID string, outletIDs []string) ([]models.Outlet, error)
 	Create(outlet *models.OutletInput, companyID string, context context.Context, tx *sql.Tx) (models.Outlet, error)
 	Update(outlet *models.OutletInput, id string) (models.Outlet, error)
 	Delete(id string) error
@@ -48,7 +49,7 @@ func (r *outletRepository) FindAll(companyID string, params models.PaginationPar
 		sortBy = params.SortBy
 	}
 
-	query := "SELECT id, code, name, supervisor, address, phone, email, is_active" + baseQuery
+	query := "SELECT id, code, name, supervisor, address, phone, email, is_active, allow_negative_stock" + baseQuery
 	query += fmt.Sprintf(" ORDER BY %s %s", sortBy, params.SortOrder)
 
 	offset := (params.Page - 1) * params.Limit
@@ -64,7 +65,7 @@ func (r *outletRepository) FindAll(companyID string, params models.PaginationPar
 	var outlets = []models.Outlet{}
 	for rows.Next() {
 		var outlet models.Outlet
-		if err := rows.Scan(&outlet.ID, &outlet.Code, &outlet.Name, &outlet.Supervisor, &outlet.Address, &outlet.Phone, &outlet.Email, &outlet.IsActive); err != nil {
+		if err := rows.Scan(&outlet.ID, &outlet.Code, &outlet.Name, &outlet.Supervisor, &outlet.Address, &outlet.Phone, &outlet.Email, &outlet.IsActive, &outlet.AllowNegativeStock); err != nil {
 			return nil, 0, err
 		}
 		outlets = append(outlets, outlet)
@@ -78,8 +79,8 @@ func (r *outletRepository) FindAll(companyID string, params models.PaginationPar
 
 func (r *outletRepository) FindByID(id string) (models.Outlet, error) {
 	var outlet models.Outlet
-	query := "SELECT id, code, name, supervisor, address, phone, email, is_active FROM outlets WHERE id = $1"
-	err := r.db.QueryRow(query, id).Scan(&outlet.ID, &outlet.Code, &outlet.Name, &outlet.Supervisor, &outlet.Address, &outlet.Phone, &outlet.Email, &outlet.IsActive)
+	query := "SELECT id, code, name, supervisor, address, phone, email, is_active, allow_negative_stock FROM outlets WHERE id = $1"
+	err := r.db.QueryRow(query, id).Scan(&outlet.ID, &outlet.Code, &outlet.Name, &outlet.Supervisor, &outlet.Address, &outlet.Phone, &outlet.Email, &outlet.IsActive, &outlet.AllowNegativeStock)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -90,17 +91,40 @@ func (r *outletRepository) FindByID(id string) (models.Outlet, error) {
 	return outlet, nil
 }
 
+// FindByIDs mengambil beberapa outlet milik company sekaligus.
+func (r *outletRepository) FindByIDs(companyID string, outletIDs []string) ([]models.Outlet, error) {
+	query := "SELECT id, company_id, code, name, supervisor, address, phone, email, is_active, allow_negative_stock FROM outlets WHERE company_id = $1 AND id = ANY($2)"
+	rows, err := r.db.Query(query, companyID, outletIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	outlets := []models.Outlet{}
+	for rows.Next() {
+		var outlet models.Outlet
+		if err := rows.Scan(&outlet.ID, &outlet.CompanyID, &outlet.Code, &outlet.Name, &outlet.Supervisor, &outlet.Address, &outlet.Phone, &outlet.Email, &outlet.IsActive, &outlet.AllowNegativeStock); err != nil {
+			return nil, err
+		}
+		outlets = append(outlets, outlet)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return outlets, nil
+}
+
 func (r *outletRepository) Create(outlet *models.OutletInput, companyID string, ctx context.Context, tx *sql.Tx) (models.Outlet, error) {
 	var createdOutlet models.Outlet
-	query := "INSERT INTO outlets (company_id, code, name, supervisor, address, phone, email, is_active) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, code, name, supervisor, address, phone, email, is_active"
+	query := "INSERT INTO outlets (company_id, code, name, supervisor, address, phone, email, is_active, allow_negative_stock) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, code, name, supervisor, address, phone, email, is_active, allow_negative_stock"
 	var row *sql.Row
 	if tx != nil {
-		row = tx.QueryRowContext(ctx, query, companyID, outlet.Code, outlet.Name, outlet.Supervisor, outlet.Address, outlet.Phone, outlet.Email, outlet.IsActive)
+		row = tx.QueryRowContext(ctx, query, companyID, outlet.Code, outlet.Name, outlet.Supervisor, outlet.Address, outlet.Phone, outlet.Email, outlet.IsActive, outlet.AllowNegativeStock)
 	} else {
-		row = r.db.QueryRowContext(ctx, query, companyID, outlet.Code, outlet.Name, outlet.Supervisor, outlet.Address, outlet.Phone, outlet.Email, outlet.IsActive)
+		row = r.db.QueryRowContext(ctx, query, companyID, outlet.Code, outlet.Name, outlet.Supervisor, outlet.Address, outlet.Phone, outlet.Email, outlet.IsActive, outlet.AllowNegativeStock)
 	}
 
-	err := row.Scan(&createdOutlet.ID, &createdOutlet.Code, &createdOutlet.Name, &createdOutlet.Supervisor, &createdOutlet.Address, &createdOutlet.Phone, &createdOutlet.Email, &createdOutlet.IsActive)
+	err := row.Scan(&createdOutlet.ID, &createdOutlet.Code, &createdOutlet.Name, &createdOutlet.Supervisor, &createdOutlet.Address, &createdOutlet.Phone, &createdOutlet.Email, &createdOutlet.IsActive, &createdOutlet.AllowNegativeStock)
 	if err != nil {
 		return models.Outlet{}, err
 	}
@@ -108,11 +132,11 @@ func (r *outletRepository) Create(outlet *models.OutletInput, companyID string,
 }
 
 func (r *outletRepository) Update(outlet *models.OutletInput, id string) (models.Outlet, error) {
-	query := "UPDATE outlets SET name = $1, supervisor = $2, address = $3, phone = $4, email = $5, is_active = $6 WHERE id = $7 RETURNING id, code, name, phone, email, address, supervisor, company_id, is_active"
-	args := []interface{}{outlet.Name, outlet.Supervisor, outlet.Address, outlet.Phone, outlet.Email, outlet.IsActive, id}
+	query := "UPDATE outlets SET name = $1, supervisor = $2, address = $3, phone = $4, email = $5, is_active = $6, allow_negative_stock = $7 WHERE id = $8 RETURNING id, code, name, phone, email, address, supervisor, company_id, is_active, allow_negative_stock"
+	args := []interface{}{outlet.Name, outlet.Supervisor, outlet.Address, outlet.Phone, outlet.Email, outlet.IsActive, outlet.AllowNegativeStock, id}
 
 	var outletResult models.Outlet
-	err := r.db.QueryRow(query, args...).Scan(&outletResult.ID, &outletResult.Code, &outletResult.Name, &outletResult.Phone, &outletResult.Email, &outletResult.Address, &outletResult.Supervisor, &outletResult.CompanyID, &outletResult.IsActive)
+	err := r.db.QueryRow(query, args...).Scan(&outletResult.ID, &outletResult.Code, &outletResult.Name, &outletResult.Phone, &outletResult.Email, &outletResult.Address, &outletResult.Supervisor, &outletResult.CompanyID, &outletResult.IsActive, &outletResult.AllowNegativeStock)
 	return outletResult, err
 }
 
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+)
+
+type PasswordResetRepository interface {
+	Create(userID string, tokenHash string, expiresAt time.Time) error
+	ResetPassword(tokenHash string, passwordHash string, now time.Time) (string, error)
+}
+
+type passwordResetRepository struct {
+	db *sql.DB
+}
+
+func NewPasswordResetRepository(db *sql.DB) PasswordResetRepository {
+	return &passwordResetRepository{db: db}
+}
+
+// Create menyimpan token reset baru; token lama user yang belum terpakai dibatalkan
+// sehingga hanya link di email terakhir yang berlaku.
+func (r *passwordResetRepository) Create(userID string, tokenHash string, expiresAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	if _, err := tx.Exec(
+		`UPDATE password_reset_tokens SET used_at = $1 WHERE user_id = $2 AND used_at IS NULL`,
+		now, userID,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4)
+	`, userID, tokenHash, expiresAt, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ResetPassword memakai token (sekali pakai), mengganti password, menaikkan token_version, dan
+// mencabut semua sesi agar JWT maupun refresh token yang sudah terbit tidak berlaku lagi.
+// sql.ErrNoRows jika token tidak valid, sudah dipakai, atau kedaluwarsa.
+func (r *passwordResetRepository) ResetPassword(tokenHash string, passwordHash string, now time.Time) (string, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var tokenID, userID string
+	if err := tx.QueryRow(`
+		SELECT id, user_id FROM password_reset_tokens
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2
+		FOR UPDATE
+	`, tokenHash, now).Scan(&tokenID, &userID); err != nil {
+		return "", err
+	}
+
+	if _, err := tx.Exec(`UPDATE password_reset_tokens SET used_at = $1 WHERE id = $2`, now, tokenID); err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(`
+		UPDATE users SET password_hash = $1, token_version = token_version + 1, updated_at = $2
+		WHERE id = $3
+	`, passwordHash, now, userID); err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(
+		`UPDATE user_sessions SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL`,
+		now, userID,
+	); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+	return userID, nil
+}
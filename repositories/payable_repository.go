@@ -0,0 +1,269 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"gowes/models"
+	"math"
+)
+
+// Error status dan nominal yang dicek di dalam transaksi pembayaran hutang
+var (
+	ErrPurchaseNotPayable     = errors.New("draft, cancelled or voided purchases cannot be invoiced or paid")
+	ErrPurchasePaymentExceeds = errors.New("payment amount exceeds the outstanding balance")
+)
+
+type PayableRepository interface {
+	FindPayments(purchaseID string, companyID string) ([]models.PurchasePayment, error)
+	SetInvoice(purchase models.Purchase) error
+	CreatePayment(payment models.PurchasePayment) (models.PurchasePayment, error)
+	Aging(companyID string, filter models.PayableAgingFilter) ([]models.PayableAging, error)
+}
+
+type payableRepository struct {
+	db *sql.DB
+}
+
+func NewPayableRepository(db *sql.DB) PayableRepository {
+	return &payableRepository{db: db}
+}
+
+// payableDueDateExpr: jatuh tempo eksplisit, atau tanggal invoice (tanggal purchase jika belum ada invoice) + termin
+const payableDueDateExpr = `COALESCE(p.due_date, COALESCE(p.invoice_date, p.created_at::date) + p.payment_term_days)`
+
+// payableOutstandingExpr: sisa hutang setelah dikurangi nilai retur dan pembayaran
+const payableOutstandingExpr = `p.grand_total - p.returned_amount - p.paid_amount`
+
+// payableCondition: purchase yang sudah menjadi hutang, yaitu sudah ada invoice atau barang sudah diterima
+const payableCondition = `p.status NOT IN ('draft', 'cancelled', 'voided')
+		AND (p.invoice_date IS NOT NULL OR p.status IN ('partially_received', 'received', 'partially_returned', 'returned'))`
+
+func (r *payableRepository) FindPayments(purchaseID string, companyID string) ([]models.PurchasePayment, error) {
+	var exists bool
+	if err := r.db.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM purchases WHERE id = $1 AND company_id = $2)`, purchaseID, companyID,
+	).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+	return loadPurchasePayments(r.db, purchaseID)
+}
+
+// SetInvoice mencatat nomor, tanggal dan jatuh tempo invoice supplier.
+func (r *payableRepository) SetInvoice(purchase models.Purchase) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	status, err := lockPurchase(tx, purchase.ID, purchase.CompanyID)
+	if err != nil {
+		return err
+	}
+	if !purchaseIsPayable(status) {
+		return ErrPurchaseNotPayable
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE purchases
+		SET invoice_number = $1, invoice_date = $2, payment_term_days = $3, due_date = $4, updated_at = $5
+		WHERE id = $6
+	`,
+		purchase.InvoiceNumber,
+		purchase.InvoiceDate,
+		purchase.PaymentTermDays,
+		purchase.DueDate,
+		purchase.UpdatedAt,
+		purchase.ID,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CreatePayment mencatat pembayaran dan memperbarui paid_amount serta payment_status purchase.
+// Pembayaran tidak boleh melebihi sisa hutang.
+func (r *payableRepository) CreatePayment(payment models.PurchasePayment) (models.PurchasePayment, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.PurchasePayment{}, err
+	}
+	defer tx.Rollback()
+
+	status, err := lockPurchase(tx, payment.PurchaseID, payment.CompanyID)
+	if err != nil {
+		return models.PurchasePayment{}, err
+	}
+	if !purchaseIsPayable(status) {
+		return models.PurchasePayment{}, ErrPurchaseNotPayable
+	}
+
+	// Barang yang sudah diretur tidak perlu dibayar
+	var payableTotal, paidAmount float64
+	if err := tx.QueryRow(
+		`SELECT grand_total - returned_amount, paid_amount FROM purchases WHERE id = $1`, payment.PurchaseID,
+	).Scan(&payableTotal, &paidAmount); err != nil {
+		return models.PurchasePayment{}, err
+	}
+	if payment.Amount > roundMoney(payableTotal-paidAmount) {
+		return models.PurchasePayment{}, ErrPurchasePaymentExceeds
+	}
+
+	if err := tx.QueryRow(`
+		INSERT INTO purchase_payments (company_id, purchase_id, user_id, amount, payment_method, reference, note, paid_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`,
+		payment.CompanyID,
+		payment.PurchaseID,
+		payment.UserID,
+		payment.Amount,
+		payment.PaymentMethod,
+		payment.Reference,
+		payment.Note,
+		payment.PaidAt,
+		payment.CreatedAt,
+	).Scan(&payment.ID); err != nil {
+		return models.PurchasePayment{}, err
+	}
+
+	paidAmount = roundMoney(paidAmount + payment.Amount)
+	paymentStatus := models.PurchasePaymentStatusPartiallyPaid
+	if paidAmount >= roundMoney(payableTotal) {
+		paymentStatus = models.PurchasePaymentStatusPaid
+	}
+
+	if _, err := tx.Exec(
+		`UPDATE purchases SET paid_amount = $1, payment_status = $2, updated_at = $3 WHERE id = $4`,
+		paidAmount, paymentStatus, payment.CreatedAt, payment.PurchaseID,
+	); err != nil {
+		return models.PurchasePayment{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.PurchasePayment{}, err
+	}
+	return payment, nil
+}
+
+// Aging mengelompokkan sisa hutang per supplier menurut jumlah hari lewat jatuh tempo pada tanggal AsOf.
+func (r *payableRepository) Aging(companyID string, filter models.PayableAgingFilter) ([]models.PayableAging, error) {
+	where := " WHERE p.company_id = $1 AND p.supplier_id IS NOT NULL AND " + payableOutstandingExpr + " > 0 AND " + payableCondition
+	args := []interface{}{companyID, filter.AsOf.Format("2006-01-02")}
+	argIdx := 3
+
+	if filter.SupplierID != "" {
+		where += fmt.Sprintf(" AND p.supplier_id = $%d", argIdx)
+		args = append(args, filter.SupplierID)
+		argIdx++
+	}
+	if filter.OutletID != "" {
+		where += fmt.Sprintf(" AND p.outlet_id = $%d", argIdx)
+		args = append(args, filter.OutletID)
+		argIdx++
+	}
+
+	query := `
+		SELECT s.id, s.name,
+			SUM(CASE WHEN x.days_overdue <= 0 THEN x.outstanding ELSE 0 END),
+			SUM(CASE WHEN x.days_overdue BETWEEN 1 AND 30 THEN x.outstanding ELSE 0 END),
+			SUM(CASE WHEN x.days_overdue BETWEEN 31 AND 60 THEN x.outstanding ELSE 0 END),
+			SUM(CASE WHEN x.days_overdue BETWEEN 61 AND 90 THEN x.outstanding ELSE 0 END),
+			SUM(CASE WHEN x.days_overdue > 90 THEN x.outstanding ELSE 0 END),
+			SUM(x.outstanding)
+		FROM (
+			SELECT p.supplier_id, ` + payableOutstandingExpr + ` AS outstanding,
+				$2::date - ` + payableDueDateExpr + ` AS days_overdue
+			FROM purchases p` + where + `
+		) x
+		JOIN suppliers s ON x.supplier_id = s.id
+		GROUP BY s.id, s.name
+		ORDER BY s.name ASC`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aging := []models.PayableAging{}
+	for rows.Next() {
+		var a models.PayableAging
+		if err := rows.Scan(
+			&a.SupplierID,
+			&a.SupplierName,
+			&a.Current,
+			&a.Days1To30,
+			&a.Days31To60,
+			&a.Days61To90,
+			&a.Over90,
+			&a.Total,
+		); err != nil {
+			return nil, err
+		}
+		aging = append(aging, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return aging, nil
+}
+
+func loadPurchasePayments(db *sql.DB, purchaseID string) ([]models.PurchasePayment, error) {
+	rows, err := db.Query(`
+		SELECT pp.id, pp.company_id, pp.purchase_id, pp.user_id, u.username, pp.amount, pp.payment_method,
+			COALESCE(pp.reference, ''), COALESCE(pp.note, ''), pp.paid_at, pp.created_at
+		FROM purchase_payments pp
+		JOIN users u ON pp.user_id = u.id
+		WHERE pp.purchase_id = $1
+		ORDER BY pp.paid_at ASC, pp.created_at ASC
+	`, purchaseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := []models.PurchasePayment{}
+	for rows.Next() {
+		var payment models.PurchasePayment
+		if err := rows.Scan(
+			&payment.ID,
+			&payment.CompanyID,
+			&payment.PurchaseID,
+			&payment.UserID,
+			&payment.UserName,
+			&payment.Amount,
+			&payment.PaymentMethod,
+			&payment.Reference,
+			&payment.Note,
+			&payment.PaidAt,
+			&payment.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+// purchaseIsPayable: purchase yang boleh dicatat invoice dan pembayarannya
+func purchaseIsPayable(status models.PurchaseStatus) bool {
+	switch status {
+	case models.PurchaseStatusDraft, models.PurchaseStatusCancelled, models.PurchaseStatusVoided:
+		return false
+	}
+	return true
+}
+
+// roundMoney membulatkan nominal ke skala kolom uang (NUMERIC(15,2))
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
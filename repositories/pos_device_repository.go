@@ -0,0 +1,175 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"gowes/models"
+	"time"
+)
+
+type PosDeviceRepository interface {
+	FindAll(companyID string, params models.PaginationParams) ([]models.PosDevice, int, error)
+	FindByID(id string, companyID string) (models.PosDevice, error)
+	FindActiveByKey(keyHash string) (models.PosDevice, error)
+	Create(device models.PosDevice, keyHash string) (models.PosDevice, error)
+	Deactivate(id string, companyID string) error
+	TouchLastSeen(id string, at time.Time) error
+}
+
+type posDeviceRepository struct {
+	db *sql.DB
+}
+
+func NewPosDeviceRepository(db *sql.DB) PosDeviceRepository {
+	return &posDeviceRepository{db: db}
+}
+
+const posDeviceSelectColumns = `SELECT d.id, d.company_id, d.outlet_id, o.name, d.name, d.is_active, d.last_seen_at, d.created_by, d.created_at, d.updated_at`
+
+const posDeviceFromClause = `
+		FROM pos_devices d
+		JOIN outlets o ON d.outlet_id = o.id
+`
+
+type posDeviceScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPosDevice(row posDeviceScanner) (models.PosDevice, error) {
+	var device models.PosDevice
+	var lastSeenAt sql.NullTime
+	var createdBy sql.NullString
+	if err := row.Scan(
+		&device.ID,
+		&device.CompanyID,
+		&device.OutletID,
+		&device.OutletName,
+		&device.Name,
+		&device.IsActive,
+		&lastSeenAt,
+		&createdBy,
+		&device.CreatedAt,
+		&device.UpdatedAt,
+	); err != nil {
+		return models.PosDevice{}, err
+	}
+	if lastSeenAt.Valid {
+		device.LastSeenAt = &lastSeenAt.Time
+	}
+	if createdBy.Valid {
+		device.CreatedBy = &createdBy.String
+	}
+	return device, nil
+}
+
+func (r *posDeviceRepository) FindAll(companyID string, params models.PaginationParams) ([]models.PosDevice, int, error) {
+	baseQuery := posDeviceFromClause + " WHERE d.company_id = $1"
+	args := []interface{}{companyID}
+	argIdx := 2
+
+	if params.Search != "" {
+		baseQuery += fmt.Sprintf(" AND (d.name ILIKE $%d OR o.name ILIKE $%d)", argIdx, argIdx)
+		args = append(args, "%"+params.Search+"%")
+		argIdx++
+	}
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*)"+baseQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	allowedSorts := map[string]string{
+		"created_at":   "d.created_at",
+		"name":         "d.name",
+		"outlet":       "o.name",
+		"last_seen_at": "d.last_seen_at",
+	}
+	sortBy := "d.created_at"
+	if col, ok := allowedSorts[params.SortBy]; ok {
+		sortBy = col
+	}
+
+	sortOrder := "DESC"
+	if params.SortOrder == "ASC" {
+		sortOrder = "ASC"
+	}
+
+	query := posDeviceSelectColumns + baseQuery
+	query += fmt.Sprintf(" ORDER BY %s %s", sortBy, sortOrder)
+
+	offset := (params.Page - 1) * params.Limit
+	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
+	args = append(args, params.Limit, offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	devices := []models.PosDevice{}
+	for rows.Next() {
+		device, err := scanPosDevice(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		devices = append(devices, device)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return devices, total, nil
+}
+
+func (r *posDeviceRepository) FindByID(id string, companyID string) (models.PosDevice, error) {
+	return scanPosDevice(r.db.QueryRow(posDeviceSelectColumns+posDeviceFromClause+" WHERE d.id = $1 AND d.company_id = $2", id, companyID))
+}
+
+// FindActiveByKey mencari perangkat aktif dari hash device key; sql.ErrNoRows jika tidak ada.
+func (r *posDeviceRepository) FindActiveByKey(keyHash string) (models.PosDevice, error) {
+	return scanPosDevice(r.db.QueryRow(posDeviceSelectColumns+posDeviceFromClause+" WHERE d.device_key_hash = $1 AND d.is_active = true", keyHash))
+}
+
+func (r *posDeviceRepository) Create(device models.PosDevice, keyHash string) (models.PosDevice, error) {
+	if err := r.db.QueryRow(`
+		INSERT INTO pos_devices (company_id, outlet_id, name, device_key_hash, is_active, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`,
+		device.CompanyID,
+		device.OutletID,
+		device.Name,
+		keyHash,
+		device.IsActive,
+		device.CreatedBy,
+		device.CreatedAt,
+		device.UpdatedAt,
+	).Scan(&device.ID); err != nil {
+		return models.PosDevice{}, err
+	}
+	return r.FindByID(device.ID, device.CompanyID)
+}
+
+func (r *posDeviceRepository) Deactivate(id string, companyID string) error {
+	res, err := r.db.Exec(
+		`UPDATE pos_devices SET is_active = false, updated_at = $1 WHERE id = $2 AND company_id = $3`,
+		time.Now().UTC(), id, companyID,
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *posDeviceRepository) TouchLastSeen(id string, at time.Time) error {
+	_, err := r.db.Exec(`UPDATE pos_devices SET last_seen_at = $1 WHERE id = $2`, at, id)
+	return err
+}
@@ -7,13 +7,14 @@ import (
 )
 
 type ProductRepository interface {
-	FindAll(companyID string, params models.PaginationParams) ([]models.ProductList, int, error)
+	FindAll(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.ProductList, int, error)
 	Create(companyID string, payload models.ProductInput) (models.Product, error)
 	FindByID(productID string) (models.Product, error)
 	Update(productID string, payload models.ProductInput) (models.Product, error)
 	DeleteById(productID string) (string, error)
 	UpdateAddOnsByProductID(addOnIDs []string, productID string, companyID string) ([]models.AddOnProduct, error)
-	FindAllMobile(companyID string) ([]models.ProductList, error)
+	FindAllMobile(companyID string, outletID string) ([]models.ProductList, error)
+	FindByIDs(companyID string, productIDs []string) ([]models.Product, error)
 }
 
 type productRepository struct {
@@ -24,7 +25,18 @@ func NewProductRepository(db *sql.DB) ProductRepository {
 	return &productRepository{db: db}
 }
 
-func (r *productRepository) FindAll(companyID string, params models.PaginationParams) ([]models.ProductList, int, error) {
+// productFilterColumns: filter query string yang didukung list produk
+var productFilterColumns = map[string]filterColumn{
+	"category_id":    {column: "p.category_id", kind: filterValue},
+	"unit_id":        {column: "p.unit_id", kind: filterValue},
+	"type":           {column: "p.type", kind: filterValue},
+	"costing_method": {column: "p.costing_method", kind: filterValue},
+	"created_at":     {column: "p.created_at", kind: filterDate},
+	"price":          {column: "p.price", kind: filterNumber},
+	"cost":           {column: "p.cost", kind: filterNumber},
+}
+
+func (r *productRepository) FindAll(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.ProductList, int, error) {
 	baseQuery := `
 		FROM products p
 		JOIN categories c ON p.category_id = c.id
@@ -33,6 +45,8 @@ func (r *productRepository) FindAll(companyID string, params models.PaginationPa
 	args := []interface{}{companyID}
 	argIndex := 2
 
+	baseQuery, args, argIndex = applyFilterParams(baseQuery, args, argIndex, filter, productFilterColumns)
+
 	if params.Search != "" {
 		baseQuery += fmt.Sprintf(" AND (p.name ILIKE $%d OR p.sku ILIKE $%d)", argIndex, argIndex+1)
 		args = append(args, "%"+params.Search+"%", "%"+params.Search+"%")
@@ -100,11 +114,11 @@ func (r *productRepository) FindAll(companyID string, params models.PaginationPa
 
 func (r *productRepository) Create(companyID string, payload models.ProductInput) (models.Product, error) {
 	var createProduct models.Product
-	queryInsert := "INSERT INTO products (name, sku, unit, unit_id, cost, price, image_url, company_id, category_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, name, sku, unit, unit_id, cost, price, image_url, company_id, category_id, created_at, updated_at"
-	args := []interface{}{payload.Name, payload.SKU, payload.Unit, payload.UnitID, payload.Cost, payload.Price, payload.ImageURL, companyID, payload.CategoryID}
+	queryInsert := "INSERT INTO products (name, sku, unit, unit_id, cost, price, image_url, company_id, category_id, type, costing_method) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id, name, sku, unit, unit_id, cost, price, image_url, company_id, category_id, type, costing_method, created_at, updated_at"
+	args := []interface{}{payload.Name, payload.SKU, payload.Unit, payload.UnitID, payload.Cost, payload.Price, payload.ImageURL, companyID, payload.CategoryID, payload.Type, payload.CostingMethod}
 	var row *sql.Row
 	row = r.db.QueryRow(queryInsert, args...)
-	if err := row.Scan(&createProduct.ID, &createProduct.Name, &createProduct.SKU, &createProduct.Unit, &createProduct.UnitID, &createProduct.Cost, &createProduct.Price, &createProduct.ImageURL, &createProduct.CompanyID, &createProduct.CategoryID, &createProduct.CreatedAt, &createProduct.UpdatedAt); err != nil {
+	if err := row.Scan(&createProduct.ID, &createProduct.Name, &createProduct.SKU, &createProduct.Unit, &createProduct.UnitID, &createProduct.Cost, &createProduct.Price, &createProduct.ImageURL, &createProduct.CompanyID, &createProduct.CategoryID, &createProduct.Type, &createProduct.CostingMethod, &createProduct.CreatedAt, &createProduct.UpdatedAt); err != nil {
 		return models.Product{}, err
 	}
 	return createProduct, nil
@@ -112,9 +126,9 @@ func (r *productRepository) Create(companyID string, payload models.ProductInput
 
 func (r *productRepository) FindByID(productID string) (models.Product, error) {
 	var product models.Product
-	query := "SELECT id, name, sku, unit, unit_id, cost, price, image_url, company_id, category_id, created_at, updated_at FROM products WHERE id = $1"
+	query := "SELECT id, name, sku, unit, unit_id, cost, price, image_url, company_id, category_id, type, costing_method, created_at, updated_at FROM products WHERE id = $1"
 	row := r.db.QueryRow(query, productID)
-	if err := row.Scan(&product.ID, &product.Name, &product.SKU, &product.Unit, &product.UnitID, &product.Cost, &product.Price, &product.ImageURL, &product.CompanyID, &product.CategoryID, &product.CreatedAt, &product.UpdatedAt); err != nil {
+	if err := row.Scan(&product.ID, &product.Name, &product.SKU, &product.Unit, &product.UnitID, &product.Cost, &product.Price, &product.ImageURL, &product.CompanyID, &product.CategoryID, &product.Type, &product.CostingMethod, &product.CreatedAt, &product.UpdatedAt); err != nil {
 		return models.Product{}, err
 	}
 	return product, nil
@@ -124,9 +138,9 @@ func (r *productRepository) Update(productID string, payload models.ProductInput
 	var product models.Product
 	query := `
 		UPDATE products
-		SET name = $1, sku = $2, unit = $3, unit_id = $4, cost = $5, price = $6, image_url = $7, category_id = $8, updated_at = NOW()
-		WHERE id = $9
-		RETURNING id, name, sku, unit, unit_id, cost, price, image_url, company_id, category_id, created_at, updated_at
+		SET name = $1, sku = $2, unit = $3, unit_id = $4, cost = $5, price = $6, image_url = $7, category_id = $8, type = $9, costing_method = $10, updated_at = NOW()
+		WHERE id = $11
+		RETURNING id, name, sku, unit, unit_id, cost, price, image_url, company_id, category_id, type, costing_method, created_at, updated_at
 	`
 	err := r.db.QueryRow(query,
 		payload.Name,
@@ -137,11 +151,13 @@ func (r *productRepository) Update(productID string, payload models.ProductInput
 		payload.Price,
 		payload.ImageURL,
 		payload.CategoryID,
+		payload.Type,
+		payload.CostingMethod,
 		productID,
 	).Scan(
 		&product.ID, &product.Name, &product.SKU, &product.Unit, &product.UnitID,
 		&product.Cost, &product.Price, &product.ImageURL,
-		&product.CompanyID, &product.CategoryID,
+		&product.CompanyID, &product.CategoryID, &product.Type, &product.CostingMethod,
 		&product.CreatedAt, &product.UpdatedAt,
 	)
 	if err != nil {
@@ -177,13 +193,25 @@ func (r *productRepository) UpdateAddOnsByProductID(addOnIDs []string, productID
 	return addOns, nil
 }
 
-func (r *productRepository) FindAllMobile(companyID string) ([]models.ProductList, error) {
+// FindAllMobile mengambil produk untuk POS. Jika outletID diisi, harga dan HPP memakai
+// pengaturan outlet_products (bila ada) dan produk yang dinonaktifkan di outlet tersebut disembunyikan.
+func (r *productRepository) FindAllMobile(companyID string, outletID string) ([]models.ProductList, error) {
 	query := `
 		SELECT id, name, sku, unit, unit_id, cost, price, image_url, category_id
 		FROM products
 		WHERE company_id = $1
 	`
-	rows, err := r.db.Query(query, companyID)
+	args := []interface{}{companyID}
+	if outletID != "" {
+		query = `
+			SELECT p.id, p.name, p.sku, p.unit, p.unit_id, COALESCE(op.cost, p.cost), COALESCE(op.price, p.price), p.image_url, p.category_id
+			FROM products p
+			LEFT JOIN outlet_products op ON op.product_id = p.id AND op.outlet_id = $2
+			WHERE p.company_id = $1 AND COALESCE(op.is_active, TRUE)
+		`
+		args = append(args, outletID)
+	}
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -221,5 +249,33 @@ func (r *productRepository) FindAllMobile(companyID string) ([]models.ProductLis
 	}
 	
 
+	return products, nil
+}
+
+// FindByIDs mengambil beberapa produk milik company sekaligus (untuk perhitungan transaksi).
+func (r *productRepository) FindByIDs(companyID string, productIDs []string) ([]models.Product, error) {
+	query := `
+		SELECT id, name, sku, COALESCE(unit, ''), COALESCE(unit_id::text, ''), cost, price, COALESCE(image_url, ''), company_id, COALESCE(category_id::text, ''), type, costing_method, created_at, updated_at
+		FROM products
+		WHERE company_id = $1 AND id = ANY($2)
+	`
+	rows, err := r.db.Query(query, companyID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []models.Product{}
+	for rows.Next() {
+		var product models.Product
+		if err := rows.Scan(&product.ID, &product.Name, &product.SKU, &product.Unit, &product.UnitID, &product.Cost, &product.Price, &product.ImageURL, &product.CompanyID, &product.CategoryID, &product.Type, &product.CostingMethod, &product.CreatedAt, &product.UpdatedAt); err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
 	return products, nil
 }
@@ -0,0 +1,283 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"gowes/models"
+)
+
+// Error yang dikembalikan Redeem ketika kode promo tidak bisa dipakai.
+// Pengecekan dilakukan di dalam transaksi dengan row lock agar tidak terjadi double redeem.
+var (
+	ErrPromoCodeInactive             = errors.New("promo code is not active")
+	ErrPromoCodeUsageLimitReached    = errors.New("promo code usage limit reached")
+	ErrPromoCodeCustomerLimitReached = errors.New("promo code usage limit for this customer reached")
+)
+
+type PromoCodeRepository interface {
+	FindAll(companyID string, params models.PaginationParams) ([]models.PromoCode, int, error)
+	FindByID(id string, companyID string) (models.PromoCode, error)
+	FindByCode(companyID string, code string) (models.PromoCode, error)
+	CountCustomerRedemptions(promoCodeID string, customerID string) (int, error)
+	Create(promoCode models.PromoCode) (models.PromoCode, error)
+	Update(promoCode models.PromoCode) (models.PromoCode, error)
+	Delete(id string, companyID string) error
+	Redeem(companyID string, code string, redemption models.PromoCodeRedemption) (models.PromoCodeRedemption, models.PromoCode, error)
+}
+
+type promoCodeRepository struct {
+	db *sql.DB
+}
+
+func NewPromoCodeRepository(db *sql.DB) PromoCodeRepository {
+	return &promoCodeRepository{db: db}
+}
+
+const promoCodeSelectQuery = `SELECT pc.id, pc.company_id, pc.discount_id, d.name, pc.code, pc.max_uses, pc.max_uses_per_customer,
+		pc.used_count, pc.is_active, pc.created_at, pc.updated_at
+		FROM promo_codes pc
+		JOIN discounts d ON pc.discount_id = d.id`
+
+type promoCodeScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPromoCode(row promoCodeScanner) (models.PromoCode, error) {
+	var pc models.PromoCode
+	var maxUses, maxUsesPerCustomer sql.NullInt64
+	if err := row.Scan(
+		&pc.ID,
+		&pc.CompanyID,
+		&pc.DiscountID,
+		&pc.DiscountName,
+		&pc.Code,
+		&maxUses,
+		&maxUsesPerCustomer,
+		&pc.UsedCount,
+		&pc.IsActive,
+		&pc.CreatedAt,
+		&pc.UpdatedAt,
+	); err != nil {
+		return models.PromoCode{}, err
+	}
+
+	if maxUses.Valid {
+		v := int(maxUses.Int64)
+		pc.MaxUses = &v
+	}
+	if maxUsesPerCustomer.Valid {
+		v := int(maxUsesPerCustomer.Int64)
+		pc.MaxUsesPerCustomer = &v
+	}
+	return pc, nil
+}
+
+func (r *promoCodeRepository) FindAll(companyID string, params models.PaginationParams) ([]models.PromoCode, int, error) {
+	baseQuery := " FROM promo_codes pc JOIN discounts d ON pc.discount_id = d.id WHERE pc.company_id = $1"
+	args := []interface{}{companyID}
+	argIdx := 2
+
+	if params.Search != "" {
+		baseQuery += fmt.Sprintf(" AND (pc.code ILIKE $%d OR d.name ILIKE $%d)", argIdx, argIdx)
+		args = append(args, "%"+params.Search+"%")
+		argIdx++
+	}
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*)"+baseQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	allowedSorts := map[string]string{
+		"code":       "pc.code",
+		"used_count": "pc.used_count",
+		"created_at": "pc.created_at",
+		"updated_at": "pc.updated_at",
+	}
+	sortBy := "pc.created_at"
+	if col, ok := allowedSorts[params.SortBy]; ok {
+		sortBy = col
+	}
+
+	sortOrder := "DESC"
+	if params.SortOrder == "ASC" {
+		sortOrder = "ASC"
+	}
+
+	query := `SELECT pc.id, pc.company_id, pc.discount_id, d.name, pc.code, pc.max_uses, pc.max_uses_per_customer,
+		pc.used_count, pc.is_active, pc.created_at, pc.updated_at` + baseQuery
+	query += fmt.Sprintf(" ORDER BY %s %s", sortBy, sortOrder)
+
+	offset := (params.Page - 1) * params.Limit
+	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
+	args = append(args, params.Limit, offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	promoCodes := []models.PromoCode{}
+	for rows.Next() {
+		pc, err := scanPromoCode(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		promoCodes = append(promoCodes, pc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return promoCodes, total, nil
+}
+
+func (r *promoCodeRepository) FindByID(id string, companyID string) (models.PromoCode, error) {
+	return scanPromoCode(r.db.QueryRow(promoCodeSelectQuery+" WHERE pc.id = $1 AND pc.company_id = $2", id, companyID))
+}
+
+func (r *promoCodeRepository) FindByCode(companyID string, code string) (models.PromoCode, error) {
+	return scanPromoCode(r.db.QueryRow(promoCodeSelectQuery+" WHERE pc.company_id = $1 AND pc.code = $2", companyID, code))
+}
+
+func (r *promoCodeRepository) CountCustomerRedemptions(promoCodeID string, customerID string) (int, error) {
+	var count int
+	err := r.db.QueryRow(
+		`SELECT COUNT(*) FROM promo_code_redemptions WHERE promo_code_id = $1 AND customer_id = $2`,
+		promoCodeID, customerID,
+	).Scan(&count)
+	return count, err
+}
+
+func (r *promoCodeRepository) Create(promoCode models.PromoCode) (models.PromoCode, error) {
+	err := r.db.QueryRow(`
+		INSERT INTO promo_codes (company_id, discount_id, code, max_uses, max_uses_per_customer, used_count, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, 0, $6, $7, $8)
+		RETURNING id
+	`,
+		promoCode.CompanyID,
+		promoCode.DiscountID,
+		promoCode.Code,
+		promoCode.MaxUses,
+		promoCode.MaxUsesPerCustomer,
+		promoCode.IsActive,
+		promoCode.CreatedAt,
+		promoCode.UpdatedAt,
+	).Scan(&promoCode.ID)
+	if err != nil {
+		return models.PromoCode{}, err
+	}
+
+	return r.FindByID(promoCode.ID, promoCode.CompanyID)
+}
+
+func (r *promoCodeRepository) Update(promoCode models.PromoCode) (models.PromoCode, error) {
+	res, err := r.db.Exec(`
+		UPDATE promo_codes
+		SET discount_id = $1, code = $2, max_uses = $3, max_uses_per_customer = $4, is_active = $5, updated_at = $6
+		WHERE id = $7 AND company_id = $8
+	`,
+		promoCode.DiscountID,
+		promoCode.Code,
+		promoCode.MaxUses,
+		promoCode.MaxUsesPerCustomer,
+		promoCode.IsActive,
+		promoCode.UpdatedAt,
+		promoCode.ID,
+		promoCode.CompanyID,
+	)
+	if err != nil {
+		return models.PromoCode{}, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return models.PromoCode{}, err
+	}
+	if count == 0 {
+		return models.PromoCode{}, sql.ErrNoRows
+	}
+
+	return r.FindByID(promoCode.ID, promoCode.CompanyID)
+}
+
+func (r *promoCodeRepository) Delete(id string, companyID string) error {
+	res, err := r.db.Exec(`DELETE FROM promo_codes WHERE id = $1 AND company_id = $2`, id, companyID)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Redeem memakai kode promo secara atomik: baris promo_codes dikunci (FOR UPDATE),
+// batas pemakaian dicek, ledger dicatat lalu used_count dinaikkan dalam satu transaksi.
+func (r *promoCodeRepository) Redeem(companyID string, code string, redemption models.PromoCodeRedemption) (models.PromoCodeRedemption, models.PromoCode, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.PromoCodeRedemption{}, models.PromoCode{}, err
+	}
+	defer tx.Rollback()
+
+	pc, err := scanPromoCode(tx.QueryRow(promoCodeSelectQuery+" WHERE pc.company_id = $1 AND pc.code = $2 FOR UPDATE OF pc", companyID, code))
+	if err != nil {
+		return models.PromoCodeRedemption{}, models.PromoCode{}, err
+	}
+
+	if !pc.IsActive {
+		return models.PromoCodeRedemption{}, models.PromoCode{}, ErrPromoCodeInactive
+	}
+	if pc.MaxUses != nil && pc.UsedCount >= *pc.MaxUses {
+		return models.PromoCodeRedemption{}, models.PromoCode{}, ErrPromoCodeUsageLimitReached
+	}
+	if pc.MaxUsesPerCustomer != nil && redemption.CustomerID != nil {
+		var customerUses int
+		if err := tx.QueryRow(
+			`SELECT COUNT(*) FROM promo_code_redemptions WHERE promo_code_id = $1 AND customer_id = $2`,
+			pc.ID, *redemption.CustomerID,
+		).Scan(&customerUses); err != nil {
+			return models.PromoCodeRedemption{}, models.PromoCode{}, err
+		}
+		if customerUses >= *pc.MaxUsesPerCustomer {
+			return models.PromoCodeRedemption{}, models.PromoCode{}, ErrPromoCodeCustomerLimitReached
+		}
+	}
+
+	redemption.PromoCodeID = pc.ID
+	redemption.CompanyID = companyID
+	if err := tx.QueryRow(`
+		INSERT INTO promo_code_redemptions (promo_code_id, company_id, user_id, customer_id, sale_id, redeemed_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`,
+		redemption.PromoCodeID,
+		redemption.CompanyID,
+		redemption.UserID,
+		redemption.CustomerID,
+		redemption.SaleID,
+		redemption.RedeemedAt,
+	).Scan(&redemption.ID); err != nil {
+		return models.PromoCodeRedemption{}, models.PromoCode{}, err
+	}
+
+	if err := tx.QueryRow(`
+		UPDATE promo_codes SET used_count = used_count + 1, updated_at = $1
+		WHERE id = $2
+		RETURNING used_count, updated_at
+	`, redemption.RedeemedAt, pc.ID).Scan(&pc.UsedCount, &pc.UpdatedAt); err != nil {
+		return models.PromoCodeRedemption{}, models.PromoCode{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.PromoCodeRedemption{}, models.PromoCode{}, err
+	}
+
+	return redemption, pc, nil
+}
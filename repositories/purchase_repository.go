@@ -2,14 +2,38 @@ package repositories
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"gowes/models"
+	"math"
+	"time"
+)
+
+// Error status dan qty yang dicek di dalam transaksi purchase order
+var (
+	ErrPurchaseNotDraft          = errors.New("purchase order is no longer a draft")
+	ErrPurchaseNotReceivable     = errors.New("purchase order is not open for receiving")
+	ErrPurchaseNotCancellable    = errors.New("only draft or ordered purchase orders can be cancelled")
+	ErrPurchaseItemNotFound      = errors.New("product is not part of this purchase order")
+	ErrPurchaseReceiveExceeds    = errors.New("received quantity exceeds ordered quantity")
+	ErrPurchaseNotReturnable     = errors.New("purchase has no received goods that can be returned")
+	ErrPurchaseNotVoidable       = errors.New("only purchases with received goods can be voided; cancel open orders instead")
+	ErrPurchaseReturnExceeds     = errors.New("returned quantity exceeds received quantity")
+	ErrPurchaseInsufficientStock = errors.New("insufficient stock at outlet to reverse the purchase")
 )
 
 type PurchaseRepository interface {
-	FindAll(companyID string, params models.PaginationParams) ([]models.Purchase, int, error)
+	FindAll(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.Purchase, int, error)
+	FindPage(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.Purchase, models.CursorPage, error)
 	FindByID(id string, companyID string) (models.Purchase, error)
-	CreateWithStockMovement(purchase models.Purchase) (models.Purchase, error)
+	Create(purchase models.Purchase) (models.Purchase, error)
+	Update(purchase models.Purchase) (models.Purchase, error)
+	Delete(id string, companyID string) error
+	MarkOrdered(id string, companyID string, userID string, orderedAt time.Time) error
+	Cancel(id string, companyID string, userID string, cancelledAt time.Time) error
+	Receive(id string, companyID string, userID string, items []models.PurchaseReceiptItem, note string, receivedAt time.Time) error
+	Return(id string, companyID string, userID string, items []models.PurchaseReturnItem, note string, returnedAt time.Time) error
+	Void(id string, companyID string, userID string, reason string, voidedAt time.Time) error
 }
 
 type purchaseRepository struct {
@@ -20,16 +44,123 @@ func NewPurchaseRepository(db *sql.DB) PurchaseRepository {
 	return &purchaseRepository{db: db}
 }
 
-func (r *purchaseRepository) FindAll(companyID string, params models.PaginationParams) ([]models.Purchase, int, error) {
-	baseQuery := `
+const purchaseColumns = `p.id, p.company_id, p.user_id, u.username, p.outlet_id, o.name, p.supplier_id, COALESCE(s.name, ''),
+		p.payment_method, p.subtotal, p.grand_total, p.tax_value, p.paid_amount, p.returned_amount, p.status, p.discount_bill,
+		COALESCE(p.invoice_number, ''), p.invoice_date, p.payment_term_days, p.due_date, p.payment_status, COALESCE(p.note, ''), p.ordered_by, p.ordered_at, p.cancelled_by, p.cancelled_at,
+		p.voided_by, p.voided_at, COALESCE(p.void_reason, ''), p.created_at, p.updated_at`
+
+const purchaseFromClause = `
 		FROM purchases p
 		JOIN users u ON p.user_id = u.id
 		JOIN outlets o ON p.outlet_id = o.id
-		WHERE p.company_id = $1
-	`
+		LEFT JOIN suppliers s ON p.supplier_id = s.id`
+
+type purchaseScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPurchase(row purchaseScanner) (models.Purchase, error) {
+	var purchase models.Purchase
+	var supplierID, orderedBy, cancelledBy, voidedBy sql.NullString
+	var invoiceDate, dueDate, orderedAt, cancelledAt, voidedAt sql.NullTime
+	if err := row.Scan(
+		&purchase.ID,
+		&purchase.CompanyID,
+		&purchase.UserID,
+		&purchase.UserName,
+		&purchase.OutletID,
+		&purchase.OutletName,
+		&supplierID,
+		&purchase.SupplierName,
+		&purchase.PaymentMethod,
+		&purchase.Subtotal,
+		&purchase.GrandTotal,
+		&purchase.TaxValue,
+		&purchase.PaidAmount,
+		&purchase.ReturnedAmount,
+		&purchase.Status,
+		&purchase.DiscountBill,
+		&purchase.InvoiceNumber,
+		&invoiceDate,
+		&purchase.PaymentTermDays,
+		&dueDate,
+		&purchase.PaymentStatus,
+		&purchase.Note,
+		&orderedBy,
+		&orderedAt,
+		&cancelledBy,
+		&cancelledAt,
+		&voidedBy,
+		&voidedAt,
+		&purchase.VoidReason,
+		&purchase.CreatedAt,
+		&purchase.UpdatedAt,
+	); err != nil {
+		return models.Purchase{}, err
+	}
+
+	purchase.Outstanding = math.Max(0, roundMoney(purchase.GrandTotal-purchase.ReturnedAmount-purchase.PaidAmount))
+	if supplierID.Valid {
+		purchase.SupplierID = &supplierID.String
+	}
+	if invoiceDate.Valid {
+		purchase.InvoiceDate = &invoiceDate.Time
+	}
+	if dueDate.Valid {
+		purchase.DueDate = &dueDate.Time
+	}
+	if orderedBy.Valid {
+		purchase.OrderedBy = &orderedBy.String
+	}
+	if orderedAt.Valid {
+		purchase.OrderedAt = &orderedAt.Time
+	}
+	if cancelledBy.Valid {
+		purchase.CancelledBy = &cancelledBy.String
+	}
+	if cancelledAt.Valid {
+		purchase.CancelledAt = &cancelledAt.Time
+	}
+	if voidedBy.Valid {
+		purchase.VoidedBy = &voidedBy.String
+	}
+	if voidedAt.Valid {
+		purchase.VoidedAt = &voidedAt.Time
+	}
+	return purchase, nil
+}
+
+// purchaseFilterColumns: filter query string yang didukung list purchase
+var purchaseFilterColumns = map[string]filterColumn{
+	"supplier_id":    {column: "p.supplier_id", kind: filterValue},
+	"outlet_id":      {column: "p.outlet_id", kind: filterValue},
+	"status":         {column: "p.status", kind: filterValue},
+	"payment_status": {column: "p.payment_status", kind: filterValue},
+	"created_at":     {column: "p.created_at", kind: filterDate},
+	"ordered_at":     {column: "p.ordered_at", kind: filterDate},
+	"invoice_date":   {column: "p.invoice_date", kind: filterDay},
+	"due_date":       {column: "p.due_date", kind: filterDay},
+	"grand_total":    {column: "p.grand_total", kind: filterNumber},
+}
+
+// purchaseListQuery menyusun FROM dan WHERE list purchase yang dipakai FindAll dan FindPage
+func purchaseListQuery(companyID string, params models.PaginationParams, filter models.FilterParams) (string, []interface{}, int) {
+	baseQuery := purchaseFromClause + " WHERE p.company_id = $1"
 	args := []interface{}{companyID}
 	argIdx := 2
 
+	baseQuery, args, argIdx = applyFilterParams(baseQuery, args, argIdx, filter, purchaseFilterColumns)
+	if params.Search != "" {
+		baseQuery += fmt.Sprintf(" AND (p.note ILIKE $%d OR s.name ILIKE $%d OR o.name ILIKE $%d)", argIdx, argIdx, argIdx)
+		args = append(args, "%"+params.Search+"%")
+		argIdx++
+	}
+	return baseQuery, args, argIdx
+}
+
+func (r *purchaseRepository) FindAll(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.Purchase, int, error) {
+	baseQuery, args, argIdx := purchaseListQuery(companyID, params, filter)
+
 	var total int
 	if err := r.db.QueryRow("SELECT COUNT(*)"+baseQuery, args...).Scan(&total); err != nil {
 		return nil, 0, err
@@ -38,6 +169,8 @@ func (r *purchaseRepository) FindAll(companyID string, params models.PaginationP
 	allowedSorts := map[string]string{
 		"created_at":  "p.created_at",
 		"updated_at":  "p.updated_at",
+		"ordered_at":  "p.ordered_at",
+		"due_date":    "p.due_date",
 		"grand_total": "p.grand_total",
 		"status":      "p.status",
 	}
@@ -51,7 +184,7 @@ func (r *purchaseRepository) FindAll(companyID string, params models.PaginationP
 		sortOrder = "ASC"
 	}
 
-	query := `SELECT p.id, p.company_id, p.user_id, u.username, p.outlet_id, o.name, p.payment_method, p.grand_total, p.tax_value, p.paid_amount, p.change_amount, p.status, p.discount_bill, p.created_at, p.updated_at` + baseQuery
+	query := "SELECT " + purchaseColumns + baseQuery
 	query += fmt.Sprintf(" ORDER BY %s %s", sortBy, sortOrder)
 
 	offset := (params.Page - 1) * params.Limit
@@ -66,24 +199,8 @@ func (r *purchaseRepository) FindAll(companyID string, params models.PaginationP
 
 	purchases := []models.Purchase{}
 	for rows.Next() {
-		var purchase models.Purchase
-		if err := rows.Scan(
-			&purchase.ID,
-			&purchase.CompanyID,
-			&purchase.UserID,
-			&purchase.UserName,
-			&purchase.OutletID,
-			&purchase.OutletName,
-			&purchase.PaymentMethod,
-			&purchase.GrandTotal,
-			&purchase.TaxValue,
-			&purchase.PaidAmount,
-			&purchase.ChangeAmount,
-			&purchase.Status,
-			&purchase.DiscountBill,
-			&purchase.CreatedAt,
-			&purchase.UpdatedAt,
-		); err != nil {
+		purchase, err := scanPurchase(rows)
+		if err != nil {
 			return nil, 0, err
 		}
 		purchases = append(purchases, purchase)
@@ -95,41 +212,56 @@ func (r *purchaseRepository) FindAll(companyID string, params models.PaginationP
 	return purchases, total, nil
 }
 
-func (r *purchaseRepository) FindByID(id string, companyID string) (models.Purchase, error) {
-	row := r.db.QueryRow(`
-		SELECT p.id, p.company_id, p.user_id, u.username, p.outlet_id, o.name, p.payment_method, p.grand_total, p.tax_value, p.paid_amount, p.change_amount, p.status, p.discount_bill, p.created_at, p.updated_at
-		FROM purchases p
-		JOIN users u ON p.user_id = u.id
-		JOIN outlets o ON p.outlet_id = o.id
-		WHERE p.id = $1 AND p.company_id = $2
-	`, id, companyID)
+// FindPage adalah list purchase mode cursor: tanpa OFFSET, dan COUNT hanya dijalankan jika params.WithTotal.
+func (r *purchaseRepository) FindPage(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.Purchase, models.CursorPage, error) {
+	baseQuery, args, argIdx := purchaseListQuery(companyID, params, filter)
 
-	var purchase models.Purchase
-	if err := row.Scan(
-		&purchase.ID,
-		&purchase.CompanyID,
-		&purchase.UserID,
-		&purchase.UserName,
-		&purchase.OutletID,
-		&purchase.OutletName,
-		&purchase.PaymentMethod,
-		&purchase.GrandTotal,
-		&purchase.TaxValue,
-		&purchase.PaidAmount,
-		&purchase.ChangeAmount,
-		&purchase.Status,
-		&purchase.DiscountBill,
-		&purchase.CreatedAt,
-		&purchase.UpdatedAt,
-	); err != nil {
+	var page models.CursorPage
+	if params.WithTotal {
+		var total int
+		if err := r.db.QueryRow("SELECT COUNT(*)"+baseQuery, args...).Scan(&total); err != nil {
+			return nil, models.CursorPage{}, err
+		}
+		page.Total = &total
+	}
+
+	query, args := applyPageCursor("SELECT "+purchaseColumns+baseQuery, args, argIdx, params, "p.created_at", "p.id")
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, models.CursorPage{}, err
+	}
+	defer rows.Close()
+
+	purchases := []models.Purchase{}
+	for rows.Next() {
+		purchase, err := scanPurchase(rows)
+		if err != nil {
+			return nil, models.CursorPage{}, err
+		}
+		purchases = append(purchases, purchase)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, models.CursorPage{}, err
+	}
+
+	purchases, page.NextCursor = cursorPageItems(purchases, params.Limit, func(p models.Purchase) models.PageCursor {
+		return models.PageCursor{CreatedAt: p.CreatedAt, ID: p.ID}
+	})
+	return purchases, page, nil
+}
+
+func (r *purchaseRepository) FindByID(id string, companyID string) (models.Purchase, error) {
+	purchase, err := scanPurchase(r.db.QueryRow("SELECT "+purchaseColumns+purchaseFromClause+" WHERE p.id = $1 AND p.company_id = $2", id, companyID))
+	if err != nil {
 		return models.Purchase{}, err
 	}
 
 	detailRows, err := r.db.Query(`
-		SELECT id, purchase_id, product_id, quantity, price, total
-		FROM purchase_details
-		WHERE purchase_id = $1
-		ORDER BY id ASC
+		SELECT d.id, d.purchase_id, d.product_id, pr.name, d.quantity, d.received_qty, d.returned_qty, d.price, d.total
+		FROM purchase_details d
+		JOIN products pr ON d.product_id = pr.id
+		WHERE d.purchase_id = $1
+		ORDER BY d.id ASC
 	`, purchase.ID)
 	if err != nil {
 		return models.Purchase{}, err
@@ -143,7 +275,10 @@ func (r *purchaseRepository) FindByID(id string, companyID string) (models.Purch
 			&detail.ID,
 			&detail.PurchaseID,
 			&detail.ProductID,
+			&detail.ProductName,
 			&detail.Quantity,
+			&detail.ReceivedQty,
+			&detail.ReturnedQty,
 			&detail.Price,
 			&detail.Total,
 		); err != nil {
@@ -155,10 +290,142 @@ func (r *purchaseRepository) FindByID(id string, companyID string) (models.Purch
 		return models.Purchase{}, err
 	}
 
+	taxes, err := loadTaxBreakdowns(r.db, "purchase_taxes", "purchase_id", purchase.ID)
+	if err != nil {
+		return models.Purchase{}, err
+	}
+	purchase.Taxes = taxes
+
+	receipts, err := r.loadReceipts(purchase.ID)
+	if err != nil {
+		return models.Purchase{}, err
+	}
+	purchase.Receipts = receipts
+
+	returns, err := r.loadReturns(purchase.ID)
+	if err != nil {
+		return models.Purchase{}, err
+	}
+	purchase.Returns = returns
+
+	payments, err := loadPurchasePayments(r.db, purchase.ID)
+	if err != nil {
+		return models.Purchase{}, err
+	}
+	purchase.Payments = payments
+
 	return purchase, nil
 }
 
-func (r *purchaseRepository) CreateWithStockMovement(purchase models.Purchase) (models.Purchase, error) {
+// loadReceipts mengambil seluruh goods received note beserta barisnya, urut dari yang paling awal
+func (r *purchaseRepository) loadReceipts(purchaseID string) ([]models.PurchaseReceipt, error) {
+	rows, err := r.db.Query(`
+		SELECT pr.id, pr.purchase_id, pr.user_id, u.username, COALESCE(pr.note, ''), pr.received_at,
+			i.id, i.purchase_detail_id, i.product_id, p.name, i.qty
+		FROM purchase_receipts pr
+		JOIN users u ON pr.user_id = u.id
+		JOIN purchase_receipt_items i ON i.purchase_receipt_id = pr.id
+		JOIN products p ON i.product_id = p.id
+		WHERE pr.purchase_id = $1
+		ORDER BY pr.received_at ASC, pr.id ASC, p.name ASC
+	`, purchaseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	receipts := []models.PurchaseReceipt{}
+	indexByID := map[string]int{}
+	for rows.Next() {
+		var receipt models.PurchaseReceipt
+		var item models.PurchaseReceiptItem
+		if err := rows.Scan(
+			&receipt.ID,
+			&receipt.PurchaseID,
+			&receipt.UserID,
+			&receipt.UserName,
+			&receipt.Note,
+			&receipt.ReceivedAt,
+			&item.ID,
+			&item.PurchaseDetailID,
+			&item.ProductID,
+			&item.ProductName,
+			&item.Qty,
+		); err != nil {
+			return nil, err
+		}
+		item.PurchaseReceiptID = receipt.ID
+
+		idx, ok := indexByID[receipt.ID]
+		if !ok {
+			idx = len(receipts)
+			indexByID[receipt.ID] = idx
+			receipts = append(receipts, receipt)
+		}
+		receipts[idx].Items = append(receipts[idx].Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return receipts, nil
+}
+
+// loadReturns mengambil seluruh retur dan void beserta barisnya, urut dari yang paling awal
+func (r *purchaseRepository) loadReturns(purchaseID string) ([]models.PurchaseReturn, error) {
+	rows, err := r.db.Query(`
+		SELECT pr.id, pr.purchase_id, pr.user_id, u.username, pr.type, COALESCE(pr.note, ''), pr.returned_at,
+			i.id, i.purchase_detail_id, i.product_id, p.name, i.qty
+		FROM purchase_returns pr
+		JOIN users u ON pr.user_id = u.id
+		JOIN purchase_return_items i ON i.purchase_return_id = pr.id
+		JOIN products p ON i.product_id = p.id
+		WHERE pr.purchase_id = $1
+		ORDER BY pr.returned_at ASC, pr.id ASC, p.name ASC
+	`, purchaseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	returns := []models.PurchaseReturn{}
+	indexByID := map[string]int{}
+	for rows.Next() {
+		var ret models.PurchaseReturn
+		var item models.PurchaseReturnItem
+		if err := rows.Scan(
+			&ret.ID,
+			&ret.PurchaseID,
+			&ret.UserID,
+			&ret.UserName,
+			&ret.Type,
+			&ret.Note,
+			&ret.ReturnedAt,
+			&item.ID,
+			&item.PurchaseDetailID,
+			&item.ProductID,
+			&item.ProductName,
+			&item.Qty,
+		); err != nil {
+			return nil, err
+		}
+		item.PurchaseReturnID = ret.ID
+
+		idx, ok := indexByID[ret.ID]
+		if !ok {
+			idx = len(returns)
+			indexByID[ret.ID] = idx
+			returns = append(returns, ret)
+		}
+		returns[idx].Items = append(returns[idx].Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return returns, nil
+}
+
+// Create hanya menyimpan dokumen purchase order; stok belum bertambah sampai barang diterima.
+func (r *purchaseRepository) Create(purchase models.Purchase) (models.Purchase, error) {
 	tx, err := r.db.Begin()
 	if err != nil {
 		return models.Purchase{}, err
@@ -167,63 +434,486 @@ func (r *purchaseRepository) CreateWithStockMovement(purchase models.Purchase) (
 
 	if err := tx.QueryRow(`
 		INSERT INTO purchases (
-			company_id, user_id, outlet_id, payment_method, grand_total, tax_value, paid_amount, change_amount, status, discount_bill, created_at, updated_at
+			company_id, user_id, outlet_id, supplier_id, payment_method, subtotal, grand_total, tax_value,
+			status, discount_bill, payment_term_days, note, ordered_by, ordered_at, created_at, updated_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-		RETURNING id, company_id, user_id, outlet_id, payment_method, grand_total, tax_value, paid_amount, change_amount, status, discount_bill, created_at, updated_at
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING id
 	`,
 		purchase.CompanyID,
 		purchase.UserID,
 		purchase.OutletID,
+		purchase.SupplierID,
 		purchase.PaymentMethod,
+		purchase.Subtotal,
 		purchase.GrandTotal,
 		purchase.TaxValue,
-		purchase.PaidAmount,
-		purchase.ChangeAmount,
 		purchase.Status,
 		purchase.DiscountBill,
+		purchase.PaymentTermDays,
+		purchase.Note,
+		purchase.OrderedBy,
+		purchase.OrderedAt,
 		purchase.CreatedAt,
 		purchase.UpdatedAt,
-	).Scan(
-		&purchase.ID,
-		&purchase.CompanyID,
-		&purchase.UserID,
-		&purchase.OutletID,
-		&purchase.PaymentMethod,
-		&purchase.GrandTotal,
-		&purchase.TaxValue,
-		&purchase.PaidAmount,
-		&purchase.ChangeAmount,
-		&purchase.Status,
-		&purchase.DiscountBill,
-		&purchase.CreatedAt,
-		&purchase.UpdatedAt,
+	).Scan(&purchase.ID); err != nil {
+		return models.Purchase{}, err
+	}
+
+	if err := insertPurchaseLines(tx, purchase); err != nil {
+		return models.Purchase{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Purchase{}, err
+	}
+	return r.FindByID(purchase.ID, purchase.CompanyID)
+}
+
+// Update mengganti header, baris dan pajak purchase order yang masih draft.
+func (r *purchaseRepository) Update(purchase models.Purchase) (models.Purchase, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.Purchase{}, err
+	}
+	defer tx.Rollback()
+
+	if err := lockDraftPurchase(tx, purchase.ID, purchase.CompanyID); err != nil {
+		return models.Purchase{}, err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE purchases
+		SET outlet_id = $1, supplier_id = $2, payment_method = $3, subtotal = $4, grand_total = $5, tax_value = $6,
+			discount_bill = $7, payment_term_days = $8, note = $9, updated_at = $10
+		WHERE id = $11
+	`,
+		purchase.OutletID,
+		purchase.SupplierID,
+		purchase.PaymentMethod,
+		purchase.Subtotal,
+		purchase.GrandTotal,
+		purchase.TaxValue,
+		purchase.DiscountBill,
+		purchase.PaymentTermDays,
+		purchase.Note,
+		purchase.UpdatedAt,
+		purchase.ID,
 	); err != nil {
 		return models.Purchase{}, err
 	}
 
-	details := make([]models.PurchaseDetail, 0, len(purchase.Details))
-	for _, detail := range purchase.Details {
-		var insertedDetail models.PurchaseDetail
-		if err := tx.QueryRow(`
-			INSERT INTO purchase_details (purchase_id, product_id, quantity, price, total)
-			VALUES ($1, $2, $3, $4, $5)
-			RETURNING id, purchase_id, product_id, quantity, price, total
+	if _, err := tx.Exec(`DELETE FROM purchase_details WHERE purchase_id = $1`, purchase.ID); err != nil {
+		return models.Purchase{}, err
+	}
+	if _, err := tx.Exec(`DELETE FROM purchase_taxes WHERE purchase_id = $1`, purchase.ID); err != nil {
+		return models.Purchase{}, err
+	}
+	if err := insertPurchaseLines(tx, purchase); err != nil {
+		return models.Purchase{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Purchase{}, err
+	}
+	return r.FindByID(purchase.ID, purchase.CompanyID)
+}
+
+// Delete hanya menghapus purchase order yang masih draft.
+func (r *purchaseRepository) Delete(id string, companyID string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockDraftPurchase(tx, id, companyID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM purchases WHERE id = $1`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// MarkOrdered mengirim purchase order draft ke supplier.
+func (r *purchaseRepository) MarkOrdered(id string, companyID string, userID string, orderedAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockDraftPurchase(tx, id, companyID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		UPDATE purchases
+		SET status = $1, ordered_by = $2, ordered_at = $3, updated_at = $3
+		WHERE id = $4
+	`, models.PurchaseStatusOrdered, userID, orderedAt, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Cancel membatalkan purchase order yang belum menerima barang sama sekali.
+func (r *purchaseRepository) Cancel(id string, companyID string, userID string, cancelledAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	status, err := lockPurchase(tx, id, companyID)
+	if err != nil {
+		return err
+	}
+	if status != models.PurchaseStatusDraft && status != models.PurchaseStatusOrdered {
+		return ErrPurchaseNotCancellable
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE purchases
+		SET status = $1, cancelled_by = $2, cancelled_at = $3, updated_at = $3
+		WHERE id = $4
+	`, models.PurchaseStatusCancelled, userID, cancelledAt, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Receive mencatat goods received note: stok outlet purchase bertambah dan movement IN
+// dicatat per baris. Penerimaan boleh dilakukan beberapa kali sampai seluruh qty terpenuhi,
+// termasuk setelah ada retur; selama ada retur status tetap partially_returned.
+func (r *purchaseRepository) Receive(id string, companyID string, userID string, items []models.PurchaseReceiptItem, note string, receivedAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	status, err := lockPurchase(tx, id, companyID)
+	if err != nil {
+		return err
+	}
+	switch status {
+	case models.PurchaseStatusOrdered, models.PurchaseStatusPartiallyReceived,
+		models.PurchaseStatusPartiallyReturned, models.PurchaseStatusReturned:
+	default:
+		return ErrPurchaseNotReceivable
+	}
+
+	var outletID string
+	var subtotal, discountBill float64
+	if err := tx.QueryRow(
+		`SELECT outlet_id, subtotal, discount_bill FROM purchases WHERE id = $1`, id,
+	).Scan(&outletID, &subtotal, &discountBill); err != nil {
+		return err
+	}
+	// Diskon nota dibebankan proporsional ke harga setiap baris
+	discountRatio := 0.0
+	if subtotal > 0 {
+		discountRatio = discountBill / subtotal
+	}
+
+	details, err := loadPurchaseDetailsForUpdate(tx, id)
+	if err != nil {
+		return err
+	}
+	detailByProduct := make(map[string]*models.PurchaseDetail, len(details))
+	for i := range details {
+		detailByProduct[details[i].ProductID] = &details[i]
+	}
+
+	var receiptID string
+	if err := tx.QueryRow(`
+		INSERT INTO purchase_receipts (company_id, purchase_id, user_id, note, received_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, companyID, id, userID, note, receivedAt).Scan(&receiptID); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		detail, ok := detailByProduct[item.ProductID]
+		if !ok {
+			return ErrPurchaseItemNotFound
+		}
+		receivedQty := roundStockQty(detail.ReceivedQty + item.Qty)
+		if receivedQty > detail.Quantity {
+			return ErrPurchaseReceiveExceeds
+		}
+		detail.ReceivedQty = receivedQty
+
+		if _, err := tx.Exec(
+			`UPDATE purchase_details SET received_qty = $1 WHERE id = $2`,
+			detail.ReceivedQty, detail.ID,
+		); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`
+			INSERT INTO purchase_receipt_items (purchase_receipt_id, purchase_detail_id, product_id, qty)
+			VALUES ($1, $2, $3, $4)
+		`, receiptID, detail.ID, detail.ProductID, item.Qty); err != nil {
+			return err
+		}
+
+		unitCost := roundUnitCost(detail.Price * (1 - discountRatio))
+		if err := stockCostIn(tx, detail.ProductID, outletID, item.Qty, unitCost, models.StockReferenceTypePurchase, id, receivedAt); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`
+			INSERT INTO stocks (product_id, outlet_id, qty)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (product_id, outlet_id)
+			DO UPDATE SET qty = stocks.qty + EXCLUDED.qty
+		`, detail.ProductID, outletID, item.Qty); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`
+			INSERT INTO stock_movements (product_id, outlet_id, type, qty, unit_cost, reference_type, reference_id, note, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		`,
-			purchase.ID,
 			detail.ProductID,
-			detail.Quantity,
-			detail.Price,
-			detail.Total,
-		).Scan(
-			&insertedDetail.ID,
-			&insertedDetail.PurchaseID,
-			&insertedDetail.ProductID,
-			&insertedDetail.Quantity,
-			&insertedDetail.Price,
-			&insertedDetail.Total,
+			outletID,
+			models.StockMovementTypeIn,
+			item.Qty,
+			unitCost,
+			models.StockReferenceTypePurchase,
+			id,
+			"purchase receipt",
+			receivedAt,
 		); err != nil {
-			return models.Purchase{}, err
+			return err
+		}
+	}
+
+	newStatus := models.PurchaseStatusReceived
+	for _, detail := range details {
+		if detail.ReceivedQty < detail.Quantity {
+			newStatus = models.PurchaseStatusPartiallyReceived
+			break
+		}
+	}
+	for _, detail := range details {
+		if detail.ReturnedQty > 0 {
+			newStatus = models.PurchaseStatusPartiallyReturned
+			break
+		}
+	}
+
+	if _, err := tx.Exec(
+		`UPDATE purchases SET status = $1, updated_at = $2 WHERE id = $3`,
+		newStatus, receivedAt, id,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Return mengembalikan sebagian barang yang sudah diterima ke supplier. Setiap baris menulis
+// movement OUT yang mereferensikan purchase asal. Status menjadi returned jika seluruh barang
+// yang diterima sudah diretur; sisa qty yang belum diterima tetap bisa diterima lewat Receive.
+func (r *purchaseRepository) Return(id string, companyID string, userID string, items []models.PurchaseReturnItem, note string, returnedAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	status, err := lockPurchase(tx, id, companyID)
+	if err != nil {
+		return err
+	}
+	if !purchaseHasReceivedGoods(status) {
+		return ErrPurchaseNotReturnable
+	}
+
+	details, err := loadPurchaseDetailsForUpdate(tx, id)
+	if err != nil {
+		return err
+	}
+	detailByProduct := make(map[string]*models.PurchaseDetail, len(details))
+	for i := range details {
+		detailByProduct[details[i].ProductID] = &details[i]
+	}
+
+	returns := make([]models.PurchaseReturnItem, 0, len(items))
+	for _, item := range items {
+		detail, ok := detailByProduct[item.ProductID]
+		if !ok {
+			return ErrPurchaseItemNotFound
+		}
+		if roundStockQty(detail.ReturnedQty+item.Qty) > detail.ReceivedQty {
+			return ErrPurchaseReturnExceeds
+		}
+		item.PurchaseDetailID = detail.ID
+		returns = append(returns, item)
+	}
+
+	if err := postPurchaseReturn(tx, id, companyID, userID, models.PurchaseReturnTypeReturn, returns, note, returnedAt); err != nil {
+		return err
+	}
+
+	// Nilai retur mengurangi hutang ke supplier, proporsional terhadap diskon bill dan pajak
+	var subtotal, grandTotal float64
+	if err := tx.QueryRow(
+		`SELECT subtotal, grand_total FROM purchases WHERE id = $1`, id,
+	).Scan(&subtotal, &grandTotal); err != nil {
+		return err
+	}
+	ratio := 1.0
+	if subtotal > 0 {
+		ratio = grandTotal / subtotal
+	}
+	returnedAmount := 0.0
+	for _, item := range returns {
+		detail := detailByProduct[item.ProductID]
+		detail.ReturnedQty = roundStockQty(detail.ReturnedQty + item.Qty)
+		returnedAmount += item.Qty * detail.Price * ratio
+	}
+	returnedAmount = roundMoney(returnedAmount)
+	newStatus := models.PurchaseStatusReturned
+	for _, detail := range details {
+		if detail.ReturnedQty < detail.ReceivedQty {
+			newStatus = models.PurchaseStatusPartiallyReturned
+			break
+		}
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE purchases
+		SET status = $1,
+			returned_amount = LEAST(grand_total, returned_amount + $2),
+			payment_status = CASE
+				WHEN paid_amount > 0 AND paid_amount >= grand_total - LEAST(grand_total, returned_amount + $2) THEN $3
+				ELSE payment_status
+			END,
+			updated_at = $4
+		WHERE id = $5
+	`, newStatus, returnedAmount, models.PurchasePaymentStatusPaid, returnedAt, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Void membatalkan purchase yang barangnya sudah (sebagian) diterima: seluruh sisa barang
+// yang belum diretur dikeluarkan lewat movement OUT dan status menjadi voided.
+// returned_amount diisi grand_total sehingga purchase void tidak lagi punya hutang.
+func (r *purchaseRepository) Void(id string, companyID string, userID string, reason string, voidedAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	status, err := lockPurchase(tx, id, companyID)
+	if err != nil {
+		return err
+	}
+	if !purchaseHasReceivedGoods(status) {
+		return ErrPurchaseNotVoidable
+	}
+
+	details, err := loadPurchaseDetailsForUpdate(tx, id)
+	if err != nil {
+		return err
+	}
+
+	returns := []models.PurchaseReturnItem{}
+	for _, detail := range details {
+		qty := roundStockQty(detail.ReceivedQty - detail.ReturnedQty)
+		if qty <= 0 {
+			continue
+		}
+		returns = append(returns, models.PurchaseReturnItem{
+			PurchaseDetailID: detail.ID,
+			ProductID:        detail.ProductID,
+			Qty:              qty,
+		})
+	}
+
+	if len(returns) > 0 {
+		if err := postPurchaseReturn(tx, id, companyID, userID, models.PurchaseReturnTypeVoid, returns, reason, voidedAt); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE purchases
+		SET status = $1, voided_by = $2, voided_at = $3, void_reason = $4, returned_amount = grand_total, updated_at = $3
+		WHERE id = $5
+	`, models.PurchaseStatusVoided, userID, voidedAt, reason, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// postPurchaseReturn menulis dokumen retur/void, menambah returned_qty, dan mengurangi stok outlet
+// purchase dengan movement OUT. Stok tidak boleh minus kecuali outlet mengizinkan.
+func postPurchaseReturn(tx *sql.Tx, purchaseID string, companyID string, userID string, returnType models.PurchaseReturnType, items []models.PurchaseReturnItem, note string, returnedAt time.Time) error {
+	var outletID string
+	var allowNegative bool
+	if err := tx.QueryRow(`
+		SELECT p.outlet_id, o.allow_negative_stock
+		FROM purchases p
+		JOIN outlets o ON p.outlet_id = o.id
+		WHERE p.id = $1
+	`, purchaseID).Scan(&outletID, &allowNegative); err != nil {
+		return err
+	}
+
+	var returnID string
+	if err := tx.QueryRow(`
+		INSERT INTO purchase_returns (company_id, purchase_id, user_id, type, note, returned_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, companyID, purchaseID, userID, returnType, note, returnedAt).Scan(&returnID); err != nil {
+		return err
+	}
+
+	movementNote := "purchase return"
+	if returnType == models.PurchaseReturnTypeVoid {
+		movementNote = "purchase void"
+	}
+
+	for _, item := range items {
+		if _, err := tx.Exec(`
+			INSERT INTO purchase_return_items (purchase_return_id, purchase_detail_id, product_id, qty)
+			VALUES ($1, $2, $3, $4)
+		`, returnID, item.PurchaseDetailID, item.ProductID, item.Qty); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(
+			`UPDATE purchase_details SET returned_qty = returned_qty + $1 WHERE id = $2`,
+			item.Qty, item.PurchaseDetailID,
+		); err != nil {
+			return err
+		}
+
+		var available float64
+		err := tx.QueryRow(
+			`SELECT qty FROM stocks WHERE product_id = $1 AND outlet_id = $2 FOR UPDATE`,
+			item.ProductID, outletID,
+		).Scan(&available)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if available < item.Qty && !allowNegative {
+			return ErrPurchaseInsufficientStock
+		}
+
+		unitCost, err := stockCostOut(tx, item.ProductID, outletID, item.Qty)
+		if err != nil {
+			return err
 		}
 
 		if _, err := tx.Exec(`
@@ -231,33 +921,98 @@ func (r *purchaseRepository) CreateWithStockMovement(purchase models.Purchase) (
 			VALUES ($1, $2, $3)
 			ON CONFLICT (product_id, outlet_id)
 			DO UPDATE SET qty = stocks.qty + EXCLUDED.qty
-		`, insertedDetail.ProductID, purchase.OutletID, insertedDetail.Quantity); err != nil {
-			return models.Purchase{}, err
+		`, item.ProductID, outletID, -item.Qty); err != nil {
+			return err
 		}
 
 		if _, err := tx.Exec(`
-			INSERT INTO stock_movements (product_id, outlet_id, type, qty, reference_type, reference_id, note, created_at)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			INSERT INTO stock_movements (product_id, outlet_id, type, qty, unit_cost, reference_type, reference_id, note, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		`,
-			insertedDetail.ProductID,
-			purchase.OutletID,
-			"IN",
-			insertedDetail.Quantity,
-			"purchase",
-			purchase.ID,
-			"purchase stock in",
-			purchase.CreatedAt,
+			item.ProductID,
+			outletID,
+			models.StockMovementTypeOut,
+			item.Qty,
+			unitCost,
+			models.StockReferenceTypePurchase,
+			purchaseID,
+			movementNote,
+			returnedAt,
 		); err != nil {
-			return models.Purchase{}, err
+			return err
 		}
+	}
+	return nil
+}
 
-		details = append(details, insertedDetail)
+// purchaseHasReceivedGoods: status yang barangnya sudah masuk stok dan masih bisa dikeluarkan lagi
+func purchaseHasReceivedGoods(status models.PurchaseStatus) bool {
+	switch status {
+	case models.PurchaseStatusPartiallyReceived, models.PurchaseStatusReceived, models.PurchaseStatusPartiallyReturned:
+		return true
 	}
-	purchase.Details = details
+	return false
+}
 
-	if err := tx.Commit(); err != nil {
-		return models.Purchase{}, err
+func insertPurchaseLines(tx *sql.Tx, purchase models.Purchase) error {
+	for _, detail := range purchase.Details {
+		if _, err := tx.Exec(`
+			INSERT INTO purchase_details (purchase_id, product_id, quantity, price, total)
+			VALUES ($1, $2, $3, $4, $5)
+		`, purchase.ID, detail.ProductID, detail.Quantity, detail.Price, detail.Total); err != nil {
+			return fmt.Errorf("insert purchase detail: %w", err)
+		}
 	}
 
-	return purchase, nil
+	if _, err := insertTaxBreakdowns(tx, "purchase_taxes", "purchase_id", purchase.ID, purchase.Taxes); err != nil {
+		return err
+	}
+	return nil
+}
+
+func loadPurchaseDetailsForUpdate(tx *sql.Tx, purchaseID string) ([]models.PurchaseDetail, error) {
+	rows, err := tx.Query(`
+		SELECT id, product_id, quantity, received_qty, returned_qty, price
+		FROM purchase_details
+		WHERE purchase_id = $1
+		FOR UPDATE
+	`, purchaseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	details := []models.PurchaseDetail{}
+	for rows.Next() {
+		var detail models.PurchaseDetail
+		if err := rows.Scan(&detail.ID, &detail.ProductID, &detail.Quantity, &detail.ReceivedQty, &detail.ReturnedQty, &detail.Price); err != nil {
+			return nil, err
+		}
+		details = append(details, detail)
+	}
+	return details, rows.Err()
+}
+
+// lockPurchase mengunci baris purchase order dan mengembalikan statusnya.
+func lockPurchase(tx *sql.Tx, id string, companyID string) (models.PurchaseStatus, error) {
+	var status models.PurchaseStatus
+	if err := tx.QueryRow(
+		`SELECT status FROM purchases WHERE id = $1 AND company_id = $2 FOR UPDATE`,
+		id, companyID,
+	).Scan(&status); err != nil {
+		return "", err
+	}
+	return status, nil
+}
+
+// lockDraftPurchase mengunci baris purchase order dan memastikan statusnya masih draft.
+func lockDraftPurchase(tx *sql.Tx, id string, companyID string) error {
+	status, err := lockPurchase(tx, id, companyID)
+	if err != nil {
+		return err
+	}
+	if status != models.PurchaseStatusDraft {
+		return ErrPurchaseNotDraft
+	}
+	return nil
 }
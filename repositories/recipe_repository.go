@@ -4,14 +4,18 @@ import (
 	"database/sql"
 	"fmt"
 	"gowes/models"
+	"time"
 )
 
 type RecipeRepository interface {
 	FindAll(companyID string, params models.PaginationParams) ([]models.Recipe, int, error)
 	FindByID(id string, companyID string) (models.Recipe, error)
+	FindByProduct(companyID string, productID string) ([]models.Recipe, error)
+	FindActiveByProducts(companyID string, productIDs []string) ([]models.Recipe, error)
 	Create(recipe models.Recipe) (models.Recipe, error)
 	Update(recipe models.Recipe) (models.Recipe, error)
 	Delete(id string, companyID string) error
+	ReplaceForProduct(companyID string, productID string, userID string, recipes []models.Recipe, updatedAt time.Time) error
 }
 
 type recipeRepository struct {
@@ -22,13 +26,73 @@ func NewRecipeRepository(db *sql.DB) RecipeRepository {
 	return &recipeRepository{db: db}
 }
 
+const recipeSelectQuery = `SELECT r.id, r.company_id, r.product_id, r.ingredient_id, COALESCE(i.name, ''), r.quantity,
+		r.unit_id, COALESCE(u.name, ''), r.is_active, r.created_by, r.created_at, r.updated_at, r.updated_by
+		FROM recipes r
+		LEFT JOIN products i ON r.ingredient_id = i.id
+		LEFT JOIN units u ON r.unit_id = u.id`
+
+type recipeScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRecipe(row recipeScanner) (models.Recipe, error) {
+	var recipe models.Recipe
+	var unitID sql.NullString
+	if err := row.Scan(
+		&recipe.ID,
+		&recipe.CompanyID,
+		&recipe.ProductID,
+		&recipe.IngredientID,
+		&recipe.IngredientName,
+		&recipe.Quantity,
+		&unitID,
+		&recipe.UnitName,
+		&recipe.IsActive,
+		&recipe.CreatedBy,
+		&recipe.CreatedAt,
+		&recipe.UpdatedAt,
+		&recipe.UpdatedBy,
+	); err != nil {
+		return models.Recipe{}, err
+	}
+
+	if unitID.Valid {
+		recipe.UnitID = &unitID.String
+	}
+	return recipe, nil
+}
+
+func (r *recipeRepository) queryRecipes(query string, args ...interface{}) ([]models.Recipe, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recipes := []models.Recipe{}
+	for rows.Next() {
+		recipe, err := scanRecipe(rows)
+		if err != nil {
+			return nil, err
+		}
+		recipes = append(recipes, recipe)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return recipes, nil
+}
+
 func (r *recipeRepository) FindAll(companyID string, params models.PaginationParams) ([]models.Recipe, int, error) {
-	baseQuery := " FROM recipes WHERE company_id = $1"
+	baseQuery := " FROM recipes r LEFT JOIN products i ON r.ingredient_id = i.id LEFT JOIN units u ON r.unit_id = u.id WHERE r.company_id = $1"
 	args := []interface{}{companyID}
 	argIdx := 2
 
 	if params.Search != "" {
-		baseQuery += fmt.Sprintf(" AND (product_id::text ILIKE $%d OR ingredient_id::text ILIKE $%d)", argIdx, argIdx)
+		baseQuery += fmt.Sprintf(" AND (r.product_id::text ILIKE $%d OR r.ingredient_id::text ILIKE $%d OR i.name ILIKE $%d)", argIdx, argIdx, argIdx)
 		args = append(args, "%"+params.Search+"%")
 		argIdx++
 	}
@@ -39,10 +103,16 @@ func (r *recipeRepository) FindAll(companyID string, params models.PaginationPar
 		return nil, 0, err
 	}
 
-	allowedSorts := map[string]bool{"product_id": true, "ingredient_id": true, "created_at": true, "updated_at": true}
-	sortBy := "created_at"
-	if allowedSorts[params.SortBy] {
-		sortBy = params.SortBy
+	allowedSorts := map[string]string{
+		"product_id":    "r.product_id",
+		"ingredient_id": "r.ingredient_id",
+		"quantity":      "r.quantity",
+		"created_at":    "r.created_at",
+		"updated_at":    "r.updated_at",
+	}
+	sortBy := "r.created_at"
+	if col, ok := allowedSorts[params.SortBy]; ok {
+		sortBy = col
 	}
 
 	sortOrder := "DESC"
@@ -50,56 +120,42 @@ func (r *recipeRepository) FindAll(companyID string, params models.PaginationPar
 		sortOrder = "ASC"
 	}
 
-	query := "SELECT id, company_id, product_id, ingredient_id, is_active, created_by, created_at, updated_at, updated_by" + baseQuery
+	query := `SELECT r.id, r.company_id, r.product_id, r.ingredient_id, COALESCE(i.name, ''), r.quantity,
+		r.unit_id, COALESCE(u.name, ''), r.is_active, r.created_by, r.created_at, r.updated_at, r.updated_by` + baseQuery
 	query += fmt.Sprintf(" ORDER BY %s %s", sortBy, sortOrder)
 
 	offset := (params.Page - 1) * params.Limit
 	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
 	args = append(args, params.Limit, offset)
 
-	rows, err := r.db.Query(query, args...)
+	recipes, err := r.queryRecipes(query, args...)
 	if err != nil {
 		return nil, 0, err
 	}
-	defer rows.Close()
-
-	recipes := []models.Recipe{}
-	for rows.Next() {
-		var recipe models.Recipe
-		if err := rows.Scan(&recipe.ID, &recipe.CompanyID, &recipe.ProductID, &recipe.IngredientID, &recipe.IsActive, &recipe.CreatedBy, &recipe.CreatedAt, &recipe.UpdatedAt, &recipe.UpdatedBy); err != nil {
-			return nil, 0, err
-		}
-		recipes = append(recipes, recipe)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, 0, err
-	}
 
 	return recipes, total, nil
 }
 
 func (r *recipeRepository) FindByID(id string, companyID string) (models.Recipe, error) {
-	row := r.db.QueryRow(`
-		SELECT id, company_id, product_id, ingredient_id, is_active, created_by, created_at, updated_at, updated_by
-		FROM recipes
-		WHERE id = $1 AND company_id = $2
-	`, id, companyID)
+	return scanRecipe(r.db.QueryRow(recipeSelectQuery+" WHERE r.id = $1 AND r.company_id = $2", id, companyID))
+}
 
-	var recipe models.Recipe
-	if err := row.Scan(&recipe.ID, &recipe.CompanyID, &recipe.ProductID, &recipe.IngredientID, &recipe.IsActive, &recipe.CreatedBy, &recipe.CreatedAt, &recipe.UpdatedAt, &recipe.UpdatedBy); err != nil {
-		return models.Recipe{}, err
-	}
+// FindByProduct mengambil seluruh baris resep (aktif maupun tidak) milik satu produk jadi
+func (r *recipeRepository) FindByProduct(companyID string, productID string) ([]models.Recipe, error) {
+	return r.queryRecipes(recipeSelectQuery+" WHERE r.company_id = $1 AND r.product_id = $2 ORDER BY i.name", companyID, productID)
+}
 
-	return recipe, nil
+// FindActiveByProducts mengambil resep aktif untuk beberapa produk sekaligus (dipakai saat penjualan)
+func (r *recipeRepository) FindActiveByProducts(companyID string, productIDs []string) ([]models.Recipe, error) {
+	return r.queryRecipes(recipeSelectQuery+" WHERE r.company_id = $1 AND r.product_id = ANY($2) AND r.is_active = true", companyID, productIDs)
 }
 
 func (r *recipeRepository) Create(recipe models.Recipe) (models.Recipe, error) {
 	err := r.db.QueryRow(`
-		INSERT INTO recipes (company_id, product_id, ingredient_id, is_active, created_by, created_at, updated_at, updated_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO recipes (company_id, product_id, ingredient_id, quantity, unit_id, is_active, created_by, created_at, updated_at, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id
-	`, recipe.CompanyID, recipe.ProductID, recipe.IngredientID, recipe.IsActive, recipe.CreatedBy, recipe.CreatedAt, recipe.UpdatedAt, recipe.UpdatedBy).Scan(&recipe.ID)
+	`, recipe.CompanyID, recipe.ProductID, recipe.IngredientID, recipe.Quantity, recipe.UnitID, recipe.IsActive, recipe.CreatedBy, recipe.CreatedAt, recipe.UpdatedAt, recipe.UpdatedBy).Scan(&recipe.ID)
 	if err != nil {
 		return models.Recipe{}, err
 	}
@@ -110,10 +166,10 @@ func (r *recipeRepository) Create(recipe models.Recipe) (models.Recipe, error) {
 func (r *recipeRepository) Update(recipe models.Recipe) (models.Recipe, error) {
 	err := r.db.QueryRow(`
 		UPDATE recipes
-		SET product_id = $1, ingredient_id = $2, is_active = $3, updated_at = $4, updated_by = $5
-		WHERE id = $6 AND company_id = $7
+		SET product_id = $1, ingredient_id = $2, quantity = $3, unit_id = $4, is_active = $5, updated_at = $6, updated_by = $7
+		WHERE id = $8 AND company_id = $9
 		RETURNING id
-	`, recipe.ProductID, recipe.IngredientID, recipe.IsActive, recipe.UpdatedAt, recipe.UpdatedBy, recipe.ID, recipe.CompanyID).Scan(&recipe.ID)
+	`, recipe.ProductID, recipe.IngredientID, recipe.Quantity, recipe.UnitID, recipe.IsActive, recipe.UpdatedAt, recipe.UpdatedBy, recipe.ID, recipe.CompanyID).Scan(&recipe.ID)
 	if err != nil {
 		return models.Recipe{}, err
 	}
@@ -137,3 +193,39 @@ func (r *recipeRepository) Delete(id string, companyID string) error {
 
 	return nil
 }
+
+// ReplaceForProduct mengganti seluruh resep sebuah produk jadi dalam satu transaksi.
+// Bahan yang sudah ada diperbarui, bahan baru ditambahkan, dan bahan yang tidak dikirim dihapus.
+func (r *recipeRepository) ReplaceForProduct(companyID string, productID string, userID string, recipes []models.Recipe, updatedAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	ingredientIDs := make([]string, 0, len(recipes))
+	for _, recipe := range recipes {
+		ingredientIDs = append(ingredientIDs, recipe.IngredientID)
+	}
+
+	if _, err := tx.Exec(`
+		DELETE FROM recipes
+		WHERE company_id = $1 AND product_id = $2 AND NOT (ingredient_id = ANY($3))
+	`, companyID, productID, ingredientIDs); err != nil {
+		return err
+	}
+
+	for _, recipe := range recipes {
+		if _, err := tx.Exec(`
+			INSERT INTO recipes (company_id, product_id, ingredient_id, quantity, unit_id, is_active, created_by, created_at, updated_at, updated_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8, $7)
+			ON CONFLICT (product_id, ingredient_id)
+			DO UPDATE SET quantity = EXCLUDED.quantity, unit_id = EXCLUDED.unit_id, is_active = EXCLUDED.is_active,
+				updated_at = EXCLUDED.updated_at, updated_by = EXCLUDED.updated_by
+		`, companyID, productID, recipe.IngredientID, recipe.Quantity, recipe.UnitID, recipe.IsActive, userID, updatedAt); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -12,6 +12,7 @@ type RoleRepository interface {
 	Create(role models.Role) (models.Role, error)
 	Update(role models.Role) (models.Role, error)
 	Delete(id string, companyID string) error
+	FindUserAccess(userID string) (models.UserAccess, error)
 }
 
 type roleRepository struct {
@@ -64,18 +65,35 @@ func (r *roleRepository) FindAll(companyID string, params models.PaginationParam
 	defer rows.Close()
 
 	roles := []models.Role{}
+	roleIDs := []string{}
 	for rows.Next() {
 		var role models.Role
 		if err := rows.Scan(&role.ID, &role.Name, &role.CompanyID, &role.CreatedBy, &role.UpdatedBy, &role.CreatedAt, &role.UpdatedAt); err != nil {
 			return nil, 0, err
 		}
+		role.Permissions = []models.Permission{}
 		roles = append(roles, role)
+		roleIDs = append(roleIDs, role.ID)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, 0, err
 	}
 
+	if len(roleIDs) == 0 {
+		return roles, total, nil
+	}
+
+	permissions, err := r.findPermissions(roleIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range roles {
+		if perms, ok := permissions[roles[i].ID]; ok {
+			roles[i].Permissions = perms
+		}
+	}
+
 	return roles, total, nil
 }
 
@@ -91,11 +109,26 @@ func (r *roleRepository) FindByID(id string, companyID string) (models.Role, err
 		return models.Role{}, err
 	}
 
+	permissions, err := r.findPermissions([]string{role.ID})
+	if err != nil {
+		return models.Role{}, err
+	}
+	role.Permissions = permissions[role.ID]
+	if role.Permissions == nil {
+		role.Permissions = []models.Permission{}
+	}
+
 	return role, nil
 }
 
 func (r *roleRepository) Create(role models.Role) (models.Role, error) {
-	err := r.db.QueryRow(`
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.Role{}, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
 		INSERT INTO roles (name, company_id, created_by, updated_by, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id
@@ -104,11 +137,25 @@ func (r *roleRepository) Create(role models.Role) (models.Role, error) {
 		return models.Role{}, err
 	}
 
+	if err := replaceRolePermissions(tx, role.ID, role.Permissions); err != nil {
+		return models.Role{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Role{}, err
+	}
+
 	return role, nil
 }
 
 func (r *roleRepository) Update(role models.Role) (models.Role, error) {
-	err := r.db.QueryRow(`
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.Role{}, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
 		UPDATE roles
 		SET name = $1, company_id = $2, updated_by = $3, updated_at = $4
 		WHERE id = $5 AND company_id = $6
@@ -118,9 +165,84 @@ func (r *roleRepository) Update(role models.Role) (models.Role, error) {
 		return models.Role{}, err
 	}
 
+	if err := replaceRolePermissions(tx, role.ID, role.Permissions); err != nil {
+		return models.Role{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Role{}, err
+	}
+
 	return role, nil
 }
 
+// replaceRolePermissions mengganti seluruh permission role dengan daftar baru
+func replaceRolePermissions(tx *sql.Tx, roleID string, permissions []models.Permission) error {
+	if _, err := tx.Exec(`DELETE FROM role_permissions WHERE role_id = $1`, roleID); err != nil {
+		return err
+	}
+
+	for _, permission := range permissions {
+		if _, err := tx.Exec(`
+			INSERT INTO role_permissions (role_id, permission)
+			VALUES ($1, $2)
+			ON CONFLICT (role_id, permission) DO NOTHING
+		`, roleID, string(permission)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *roleRepository) findPermissions(roleIDs []string) (map[string][]models.Permission, error) {
+	rows, err := r.db.Query(`
+		SELECT role_id, permission
+		FROM role_permissions
+		WHERE role_id = ANY($1)
+		ORDER BY permission
+	`, roleIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := map[string][]models.Permission{}
+	for rows.Next() {
+		var roleID, permission string
+		if err := rows.Scan(&roleID, &permission); err != nil {
+			return nil, err
+		}
+		permissions[roleID] = append(permissions[roleID], models.Permission(permission))
+	}
+
+	return permissions, rows.Err()
+}
+
+// FindUserAccess mengambil role bawaan user, role kustom (jika ada), dan permission role kustom tersebut
+func (r *roleRepository) FindUserAccess(userID string) (models.UserAccess, error) {
+	var access models.UserAccess
+	if err := r.db.QueryRow(`
+		SELECT role, role_id
+		FROM users
+		WHERE id = $1
+	`, userID).Scan(&access.Role, &access.RoleID); err != nil {
+		return models.UserAccess{}, err
+	}
+
+	if access.RoleID == nil {
+		return access, nil
+	}
+
+	permissions, err := r.findPermissions([]string{*access.RoleID})
+	if err != nil {
+		return models.UserAccess{}, err
+	}
+	access.Permissions = permissions[*access.RoleID]
+
+	return access, nil
+}
+
 func (r *roleRepository) Delete(id string, companyID string) error {
 	res, err := r.db.Exec(`DELETE FROM roles WHERE id = $1 AND company_id = $2`, id, companyID)
 	if err != nil {
@@ -0,0 +1,392 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"gowes/models"
+)
+
+var ErrSaleInsufficientStock = errors.New("insufficient stock at outlet")
+
+type SaleRepository interface {
+	FindAll(companyID string, outletID string, params models.PaginationParams) ([]models.Sale, int, error)
+	FindByID(id string, companyID string) (models.Sale, error)
+	CreateWithStockMovement(sale models.Sale) (models.Sale, error)
+}
+
+type saleRepository struct {
+	db *sql.DB
+}
+
+func NewSaleRepository(db *sql.DB) SaleRepository {
+	return &saleRepository{db: db}
+}
+
+const saleSelectColumns = `SELECT s.id, s.company_id, s.user_id, u.username, s.outlet_id, o.name, s.order_type_id, COALESCE(ot.name, ''), s.customer_id, COALESCE(c.name, ''),
+		s.payment_method, s.subtotal, s.discount_bill, s.tax_value, s.grand_total, s.paid_amount, s.change_amount, s.cogs, s.status, COALESCE(s.note, ''), s.created_at, s.updated_at`
+
+const saleFromClause = `
+		FROM sales s
+		JOIN users u ON s.user_id = u.id
+		JOIN outlets o ON s.outlet_id = o.id
+		LEFT JOIN order_types ot ON s.order_type_id = ot.id
+		LEFT JOIN customers c ON s.customer_id = c.id
+`
+
+type saleScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSale(row saleScanner) (models.Sale, error) {
+	var sale models.Sale
+	var orderTypeID, customerID sql.NullString
+	if err := row.Scan(
+		&sale.ID,
+		&sale.CompanyID,
+		&sale.UserID,
+		&sale.UserName,
+		&sale.OutletID,
+		&sale.OutletName,
+		&orderTypeID,
+		&sale.OrderTypeName,
+		&customerID,
+		&sale.CustomerName,
+		&sale.PaymentMethod,
+		&sale.Subtotal,
+		&sale.DiscountBill,
+		&sale.TaxValue,
+		&sale.GrandTotal,
+		&sale.PaidAmount,
+		&sale.ChangeAmount,
+		&sale.COGS,
+		&sale.Status,
+		&sale.Note,
+		&sale.CreatedAt,
+		&sale.UpdatedAt,
+	); err != nil {
+		return models.Sale{}, err
+	}
+
+	if orderTypeID.Valid {
+		sale.OrderTypeID = &orderTypeID.String
+	}
+	if customerID.Valid {
+		sale.CustomerID = &customerID.String
+	}
+	return sale, nil
+}
+
+// FindAll: outletID kosong berarti semua outlet company
+func (r *saleRepository) FindAll(companyID string, outletID string, params models.PaginationParams) ([]models.Sale, int, error) {
+	baseQuery := saleFromClause + " WHERE s.company_id = $1"
+	args := []interface{}{companyID}
+	argIdx := 2
+
+	if outletID != "" {
+		baseQuery += fmt.Sprintf(" AND s.outlet_id = $%d", argIdx)
+		args = append(args, outletID)
+		argIdx++
+	}
+
+	if params.Search != "" {
+		baseQuery += fmt.Sprintf(" AND (o.name ILIKE $%d OR u.username ILIKE $%d OR COALESCE(c.name, '') ILIKE $%d)", argIdx, argIdx, argIdx)
+		args = append(args, "%"+params.Search+"%")
+		argIdx++
+	}
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*)"+baseQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	allowedSorts := map[string]string{
+		"created_at":  "s.created_at",
+		"updated_at":  "s.updated_at",
+		"grand_total": "s.grand_total",
+		"status":      "s.status",
+	}
+	sortBy := "s.created_at"
+	if col, ok := allowedSorts[params.SortBy]; ok {
+		sortBy = col
+	}
+
+	sortOrder := "DESC"
+	if params.SortOrder == "ASC" {
+		sortOrder = "ASC"
+	}
+
+	query := saleSelectColumns + baseQuery
+	query += fmt.Sprintf(" ORDER BY %s %s", sortBy, sortOrder)
+
+	offset := (params.Page - 1) * params.Limit
+	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
+	args = append(args, params.Limit, offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	sales := []models.Sale{}
+	for rows.Next() {
+		sale, err := scanSale(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		sales = append(sales, sale)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return sales, total, nil
+}
+
+func (r *saleRepository) FindByID(id string, companyID string) (models.Sale, error) {
+	sale, err := scanSale(r.db.QueryRow(saleSelectColumns+saleFromClause+" WHERE s.id = $1 AND s.company_id = $2", id, companyID))
+	if err != nil {
+		return models.Sale{}, err
+	}
+
+	detailRows, err := r.db.Query(`
+		SELECT sd.id, sd.sale_id, sd.product_id, p.name, sd.quantity, sd.price, sd.add_on_total, sd.total, sd.cogs, COALESCE(sd.note, '')
+		FROM sale_details sd
+		JOIN products p ON sd.product_id = p.id
+		WHERE sd.sale_id = $1
+		ORDER BY sd.id ASC
+	`, sale.ID)
+	if err != nil {
+		return models.Sale{}, err
+	}
+	defer detailRows.Close()
+
+	sale.Details = []models.SaleDetail{}
+	detailIndex := map[string]int{}
+	for detailRows.Next() {
+		var detail models.SaleDetail
+		if err := detailRows.Scan(
+			&detail.ID,
+			&detail.SaleID,
+			&detail.ProductID,
+			&detail.ProductName,
+			&detail.Quantity,
+			&detail.Price,
+			&detail.AddOnTotal,
+			&detail.Total,
+			&detail.COGS,
+			&detail.Note,
+		); err != nil {
+			return models.Sale{}, err
+		}
+		detail.AddOns = []models.SaleDetailAddOn{}
+		detailIndex[detail.ID] = len(sale.Details)
+		sale.Details = append(sale.Details, detail)
+	}
+	if err := detailRows.Err(); err != nil {
+		return models.Sale{}, err
+	}
+
+	addOnRows, err := r.db.Query(`
+		SELECT sda.id, sda.sale_detail_id, sda.add_on_id, a.name, sda.quantity, sda.price, sda.total
+		FROM sale_detail_add_ons sda
+		JOIN sale_details sd ON sda.sale_detail_id = sd.id
+		JOIN add_ons a ON sda.add_on_id = a.id
+		WHERE sd.sale_id = $1
+		ORDER BY sda.id ASC
+	`, sale.ID)
+	if err != nil {
+		return models.Sale{}, err
+	}
+	defer addOnRows.Close()
+
+	for addOnRows.Next() {
+		var addOn models.SaleDetailAddOn
+		if err := addOnRows.Scan(
+			&addOn.ID,
+			&addOn.SaleDetailID,
+			&addOn.AddOnID,
+			&addOn.AddOnName,
+			&addOn.Quantity,
+			&addOn.Price,
+			&addOn.Total,
+		); err != nil {
+			return models.Sale{}, err
+		}
+		if idx, ok := detailIndex[addOn.SaleDetailID]; ok {
+			sale.Details[idx].AddOns = append(sale.Details[idx].AddOns, addOn)
+		}
+	}
+	if err := addOnRows.Err(); err != nil {
+		return models.Sale{}, err
+	}
+
+	taxes, err := loadTaxBreakdowns(r.db, "sale_taxes", "sale_id", sale.ID)
+	if err != nil {
+		return models.Sale{}, err
+	}
+	sale.Taxes = taxes
+
+	return sale, nil
+}
+
+func (r *saleRepository) CreateWithStockMovement(sale models.Sale) (models.Sale, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.Sale{}, err
+	}
+	defer tx.Rollback()
+
+	if err := tx.QueryRow(`
+		INSERT INTO sales (
+			company_id, user_id, outlet_id, order_type_id, customer_id, payment_method, subtotal, discount_bill, tax_value, grand_total, paid_amount, change_amount, status, note, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING id
+	`,
+		sale.CompanyID,
+		sale.UserID,
+		sale.OutletID,
+		sale.OrderTypeID,
+		sale.CustomerID,
+		sale.PaymentMethod,
+		sale.Subtotal,
+		sale.DiscountBill,
+		sale.TaxValue,
+		sale.GrandTotal,
+		sale.PaidAmount,
+		sale.ChangeAmount,
+		sale.Status,
+		sale.Note,
+		sale.CreatedAt,
+		sale.UpdatedAt,
+	).Scan(&sale.ID); err != nil {
+		return models.Sale{}, err
+	}
+
+	var allowNegative bool
+	if err := tx.QueryRow(
+		`SELECT allow_negative_stock FROM outlets WHERE id = $1`, sale.OutletID,
+	).Scan(&allowNegative); err != nil {
+		return models.Sale{}, err
+	}
+
+	// Stok yang keluar sudah dihitung service (bahan baku untuk produk beresep).
+	// Baris stok dikunci dulu; stok tidak boleh minus kecuali outlet mengizinkan.
+	// Biaya per unit tiap produk dipakai untuk menghitung HPP baris penjualan.
+	unitCostByProduct := make(map[string]float64, len(sale.StockDeductions))
+	for _, deduction := range sale.StockDeductions {
+		var available float64
+		err := tx.QueryRow(
+			`SELECT qty FROM stocks WHERE product_id = $1 AND outlet_id = $2 FOR UPDATE`,
+			deduction.ProductID, sale.OutletID,
+		).Scan(&available)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return models.Sale{}, err
+		}
+		if available < deduction.Qty && !allowNegative {
+			return models.Sale{}, ErrSaleInsufficientStock
+		}
+
+		unitCost, err := stockCostOut(tx, deduction.ProductID, sale.OutletID, deduction.Qty)
+		if err != nil {
+			return models.Sale{}, err
+		}
+		unitCostByProduct[deduction.ProductID] = unitCost
+
+		if _, err := tx.Exec(`
+			INSERT INTO stocks (product_id, outlet_id, qty)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (product_id, outlet_id)
+			DO UPDATE SET qty = stocks.qty + EXCLUDED.qty
+		`, deduction.ProductID, sale.OutletID, -deduction.Qty); err != nil {
+			return models.Sale{}, err
+		}
+
+		if _, err := tx.Exec(`
+			INSERT INTO stock_movements (product_id, outlet_id, type, qty, unit_cost, reference_type, reference_id, note, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`,
+			deduction.ProductID,
+			sale.OutletID,
+			models.StockMovementTypeOut,
+			deduction.Qty,
+			unitCost,
+			models.StockReferenceTypeSale,
+			sale.ID,
+			deduction.Note,
+			sale.CreatedAt,
+		); err != nil {
+			return models.Sale{}, err
+		}
+	}
+
+	details := make([]models.SaleDetail, 0, len(sale.Details))
+	for _, detail := range sale.Details {
+		detail.SaleID = sale.ID
+		cogs := 0.0
+		for _, component := range detail.StockComponents {
+			cogs += component.Qty * unitCostByProduct[component.ProductID]
+		}
+		detail.COGS = roundMoney(cogs)
+		sale.COGS += detail.COGS
+
+		if err := tx.QueryRow(`
+			INSERT INTO sale_details (sale_id, product_id, quantity, price, add_on_total, total, cogs, note)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id
+		`,
+			detail.SaleID,
+			detail.ProductID,
+			detail.Quantity,
+			detail.Price,
+			detail.AddOnTotal,
+			detail.Total,
+			detail.COGS,
+			detail.Note,
+		).Scan(&detail.ID); err != nil {
+			return models.Sale{}, err
+		}
+
+		addOns := make([]models.SaleDetailAddOn, 0, len(detail.AddOns))
+		for _, addOn := range detail.AddOns {
+			addOn.SaleDetailID = detail.ID
+			if err := tx.QueryRow(`
+				INSERT INTO sale_detail_add_ons (sale_detail_id, add_on_id, quantity, price, total)
+				VALUES ($1, $2, $3, $4, $5)
+				RETURNING id
+			`,
+				addOn.SaleDetailID,
+				addOn.AddOnID,
+				addOn.Quantity,
+				addOn.Price,
+				addOn.Total,
+			).Scan(&addOn.ID); err != nil {
+				return models.Sale{}, err
+			}
+			addOns = append(addOns, addOn)
+		}
+		detail.AddOns = addOns
+
+		details = append(details, detail)
+	}
+	sale.Details = details
+	sale.COGS = roundMoney(sale.COGS)
+
+	if _, err := tx.Exec(`UPDATE sales SET cogs = $1 WHERE id = $2`, sale.COGS, sale.ID); err != nil {
+		return models.Sale{}, err
+	}
+
+	taxes, err := insertTaxBreakdowns(tx, "sale_taxes", "sale_id", sale.ID, sale.Taxes)
+	if err != nil {
+		return models.Sale{}, err
+	}
+	sale.Taxes = taxes
+
+	if err := tx.Commit(); err != nil {
+		return models.Sale{}, err
+	}
+
+	return sale, nil
+}
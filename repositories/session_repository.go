@@ -0,0 +1,187 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"gowes/models"
+	"time"
+)
+
+type SessionRepository interface {
+	Create(session models.UserSession, refreshTokenHash string) (models.UserSession, error)
+	Rotate(refreshTokenHash string, newRefreshTokenHash string, expiresAt time.Time, now time.Time) (models.UserSession, error)
+	FindAuthState(id string) (models.SessionAuthState, error)
+	Revoke(id string, userID string) error
+	RevokeAllByUser(userID string) error
+	RevokeAllByDevice(deviceID string) error
+}
+
+type sessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+const sessionSelectColumns = `SELECT id, user_id, outlet_id, device_id, user_agent, ip_address, expires_at, last_used_at, revoked_at, created_at FROM user_sessions`
+
+type sessionScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSession(row sessionScanner) (models.UserSession, error) {
+	var session models.UserSession
+	var outletID, deviceID sql.NullString
+	var revokedAt sql.NullTime
+	if err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&outletID,
+		&deviceID,
+		&session.UserAgent,
+		&session.IPAddress,
+		&session.ExpiresAt,
+		&session.LastUsedAt,
+		&revokedAt,
+		&session.CreatedAt,
+	); err != nil {
+		return models.UserSession{}, err
+	}
+	if outletID.Valid {
+		session.OutletID = &outletID.String
+	}
+	if deviceID.Valid {
+		session.DeviceID = &deviceID.String
+	}
+	if revokedAt.Valid {
+		session.RevokedAt = &revokedAt.Time
+	}
+	return session, nil
+}
+
+func (r *sessionRepository) Create(session models.UserSession, refreshTokenHash string) (models.UserSession, error) {
+	if err := r.db.QueryRow(`
+		INSERT INTO user_sessions (user_id, refresh_token_hash, outlet_id, device_id, user_agent, ip_address, expires_at, last_used_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`,
+		session.UserID,
+		refreshTokenHash,
+		session.OutletID,
+		session.DeviceID,
+		session.UserAgent,
+		session.IPAddress,
+		session.ExpiresAt,
+		session.LastUsedAt,
+		session.CreatedAt,
+	).Scan(&session.ID); err != nil {
+		return models.UserSession{}, err
+	}
+	return session, nil
+}
+
+// Rotate menukar refresh token aktif dengan yang baru dan memperpanjang sesi; sesi terminal POS
+// (device_id terisi) tidak diperpanjang sehingga kasir tetap harus login PIN ulang.
+// Jika yang dipakai adalah refresh token sebelumnya (sudah pernah ditukar), token itu dianggap bocor
+// sehingga sesinya dicabut. Token tidak dikenal, sesi dicabut, atau kedaluwarsa mengembalikan sql.ErrNoRows.
+func (r *sessionRepository) Rotate(refreshTokenHash string, newRefreshTokenHash string, expiresAt time.Time, now time.Time) (models.UserSession, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.UserSession{}, err
+	}
+	defer tx.Rollback()
+
+	session, err := scanSession(tx.QueryRow(sessionSelectColumns+` WHERE refresh_token_hash = $1 FOR UPDATE`, refreshTokenHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		res, err := tx.Exec(`
+			UPDATE user_sessions SET revoked_at = $1
+			WHERE previous_refresh_token_hash = $2 AND revoked_at IS NULL
+		`, now, refreshTokenHash)
+		if err != nil {
+			return models.UserSession{}, err
+		}
+		if affected, err := res.RowsAffected(); err == nil && affected > 0 {
+			if err := tx.Commit(); err != nil {
+				return models.UserSession{}, err
+			}
+		}
+		return models.UserSession{}, sql.ErrNoRows
+	}
+	if err != nil {
+		return models.UserSession{}, err
+	}
+	if session.RevokedAt != nil || !session.ExpiresAt.After(now) {
+		return models.UserSession{}, sql.ErrNoRows
+	}
+
+	if session.DeviceID != nil {
+		expiresAt = session.ExpiresAt
+	}
+	if _, err := tx.Exec(`
+		UPDATE user_sessions
+		SET previous_refresh_token_hash = refresh_token_hash, refresh_token_hash = $1, expires_at = $2, last_used_at = $3
+		WHERE id = $4
+	`, newRefreshTokenHash, expiresAt, now, session.ID); err != nil {
+		return models.UserSession{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return models.UserSession{}, err
+	}
+
+	session.ExpiresAt = expiresAt
+	session.LastUsedAt = now
+	return session, nil
+}
+
+// FindAuthState dipakai Middleware.Auth: status sesi dan token_version user diambil dalam satu query.
+// Sesi yang sudah dicabut atau kedaluwarsa menolak access token-nya.
+func (r *sessionRepository) FindAuthState(id string) (models.SessionAuthState, error) {
+	var state models.SessionAuthState
+	err := r.db.QueryRow(
+		`SELECT s.user_id, u.token_version, s.revoked_at IS NULL AND s.expires_at > NOW()
+		FROM user_sessions s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.id = $1`,
+		id,
+	).Scan(&state.UserID, &state.TokenVersion, &state.Active)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.SessionAuthState{}, nil
+	}
+	return state, err
+}
+
+func (r *sessionRepository) Revoke(id string, userID string) error {
+	res, err := r.db.Exec(
+		`UPDATE user_sessions SET revoked_at = $1 WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL`,
+		time.Now().UTC(), id, userID,
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *sessionRepository) RevokeAllByUser(userID string) error {
+	_, err := r.db.Exec(
+		`UPDATE user_sessions SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL`,
+		time.Now().UTC(), userID,
+	)
+	return err
+}
+
+// RevokeAllByDevice mencabut semua sesi di satu terminal POS (ganti kasir atau perangkat dinonaktifkan)
+func (r *sessionRepository) RevokeAllByDevice(deviceID string) error {
+	_, err := r.db.Exec(
+		`UPDATE user_sessions SET revoked_at = $1 WHERE device_id = $2 AND revoked_at IS NULL`,
+		time.Now().UTC(), deviceID,
+	)
+	return err
+}
@@ -8,10 +8,16 @@ import (
 )
 
 type StockMovementRepository interface {
-	FindAll(companyID string, params models.PaginationParams, outletID string, productID string, movementType string, referenceType string) ([]models.StockMovement, int, error)
+	FindAll(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.StockMovement, int, error)
+	FindPage(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.StockMovement, models.CursorPage, error)
 	FindByID(companyID string, id string) (models.StockMovement, error)
+	FindStockCard(companyID string, filter models.StockLedgerFilter) (models.StockCard, error)
+	Summary(companyID string, filter models.StockLedgerFilter) ([]models.StockSummaryItem, error)
 }
 
+// stockMovementSignedQty: qty OUT disimpan positif, sehingga dibalik tandanya saat menghitung saldo
+const stockMovementSignedQty = `CASE WHEN sm.type = 'OUT' THEN -sm.qty ELSE sm.qty END`
+
 type stockMovementRepository struct {
 	db *sql.DB
 }
@@ -20,41 +26,82 @@ func NewStockMovementRepository(db *sql.DB) StockMovementRepository {
 	return &stockMovementRepository{db: db}
 }
 
-func (r *stockMovementRepository) FindAll(companyID string, params models.PaginationParams, outletID string, productID string, movementType string, referenceType string) ([]models.StockMovement, int, error) {
-	baseQuery := `
+// stockMovementFilterColumns: filter query string yang didukung list stock movement
+var stockMovementFilterColumns = map[string]filterColumn{
+	"outlet_id":      {column: "sm.outlet_id", kind: filterValue},
+	"product_id":     {column: "sm.product_id", kind: filterValue},
+	"type":           {column: "sm.type", kind: filterValue},
+	"reference_type": {column: "sm.reference_type", kind: filterValue},
+	"reference_id":   {column: "sm.reference_id", kind: filterValue},
+	"created_at":     {column: "sm.created_at", kind: filterDate},
+	"qty":            {column: "sm.qty", kind: filterNumber},
+}
+
+const stockMovementSelectColumns = `SELECT sm.id, sm.product_id, p.name, p.sku, sm.outlet_id, o.name, sm.type, sm.qty, sm.unit_cost, sm.reference_type, sm.reference_id, sm.note, sm.created_at`
+
+const stockMovementFromClause = `
 		FROM stock_movements sm
 		JOIN products p ON sm.product_id = p.id
 		JOIN outlets o ON sm.outlet_id = o.id
 		WHERE o.company_id = $1
 	`
-	args := []interface{}{companyID}
-	argIdx := 2
 
-	if strings.TrimSpace(outletID) != "" {
-		baseQuery += fmt.Sprintf(" AND sm.outlet_id = $%d", argIdx)
-		args = append(args, strings.TrimSpace(outletID))
-		argIdx++
+type stockMovementScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanStockMovement(row stockMovementScanner) (models.StockMovement, error) {
+	var movement models.StockMovement
+	var referenceTypeNull sql.NullString
+	var referenceIDNull sql.NullString
+	var noteNull sql.NullString
+	if err := row.Scan(
+		&movement.ID,
+		&movement.ProductID,
+		&movement.ProductName,
+		&movement.ProductSKU,
+		&movement.OutletID,
+		&movement.OutletName,
+		&movement.Type,
+		&movement.Qty,
+		&movement.UnitCost,
+		&referenceTypeNull,
+		&referenceIDNull,
+		&noteNull,
+		&movement.CreatedAt,
+	); err != nil {
+		return models.StockMovement{}, err
 	}
-	if strings.TrimSpace(productID) != "" {
-		baseQuery += fmt.Sprintf(" AND sm.product_id = $%d", argIdx)
-		args = append(args, strings.TrimSpace(productID))
-		argIdx++
+
+	if referenceTypeNull.Valid {
+		movement.ReferenceType = models.StockReferenceType(referenceTypeNull.String)
 	}
-	if strings.TrimSpace(movementType) != "" {
-		baseQuery += fmt.Sprintf(" AND sm.type = $%d", argIdx)
-		args = append(args, strings.TrimSpace(movementType))
-		argIdx++
+	if referenceIDNull.Valid {
+		movement.ReferenceID = referenceIDNull.String
 	}
-	if strings.TrimSpace(referenceType) != "" {
-		baseQuery += fmt.Sprintf(" AND sm.reference_type = $%d", argIdx)
-		args = append(args, strings.TrimSpace(referenceType))
-		argIdx++
+	if noteNull.Valid {
+		movement.Note = noteNull.String
 	}
+	return movement, nil
+}
+
+// stockMovementListQuery menyusun FROM dan WHERE list stock movement yang dipakai FindAll dan FindPage
+func stockMovementListQuery(companyID string, params models.PaginationParams, filter models.FilterParams) (string, []interface{}, int) {
+	baseQuery := stockMovementFromClause
+	args := []interface{}{companyID}
+	argIdx := 2
+
+	baseQuery, args, argIdx = applyFilterParams(baseQuery, args, argIdx, filter, stockMovementFilterColumns)
 	if strings.TrimSpace(params.Search) != "" {
 		baseQuery += fmt.Sprintf(" AND (p.name ILIKE $%d OR p.sku ILIKE $%d OR o.name ILIKE $%d OR COALESCE(sm.note, '') ILIKE $%d)", argIdx, argIdx, argIdx, argIdx)
 		args = append(args, "%"+strings.TrimSpace(params.Search)+"%")
 		argIdx++
 	}
+	return baseQuery, args, argIdx
+}
+
+func (r *stockMovementRepository) FindAll(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.StockMovement, int, error) {
+	baseQuery, args, argIdx := stockMovementListQuery(companyID, params, filter)
 
 	var total int
 	if err := r.db.QueryRow("SELECT COUNT(*)"+baseQuery, args...).Scan(&total); err != nil {
@@ -78,7 +125,7 @@ func (r *stockMovementRepository) FindAll(companyID string, params models.Pagina
 		sortOrder = "ASC"
 	}
 
-	query := `SELECT sm.id, sm.product_id, p.name, p.sku, sm.outlet_id, o.name, sm.type, sm.qty, sm.reference_type, sm.reference_id, sm.note, sm.created_at` + baseQuery
+	query := stockMovementSelectColumns + baseQuery
 	query += fmt.Sprintf(" ORDER BY %s %s", sortBy, sortOrder)
 
 	offset := (params.Page - 1) * params.Limit
@@ -93,86 +140,230 @@ func (r *stockMovementRepository) FindAll(companyID string, params models.Pagina
 
 	movements := []models.StockMovement{}
 	for rows.Next() {
-		var movement models.StockMovement
+		movement, err := scanStockMovement(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		movements = append(movements, movement)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return movements, total, nil
+}
+
+// FindPage adalah list stock movement mode cursor: tanpa OFFSET, dan COUNT hanya dijalankan jika params.WithTotal.
+func (r *stockMovementRepository) FindPage(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.StockMovement, models.CursorPage, error) {
+	baseQuery, args, argIdx := stockMovementListQuery(companyID, params, filter)
+
+	var page models.CursorPage
+	if params.WithTotal {
+		var total int
+		if err := r.db.QueryRow("SELECT COUNT(*)"+baseQuery, args...).Scan(&total); err != nil {
+			return nil, models.CursorPage{}, err
+		}
+		page.Total = &total
+	}
+
+	query, args := applyPageCursor(stockMovementSelectColumns+baseQuery, args, argIdx, params, "sm.created_at", "sm.id")
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, models.CursorPage{}, err
+	}
+	defer rows.Close()
+
+	movements := []models.StockMovement{}
+	for rows.Next() {
+		movement, err := scanStockMovement(rows)
+		if err != nil {
+			return nil, models.CursorPage{}, err
+		}
+		movements = append(movements, movement)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, models.CursorPage{}, err
+	}
+
+	movements, page.NextCursor = cursorPageItems(movements, params.Limit, func(m models.StockMovement) models.PageCursor {
+		return models.PageCursor{CreatedAt: m.CreatedAt, ID: m.ID}
+	})
+	return movements, page, nil
+}
+
+func (r *stockMovementRepository) FindByID(companyID string, id string) (models.StockMovement, error) {
+	return scanStockMovement(r.db.QueryRow(stockMovementSelectColumns+stockMovementFromClause+" AND sm.id = $2", companyID, id))
+}
+
+// FindStockCard menyusun kartu stok produk di outlet. Saldo dihitung mundur dari qty stok saat ini
+// agar tetap sama dengan tabel stocks walaupun ada stok awal yang tidak punya movement.
+func (r *stockMovementRepository) FindStockCard(companyID string, filter models.StockLedgerFilter) (models.StockCard, error) {
+	until := filter.DateTo.AddDate(0, 0, 1)
+
+	card := models.StockCard{
+		ProductID: filter.ProductID,
+		OutletID:  filter.OutletID,
+		DateFrom:  filter.DateFrom,
+		DateTo:    filter.DateTo,
+	}
+	var currentQty, sinceFrom float64
+	if err := r.db.QueryRow(`
+		SELECT p.name, p.sku, o.name, COALESCE(s.qty, 0),
+			COALESCE((
+				SELECT SUM(`+stockMovementSignedQty+`)
+				FROM stock_movements sm
+				WHERE sm.product_id = p.id AND sm.outlet_id = o.id AND sm.created_at >= $4
+			), 0)
+		FROM products p
+		JOIN outlets o ON o.id = $2 AND o.company_id = $3
+		LEFT JOIN stocks s ON s.product_id = p.id AND s.outlet_id = o.id
+		WHERE p.id = $1 AND p.company_id = $3
+	`, filter.ProductID, filter.OutletID, companyID, filter.DateFrom).Scan(
+		&card.ProductName,
+		&card.ProductSKU,
+		&card.OutletName,
+		&currentQty,
+		&sinceFrom,
+	); err != nil {
+		return models.StockCard{}, err
+	}
+	card.OpeningQty = roundStockQty(currentQty - sinceFrom)
+
+	rows, err := r.db.Query(`
+		SELECT sm.id, sm.type, sm.qty, sm.unit_cost, sm.reference_type, sm.reference_id, sm.note, sm.created_at
+		FROM stock_movements sm
+		WHERE sm.product_id = $1 AND sm.outlet_id = $2 AND sm.created_at >= $3 AND sm.created_at < $4
+		ORDER BY sm.created_at ASC, sm.id ASC
+	`, filter.ProductID, filter.OutletID, filter.DateFrom, until)
+	if err != nil {
+		return models.StockCard{}, err
+	}
+	defer rows.Close()
+
+	balance := card.OpeningQty
+	card.Entries = []models.StockCardEntry{}
+	for rows.Next() {
+		entry := models.StockCardEntry{}
 		var referenceTypeNull sql.NullString
 		var referenceIDNull sql.NullString
 		var noteNull sql.NullString
-
 		if err := rows.Scan(
-			&movement.ID,
-			&movement.ProductID,
-			&movement.ProductName,
-			&movement.ProductSKU,
-			&movement.OutletID,
-			&movement.OutletName,
-			&movement.Type,
-			&movement.Qty,
+			&entry.ID,
+			&entry.Type,
+			&entry.Qty,
+			&entry.UnitCost,
 			&referenceTypeNull,
 			&referenceIDNull,
 			&noteNull,
-			&movement.CreatedAt,
+			&entry.CreatedAt,
 		); err != nil {
-			return nil, 0, err
+			return models.StockCard{}, err
 		}
-
+		entry.ProductID = card.ProductID
+		entry.OutletID = card.OutletID
 		if referenceTypeNull.Valid {
-			movement.ReferenceType = models.StockReferenceType(referenceTypeNull.String)
+			entry.ReferenceType = models.StockReferenceType(referenceTypeNull.String)
 		}
 		if referenceIDNull.Valid {
-			movement.ReferenceID = referenceIDNull.String
+			entry.ReferenceID = referenceIDNull.String
 		}
 		if noteNull.Valid {
-			movement.Note = noteNull.String
+			entry.Note = noteNull.String
 		}
 
-		movements = append(movements, movement)
+		signed := entry.Qty
+		if entry.Type == models.StockMovementTypeOut {
+			signed = -entry.Qty
+		}
+		if signed >= 0 {
+			entry.QtyIn = signed
+			card.TotalIn = roundStockQty(card.TotalIn + signed)
+		} else {
+			entry.QtyOut = -signed
+			card.TotalOut = roundStockQty(card.TotalOut - signed)
+		}
+		balance = roundStockQty(balance + signed)
+		entry.Balance = balance
+
+		card.Entries = append(card.Entries, entry)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, 0, err
+		return models.StockCard{}, err
 	}
+	card.ClosingQty = balance
 
-	return movements, total, nil
+	return card, nil
 }
 
-func (r *stockMovementRepository) FindByID(companyID string, id string) (models.StockMovement, error) {
-	row := r.db.QueryRow(`
-		SELECT sm.id, sm.product_id, p.name, p.sku, sm.outlet_id, o.name, sm.type, sm.qty, sm.reference_type, sm.reference_id, sm.note, sm.created_at
-		FROM stock_movements sm
-		JOIN products p ON sm.product_id = p.id
-		JOIN outlets o ON sm.outlet_id = o.id
-		WHERE o.company_id = $1 AND sm.id = $2
-	`, companyID, id)
+// Summary menghitung mutasi stok per produk per outlet pada periode: saldo awal, total per jenis
+// movement (transfer dipisah masuk/keluar), dan saldo akhir.
+func (r *stockMovementRepository) Summary(companyID string, filter models.StockLedgerFilter) ([]models.StockSummaryItem, error) {
+	until := filter.DateTo.AddDate(0, 0, 1)
 
-	var movement models.StockMovement
-	var referenceTypeNull sql.NullString
-	var referenceIDNull sql.NullString
-	var noteNull sql.NullString
-	if err := row.Scan(
-		&movement.ID,
-		&movement.ProductID,
-		&movement.ProductName,
-		&movement.ProductSKU,
-		&movement.OutletID,
-		&movement.OutletName,
-		&movement.Type,
-		&movement.Qty,
-		&referenceTypeNull,
-		&referenceIDNull,
-		&noteNull,
-		&movement.CreatedAt,
-	); err != nil {
-		return models.StockMovement{}, err
+	query := `
+		SELECT s.product_id, p.name, p.sku, s.outlet_id, o.name, s.qty,
+			COALESCE(SUM(` + stockMovementSignedQty + `), 0),
+			COALESCE(SUM(` + stockMovementSignedQty + `) FILTER (WHERE sm.created_at >= $3), 0),
+			COALESCE(SUM(sm.qty) FILTER (WHERE sm.type = 'IN' AND sm.created_at < $3), 0),
+			COALESCE(SUM(sm.qty) FILTER (WHERE sm.type = 'OUT' AND sm.created_at < $3), 0),
+			COALESCE(SUM(sm.qty) FILTER (WHERE sm.type = 'ADJUSTMENT' AND sm.created_at < $3), 0),
+			COALESCE(SUM(sm.qty) FILTER (WHERE sm.type = 'TRANSFER' AND sm.qty > 0 AND sm.created_at < $3), 0),
+			COALESCE(SUM(-sm.qty) FILTER (WHERE sm.type = 'TRANSFER' AND sm.qty < 0 AND sm.created_at < $3), 0)
+		FROM stocks s
+		JOIN products p ON s.product_id = p.id
+		JOIN outlets o ON s.outlet_id = o.id
+		LEFT JOIN stock_movements sm ON sm.product_id = s.product_id AND sm.outlet_id = s.outlet_id AND sm.created_at >= $2
+		WHERE o.company_id = $1
+	`
+	args := []interface{}{companyID, filter.DateFrom, until}
+	argIdx := 4
+
+	if filter.OutletID != "" {
+		query += fmt.Sprintf(" AND s.outlet_id = $%d", argIdx)
+		args = append(args, filter.OutletID)
+		argIdx++
 	}
+	if filter.ProductID != "" {
+		query += fmt.Sprintf(" AND s.product_id = $%d", argIdx)
+		args = append(args, filter.ProductID)
+		argIdx++
+	}
+	query += " GROUP BY s.product_id, p.name, p.sku, s.outlet_id, o.name, s.qty ORDER BY p.name ASC, o.name ASC"
 
-	if referenceTypeNull.Valid {
-		movement.ReferenceType = models.StockReferenceType(referenceTypeNull.String)
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
 	}
-	if referenceIDNull.Valid {
-		movement.ReferenceID = referenceIDNull.String
+	defer rows.Close()
+
+	items := []models.StockSummaryItem{}
+	for rows.Next() {
+		var item models.StockSummaryItem
+		var currentQty, sinceFrom, sinceUntil float64
+		if err := rows.Scan(
+			&item.ProductID,
+			&item.ProductName,
+			&item.ProductSKU,
+			&item.OutletID,
+			&item.OutletName,
+			&currentQty,
+			&sinceFrom,
+			&sinceUntil,
+			&item.InQty,
+			&item.OutQty,
+			&item.AdjustmentQty,
+			&item.TransferIn,
+			&item.TransferOut,
+		); err != nil {
+			return nil, err
+		}
+		item.OpeningQty = roundStockQty(currentQty - sinceFrom)
+		item.ClosingQty = roundStockQty(currentQty - sinceUntil)
+		items = append(items, item)
 	}
-	if noteNull.Valid {
-		movement.Note = noteNull.String
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return movement, nil
+	return items, nil
 }
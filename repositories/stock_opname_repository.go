@@ -0,0 +1,422 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"gowes/models"
+	"time"
+)
+
+// ErrStockOpnameNotDraft dikembalikan ketika sesi opname yang sudah diposting diubah lagi
+var ErrStockOpnameNotDraft = errors.New("stock opname is already posted")
+
+type StockOpnameRepository interface {
+	FindAll(companyID string, params models.PaginationParams, filter models.StockOpnameFilter) ([]models.StockOpname, int, error)
+	FindByID(id string, companyID string) (models.StockOpname, error)
+	Create(opname models.StockOpname) (models.StockOpname, error)
+	Delete(id string, companyID string) error
+	UpsertItems(id string, companyID string, items []models.StockOpnameItem, updatedAt time.Time) error
+	DeleteItem(id string, companyID string, productID string, updatedAt time.Time) error
+	Post(id string, companyID string, userID string, note string, postedAt time.Time) error
+}
+
+type stockOpnameRepository struct {
+	db *sql.DB
+}
+
+func NewStockOpnameRepository(db *sql.DB) StockOpnameRepository {
+	return &stockOpnameRepository{db: db}
+}
+
+const stockOpnameSelectQuery = `SELECT so.id, so.company_id, so.outlet_id, o.name, so.user_id, so.status, COALESCE(so.note, ''),
+		so.posted_by, so.posted_at, so.created_at, so.updated_at
+		FROM stock_opnames so
+		JOIN outlets o ON so.outlet_id = o.id`
+
+type stockOpnameScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanStockOpname(row stockOpnameScanner) (models.StockOpname, error) {
+	var so models.StockOpname
+	var postedBy sql.NullString
+	var postedAt sql.NullTime
+	if err := row.Scan(
+		&so.ID,
+		&so.CompanyID,
+		&so.OutletID,
+		&so.OutletName,
+		&so.UserID,
+		&so.Status,
+		&so.Note,
+		&postedBy,
+		&postedAt,
+		&so.CreatedAt,
+		&so.UpdatedAt,
+	); err != nil {
+		return models.StockOpname{}, err
+	}
+
+	if postedBy.Valid {
+		so.PostedBy = &postedBy.String
+	}
+	if postedAt.Valid {
+		so.PostedAt = &postedAt.Time
+	}
+	return so, nil
+}
+
+func (r *stockOpnameRepository) FindAll(companyID string, params models.PaginationParams, filter models.StockOpnameFilter) ([]models.StockOpname, int, error) {
+	baseQuery := " FROM stock_opnames so JOIN outlets o ON so.outlet_id = o.id WHERE so.company_id = $1"
+	args := []interface{}{companyID}
+	argIdx := 2
+
+	if filter.OutletID != "" {
+		baseQuery += fmt.Sprintf(" AND so.outlet_id = $%d", argIdx)
+		args = append(args, filter.OutletID)
+		argIdx++
+	}
+	if filter.Status != "" {
+		baseQuery += fmt.Sprintf(" AND so.status = $%d", argIdx)
+		args = append(args, filter.Status)
+		argIdx++
+	}
+	if params.Search != "" {
+		baseQuery += fmt.Sprintf(" AND (so.note ILIKE $%d OR o.name ILIKE $%d)", argIdx, argIdx)
+		args = append(args, "%"+params.Search+"%")
+		argIdx++
+	}
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*)"+baseQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	allowedSorts := map[string]string{
+		"status":     "so.status",
+		"posted_at":  "so.posted_at",
+		"created_at": "so.created_at",
+		"updated_at": "so.updated_at",
+	}
+	sortBy := "so.created_at"
+	if col, ok := allowedSorts[params.SortBy]; ok {
+		sortBy = col
+	}
+
+	sortOrder := "DESC"
+	if params.SortOrder == "ASC" {
+		sortOrder = "ASC"
+	}
+
+	query := `SELECT so.id, so.company_id, so.outlet_id, o.name, so.user_id, so.status, COALESCE(so.note, ''),
+		so.posted_by, so.posted_at, so.created_at, so.updated_at` + baseQuery
+	query += fmt.Sprintf(" ORDER BY %s %s", sortBy, sortOrder)
+
+	offset := (params.Page - 1) * params.Limit
+	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
+	args = append(args, params.Limit, offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	opnames := []models.StockOpname{}
+	for rows.Next() {
+		so, err := scanStockOpname(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		opnames = append(opnames, so)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return opnames, total, nil
+}
+
+// FindByID mengambil sesi opname beserta item dan ringkasan selisihnya.
+func (r *stockOpnameRepository) FindByID(id string, companyID string) (models.StockOpname, error) {
+	so, err := scanStockOpname(r.db.QueryRow(stockOpnameSelectQuery+" WHERE so.id = $1 AND so.company_id = $2", id, companyID))
+	if err != nil {
+		return models.StockOpname{}, err
+	}
+
+	// Selama draft, stok sistem dibaca langsung dari tabel stocks sebagai preview
+	rows, err := r.db.Query(`
+		SELECT i.id, i.stock_opname_id, i.product_id, p.name, p.sku, i.counted_qty,
+			CASE WHEN so.status = 'draft' THEN COALESCE(s.qty, 0) ELSE COALESCE(i.system_qty, 0) END,
+			COALESCE(i.note, '')
+		FROM stock_opname_items i
+		JOIN stock_opnames so ON i.stock_opname_id = so.id
+		JOIN products p ON i.product_id = p.id
+		LEFT JOIN stocks s ON s.product_id = i.product_id AND s.outlet_id = so.outlet_id
+		WHERE i.stock_opname_id = $1
+		ORDER BY p.name ASC
+	`, so.ID)
+	if err != nil {
+		return models.StockOpname{}, err
+	}
+	defer rows.Close()
+
+	summary := models.StockOpnameSummary{}
+	so.Items = []models.StockOpnameItem{}
+	for rows.Next() {
+		var item models.StockOpnameItem
+		if err := rows.Scan(&item.ID, &item.StockOpnameID, &item.ProductID, &item.ProductName, &item.ProductSKU, &item.CountedQty, &item.SystemQty, &item.Note); err != nil {
+			return models.StockOpname{}, err
+		}
+		item.Variance = roundStockQty(item.CountedQty - item.SystemQty)
+
+		summary.TotalItems++
+		switch {
+		case item.Variance > 0:
+			summary.VarianceItems++
+			summary.TotalSurplus = roundStockQty(summary.TotalSurplus + item.Variance)
+		case item.Variance < 0:
+			summary.VarianceItems++
+			summary.TotalShortage = roundStockQty(summary.TotalShortage - item.Variance)
+		}
+		so.Items = append(so.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return models.StockOpname{}, err
+	}
+	so.Summary = &summary
+
+	return so, nil
+}
+
+func (r *stockOpnameRepository) Create(opname models.StockOpname) (models.StockOpname, error) {
+	err := r.db.QueryRow(`
+		INSERT INTO stock_opnames (company_id, outlet_id, user_id, status, note, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`,
+		opname.CompanyID,
+		opname.OutletID,
+		opname.UserID,
+		opname.Status,
+		opname.Note,
+		opname.CreatedAt,
+		opname.UpdatedAt,
+	).Scan(&opname.ID)
+	if err != nil {
+		return models.StockOpname{}, err
+	}
+
+	return r.FindByID(opname.ID, opname.CompanyID)
+}
+
+// Delete hanya menghapus sesi yang masih draft.
+func (r *stockOpnameRepository) Delete(id string, companyID string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockDraftStockOpname(tx, id, companyID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM stock_opnames WHERE id = $1`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpsertItems menyimpan hasil hitung; produk yang sudah ada di sesi akan ditimpa.
+func (r *stockOpnameRepository) UpsertItems(id string, companyID string, items []models.StockOpnameItem, updatedAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockDraftStockOpname(tx, id, companyID); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if _, err := tx.Exec(`
+			INSERT INTO stock_opname_items (stock_opname_id, product_id, counted_qty, note)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (stock_opname_id, product_id)
+			DO UPDATE SET counted_qty = EXCLUDED.counted_qty, note = EXCLUDED.note
+		`, id, item.ProductID, item.CountedQty, item.Note); err != nil {
+			return fmt.Errorf("upsert stock opname item: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(`UPDATE stock_opnames SET updated_at = $1 WHERE id = $2`, updatedAt, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *stockOpnameRepository) DeleteItem(id string, companyID string, productID string, updatedAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockDraftStockOpname(tx, id, companyID); err != nil {
+		return err
+	}
+
+	res, err := tx.Exec(`DELETE FROM stock_opname_items WHERE stock_opname_id = $1 AND product_id = $2`, id, productID)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.Exec(`UPDATE stock_opnames SET updated_at = $1 WHERE id = $2`, updatedAt, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Post membekukan stok sistem, menulis stock movement ADJUSTMENT untuk setiap selisih
+// dan menyamakan stocks.qty dengan hasil hitung, semuanya dalam satu transaksi.
+// Baris stocks dikunci agar transaksi lain tidak mengubah stok di tengah posting.
+func (r *stockOpnameRepository) Post(id string, companyID string, userID string, note string, postedAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockDraftStockOpname(tx, id, companyID); err != nil {
+		return err
+	}
+
+	var outletID string
+	if err := tx.QueryRow(`SELECT outlet_id FROM stock_opnames WHERE id = $1`, id).Scan(&outletID); err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(`SELECT id, product_id, counted_qty FROM stock_opname_items WHERE stock_opname_id = $1`, id)
+	if err != nil {
+		return err
+	}
+	items := []models.StockOpnameItem{}
+	for rows.Next() {
+		var item models.StockOpnameItem
+		if err := rows.Scan(&item.ID, &item.ProductID, &item.CountedQty); err != nil {
+			rows.Close()
+			return err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return err
+	}
+	rows.Close()
+
+	for _, item := range items {
+		if _, err := tx.Exec(`
+			INSERT INTO stocks (product_id, outlet_id, qty)
+			VALUES ($1, $2, 0)
+			ON CONFLICT (product_id, outlet_id) DO NOTHING
+		`, item.ProductID, outletID); err != nil {
+			return err
+		}
+
+		var systemQty float64
+		if err := tx.QueryRow(
+			`SELECT qty FROM stocks WHERE product_id = $1 AND outlet_id = $2 FOR UPDATE`,
+			item.ProductID, outletID,
+		).Scan(&systemQty); err != nil {
+			return err
+		}
+		variance := roundStockQty(item.CountedQty - systemQty)
+
+		if _, err := tx.Exec(
+			`UPDATE stock_opname_items SET system_qty = $1, variance = $2 WHERE id = $3`,
+			systemQty, variance, item.ID,
+		); err != nil {
+			return err
+		}
+
+		if variance == 0 {
+			continue
+		}
+
+		// Selisih lebih dinilai dengan biaya rata-rata saat ini; selisih kurang mengurangi layer biaya
+		var unitCost float64
+		if variance > 0 {
+			unitCost, err = currentStockCost(tx, item.ProductID, outletID)
+			if err != nil {
+				return err
+			}
+			if err := stockCostIn(tx, item.ProductID, outletID, variance, unitCost, models.StockReferenceTypeAdjustment, id, postedAt); err != nil {
+				return err
+			}
+		} else {
+			unitCost, err = stockCostOut(tx, item.ProductID, outletID, -variance)
+			if err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.Exec(
+			`UPDATE stocks SET qty = $1 WHERE product_id = $2 AND outlet_id = $3`,
+			item.CountedQty, item.ProductID, outletID,
+		); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`
+			INSERT INTO stock_movements (product_id, outlet_id, type, qty, unit_cost, reference_type, reference_id, note, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`,
+			item.ProductID,
+			outletID,
+			models.StockMovementTypeAdjustment,
+			variance,
+			unitCost,
+			models.StockReferenceTypeAdjustment,
+			id,
+			note,
+			postedAt,
+		); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE stock_opnames
+		SET status = $1, note = $2, posted_by = $3, posted_at = $4, updated_at = $4
+		WHERE id = $5
+	`, models.StockOpnameStatusPosted, note, userID, postedAt, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// lockDraftStockOpname mengunci baris sesi opname dan memastikan statusnya masih draft.
+func lockDraftStockOpname(tx *sql.Tx, id string, companyID string) error {
+	var status models.StockOpnameStatus
+	if err := tx.QueryRow(
+		`SELECT status FROM stock_opnames WHERE id = $1 AND company_id = $2 FOR UPDATE`,
+		id, companyID,
+	).Scan(&status); err != nil {
+		return err
+	}
+	if status != models.StockOpnameStatusDraft {
+		return ErrStockOpnameNotDraft
+	}
+	return nil
+}
@@ -4,11 +4,18 @@ import (
 	"database/sql"
 	"fmt"
 	"gowes/models"
+	"math"
 	"strings"
 )
 
+// roundStockQty membulatkan hasil hitung qty di Go ke skala kolom NUMERIC(18,4)
+// agar selisih pembulatan float tidak ikut tersimpan atau memengaruhi perbandingan.
+func roundStockQty(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
+
 type StockRepository interface {
-	FindAll(companyID string, params models.PaginationParams, outletID string, productID string) ([]models.StockPerOutlet, int, error)
+	FindAll(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.StockPerOutlet, int, error)
 	FindByOutletAndProduct(companyID string, outletID string, productID string) (models.StockPerOutlet, error)
 }
 
@@ -20,7 +27,15 @@ func NewStockRepository(db *sql.DB) StockRepository {
 	return &stockRepository{db: db}
 }
 
-func (r *stockRepository) FindAll(companyID string, params models.PaginationParams, outletID string, productID string) ([]models.StockPerOutlet, int, error) {
+// stockFilterColumns: filter query string yang didukung list stok
+var stockFilterColumns = map[string]filterColumn{
+	"outlet_id":   {column: "s.outlet_id", kind: filterValue},
+	"product_id":  {column: "s.product_id", kind: filterValue},
+	"category_id": {column: "p.category_id", kind: filterValue},
+	"qty":         {column: "s.qty", kind: filterNumber},
+}
+
+func (r *stockRepository) FindAll(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.StockPerOutlet, int, error) {
 	baseQuery := `
 		FROM stocks s
 		JOIN products p ON s.product_id = p.id
@@ -30,17 +45,7 @@ func (r *stockRepository) FindAll(companyID string, params models.PaginationPara
 	args := []interface{}{companyID}
 	argIdx := 2
 
-	if strings.TrimSpace(outletID) != "" {
-		baseQuery += fmt.Sprintf(" AND s.outlet_id = $%d", argIdx)
-		args = append(args, strings.TrimSpace(outletID))
-		argIdx++
-	}
-
-	if strings.TrimSpace(productID) != "" {
-		baseQuery += fmt.Sprintf(" AND s.product_id = $%d", argIdx)
-		args = append(args, strings.TrimSpace(productID))
-		argIdx++
-	}
+	baseQuery, args, argIdx = applyFilterParams(baseQuery, args, argIdx, filter, stockFilterColumns)
 
 	if strings.TrimSpace(params.Search) != "" {
 		baseQuery += fmt.Sprintf(" AND (p.name ILIKE $%d OR p.sku ILIKE $%d OR o.name ILIKE $%d)", argIdx, argIdx, argIdx)
@@ -69,7 +74,7 @@ func (r *stockRepository) FindAll(companyID string, params models.PaginationPara
 		sortOrder = "ASC"
 	}
 
-	query := `SELECT s.id, s.product_id, p.name, p.sku, s.outlet_id, o.name, s.qty` + baseQuery
+	query := `SELECT s.id, s.product_id, p.name, p.sku, s.outlet_id, o.name, s.qty, COALESCE(s.avg_cost, p.cost)` + baseQuery
 	query += fmt.Sprintf(" ORDER BY %s %s", sortBy, sortOrder)
 
 	offset := (params.Page - 1) * params.Limit
@@ -93,9 +98,11 @@ func (r *stockRepository) FindAll(companyID string, params models.PaginationPara
 			&s.OutletID,
 			&s.OutletName,
 			&s.Qty,
+			&s.AvgCost,
 		); err != nil {
 			return nil, 0, err
 		}
+		s.StockValue = roundMoney(s.Qty * s.AvgCost)
 		stocks = append(stocks, s)
 	}
 	if err := rows.Err(); err != nil {
@@ -107,7 +114,7 @@ func (r *stockRepository) FindAll(companyID string, params models.PaginationPara
 
 func (r *stockRepository) FindByOutletAndProduct(companyID string, outletID string, productID string) (models.StockPerOutlet, error) {
 	row := r.db.QueryRow(`
-		SELECT s.id, s.product_id, p.name, p.sku, s.outlet_id, o.name, s.qty
+		SELECT s.id, s.product_id, p.name, p.sku, s.outlet_id, o.name, s.qty, COALESCE(s.avg_cost, p.cost)
 		FROM stocks s
 		JOIN products p ON s.product_id = p.id
 		JOIN outlets o ON s.outlet_id = o.id
@@ -123,9 +130,11 @@ func (r *stockRepository) FindByOutletAndProduct(companyID string, outletID stri
 		&s.OutletID,
 		&s.OutletName,
 		&s.Qty,
+		&s.AvgCost,
 	); err != nil {
 		return models.StockPerOutlet{}, err
 	}
+	s.StockValue = roundMoney(s.Qty * s.AvgCost)
 
 	return s, nil
 }
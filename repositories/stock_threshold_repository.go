@@ -0,0 +1,264 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"gowes/models"
+	"time"
+)
+
+type StockThresholdRepository interface {
+	FindAll(companyID string, params models.PaginationParams, filter models.StockThresholdFilter) ([]models.StockThreshold, int, error)
+	FindByID(id string, companyID string) (models.StockThreshold, error)
+	Upsert(threshold models.StockThreshold) (models.StockThreshold, error)
+	Delete(id string, companyID string) error
+	FindLowStock(companyID string, outletID string, usageSince time.Time) ([]models.LowStockItem, error)
+}
+
+type stockThresholdRepository struct {
+	db *sql.DB
+}
+
+func NewStockThresholdRepository(db *sql.DB) StockThresholdRepository {
+	return &stockThresholdRepository{db: db}
+}
+
+const stockThresholdSelectColumns = `SELECT t.id, t.company_id, t.product_id, p.name, p.sku, t.outlet_id, o.name,
+		t.min_qty, t.max_qty, t.preferred_supplier_id, COALESCE(sp.name, ''), t.created_at, t.updated_at`
+
+const stockThresholdFromClause = `
+		FROM stock_thresholds t
+		JOIN products p ON t.product_id = p.id
+		JOIN outlets o ON t.outlet_id = o.id
+		LEFT JOIN suppliers sp ON t.preferred_supplier_id = sp.id
+`
+
+type stockThresholdScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanStockThreshold(row stockThresholdScanner) (models.StockThreshold, error) {
+	var t models.StockThreshold
+	var supplierID sql.NullString
+	if err := row.Scan(
+		&t.ID,
+		&t.CompanyID,
+		&t.ProductID,
+		&t.ProductName,
+		&t.ProductSKU,
+		&t.OutletID,
+		&t.OutletName,
+		&t.MinQty,
+		&t.MaxQty,
+		&supplierID,
+		&t.PreferredSupplierName,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	); err != nil {
+		return models.StockThreshold{}, err
+	}
+
+	if supplierID.Valid {
+		t.PreferredSupplierID = &supplierID.String
+	}
+	return t, nil
+}
+
+func (r *stockThresholdRepository) FindAll(companyID string, params models.PaginationParams, filter models.StockThresholdFilter) ([]models.StockThreshold, int, error) {
+	baseQuery := stockThresholdFromClause + " WHERE t.company_id = $1"
+	args := []interface{}{companyID}
+	argIdx := 2
+
+	if filter.OutletID != "" {
+		baseQuery += fmt.Sprintf(" AND t.outlet_id = $%d", argIdx)
+		args = append(args, filter.OutletID)
+		argIdx++
+	}
+	if filter.ProductID != "" {
+		baseQuery += fmt.Sprintf(" AND t.product_id = $%d", argIdx)
+		args = append(args, filter.ProductID)
+		argIdx++
+	}
+	if params.Search != "" {
+		baseQuery += fmt.Sprintf(" AND (p.name ILIKE $%d OR p.sku ILIKE $%d OR o.name ILIKE $%d)", argIdx, argIdx, argIdx)
+		args = append(args, "%"+params.Search+"%")
+		argIdx++
+	}
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*)"+baseQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	allowedSorts := map[string]string{
+		"created_at": "t.created_at",
+		"updated_at": "t.updated_at",
+		"product":    "p.name",
+		"outlet":     "o.name",
+		"min_qty":    "t.min_qty",
+	}
+	sortBy := "t.created_at"
+	if col, ok := allowedSorts[params.SortBy]; ok {
+		sortBy = col
+	}
+
+	sortOrder := "DESC"
+	if params.SortOrder == "ASC" {
+		sortOrder = "ASC"
+	}
+
+	query := stockThresholdSelectColumns + baseQuery
+	query += fmt.Sprintf(" ORDER BY %s %s", sortBy, sortOrder)
+
+	offset := (params.Page - 1) * params.Limit
+	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
+	args = append(args, params.Limit, offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	thresholds := []models.StockThreshold{}
+	for rows.Next() {
+		t, err := scanStockThreshold(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		thresholds = append(thresholds, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return thresholds, total, nil
+}
+
+func (r *stockThresholdRepository) FindByID(id string, companyID string) (models.StockThreshold, error) {
+	return scanStockThreshold(r.db.QueryRow(stockThresholdSelectColumns+stockThresholdFromClause+" WHERE t.id = $1 AND t.company_id = $2", id, companyID))
+}
+
+// Upsert menyimpan batas stok; pengaturan yang sudah ada untuk produk dan outlet yang sama ditimpa.
+func (r *stockThresholdRepository) Upsert(threshold models.StockThreshold) (models.StockThreshold, error) {
+	if err := r.db.QueryRow(`
+		INSERT INTO stock_thresholds (company_id, product_id, outlet_id, min_qty, max_qty, preferred_supplier_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (product_id, outlet_id)
+		DO UPDATE SET min_qty = EXCLUDED.min_qty, max_qty = EXCLUDED.max_qty,
+			preferred_supplier_id = EXCLUDED.preferred_supplier_id, updated_at = EXCLUDED.updated_at
+		RETURNING id
+	`,
+		threshold.CompanyID,
+		threshold.ProductID,
+		threshold.OutletID,
+		threshold.MinQty,
+		threshold.MaxQty,
+		threshold.PreferredSupplierID,
+		threshold.CreatedAt,
+		threshold.UpdatedAt,
+	).Scan(&threshold.ID); err != nil {
+		return models.StockThreshold{}, err
+	}
+
+	return r.FindByID(threshold.ID, threshold.CompanyID)
+}
+
+func (r *stockThresholdRepository) Delete(id string, companyID string) error {
+	res, err := r.db.Exec(`DELETE FROM stock_thresholds WHERE id = $1 AND company_id = $2`, id, companyID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// FindLowStock mengambil stok yang sudah mencapai atau di bawah min_qty beserta pemakaiannya
+// (movement OUT karena penjualan) sejak usageSince. Supplier diambil dari preferred supplier,
+// atau supplier pembelian terakhir produk jika belum diatur.
+func (r *stockThresholdRepository) FindLowStock(companyID string, outletID string, usageSince time.Time) ([]models.LowStockItem, error) {
+	query := `
+		SELECT t.product_id, p.name, p.sku, COALESCE(p.unit, ''), t.outlet_id, o.name,
+			COALESCE(s.qty, 0), t.min_qty, t.max_qty, COALESCE(u.qty, 0),
+			sp.id, COALESCE(sp.name, ''), COALESCE(s.avg_cost, p.cost)
+		FROM stock_thresholds t
+		JOIN products p ON t.product_id = p.id
+		JOIN outlets o ON t.outlet_id = o.id
+		LEFT JOIN stocks s ON s.product_id = t.product_id AND s.outlet_id = t.outlet_id
+		LEFT JOIN LATERAL (
+			SELECT SUM(sm.qty) AS qty
+			FROM stock_movements sm
+			WHERE sm.product_id = t.product_id AND sm.outlet_id = t.outlet_id
+				AND sm.type = $2 AND sm.reference_type = $3 AND sm.created_at >= $4
+		) u ON true
+		LEFT JOIN LATERAL (
+			SELECT pu.supplier_id
+			FROM purchase_details pd
+			JOIN purchases pu ON pd.purchase_id = pu.id
+			WHERE pd.product_id = t.product_id AND pu.company_id = t.company_id
+				AND pu.supplier_id IS NOT NULL AND pu.status NOT IN ($5, $6, $7)
+			ORDER BY pu.created_at DESC
+			LIMIT 1
+		) lp ON true
+		LEFT JOIN suppliers sp ON sp.id = COALESCE(t.preferred_supplier_id, lp.supplier_id)
+		WHERE t.company_id = $1 AND COALESCE(s.qty, 0) <= t.min_qty
+	`
+	args := []interface{}{
+		companyID,
+		models.StockMovementTypeOut,
+		models.StockReferenceTypeSale,
+		usageSince,
+		models.PurchaseStatusDraft,
+		models.PurchaseStatusCancelled,
+		models.PurchaseStatusVoided,
+	}
+	if outletID != "" {
+		query += " AND t.outlet_id = $8"
+		args = append(args, outletID)
+	}
+	query += " ORDER BY o.name ASC, p.name ASC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.LowStockItem{}
+	for rows.Next() {
+		var item models.LowStockItem
+		var supplierID sql.NullString
+		if err := rows.Scan(
+			&item.ProductID,
+			&item.ProductName,
+			&item.ProductSKU,
+			&item.Unit,
+			&item.OutletID,
+			&item.OutletName,
+			&item.Qty,
+			&item.MinQty,
+			&item.MaxQty,
+			&item.UsageQty,
+			&supplierID,
+			&item.SupplierName,
+			&item.UnitCost,
+		); err != nil {
+			return nil, err
+		}
+		if supplierID.Valid {
+			item.SupplierID = &supplierID.String
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
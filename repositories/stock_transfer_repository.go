@@ -0,0 +1,535 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"gowes/models"
+	"time"
+)
+
+// Error status dan stok yang dicek di dalam transaksi transfer
+var (
+	ErrStockTransferNotDraft          = errors.New("stock transfer is no longer a draft")
+	ErrStockTransferNotInTransit      = errors.New("stock transfer is not in transit")
+	ErrStockTransferInsufficientStock = errors.New("insufficient stock at source outlet")
+	ErrStockTransferItemNotFound      = errors.New("product is not part of this stock transfer")
+	ErrStockTransferReceiveExceeds    = errors.New("received quantity exceeds transferred quantity")
+	ErrStockTransferDiscrepancyNote   = errors.New("a discrepancy note is required for items that were not fully received")
+)
+
+type StockTransferRepository interface {
+	FindAll(companyID string, params models.PaginationParams, filter models.StockTransferFilter) ([]models.StockTransfer, int, error)
+	FindByID(id string, companyID string) (models.StockTransfer, error)
+	Create(transfer models.StockTransfer) (models.StockTransfer, error)
+	Update(transfer models.StockTransfer) (models.StockTransfer, error)
+	Delete(id string, companyID string) error
+	Send(id string, companyID string, userID string, sentAt time.Time) error
+	Receive(id string, companyID string, userID string, receipts []models.StockTransferItem, note string, complete bool, receivedAt time.Time) error
+}
+
+type stockTransferRepository struct {
+	db *sql.DB
+}
+
+func NewStockTransferRepository(db *sql.DB) StockTransferRepository {
+	return &stockTransferRepository{db: db}
+}
+
+const stockTransferColumns = `st.id, st.company_id, st.source_outlet_id, src.name, st.destination_outlet_id, dst.name,
+		st.user_id, st.status, COALESCE(st.note, ''), COALESCE(st.receive_note, ''),
+		st.sent_by, st.sent_at, st.received_by, st.received_at, st.created_at, st.updated_at`
+
+const stockTransferFromClause = `
+		FROM stock_transfers st
+		JOIN outlets src ON st.source_outlet_id = src.id
+		JOIN outlets dst ON st.destination_outlet_id = dst.id`
+
+type stockTransferScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanStockTransfer(row stockTransferScanner) (models.StockTransfer, error) {
+	var st models.StockTransfer
+	var sentBy, receivedBy sql.NullString
+	var sentAt, receivedAt sql.NullTime
+	if err := row.Scan(
+		&st.ID,
+		&st.CompanyID,
+		&st.SourceOutletID,
+		&st.SourceOutletName,
+		&st.DestinationOutletID,
+		&st.DestinationOutletName,
+		&st.UserID,
+		&st.Status,
+		&st.Note,
+		&st.ReceiveNote,
+		&sentBy,
+		&sentAt,
+		&receivedBy,
+		&receivedAt,
+		&st.CreatedAt,
+		&st.UpdatedAt,
+	); err != nil {
+		return models.StockTransfer{}, err
+	}
+
+	if sentBy.Valid {
+		st.SentBy = &sentBy.String
+	}
+	if sentAt.Valid {
+		st.SentAt = &sentAt.Time
+	}
+	if receivedBy.Valid {
+		st.ReceivedBy = &receivedBy.String
+	}
+	if receivedAt.Valid {
+		st.ReceivedAt = &receivedAt.Time
+	}
+	return st, nil
+}
+
+func (r *stockTransferRepository) FindAll(companyID string, params models.PaginationParams, filter models.StockTransferFilter) ([]models.StockTransfer, int, error) {
+	baseQuery := stockTransferFromClause + " WHERE st.company_id = $1"
+	args := []interface{}{companyID}
+	argIdx := 2
+
+	if filter.OutletID != "" {
+		baseQuery += fmt.Sprintf(" AND (st.source_outlet_id = $%d OR st.destination_outlet_id = $%d)", argIdx, argIdx)
+		args = append(args, filter.OutletID)
+		argIdx++
+	}
+	if filter.Status != "" {
+		baseQuery += fmt.Sprintf(" AND st.status = $%d", argIdx)
+		args = append(args, filter.Status)
+		argIdx++
+	}
+	if params.Search != "" {
+		baseQuery += fmt.Sprintf(" AND (st.note ILIKE $%d OR src.name ILIKE $%d OR dst.name ILIKE $%d)", argIdx, argIdx, argIdx)
+		args = append(args, "%"+params.Search+"%")
+		argIdx++
+	}
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*)"+baseQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	allowedSorts := map[string]string{
+		"status":      "st.status",
+		"sent_at":     "st.sent_at",
+		"received_at": "st.received_at",
+		"created_at":  "st.created_at",
+		"updated_at":  "st.updated_at",
+	}
+	sortBy := "st.created_at"
+	if col, ok := allowedSorts[params.SortBy]; ok {
+		sortBy = col
+	}
+
+	sortOrder := "DESC"
+	if params.SortOrder == "ASC" {
+		sortOrder = "ASC"
+	}
+
+	query := "SELECT " + stockTransferColumns + baseQuery
+	query += fmt.Sprintf(" ORDER BY %s %s", sortBy, sortOrder)
+
+	offset := (params.Page - 1) * params.Limit
+	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
+	args = append(args, params.Limit, offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	transfers := []models.StockTransfer{}
+	for rows.Next() {
+		st, err := scanStockTransfer(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		transfers = append(transfers, st)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return transfers, total, nil
+}
+
+func (r *stockTransferRepository) FindByID(id string, companyID string) (models.StockTransfer, error) {
+	st, err := scanStockTransfer(r.db.QueryRow("SELECT "+stockTransferColumns+stockTransferFromClause+" WHERE st.id = $1 AND st.company_id = $2", id, companyID))
+	if err != nil {
+		return models.StockTransfer{}, err
+	}
+
+	rows, err := r.db.Query(`
+		SELECT i.id, i.stock_transfer_id, i.product_id, p.name, p.sku, i.qty, i.received_qty, COALESCE(i.discrepancy_note, ''), i.unit_cost
+		FROM stock_transfer_items i
+		JOIN products p ON i.product_id = p.id
+		WHERE i.stock_transfer_id = $1
+		ORDER BY p.name ASC
+	`, st.ID)
+	if err != nil {
+		return models.StockTransfer{}, err
+	}
+	defer rows.Close()
+
+	st.Items = []models.StockTransferItem{}
+	for rows.Next() {
+		var item models.StockTransferItem
+		if err := rows.Scan(&item.ID, &item.StockTransferID, &item.ProductID, &item.ProductName, &item.ProductSKU, &item.Qty, &item.ReceivedQty, &item.DiscrepancyNote, &item.UnitCost); err != nil {
+			return models.StockTransfer{}, err
+		}
+		st.Items = append(st.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return models.StockTransfer{}, err
+	}
+
+	return st, nil
+}
+
+func (r *stockTransferRepository) Create(transfer models.StockTransfer) (models.StockTransfer, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.StockTransfer{}, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		INSERT INTO stock_transfers (company_id, source_outlet_id, destination_outlet_id, user_id, status, note, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`,
+		transfer.CompanyID,
+		transfer.SourceOutletID,
+		transfer.DestinationOutletID,
+		transfer.UserID,
+		transfer.Status,
+		transfer.Note,
+		transfer.CreatedAt,
+		transfer.UpdatedAt,
+	).Scan(&transfer.ID)
+	if err != nil {
+		return models.StockTransfer{}, err
+	}
+
+	if err := insertStockTransferItems(tx, transfer.ID, transfer.Items); err != nil {
+		return models.StockTransfer{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.StockTransfer{}, err
+	}
+	return r.FindByID(transfer.ID, transfer.CompanyID)
+}
+
+// Update mengganti outlet, catatan dan seluruh baris transfer yang masih draft.
+func (r *stockTransferRepository) Update(transfer models.StockTransfer) (models.StockTransfer, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.StockTransfer{}, err
+	}
+	defer tx.Rollback()
+
+	if err := lockDraftStockTransfer(tx, transfer.ID, transfer.CompanyID); err != nil {
+		return models.StockTransfer{}, err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE stock_transfers
+		SET source_outlet_id = $1, destination_outlet_id = $2, note = $3, updated_at = $4
+		WHERE id = $5
+	`,
+		transfer.SourceOutletID,
+		transfer.DestinationOutletID,
+		transfer.Note,
+		transfer.UpdatedAt,
+		transfer.ID,
+	); err != nil {
+		return models.StockTransfer{}, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM stock_transfer_items WHERE stock_transfer_id = $1`, transfer.ID); err != nil {
+		return models.StockTransfer{}, err
+	}
+	if err := insertStockTransferItems(tx, transfer.ID, transfer.Items); err != nil {
+		return models.StockTransfer{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.StockTransfer{}, err
+	}
+	return r.FindByID(transfer.ID, transfer.CompanyID)
+}
+
+// Delete hanya menghapus transfer yang masih draft.
+func (r *stockTransferRepository) Delete(id string, companyID string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockDraftStockTransfer(tx, id, companyID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM stock_transfers WHERE id = $1`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Send mengurangi stok outlet asal dan mencatat movement TRANSFER bernilai negatif.
+// Stok asal dikunci dan harus mencukupi seluruh baris.
+func (r *stockTransferRepository) Send(id string, companyID string, userID string, sentAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockDraftStockTransfer(tx, id, companyID); err != nil {
+		return err
+	}
+
+	var sourceOutletID, destinationOutletID string
+	if err := tx.QueryRow(
+		`SELECT source_outlet_id, destination_outlet_id FROM stock_transfers WHERE id = $1`, id,
+	).Scan(&sourceOutletID, &destinationOutletID); err != nil {
+		return err
+	}
+
+	items, err := loadStockTransferItemsForUpdate(tx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		var available float64
+		err := tx.QueryRow(
+			`SELECT qty FROM stocks WHERE product_id = $1 AND outlet_id = $2 FOR UPDATE`,
+			item.ProductID, sourceOutletID,
+		).Scan(&available)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if available < item.Qty {
+			return ErrStockTransferInsufficientStock
+		}
+
+		// Biaya di outlet asal dibawa ke outlet tujuan saat barang diterima
+		unitCost, err := stockCostOut(tx, item.ProductID, sourceOutletID, item.Qty)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(
+			`UPDATE stock_transfer_items SET unit_cost = $1 WHERE id = $2`,
+			unitCost, item.ID,
+		); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(
+			`UPDATE stocks SET qty = qty - $1 WHERE product_id = $2 AND outlet_id = $3`,
+			item.Qty, item.ProductID, sourceOutletID,
+		); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`
+			INSERT INTO stock_movements (product_id, outlet_id, type, qty, unit_cost, reference_type, reference_id, note, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`,
+			item.ProductID,
+			sourceOutletID,
+			models.StockMovementTypeTransfer,
+			-item.Qty,
+			unitCost,
+			models.StockReferenceTypeTransfer,
+			id,
+			"transfer out",
+			sentAt,
+		); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE stock_transfers
+		SET status = $1, sent_by = $2, sent_at = $3, updated_at = $3
+		WHERE id = $4
+	`, models.StockTransferStatusSent, userID, sentAt, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Receive menambah stok outlet tujuan sesuai jumlah yang diterima dan mencatat movement
+// TRANSFER bernilai positif. Penerimaan boleh dilakukan beberapa kali (parsial).
+// Jika complete = true, transfer ditutup walaupun masih ada selisih; setiap baris yang
+// kurang harus punya catatan selisih (atau note penerimaan diisi).
+func (r *stockTransferRepository) Receive(id string, companyID string, userID string, receipts []models.StockTransferItem, note string, complete bool, receivedAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status models.StockTransferStatus
+	var destinationOutletID string
+	if err := tx.QueryRow(
+		`SELECT status, destination_outlet_id FROM stock_transfers WHERE id = $1 AND company_id = $2 FOR UPDATE`,
+		id, companyID,
+	).Scan(&status, &destinationOutletID); err != nil {
+		return err
+	}
+	if status != models.StockTransferStatusSent && status != models.StockTransferStatusPartiallyReceived {
+		return ErrStockTransferNotInTransit
+	}
+
+	items, err := loadStockTransferItemsForUpdate(tx, id)
+	if err != nil {
+		return err
+	}
+	itemByProduct := make(map[string]*models.StockTransferItem, len(items))
+	for i := range items {
+		itemByProduct[items[i].ProductID] = &items[i]
+	}
+
+	for _, receipt := range receipts {
+		item, ok := itemByProduct[receipt.ProductID]
+		if !ok {
+			return ErrStockTransferItemNotFound
+		}
+		receivedQty := roundStockQty(item.ReceivedQty + receipt.ReceivedQty)
+		if receivedQty > item.Qty {
+			return ErrStockTransferReceiveExceeds
+		}
+		item.ReceivedQty = receivedQty
+		if receipt.DiscrepancyNote != "" {
+			item.DiscrepancyNote = receipt.DiscrepancyNote
+		}
+
+		if _, err := tx.Exec(
+			`UPDATE stock_transfer_items SET received_qty = $1, discrepancy_note = $2 WHERE id = $3`,
+			item.ReceivedQty, item.DiscrepancyNote, item.ID,
+		); err != nil {
+			return err
+		}
+
+		if receipt.ReceivedQty == 0 {
+			continue
+		}
+
+		if err := stockCostIn(tx, item.ProductID, destinationOutletID, receipt.ReceivedQty, item.UnitCost, models.StockReferenceTypeTransfer, id, receivedAt); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`
+			INSERT INTO stocks (product_id, outlet_id, qty)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (product_id, outlet_id)
+			DO UPDATE SET qty = stocks.qty + EXCLUDED.qty
+		`, item.ProductID, destinationOutletID, receipt.ReceivedQty); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`
+			INSERT INTO stock_movements (product_id, outlet_id, type, qty, unit_cost, reference_type, reference_id, note, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`,
+			item.ProductID,
+			destinationOutletID,
+			models.StockMovementTypeTransfer,
+			receipt.ReceivedQty,
+			item.UnitCost,
+			models.StockReferenceTypeTransfer,
+			id,
+			"transfer in",
+			receivedAt,
+		); err != nil {
+			return err
+		}
+	}
+
+	fullyReceived := true
+	for _, item := range items {
+		if item.ReceivedQty < item.Qty {
+			fullyReceived = false
+			if complete && item.DiscrepancyNote == "" && note == "" {
+				return ErrStockTransferDiscrepancyNote
+			}
+		}
+	}
+
+	newStatus := models.StockTransferStatusPartiallyReceived
+	if fullyReceived || complete {
+		newStatus = models.StockTransferStatusReceived
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE stock_transfers
+		SET status = $1, receive_note = COALESCE(NULLIF($2, ''), receive_note), received_by = $3, received_at = $4, updated_at = $4
+		WHERE id = $5
+	`, newStatus, note, userID, receivedAt, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insertStockTransferItems(tx *sql.Tx, transferID string, items []models.StockTransferItem) error {
+	for _, item := range items {
+		if _, err := tx.Exec(`
+			INSERT INTO stock_transfer_items (stock_transfer_id, product_id, qty)
+			VALUES ($1, $2, $3)
+		`, transferID, item.ProductID, item.Qty); err != nil {
+			return fmt.Errorf("insert stock transfer item: %w", err)
+		}
+	}
+	return nil
+}
+
+func loadStockTransferItemsForUpdate(tx *sql.Tx, transferID string) ([]models.StockTransferItem, error) {
+	rows, err := tx.Query(`
+		SELECT id, product_id, qty, received_qty, COALESCE(discrepancy_note, ''), unit_cost
+		FROM stock_transfer_items
+		WHERE stock_transfer_id = $1
+		FOR UPDATE
+	`, transferID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.StockTransferItem{}
+	for rows.Next() {
+		var item models.StockTransferItem
+		if err := rows.Scan(&item.ID, &item.ProductID, &item.Qty, &item.ReceivedQty, &item.DiscrepancyNote, &item.UnitCost); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// lockDraftStockTransfer mengunci baris transfer dan memastikan statusnya masih draft.
+func lockDraftStockTransfer(tx *sql.Tx, id string, companyID string) error {
+	var status models.StockTransferStatus
+	if err := tx.QueryRow(
+		`SELECT status FROM stock_transfers WHERE id = $1 AND company_id = $2 FOR UPDATE`,
+		id, companyID,
+	).Scan(&status); err != nil {
+		return err
+	}
+	if status != models.StockTransferStatusDraft {
+		return ErrStockTransferNotDraft
+	}
+	return nil
+}
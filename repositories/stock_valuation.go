@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"gowes/models"
+	"math"
+	"time"
+)
+
+// roundUnitCost membulatkan biaya per unit ke skala kolom NUMERIC(18,4).
+func roundUnitCost(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
+
+// currentStockCost mengembalikan biaya rata-rata stok produk di outlet. Stok yang belum pernah
+// dinilai (atau belum punya baris stok) memakai products.cost sebagai biaya awal.
+func currentStockCost(tx *sql.Tx, productID string, outletID string) (float64, error) {
+	var unitCost float64
+	err := tx.QueryRow(`
+		SELECT COALESCE(s.avg_cost, p.cost)
+		FROM products p
+		LEFT JOIN stocks s ON s.product_id = p.id AND s.outlet_id = $2
+		WHERE p.id = $1
+	`, productID, outletID).Scan(&unitCost)
+	return unitCost, err
+}
+
+// stockCostIn menilai stok yang masuk: avg_cost dihitung ulang dengan rata-rata tertimbang
+// dan satu layer FIFO dibuat. Dipanggil sebelum qty stok ditambah.
+// Jika stok sedang minus, qty masuk lebih dulu menutup kekurangan sehingga sisa layer berkurang.
+func stockCostIn(tx *sql.Tx, productID string, outletID string, qty float64, unitCost float64, referenceType models.StockReferenceType, referenceID string, at time.Time) error {
+	var oldQty float64
+	var oldCost sql.NullFloat64
+	err := tx.QueryRow(
+		`SELECT qty, avg_cost FROM stocks WHERE product_id = $1 AND outlet_id = $2 FOR UPDATE`,
+		productID, outletID,
+	).Scan(&oldQty, &oldCost)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	avgCost := unitCost
+	if oldQty > 0 && oldCost.Valid && oldQty+qty > 0 {
+		avgCost = (oldQty*oldCost.Float64 + qty*unitCost) / (oldQty + qty)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO stocks (product_id, outlet_id, qty, avg_cost)
+		VALUES ($1, $2, 0, $3)
+		ON CONFLICT (product_id, outlet_id)
+		DO UPDATE SET avg_cost = EXCLUDED.avg_cost
+	`, productID, outletID, roundUnitCost(avgCost)); err != nil {
+		return err
+	}
+
+	remaining := qty
+	if oldQty < 0 {
+		remaining = roundStockQty(qty + oldQty)
+	}
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO stock_cost_layers (product_id, outlet_id, reference_type, reference_id, unit_cost, qty_in, qty_remaining, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, productID, outletID, referenceType, referenceID, roundUnitCost(unitCost), qty, remaining, at)
+	return err
+}
+
+// stockCostOut menilai stok yang keluar dan mengembalikan biaya per unitnya.
+// Layer FIFO selalu dikurangi dari yang terlama agar tetap sinkron dengan qty stok;
+// produk dengan costing_method fifo memakai biaya dari layer yang terpakai,
+// selain itu memakai avg_cost. Qty yang tidak tertutup layer (stok minus) dinilai dengan avg_cost.
+func stockCostOut(tx *sql.Tx, productID string, outletID string, qty float64) (float64, error) {
+	var method string
+	if err := tx.QueryRow(`SELECT costing_method FROM products WHERE id = $1`, productID).Scan(&method); err != nil {
+		return 0, err
+	}
+	avgCost, err := currentStockCost(tx, productID, outletID)
+	if err != nil {
+		return 0, err
+	}
+	if qty <= 0 {
+		return avgCost, nil
+	}
+
+	rows, err := tx.Query(`
+		SELECT id, unit_cost, qty_remaining
+		FROM stock_cost_layers
+		WHERE product_id = $1 AND outlet_id = $2 AND qty_remaining > 0
+		ORDER BY created_at ASC, id ASC
+		FOR UPDATE
+	`, productID, outletID)
+	if err != nil {
+		return 0, err
+	}
+	type costLayer struct {
+		id        string
+		unitCost  float64
+		remaining float64
+	}
+	layers := []costLayer{}
+	for rows.Next() {
+		var layer costLayer
+		if err := rows.Scan(&layer.id, &layer.unitCost, &layer.remaining); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		layers = append(layers, layer)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return 0, err
+	}
+	rows.Close()
+
+	left := qty
+	fifoTotal := 0.0
+	for _, layer := range layers {
+		if left <= 0 {
+			break
+		}
+		take := layer.remaining
+		if take > left {
+			take = left
+		}
+		if _, err := tx.Exec(
+			`UPDATE stock_cost_layers SET qty_remaining = $1 WHERE id = $2`,
+			roundStockQty(layer.remaining-take), layer.id,
+		); err != nil {
+			return 0, err
+		}
+		fifoTotal += take * layer.unitCost
+		left = roundStockQty(left - take)
+	}
+	if left > 0 {
+		fifoTotal += left * avgCost
+	}
+
+	if models.CostingMethod(method) == models.CostingMethodFIFO {
+		return roundUnitCost(fifoTotal / qty), nil
+	}
+	return avgCost, nil
+}
@@ -50,7 +50,7 @@ func (r *supplierRepository) FindAll(companyID string, params models.PaginationP
 		sortOrder = "ASC"
 	}
 
-	query := "SELECT id, name, company_id, address, phone, email, company_name, tax_number, is_active, created_by, created_at, updated_at, updated_by" + baseQuery
+	query := "SELECT id, name, company_id, address, phone, email, company_name, tax_number, payment_term_days, is_active, created_by, created_at, updated_at, updated_by" + baseQuery
 	query += fmt.Sprintf(" ORDER BY %s %s", sortBy, sortOrder)
 
 	offset := (params.Page - 1) * params.Limit
@@ -66,7 +66,7 @@ func (r *supplierRepository) FindAll(companyID string, params models.PaginationP
 	suppliers := []models.Supplier{}
 	for rows.Next() {
 		var supplier models.Supplier
-		if err := rows.Scan(&supplier.ID, &supplier.Name, &supplier.CompanyID, &supplier.Address, &supplier.Phone, &supplier.Email, &supplier.CompanyName, &supplier.TaxNumber, &supplier.IsActive, &supplier.CreatedBy, &supplier.CreatedAt, &supplier.UpdatedAt, &supplier.UpdatedBy); err != nil {
+		if err := rows.Scan(&supplier.ID, &supplier.Name, &supplier.CompanyID, &supplier.Address, &supplier.Phone, &supplier.Email, &supplier.CompanyName, &supplier.TaxNumber, &supplier.PaymentTermDays, &supplier.IsActive, &supplier.CreatedBy, &supplier.CreatedAt, &supplier.UpdatedAt, &supplier.UpdatedBy); err != nil {
 			return nil, 0, err
 		}
 		suppliers = append(suppliers, supplier)
@@ -81,13 +81,13 @@ func (r *supplierRepository) FindAll(companyID string, params models.PaginationP
 
 func (r *supplierRepository) FindByID(id string, companyID string) (models.Supplier, error) {
 	row := r.db.QueryRow(`
-		SELECT id, name, company_id, address, phone, email, company_name, tax_number, is_active, created_by, created_at, updated_at, updated_by
+		SELECT id, name, company_id, address, phone, email, company_name, tax_number, payment_term_days, is_active, created_by, created_at, updated_at, updated_by
 		FROM suppliers
 		WHERE id = $1 AND company_id = $2
 	`, id, companyID)
 
 	var supplier models.Supplier
-	if err := row.Scan(&supplier.ID, &supplier.Name, &supplier.CompanyID, &supplier.Address, &supplier.Phone, &supplier.Email, &supplier.CompanyName, &supplier.TaxNumber, &supplier.IsActive, &supplier.CreatedBy, &supplier.CreatedAt, &supplier.UpdatedAt, &supplier.UpdatedBy); err != nil {
+	if err := row.Scan(&supplier.ID, &supplier.Name, &supplier.CompanyID, &supplier.Address, &supplier.Phone, &supplier.Email, &supplier.CompanyName, &supplier.TaxNumber, &supplier.PaymentTermDays, &supplier.IsActive, &supplier.CreatedBy, &supplier.CreatedAt, &supplier.UpdatedAt, &supplier.UpdatedBy); err != nil {
 		return models.Supplier{}, err
 	}
 
@@ -96,10 +96,10 @@ func (r *supplierRepository) FindByID(id string, companyID string) (models.Suppl
 
 func (r *supplierRepository) Create(supplier models.Supplier) (models.Supplier, error) {
 	err := r.db.QueryRow(`
-		INSERT INTO suppliers (name, company_id, address, phone, email, company_name, tax_number, is_active, created_by, created_at, updated_at, updated_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		INSERT INTO suppliers (name, company_id, address, phone, email, company_name, tax_number, payment_term_days, is_active, created_by, created_at, updated_at, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		RETURNING id
-	`, supplier.Name, supplier.CompanyID, supplier.Address, supplier.Phone, supplier.Email, supplier.CompanyName, supplier.TaxNumber, supplier.IsActive, supplier.CreatedBy, supplier.CreatedAt, supplier.UpdatedAt, supplier.UpdatedBy).Scan(&supplier.ID)
+	`, supplier.Name, supplier.CompanyID, supplier.Address, supplier.Phone, supplier.Email, supplier.CompanyName, supplier.TaxNumber, supplier.PaymentTermDays, supplier.IsActive, supplier.CreatedBy, supplier.CreatedAt, supplier.UpdatedAt, supplier.UpdatedBy).Scan(&supplier.ID)
 	if err != nil {
 		return models.Supplier{}, err
 	}
@@ -110,10 +110,10 @@ func (r *supplierRepository) Create(supplier models.Supplier) (models.Supplier,
 func (r *supplierRepository) Update(supplier models.Supplier) (models.Supplier, error) {
 	err := r.db.QueryRow(`
 		UPDATE suppliers
-		SET name = $1, address = $2, phone = $3, email = $4, company_name = $5, tax_number = $6, is_active = $7, updated_at = $8, updated_by = $9
-		WHERE id = $10 AND company_id = $11
+		SET name = $1, address = $2, phone = $3, email = $4, company_name = $5, tax_number = $6, payment_term_days = $7, is_active = $8, updated_at = $9, updated_by = $10
+		WHERE id = $11 AND company_id = $12
 		RETURNING id
-	`, supplier.Name, supplier.Address, supplier.Phone, supplier.Email, supplier.CompanyName, supplier.TaxNumber, supplier.IsActive, supplier.UpdatedAt, supplier.UpdatedBy, supplier.ID, supplier.CompanyID).Scan(&supplier.ID)
+	`, supplier.Name, supplier.Address, supplier.Phone, supplier.Email, supplier.CompanyName, supplier.TaxNumber, supplier.PaymentTermDays, supplier.IsActive, supplier.UpdatedAt, supplier.UpdatedBy, supplier.ID, supplier.CompanyID).Scan(&supplier.ID)
 	if err != nil {
 		return models.Supplier{}, err
 	}
@@ -9,6 +9,7 @@ import (
 type TaxRepository interface {
 	FindAll(companyID string, params models.PaginationParams) ([]models.Tax, int, error)
 	FindByID(id string) (models.Tax, error)
+	FindActiveByCompany(companyID string) ([]models.Tax, error)
 	Create(tax models.Tax) (models.Tax, error)
 	Update(tax models.Tax) (models.Tax, error)
 	Delete(id string) error
@@ -22,6 +23,87 @@ func NewTaxRepository(db *sql.DB) TaxRepository {
 	return &taxRepository{db: db}
 }
 
+const taxSelectColumns = "SELECT id, name, rate, company_id, type, target_type, is_inclusive, sequence, is_compound, is_active, created_at, updated_at"
+
+type taxScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTax(row taxScanner) (models.Tax, error) {
+	var t models.Tax
+	if err := row.Scan(
+		&t.ID, &t.Name, &t.Rate, &t.CompanyID,
+		&t.Type, &t.TargetType, &t.IsInclusive, &t.Sequence, &t.IsCompound, &t.IsActive,
+		&t.CreatedAt, &t.UpdatedAt,
+	); err != nil {
+		return models.Tax{}, err
+	}
+	return t, nil
+}
+
+// loadRelations mengisi outlet, produk dan kategori yang terhubung ke pajak.
+func (r *taxRepository) loadRelations(t *models.Tax) error {
+	relations := []struct {
+		query string
+		dest  *[]string
+	}{
+		{`SELECT outlet_id FROM tax_outlets WHERE tax_id = $1`, &t.OutletIDs},
+		{`SELECT product_id FROM tax_products WHERE tax_id = $1`, &t.ProductIDs},
+		{`SELECT category_id FROM tax_categories WHERE tax_id = $1`, &t.CategoryIDs},
+	}
+
+	for _, rel := range relations {
+		rows, err := r.db.Query(rel.query, t.ID)
+		if err != nil {
+			return err
+		}
+		ids := []string{}
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			ids = append(ids, id)
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return err
+		}
+		rows.Close()
+		*rel.dest = ids
+	}
+	return nil
+}
+
+// replaceTaxRelations menghapus lalu menyisipkan ulang junction table pajak dalam satu transaksi.
+func replaceTaxRelations(tx *sql.Tx, t models.Tax) error {
+	relations := []struct {
+		table  string
+		column string
+		ids    []string
+	}{
+		{"tax_outlets", "outlet_id", t.OutletIDs},
+		{"tax_products", "product_id", t.ProductIDs},
+		{"tax_categories", "category_id", t.CategoryIDs},
+	}
+
+	for _, rel := range relations {
+		if _, err := tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE tax_id = $1`, rel.table), t.ID); err != nil {
+			return fmt.Errorf("delete %s: %w", rel.table, err)
+		}
+		for _, id := range rel.ids {
+			if _, err := tx.Exec(
+				fmt.Sprintf(`INSERT INTO %s (tax_id, %s) VALUES ($1, $2)`, rel.table, rel.column),
+				t.ID, id,
+			); err != nil {
+				return fmt.Errorf("insert %s: %w", rel.table, err)
+			}
+		}
+	}
+	return nil
+}
+
 func (r *taxRepository) FindAll(companyID string, params models.PaginationParams) ([]models.Tax, int, error) {
 	baseQuery := " FROM taxes WHERE company_id = $1"
 	args := []interface{}{companyID}
@@ -41,7 +123,7 @@ func (r *taxRepository) FindAll(companyID string, params models.PaginationParams
 	}
 
 	// Validate sort column
-	allowedSorts := map[string]bool{"name": true, "rate": true, "created_at": true, "updated_at": true}
+	allowedSorts := map[string]bool{"name": true, "rate": true, "sequence": true, "created_at": true, "updated_at": true}
 	sortBy := "created_at"
 	if allowedSorts[params.SortBy] {
 		sortBy = params.SortBy
@@ -52,7 +134,7 @@ func (r *taxRepository) FindAll(companyID string, params models.PaginationParams
 		sortOrder = "ASC"
 	}
 
-	query := "SELECT id, name, rate, company_id, created_at, updated_at" + baseQuery
+	query := taxSelectColumns + baseQuery
 	query += fmt.Sprintf(" ORDER BY %s %s", sortBy, sortOrder)
 
 	offset := (params.Page - 1) * params.Limit
@@ -67,8 +149,8 @@ func (r *taxRepository) FindAll(companyID string, params models.PaginationParams
 
 	taxes := []models.Tax{}
 	for rows.Next() {
-		var t models.Tax
-		if err := rows.Scan(&t.ID, &t.Name, &t.Rate, &t.CompanyID, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		t, err := scanTax(rows)
+		if err != nil {
 			return nil, 0, err
 		}
 		taxes = append(taxes, t)
@@ -78,47 +160,117 @@ func (r *taxRepository) FindAll(companyID string, params models.PaginationParams
 		return nil, 0, err
 	}
 
+	for i := range taxes {
+		if err := r.loadRelations(&taxes[i]); err != nil {
+			return nil, 0, err
+		}
+	}
+
 	return taxes, total, nil
 }
 
 func (r *taxRepository) FindByID(id string) (models.Tax, error) {
-	row := r.db.QueryRow(`
-		SELECT id, name, rate, company_id, created_at, updated_at
-		FROM taxes
-		WHERE id = $1
-	`, id)
-
-	var t models.Tax
-	if err := row.Scan(&t.ID, &t.Name, &t.Rate, &t.CompanyID, &t.CreatedAt, &t.UpdatedAt); err != nil {
+	t, err := scanTax(r.db.QueryRow(taxSelectColumns+" FROM taxes WHERE id = $1", id))
+	if err != nil {
+		return models.Tax{}, err
+	}
+	if err := r.loadRelations(&t); err != nil {
 		return models.Tax{}, err
 	}
 	return t, nil
 }
 
+// FindActiveByCompany mengambil semua pajak aktif milik company, diurutkan sesuai urutan perhitungan.
+func (r *taxRepository) FindActiveByCompany(companyID string) ([]models.Tax, error) {
+	rows, err := r.db.Query(taxSelectColumns+`
+		FROM taxes
+		WHERE company_id = $1 AND is_active = TRUE
+		ORDER BY sequence ASC, created_at ASC, id ASC
+	`, companyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	taxes := []models.Tax{}
+	for rows.Next() {
+		t, err := scanTax(rows)
+		if err != nil {
+			return nil, err
+		}
+		taxes = append(taxes, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range taxes {
+		if err := r.loadRelations(&taxes[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return taxes, nil
+}
+
 func (r *taxRepository) Create(tax models.Tax) (models.Tax, error) {
-	err := r.db.QueryRow(`
-		INSERT INTO taxes (company_id, name, rate, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id
-	`, tax.CompanyID, tax.Name, tax.Rate, tax.CreatedAt, tax.UpdatedAt).Scan(&tax.ID)
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.Tax{}, err
+	}
+	defer tx.Rollback()
 
+	err = tx.QueryRow(`
+		INSERT INTO taxes (company_id, name, rate, type, target_type, is_inclusive, sequence, is_compound, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`,
+		tax.CompanyID, tax.Name, tax.Rate, tax.Type, tax.TargetType,
+		tax.IsInclusive, tax.Sequence, tax.IsCompound, tax.IsActive,
+		tax.CreatedAt, tax.UpdatedAt,
+	).Scan(&tax.ID)
 	if err != nil {
 		return models.Tax{}, err
 	}
+
+	if err := replaceTaxRelations(tx, tax); err != nil {
+		return models.Tax{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Tax{}, err
+	}
 	return tax, nil
 }
 
 func (r *taxRepository) Update(tax models.Tax) (models.Tax, error) {
-	err := r.db.QueryRow(`
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.Tax{}, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
 		UPDATE taxes
-		SET name = $1, rate = $2, updated_at = $3
-		WHERE id = $4
+		SET name = $1, rate = $2, type = $3, target_type = $4, is_inclusive = $5, sequence = $6,
+		    is_compound = $7, is_active = $8, updated_at = $9
+		WHERE id = $10
 		RETURNING id
-	`, tax.Name, tax.Rate, tax.UpdatedAt, tax.ID).Scan(&tax.ID)
-
+	`,
+		tax.Name, tax.Rate, tax.Type, tax.TargetType, tax.IsInclusive, tax.Sequence,
+		tax.IsCompound, tax.IsActive, tax.UpdatedAt, tax.ID,
+	).Scan(&tax.ID)
 	if err != nil {
 		return models.Tax{}, err
 	}
+
+	if err := replaceTaxRelations(tx, tax); err != nil {
+		return models.Tax{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Tax{}, err
+	}
 	return tax, nil
 }
 
@@ -136,3 +288,46 @@ func (r *taxRepository) Delete(id string) error {
 	}
 	return nil
 }
+
+// insertTaxBreakdowns menyimpan rincian pajak transaksi ke tabel purchase_taxes / sale_taxes.
+func insertTaxBreakdowns(tx *sql.Tx, table string, parentColumn string, parentID string, taxes []models.TaxBreakdown) ([]models.TaxBreakdown, error) {
+	inserted := make([]models.TaxBreakdown, 0, len(taxes))
+	for _, t := range taxes {
+		if err := tx.QueryRow(
+			fmt.Sprintf(`INSERT INTO %s (%s, tax_id, name, type, rate, is_inclusive, taxable_amount, amount)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`, table, parentColumn),
+			parentID, t.TaxID, t.Name, t.Type, t.Rate, t.IsInclusive, t.TaxableAmount, t.Amount,
+		).Scan(&t.ID); err != nil {
+			return nil, fmt.Errorf("insert %s: %w", table, err)
+		}
+		inserted = append(inserted, t)
+	}
+	return inserted, nil
+}
+
+// loadTaxBreakdowns mengambil rincian pajak sebuah transaksi.
+func loadTaxBreakdowns(db *sql.DB, table string, parentColumn string, parentID string) ([]models.TaxBreakdown, error) {
+	rows, err := db.Query(
+		fmt.Sprintf(`SELECT id, tax_id, name, type, rate, is_inclusive, taxable_amount, amount
+			FROM %s WHERE %s = $1 ORDER BY is_inclusive DESC, name ASC`, table, parentColumn),
+		parentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	taxes := []models.TaxBreakdown{}
+	for rows.Next() {
+		var t models.TaxBreakdown
+		var taxID sql.NullString
+		if err := rows.Scan(&t.ID, &taxID, &t.Name, &t.Type, &t.Rate, &t.IsInclusive, &t.TaxableAmount, &t.Amount); err != nil {
+			return nil, err
+		}
+		if taxID.Valid {
+			t.TaxID = &taxID.String
+		}
+		taxes = append(taxes, t)
+	}
+	return taxes, rows.Err()
+}
@@ -9,9 +9,11 @@ import (
 type UnitRepository interface {
 	FindAll(companyID string, params models.PaginationParams) ([]models.Unit, int, error)
 	FindByID(id string, companyID string) (models.Unit, error)
+	FindByIDs(companyID string, ids []string) ([]models.Unit, error)
 	Create(unit models.Unit) (models.Unit, error)
 	Update(unit models.Unit) (models.Unit, error)
 	Delete(id string, companyID string) error
+	IsInUse(id string) (bool, error)
 }
 
 type unitRepository struct {
@@ -50,7 +52,7 @@ func (r *unitRepository) FindAll(companyID string, params models.PaginationParam
 		sortOrder = "ASC"
 	}
 
-	query := "SELECT id, name, symbol, type, company_id, created_by, updated_by, created_at, updated_at" + baseQuery
+	query := "SELECT id, name, symbol, type, conversion_factor, decimal_places, company_id, created_by, updated_by, created_at, updated_at" + baseQuery
 	query += fmt.Sprintf(" ORDER BY %s %s", sortBy, sortOrder)
 
 	offset := (params.Page - 1) * params.Limit
@@ -66,7 +68,7 @@ func (r *unitRepository) FindAll(companyID string, params models.PaginationParam
 	units := []models.Unit{}
 	for rows.Next() {
 		var unit models.Unit
-		if err := rows.Scan(&unit.ID, &unit.Name, &unit.Symbol, &unit.Type, &unit.CompanyID, &unit.CreatedBy, &unit.UpdatedBy, &unit.CreatedAt, &unit.UpdatedAt); err != nil {
+		if err := rows.Scan(&unit.ID, &unit.Name, &unit.Symbol, &unit.Type, &unit.ConversionFactor, &unit.DecimalPlaces, &unit.CompanyID, &unit.CreatedBy, &unit.UpdatedBy, &unit.CreatedAt, &unit.UpdatedAt); err != nil {
 			return nil, 0, err
 		}
 		units = append(units, unit)
@@ -81,25 +83,53 @@ func (r *unitRepository) FindAll(companyID string, params models.PaginationParam
 
 func (r *unitRepository) FindByID(id string, companyID string) (models.Unit, error) {
 	row := r.db.QueryRow(`
-		SELECT id, name, symbol, type, company_id, created_by, updated_by, created_at, updated_at
+		SELECT id, name, symbol, type, conversion_factor, decimal_places, company_id, created_by, updated_by, created_at, updated_at
 		FROM units
 		WHERE id = $1 AND company_id = $2
 	`, id, companyID)
 
 	var unit models.Unit
-	if err := row.Scan(&unit.ID, &unit.Name, &unit.Symbol, &unit.Type, &unit.CompanyID, &unit.CreatedBy, &unit.UpdatedBy, &unit.CreatedAt, &unit.UpdatedAt); err != nil {
+	if err := row.Scan(&unit.ID, &unit.Name, &unit.Symbol, &unit.Type, &unit.ConversionFactor, &unit.DecimalPlaces, &unit.CompanyID, &unit.CreatedBy, &unit.UpdatedBy, &unit.CreatedAt, &unit.UpdatedAt); err != nil {
 		return models.Unit{}, err
 	}
 
 	return unit, nil
 }
 
+// FindByIDs mengambil beberapa satuan milik company sekaligus (untuk konversi)
+func (r *unitRepository) FindByIDs(companyID string, ids []string) ([]models.Unit, error) {
+	rows, err := r.db.Query(`
+		SELECT id, name, symbol, type, conversion_factor, decimal_places, company_id, created_by, updated_by, created_at, updated_at
+		FROM units
+		WHERE company_id = $1 AND id = ANY($2)
+	`, companyID, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	units := []models.Unit{}
+	for rows.Next() {
+		var unit models.Unit
+		if err := rows.Scan(&unit.ID, &unit.Name, &unit.Symbol, &unit.Type, &unit.ConversionFactor, &unit.DecimalPlaces, &unit.CompanyID, &unit.CreatedBy, &unit.UpdatedBy, &unit.CreatedAt, &unit.UpdatedAt); err != nil {
+			return nil, err
+		}
+		units = append(units, unit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return units, nil
+}
+
 func (r *unitRepository) Create(unit models.Unit) (models.Unit, error) {
 	err := r.db.QueryRow(`
-		INSERT INTO units (name, symbol, type, company_id, created_by, updated_by, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO units (name, symbol, type, conversion_factor, decimal_places, company_id, created_by, updated_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id
-	`, unit.Name, unit.Symbol, unit.Type, unit.CompanyID, unit.CreatedBy, unit.UpdatedBy, unit.CreatedAt, unit.UpdatedAt).Scan(&unit.ID)
+	`, unit.Name, unit.Symbol, unit.Type, unit.ConversionFactor, unit.DecimalPlaces, unit.CompanyID, unit.CreatedBy, unit.UpdatedBy, unit.CreatedAt, unit.UpdatedAt).Scan(&unit.ID)
 	if err != nil {
 		return models.Unit{}, err
 	}
@@ -110,10 +140,10 @@ func (r *unitRepository) Create(unit models.Unit) (models.Unit, error) {
 func (r *unitRepository) Update(unit models.Unit) (models.Unit, error) {
 	err := r.db.QueryRow(`
 		UPDATE units
-		SET name = $1, symbol = $2, type = $3, company_id = $4, updated_by = $5, updated_at = $6
-		WHERE id = $7 AND company_id = $8
+		SET name = $1, symbol = $2, type = $3, conversion_factor = $4, decimal_places = $5, company_id = $6, updated_by = $7, updated_at = $8
+		WHERE id = $9 AND company_id = $10
 		RETURNING id
-	`, unit.Name, unit.Symbol, unit.Type, unit.CompanyID, unit.UpdatedBy, unit.UpdatedAt, unit.ID, unit.CompanyID).Scan(&unit.ID)
+	`, unit.Name, unit.Symbol, unit.Type, unit.ConversionFactor, unit.DecimalPlaces, unit.CompanyID, unit.UpdatedBy, unit.UpdatedAt, unit.ID, unit.CompanyID).Scan(&unit.ID)
 	if err != nil {
 		return models.Unit{}, err
 	}
@@ -121,6 +151,16 @@ func (r *unitRepository) Update(unit models.Unit) (models.Unit, error) {
 	return unit, nil
 }
 
+// IsInUse: satuan dipakai produk (termasuk stoknya) atau resep
+func (r *unitRepository) IsInUse(id string) (bool, error) {
+	var inUse bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM products WHERE unit_id = $1)
+			OR EXISTS (SELECT 1 FROM recipes WHERE unit_id = $1)
+	`, id).Scan(&inUse)
+	return inUse, err
+}
+
 func (r *unitRepository) Delete(id string, companyID string) error {
 	res, err := r.db.Exec(`DELETE FROM units WHERE id = $1 AND company_id = $2`, id, companyID)
 	if err != nil {
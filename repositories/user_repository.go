@@ -4,7 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"gowes/models"
+	"time"
 )
 
 type UserRepository interface {
@@ -13,6 +15,15 @@ type UserRepository interface {
 	FindByUsername(username string) (models.User, error)
 	FindByID(user_id string) (models.User, error)
 	ChangeActivateUser(user_id string) (models.User, error)
+	FindAllByCompany(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.User, int, error)
+	FindByIDAndCompany(id string, companyID string) (models.User, error)
+	Update(user models.User) (models.User, error)
+	SetActive(id string, companyID string, active bool) error
+	UpdatePassword(id string, companyID string, passwordHash string) error
+	UpdatePosPIN(id string, companyID string, pinHash string) error
+	FindForPinLogin(username string, companyID string) (models.User, models.PinLock, error)
+	RecordPinFailure(id string, maxAttempts int, lockedUntil time.Time) (bool, error)
+	ResetPinAttempts(id string) error
 }
 
 type userRepository struct {
@@ -26,8 +37,8 @@ func NewUserRepository(db *sql.DB) UserRepository {
 func (r *userRepository) Create(ctx context.Context, tx *sql.Tx, user models.User) (models.User, error) {
 	// Note: We use DEFAULT uuid_generate_v4() for ID in SQL, so we scan it back
 	query := `
-		INSERT INTO users (username, email, password_hash, role, pos_pin, company_id, is_owner, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO users (username, email, password_hash, role, role_id, pos_pin, company_id, is_owner, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id
 	`
 	var row *sql.Row
@@ -37,9 +48,11 @@ func (r *userRepository) Create(ctx context.Context, tx *sql.Tx, user models.Use
 			user.Email,
 			user.PasswordHash,
 			user.Role,
+			user.RoleID,
 			user.PosPIN,
 			user.CompanyID,
 			user.IsOwner,
+			user.Active,
 			user.CreatedAt,
 			user.UpdatedAt,
 		)
@@ -49,9 +62,11 @@ func (r *userRepository) Create(ctx context.Context, tx *sql.Tx, user models.Use
 			user.Email,
 			user.PasswordHash,
 			user.Role,
+			user.RoleID,
 			user.PosPIN,
 			user.CompanyID,
 			user.IsOwner,
+			user.Active,
 			user.CreatedAt,
 			user.UpdatedAt,
 		)
@@ -62,12 +77,13 @@ func (r *userRepository) Create(ctx context.Context, tx *sql.Tx, user models.Use
 	if err != nil {
 		return models.User{}, err
 	}
+	user.HasPosPIN = user.PosPIN != nil
 	return user, nil
 }
 
 func (r *userRepository) FindByEmail(email string) (models.User, error) {
 	query := `
-		SELECT id, username, email, password_hash, role, pos_pin, company_id, created_at, updated_at, active, is_owner
+		SELECT id, username, email, password_hash, role, pos_pin, company_id, created_at, updated_at, active, is_owner, token_version
 		FROM users
 		WHERE email = $1 OR username = $1
 	`
@@ -84,6 +100,7 @@ func (r *userRepository) FindByEmail(email string) (models.User, error) {
 		&user.UpdatedAt,
 		&user.Active,
 		&user.IsOwner,
+		&user.TokenVersion,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -91,6 +108,7 @@ func (r *userRepository) FindByEmail(email string) (models.User, error) {
 		}
 		return models.User{}, err
 	}
+	user.HasPosPIN = user.PosPIN != nil
 	return user, nil
 }
 
@@ -112,6 +130,7 @@ func (r *userRepository) FindByUsername(username string) (models.User, error) {
 		&user.CreatedAt,
 		&user.UpdatedAt,
 		&user.Active,
+		&user.IsOwner,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -119,12 +138,13 @@ func (r *userRepository) FindByUsername(username string) (models.User, error) {
 		}
 		return models.User{}, err
 	}
+	user.HasPosPIN = user.PosPIN != nil
 	return user, nil
 }
 
 func (r *userRepository) FindByID(user_id string) (models.User, error) {
 	query := `
-		SELECT id, username, email, password_hash, role, pos_pin, company_id, created_at, updated_at, is_owner
+		SELECT id, username, email, password_hash, role, pos_pin, company_id, created_at, updated_at, active, is_owner, token_version
 		FROM users
 		WHERE id = $1
 	`
@@ -139,7 +159,9 @@ func (r *userRepository) FindByID(user_id string) (models.User, error) {
 		&user.CompanyID,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.Active,
 		&user.IsOwner,
+		&user.TokenVersion,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -147,6 +169,7 @@ func (r *userRepository) FindByID(user_id string) (models.User, error) {
 		}
 		return models.User{}, err
 	}
+	user.HasPosPIN = user.PosPIN != nil
 	return user, nil
 }
 
@@ -158,3 +181,212 @@ func (r *userRepository) ChangeActivateUser(user_id string) (models.User, error)
 	}
 	return r.FindByID(user_id)
 }
+
+const userSelectColumns = `SELECT id, username, email, role, role_id, pos_pin, company_id, active, is_owner, created_at, updated_at FROM users`
+
+type userScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUser(row userScanner) (models.User, error) {
+	var user models.User
+	if err := row.Scan(
+		&user.ID,
+		&user.Username,
+		&user.Email,
+		&user.Role,
+		&user.RoleID,
+		&user.PosPIN,
+		&user.CompanyID,
+		&user.Active,
+		&user.IsOwner,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	); err != nil {
+		return models.User{}, err
+	}
+	user.HasPosPIN = user.PosPIN != nil
+	return user, nil
+}
+
+// userFilterColumns: filter query string yang didukung list user; active dan is_owner diisi true / false
+var userFilterColumns = map[string]filterColumn{
+	"role":       {column: "role::text", kind: filterValue},
+	"active":     {column: "active::text", kind: filterValue},
+	"is_owner":   {column: "is_owner::text", kind: filterValue},
+	"created_at": {column: "created_at", kind: filterDate},
+}
+
+func (r *userRepository) FindAllByCompany(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.User, int, error) {
+	baseQuery := " WHERE company_id = $1"
+	args := []interface{}{companyID}
+	argIdx := 2
+
+	baseQuery, args, argIdx = applyFilterParams(baseQuery, args, argIdx, filter, userFilterColumns)
+	if params.Search != "" {
+		baseQuery += fmt.Sprintf(" AND (username ILIKE $%d OR email ILIKE $%d)", argIdx, argIdx)
+		args = append(args, "%"+params.Search+"%")
+		argIdx++
+	}
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM users"+baseQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	allowedSorts := map[string]string{
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+		"username":   "username",
+		"email":      "email",
+		"role":       "role",
+	}
+	sortBy := "created_at"
+	if col, ok := allowedSorts[params.SortBy]; ok {
+		sortBy = col
+	}
+
+	sortOrder := "DESC"
+	if params.SortOrder == "ASC" {
+		sortOrder = "ASC"
+	}
+
+	query := userSelectColumns + baseQuery
+	query += fmt.Sprintf(" ORDER BY %s %s", sortBy, sortOrder)
+
+	offset := (params.Page - 1) * params.Limit
+	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
+	args = append(args, params.Limit, offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// FindByIDAndCompany mengembalikan sql.ErrNoRows jika user tidak ada di company tersebut
+func (r *userRepository) FindByIDAndCompany(id string, companyID string) (models.User, error) {
+	return scanUser(r.db.QueryRow(userSelectColumns+" WHERE id = $1 AND company_id = $2", id, companyID))
+}
+
+func (r *userRepository) Update(user models.User) (models.User, error) {
+	res, err := r.db.Exec(`
+		UPDATE users SET username = $1, email = $2, role = $3, role_id = $4, updated_at = $5
+		WHERE id = $6 AND company_id = $7
+	`, user.Username, user.Email, user.Role, user.RoleID, user.UpdatedAt, user.ID, user.CompanyID)
+	if err != nil {
+		return models.User{}, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return models.User{}, err
+	}
+	if affected == 0 {
+		return models.User{}, sql.ErrNoRows
+	}
+	return r.FindByIDAndCompany(user.ID, *user.CompanyID)
+}
+
+func (r *userRepository) SetActive(id string, companyID string, active bool) error {
+	return r.execUserUpdate(`UPDATE users SET active = $1, updated_at = $2 WHERE id = $3 AND company_id = $4`, active, time.Now().UTC(), id, companyID)
+}
+
+// UpdatePassword juga menaikkan token_version sehingga token lama user tersebut ditolak
+func (r *userRepository) UpdatePassword(id string, companyID string, passwordHash string) error {
+	return r.execUserUpdate(`
+		UPDATE users SET password_hash = $1, token_version = token_version + 1, updated_at = $2
+		WHERE id = $3 AND company_id = $4
+	`, passwordHash, time.Now().UTC(), id, companyID)
+}
+
+// UpdatePosPIN juga membuka kunci login PIN user
+func (r *userRepository) UpdatePosPIN(id string, companyID string, pinHash string) error {
+	return r.execUserUpdate(`
+		UPDATE users SET pos_pin = $1, pin_failed_attempts = 0, pin_locked_until = NULL, updated_at = $2
+		WHERE id = $3 AND company_id = $4
+	`, pinHash, time.Now().UTC(), id, companyID)
+}
+
+// FindForPinLogin mengambil user di company beserta status percobaan PIN-nya; sql.ErrNoRows jika tidak ada.
+func (r *userRepository) FindForPinLogin(username string, companyID string) (models.User, models.PinLock, error) {
+	var user models.User
+	var lock models.PinLock
+	var lockedUntil sql.NullTime
+	err := r.db.QueryRow(`
+		SELECT id, username, email, role, pos_pin, company_id, active, is_owner, token_version, created_at, updated_at,
+			pin_failed_attempts, pin_locked_until
+		FROM users
+		WHERE username = $1 AND company_id = $2
+	`, username, companyID).Scan(
+		&user.ID,
+		&user.Username,
+		&user.Email,
+		&user.Role,
+		&user.PosPIN,
+		&user.CompanyID,
+		&user.Active,
+		&user.IsOwner,
+		&user.TokenVersion,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&lock.FailedAttempts,
+		&lockedUntil,
+	)
+	if err != nil {
+		return models.User{}, models.PinLock{}, err
+	}
+	user.HasPosPIN = user.PosPIN != nil
+	if lockedUntil.Valid {
+		lock.LockedUntil = &lockedUntil.Time
+	}
+	return user, lock, nil
+}
+
+// RecordPinFailure menambah hitungan PIN salah; saat mencapai maxAttempts user dikunci sampai
+// lockedUntil dan hitungan diulang dari nol. Mengembalikan true jika user baru saja dikunci.
+func (r *userRepository) RecordPinFailure(id string, maxAttempts int, lockedUntil time.Time) (bool, error) {
+	var locked bool
+	err := r.db.QueryRow(`
+		UPDATE users
+		SET pin_failed_attempts = CASE WHEN pin_failed_attempts + 1 >= $1 THEN 0 ELSE pin_failed_attempts + 1 END,
+			pin_locked_until = CASE WHEN pin_failed_attempts + 1 >= $1 THEN $2 ELSE pin_locked_until END
+		WHERE id = $3
+		RETURNING pin_failed_attempts = 0
+	`, maxAttempts, lockedUntil, id).Scan(&locked)
+	return locked, err
+}
+
+func (r *userRepository) ResetPinAttempts(id string) error {
+	_, err := r.db.Exec(`UPDATE users SET pin_failed_attempts = 0, pin_locked_until = NULL WHERE id = $1`, id)
+	return err
+}
+
+func (r *userRepository) execUserUpdate(query string, args ...interface{}) error {
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -4,106 +4,194 @@ import (
 	"net/http"
 
 	"gowes/handlers"
+	"gowes/models"
 )
 
-func RegisterTodoRoutes(mux *http.ServeMux, h *handlers.TodoHandler) {
-	mux.HandleFunc("/api/todos", h.ListOrCreate)
-	mux.HandleFunc("/api/todos/", h.HandleByID)
+// protect memasang mw.Auth lalu mengecek satu permission untuk semua method
+func protect(mw *handlers.Middleware, permission models.Permission, h http.HandlerFunc) http.Handler {
+	return mw.Auth(mw.RequirePermission(permission, h))
 }
 
-func RegisterSystemRoutes(mux *http.ServeMux, h *handlers.SystemHandler) {
-	mux.HandleFunc("/api/database/tables", h.DatabaseTablesHandler)
-	mux.HandleFunc("/api/database/columns", h.TableColumnsHandler)
+// protectReadWrite: GET butuh permission read, method lain butuh permission write
+func protectReadWrite(mw *handlers.Middleware, read models.Permission, write models.Permission, h http.HandlerFunc) http.Handler {
+	return mw.Auth(mw.RequireReadWrite(read, write, h))
 }
 
-func RegisterAuthRoutes(mux *http.ServeMux, h *handlers.AuthHandler) {
+func RegisterTodoRoutes(mux *http.ServeMux, h *handlers.TodoHandler, mw *handlers.Middleware) {
+	mux.Handle("/api/todos", protect(mw, models.PermissionSystemView, h.ListOrCreate))
+	mux.Handle("/api/todos/", protect(mw, models.PermissionSystemView, h.HandleByID))
+}
+
+func RegisterSystemRoutes(mux *http.ServeMux, h *handlers.SystemHandler, mw *handlers.Middleware) {
+	mux.Handle("/api/database/tables", protect(mw, models.PermissionSystemView, h.DatabaseTablesHandler))
+	mux.Handle("/api/database/columns", protect(mw, models.PermissionSystemView, h.TableColumnsHandler))
+}
+
+func RegisterAuthRoutes(mux *http.ServeMux, h *handlers.AuthHandler, mw *handlers.Middleware) {
 	mux.HandleFunc("/api/auth/register", h.Register)
 	mux.HandleFunc("/api/auth/login", h.Login)
 	mux.HandleFunc("/api/auth/verify-email", h.VerifyEmail)
-	mux.HandleFunc("/api/auth/logout", h.Logout)
+	mux.HandleFunc("/api/auth/forgot-password", h.ForgotPassword)
+	mux.HandleFunc("/api/auth/reset-password", h.ResetPassword)
+	mux.HandleFunc("/api/auth/refresh", h.Refresh)
+	mux.HandleFunc("/api/auth/pin-login", h.PinLogin)
+	mux.Handle("/api/auth/logout", mw.Auth(http.HandlerFunc(h.Logout)))
+	mux.Handle("/api/auth/logout-all", mw.Auth(http.HandlerFunc(h.LogoutAll)))
+}
+
+func RegisterUserRoutes(mux *http.ServeMux, h *handlers.UserHandler, mw *handlers.Middleware) {
+	mux.Handle("/api/users", protect(mw, models.PermissionUserManage, h.ListOrCreate))
+	mux.Handle("/api/users/{id}", protect(mw, models.PermissionUserManage, h.HandleByID))
+	mux.Handle("/api/users/{id}/activate", protect(mw, models.PermissionUserManage, h.Activate))
+	mux.Handle("/api/users/{id}/deactivate", protect(mw, models.PermissionUserManage, h.Deactivate))
+	mux.Handle("/api/users/{id}/reset-password", protect(mw, models.PermissionUserManage, h.ResetPassword))
+	mux.Handle("/api/users/{id}/reset-pin", protect(mw, models.PermissionUserManage, h.ResetPIN))
+}
+
+func RegisterPosDeviceRoutes(mux *http.ServeMux, h *handlers.PosDeviceHandler, mw *handlers.Middleware) {
+	mux.Handle("/api/pos-devices", protect(mw, models.PermissionUserManage, h.ListOrCreate))
+	mux.Handle("/api/pos-devices/{id}", protect(mw, models.PermissionUserManage, h.HandleByID))
+}
+
+func RegisterCategoryRoutes(mux *http.ServeMux, h *handlers.CategoryHandler, mw *handlers.Middleware) {
+	// Protected routes wrapped with mw.Auth
+	mux.Handle("/api/categories", protectReadWrite(mw, models.PermissionProductView, models.PermissionProductWrite, h.ListOrCreate))
+	mux.Handle("/api/categories/", protectReadWrite(mw, models.PermissionProductView, models.PermissionProductWrite, h.HandleByID))
+}
+
+func RegisterAddOnRoutes(mux *http.ServeMux, h *handlers.AddOnHandler, mw *handlers.Middleware) {
+	mux.Handle("/api/add-on", protectReadWrite(mw, models.PermissionProductView, models.PermissionProductWrite, h.ListOrCreateAddOn))
+	// Protected routes wrapped with mw.Auth
+	mux.Handle("/api/add-on/{id}", protectReadWrite(mw, models.PermissionProductView, models.PermissionProductWrite, h.HandleByIdAddOn))
+}
+
+func RegisterOrderTypesRoutes(mux *http.ServeMux, h *handlers.OrderTypeHandler, mw *handlers.Middleware) {
+	mux.Handle("/api/order-types", protectReadWrite(mw, models.PermissionProductView, models.PermissionProductWrite, h.ListOrCreate))
+	mux.Handle("/api/order-types/{id}", protectReadWrite(mw, models.PermissionProductView, models.PermissionProductWrite, h.HandleByID))
 }
 
-func RegisterCategoryRoutes(mux *http.ServeMux, h *handlers.CategoryHandler) {
-	// Protected routes wrapped with AuthMiddleware
-	mux.Handle("/api/categories", handlers.AuthMiddleware(http.HandlerFunc(h.ListOrCreate)))
-	mux.Handle("/api/categories/", handlers.AuthMiddleware(http.HandlerFunc(h.HandleByID)))
+func RegisterOutletRoutes(mux *http.ServeMux, h *handlers.OutletHandler, mw *handlers.Middleware) {
+	mux.Handle("/api/outlets", protectReadWrite(mw, models.PermissionOutletView, models.PermissionOutletManage, h.ListOrCreate))
+	mux.Handle("/api/outlets/{id}", protectReadWrite(mw, models.PermissionOutletView, models.PermissionOutletManage, h.HandlerById))
+}
+
+func RegisterProductRoutes(mux *http.ServeMux, h *handlers.ProductHandler, mw *handlers.Middleware) {
+	mux.Handle("/api/products", protectReadWrite(mw, models.PermissionProductView, models.PermissionProductWrite, h.ListOrCreate))
+	// Protected routes wrapped with mw.Auth
+	mux.Handle("/api/products/{id}", protectReadWrite(mw, models.PermissionProductView, models.PermissionProductWrite, h.HandleByID))
+
+	mux.Handle("/api/products/mobile", protectReadWrite(mw, models.PermissionProductView, models.PermissionProductWrite, h.HandleMobile))
 }
 
-func RegisterAddOnRoutes(mux *http.ServeMux, h *handlers.AddOnHandler) {
-	mux.Handle("/api/add-on", handlers.AuthMiddleware(http.HandlerFunc(h.ListOrCreateAddOn)))
-	// Protected routes wrapped with AuthMiddleware
-	mux.Handle("/api/add-on/{id}", handlers.AuthMiddleware(http.HandlerFunc(h.HandleByIdAddOn)))
+func RegisterCustomerRoutes(mux *http.ServeMux, h *handlers.CustomerHandler, mw *handlers.Middleware) {
+	mux.Handle("/api/customers", protectReadWrite(mw, models.PermissionCustomerView, models.PermissionCustomerManage, h.ListOrCreate))
+	mux.Handle("/api/customers/{id}", protectReadWrite(mw, models.PermissionCustomerView, models.PermissionCustomerManage, h.HandleByID))
 }
 
-func RegisterOrderTypesRoutes(mux *http.ServeMux, h *handlers.OrderTypeHandler) {
-	mux.Handle("/api/order-types", handlers.AuthMiddleware(http.HandlerFunc(h.ListOrCreate)))
-	mux.Handle("/api/order-types/{id}", handlers.AuthMiddleware(http.HandlerFunc(h.HandleByID)))
+func RegisterDiscountRoutes(mux *http.ServeMux, h *handlers.DiscountHandler, mw *handlers.Middleware) {
+	mux.Handle("/api/discounts", protectReadWrite(mw, models.PermissionDiscountView, models.PermissionDiscountManage, h.ListOrCreate))
+	mux.Handle("/api/discounts/evaluate", protect(mw, models.PermissionSaleCreate, h.Evaluate))
+	mux.Handle("/api/discounts/{id}", protectReadWrite(mw, models.PermissionDiscountView, models.PermissionDiscountManage, h.HandleByID))
 }
 
-func RegisterOutletRoutes(mux *http.ServeMux, h *handlers.OutletHandler) {
-	mux.Handle("/api/outlets", handlers.AuthMiddleware(http.HandlerFunc(h.ListOrCreate)))
-	mux.Handle("/api/outlets/{id}", handlers.AuthMiddleware(http.HandlerFunc(h.HandlerById)))
+func RegisterTaxRoutes(mux *http.ServeMux, h *handlers.TaxHandler, mw *handlers.Middleware) {
+	mux.Handle("/api/taxes", protectReadWrite(mw, models.PermissionDiscountView, models.PermissionDiscountManage, h.ListOrCreate))
+	mux.Handle("/api/taxes/{id}", protectReadWrite(mw, models.PermissionDiscountView, models.PermissionDiscountManage, h.HandleByID))
 }
 
-func RegisterProductRoutes(mux *http.ServeMux, h *handlers.ProductHandler) {
-	mux.Handle("/api/products", handlers.AuthMiddleware(http.HandlerFunc(h.ListOrCreate)))
-	// Protected routes wrapped with AuthMiddleware
-	mux.Handle("/api/products/{id}", handlers.AuthMiddleware(http.HandlerFunc(h.HandleByID)))
+func RegisterRoleRoutes(mux *http.ServeMux, h *handlers.RoleHandler, mw *handlers.Middleware) {
+	mux.Handle("/api/roles", protect(mw, models.PermissionUserManage, h.ListOrCreate))
+	mux.Handle("/api/roles/{id}", protect(mw, models.PermissionUserManage, h.HandleByID))
+	mux.Handle("/api/permissions", protect(mw, models.PermissionUserManage, h.ListPermissions))
+}
+
+func RegisterUnitRoutes(mux *http.ServeMux, h *handlers.UnitHandler, mw *handlers.Middleware) {
+	mux.Handle("/api/units", protectReadWrite(mw, models.PermissionProductView, models.PermissionProductWrite, h.ListOrCreate))
+	mux.Handle("/api/units/convert", protect(mw, models.PermissionProductView, h.Convert))
+	mux.Handle("/api/units/{id}", protectReadWrite(mw, models.PermissionProductView, models.PermissionProductWrite, h.HandleByID))
+}
+
+func RegisterSupplierRoutes(mux *http.ServeMux, h *handlers.SupplierHandler, mw *handlers.Middleware) {
+	mux.Handle("/api/suppliers", protectReadWrite(mw, models.PermissionPurchaseView, models.PermissionPurchaseManage, h.ListOrCreate))
+	mux.Handle("/api/suppliers/{id}", protectReadWrite(mw, models.PermissionPurchaseView, models.PermissionPurchaseManage, h.HandleByID))
+}
 
-	mux.Handle("/api/products/mobile", handlers.AuthMiddleware(http.HandlerFunc(h.HandleMobile)))
+func RegisterRecipeRoutes(mux *http.ServeMux, h *handlers.RecipeHandler, mw *handlers.Middleware) {
+	mux.Handle("/api/recipes", protectReadWrite(mw, models.PermissionProductView, models.PermissionProductWrite, h.ListOrCreate))
+	mux.Handle("/api/recipes/{id}", protectReadWrite(mw, models.PermissionProductView, models.PermissionProductWrite, h.HandleByID))
+	mux.Handle("/api/recipes/bom/{product_id}", protectReadWrite(mw, models.PermissionProductView, models.PermissionProductWrite, h.HandleBOM))
 }
 
-func RegisterCustomerRoutes(mux *http.ServeMux, h *handlers.CustomerHandler) {
-	mux.Handle("/api/customers", handlers.AuthMiddleware(http.HandlerFunc(h.ListOrCreate)))
-	mux.Handle("/api/customers/{id}", handlers.AuthMiddleware(http.HandlerFunc(h.HandleByID)))
+func RegisterCashierShiftRoutes(mux *http.ServeMux, h *handlers.CashierShiftHandler, mw *handlers.Middleware) {
+	mux.Handle("/api/cashier-shifts", protect(mw, models.PermissionShiftManage, h.List))
+	mux.Handle("/api/cashier-shifts/start", protect(mw, models.PermissionShiftManage, h.StartShift))
+	mux.Handle("/api/cashier-shifts/end", protect(mw, models.PermissionShiftManage, h.EndShift))
 }
 
-func RegisterDiscountRoutes(mux *http.ServeMux, h *handlers.DiscountHandler) {
-	mux.Handle("/api/discounts", handlers.AuthMiddleware(http.HandlerFunc(h.ListOrCreate)))
-	mux.Handle("/api/discounts/{id}", handlers.AuthMiddleware(http.HandlerFunc(h.HandleByID)))
+func RegisterPurchaseRoutes(mux *http.ServeMux, h *handlers.PurchaseHandler, mw *handlers.Middleware) {
+	mux.Handle("/api/purchases", protectReadWrite(mw, models.PermissionPurchaseView, models.PermissionPurchaseManage, h.ListOrCreate))
+	mux.Handle("/api/purchases/{id}", protectReadWrite(mw, models.PermissionPurchaseView, models.PermissionPurchaseManage, h.HandleByID))
+	mux.Handle("/api/purchases/{id}/order", protectReadWrite(mw, models.PermissionPurchaseView, models.PermissionPurchaseManage, h.Order))
+	mux.Handle("/api/purchases/{id}/cancel", protectReadWrite(mw, models.PermissionPurchaseView, models.PermissionPurchaseManage, h.Cancel))
+	mux.Handle("/api/purchases/{id}/receive", protectReadWrite(mw, models.PermissionPurchaseView, models.PermissionPurchaseManage, h.Receive))
+	mux.Handle("/api/purchases/{id}/return", protectReadWrite(mw, models.PermissionPurchaseView, models.PermissionPurchaseManage, h.Return))
+	mux.Handle("/api/purchases/{id}/void", protectReadWrite(mw, models.PermissionPurchaseView, models.PermissionPurchaseManage, h.Void))
 }
 
-func RegisterTaxRoutes(mux *http.ServeMux, h *handlers.TaxHandler) {
-	mux.Handle("/api/taxes", handlers.AuthMiddleware(http.HandlerFunc(h.ListOrCreate)))
-	mux.Handle("/api/taxes/{id}", handlers.AuthMiddleware(http.HandlerFunc(h.HandleByID)))
+func RegisterPayableRoutes(mux *http.ServeMux, h *handlers.PayableHandler, mw *handlers.Middleware) {
+	mux.Handle("/api/purchases/{id}/invoice", protectReadWrite(mw, models.PermissionPurchaseView, models.PermissionPurchaseManage, h.Invoice))
+	mux.Handle("/api/purchases/{id}/payments", protectReadWrite(mw, models.PermissionPurchaseView, models.PermissionPurchaseManage, h.Payments))
+	mux.Handle("/api/payables/aging", protect(mw, models.PermissionReportView, h.Aging))
 }
 
-func RegisterRoleRoutes(mux *http.ServeMux, h *handlers.RoleHandler) {
-	mux.Handle("/api/roles", handlers.AuthMiddleware(http.HandlerFunc(h.ListOrCreate)))
-	mux.Handle("/api/roles/{id}", handlers.AuthMiddleware(http.HandlerFunc(h.HandleByID)))
+func RegisterStockRoutes(mux *http.ServeMux, h *handlers.StockHandler, mw *handlers.Middleware) {
+	mux.Handle("/api/stocks", protect(mw, models.PermissionStockView, h.List))
+	mux.Handle("/api/stocks/{outlet_id}/{product_id}", protect(mw, models.PermissionStockView, h.GetByOutletAndProduct))
 }
 
-func RegisterUnitRoutes(mux *http.ServeMux, h *handlers.UnitHandler) {
-	mux.Handle("/api/units", handlers.AuthMiddleware(http.HandlerFunc(h.ListOrCreate)))
-	mux.Handle("/api/units/{id}", handlers.AuthMiddleware(http.HandlerFunc(h.HandleByID)))
+func RegisterStockThresholdRoutes(mux *http.ServeMux, h *handlers.StockThresholdHandler, mw *handlers.Middleware) {
+	mux.Handle("/api/stock-thresholds", protectReadWrite(mw, models.PermissionStockView, models.PermissionStockAdjust, h.ListOrSave))
+	mux.Handle("/api/stock-thresholds/{id}", protectReadWrite(mw, models.PermissionStockView, models.PermissionStockAdjust, h.HandleByID))
+	mux.Handle("/api/stocks/low", protect(mw, models.PermissionStockView, h.LowStock))
+	mux.Handle("/api/stocks/reorder-suggestions", protect(mw, models.PermissionReportView, h.ReorderSuggestions))
 }
 
-func RegisterSupplierRoutes(mux *http.ServeMux, h *handlers.SupplierHandler) {
-	mux.Handle("/api/suppliers", handlers.AuthMiddleware(http.HandlerFunc(h.ListOrCreate)))
-	mux.Handle("/api/suppliers/{id}", handlers.AuthMiddleware(http.HandlerFunc(h.HandleByID)))
+func RegisterStockMovementRoutes(mux *http.ServeMux, h *handlers.StockMovementHandler, mw *handlers.Middleware) {
+	mux.Handle("/api/stock-movements", protect(mw, models.PermissionStockView, h.List))
+	mux.Handle("/api/stock-movements/card", protect(mw, models.PermissionReportView, h.StockCard))
+	mux.Handle("/api/stock-movements/summary", protect(mw, models.PermissionReportView, h.Summary))
+	mux.Handle("/api/stock-movements/{id}", protect(mw, models.PermissionStockView, h.GetByID))
 }
 
-func RegisterRecipeRoutes(mux *http.ServeMux, h *handlers.RecipeHandler) {
-	mux.Handle("/api/recipes", handlers.AuthMiddleware(http.HandlerFunc(h.ListOrCreate)))
-	mux.Handle("/api/recipes/{id}", handlers.AuthMiddleware(http.HandlerFunc(h.HandleByID)))
+func RegisterSaleRoutes(mux *http.ServeMux, h *handlers.SaleHandler, mw *handlers.Middleware) {
+	mux.Handle("/api/sales", protectReadWrite(mw, models.PermissionSaleView, models.PermissionSaleCreate, h.ListOrCreate))
+	mux.Handle("/api/sales/{id}", protectReadWrite(mw, models.PermissionSaleView, models.PermissionSaleCreate, h.HandleByID))
 }
 
-func RegisterCashierShiftRoutes(mux *http.ServeMux, h *handlers.CashierShiftHandler) {
-	mux.Handle("/api/cashier-shifts/start", handlers.AuthMiddleware(http.HandlerFunc(h.StartShift)))
-	mux.Handle("/api/cashier-shifts/end", handlers.AuthMiddleware(http.HandlerFunc(h.EndShift)))
+func RegisterPromoCodeRoutes(mux *http.ServeMux, h *handlers.PromoCodeHandler, mw *handlers.Middleware) {
+	mux.Handle("/api/promo-codes", protectReadWrite(mw, models.PermissionDiscountView, models.PermissionDiscountManage, h.ListOrCreate))
+	mux.Handle("/api/promo-codes/validate", protect(mw, models.PermissionSaleCreate, h.Validate))
+	mux.Handle("/api/promo-codes/redeem", protect(mw, models.PermissionSaleCreate, h.Redeem))
+	mux.Handle("/api/promo-codes/{id}", protectReadWrite(mw, models.PermissionDiscountView, models.PermissionDiscountManage, h.HandleByID))
 }
 
-func RegisterPurchaseRoutes(mux *http.ServeMux, h *handlers.PurchaseHandler) {
-	mux.Handle("/api/purchases", handlers.AuthMiddleware(http.HandlerFunc(h.ListOrCreate)))
-	mux.Handle("/api/purchases/{id}", handlers.AuthMiddleware(http.HandlerFunc(h.HandleByID)))
+func RegisterOutletProductRoutes(mux *http.ServeMux, h *handlers.OutletProductHandler, mw *handlers.Middleware) {
+	mux.Handle("/api/outlet-products", protectReadWrite(mw, models.PermissionProductView, models.PermissionProductWrite, h.ListOrCreate))
+	mux.Handle("/api/outlet-products/bulk", protectReadWrite(mw, models.PermissionProductView, models.PermissionProductWrite, h.Bulk))
+	mux.Handle("/api/outlet-products/{id}", protectReadWrite(mw, models.PermissionProductView, models.PermissionProductWrite, h.HandleByID))
 }
 
-func RegisterStockRoutes(mux *http.ServeMux, h *handlers.StockHandler) {
-	mux.Handle("/api/stocks", handlers.AuthMiddleware(http.HandlerFunc(h.List)))
-	mux.Handle("/api/stocks/{outlet_id}/{product_id}", handlers.AuthMiddleware(http.HandlerFunc(h.GetByOutletAndProduct)))
+func RegisterStockOpnameRoutes(mux *http.ServeMux, h *handlers.StockOpnameHandler, mw *handlers.Middleware) {
+	mux.Handle("/api/stock-opnames", protectReadWrite(mw, models.PermissionStockView, models.PermissionStockAdjust, h.ListOrCreate))
+	mux.Handle("/api/stock-opnames/{id}", protectReadWrite(mw, models.PermissionStockView, models.PermissionStockAdjust, h.HandleByID))
+	mux.Handle("/api/stock-opnames/{id}/items", protectReadWrite(mw, models.PermissionStockView, models.PermissionStockAdjust, h.SaveItems))
+	mux.Handle("/api/stock-opnames/{id}/items/{product_id}", protectReadWrite(mw, models.PermissionStockView, models.PermissionStockAdjust, h.DeleteItem))
+	mux.Handle("/api/stock-opnames/{id}/post", protectReadWrite(mw, models.PermissionStockView, models.PermissionStockAdjust, h.Post))
 }
 
-func RegisterStockMovementRoutes(mux *http.ServeMux, h *handlers.StockMovementHandler) {
-	mux.Handle("/api/stock-movements", handlers.AuthMiddleware(http.HandlerFunc(h.List)))
-	mux.Handle("/api/stock-movements/{id}", handlers.AuthMiddleware(http.HandlerFunc(h.GetByID)))
+func RegisterStockTransferRoutes(mux *http.ServeMux, h *handlers.StockTransferHandler, mw *handlers.Middleware) {
+	mux.Handle("/api/stock-transfers", protectReadWrite(mw, models.PermissionStockView, models.PermissionStockAdjust, h.ListOrCreate))
+	mux.Handle("/api/stock-transfers/{id}", protectReadWrite(mw, models.PermissionStockView, models.PermissionStockAdjust, h.HandleByID))
+	mux.Handle("/api/stock-transfers/{id}/send", protectReadWrite(mw, models.PermissionStockView, models.PermissionStockAdjust, h.Send))
+	mux.Handle("/api/stock-transfers/{id}/receive", protectReadWrite(mw, models.PermissionStockView, models.PermissionStockAdjust, h.Receive))
 }
@@ -2,7 +2,10 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"gowes/models"
@@ -15,27 +18,64 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrResetTokenInvalid   = errors.New("reset token is invalid or has expired")
+	ErrResetEmailRequired  = errors.New("email cannot be empty")
+	ErrPasswordTooShort    = errors.New("password must be at least 6 characters")
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid or has expired")
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrPosDeviceInvalid    = errors.New("pos device is not registered or inactive")
+	ErrPinLoginInvalid     = errors.New("invalid username or pin")
+	ErrPinLocked           = errors.New("too many wrong pin attempts, try again later")
+)
+
+const (
+	// passwordResetTTL adalah masa berlaku link reset password
+	passwordResetTTL = 30 * time.Minute
+	// accessTokenTTL dibuat pendek; perpanjangan lewat /api/auth/refresh
+	accessTokenTTL = 15 * time.Minute
+	// verifyTokenTTL adalah masa berlaku link verifikasi email
+	verifyTokenTTL  = 24 * time.Hour
+	refreshTokenTTL = 30 * 24 * time.Hour
+	// posSessionTTL: sesi login PIN tidak diperpanjang saat refresh, kira-kira sepanjang satu shift
+	posSessionTTL   = 12 * time.Hour
+	maxPinAttempts  = 5
+	pinLockDuration = 15 * time.Minute
+)
+
 type AuthService interface {
 	Register(input models.UserRegisterInput) (models.User, error)
 	Login(input models.LoginInput) (models.AuthResponse, error)
 	VerifyEmail(token string) error
+	ForgotPassword(input models.ForgotPasswordInput) error
+	ResetPassword(input models.ResetPasswordInput) error
+	Refresh(input models.RefreshTokenInput) (models.AuthResponse, error)
+	PinLogin(input models.PinLoginInput) (models.AuthResponse, error)
+	Logout(userID string, sessionID string) error
+	LogoutAll(userID string) error
 }
 
 type authService struct {
-	userRepo    repositories.UserRepository
-	outletRepo  repositories.OutletRepository
-	companyRepo repositories.CompanyRepository
-	emailRepo   repositories.EmailRepository
-	db          *sql.DB
+	userRepo          repositories.UserRepository
+	outletRepo        repositories.OutletRepository
+	companyRepo       repositories.CompanyRepository
+	emailRepo         repositories.EmailRepository
+	passwordResetRepo repositories.PasswordResetRepository
+	sessionRepo       repositories.SessionRepository
+	posDeviceRepo     repositories.PosDeviceRepository
+	db                *sql.DB
 }
 
-func NewAuthService(userRepo repositories.UserRepository, companyRepo repositories.CompanyRepository, outletRepo repositories.OutletRepository, emailRepo repositories.EmailRepository, db *sql.DB) AuthService {
+func NewAuthService(userRepo repositories.UserRepository, companyRepo repositories.CompanyRepository, outletRepo repositories.OutletRepository, emailRepo repositories.EmailRepository, passwordResetRepo repositories.PasswordResetRepository, sessionRepo repositories.SessionRepository, posDeviceRepo repositories.PosDeviceRepository, db *sql.DB) AuthService {
 	return &authService{
-		userRepo:    userRepo,
-		companyRepo: companyRepo,
-		outletRepo:  outletRepo,
-		emailRepo:   emailRepo,
-		db:          db,
+		userRepo:          userRepo,
+		companyRepo:       companyRepo,
+		outletRepo:        outletRepo,
+		emailRepo:         emailRepo,
+		passwordResetRepo: passwordResetRepo,
+		sessionRepo:       sessionRepo,
+		posDeviceRepo:     posDeviceRepo,
+		db:                db,
 	}
 }
 
@@ -48,7 +88,7 @@ func (s *authService) Register(input models.UserRegisterInput) (models.User, err
 		return models.User{}, errors.New("email cannot be empty")
 	}
 	if len(input.Password) < 6 {
-		return models.User{}, errors.New("password must be at least 6 characters")
+		return models.User{}, ErrPasswordTooShort
 	}
 	if strings.TrimSpace(input.BussinessName) == "" {
 		return models.User{}, errors.New("business name cannot be empty")
@@ -112,8 +152,11 @@ func (s *authService) Register(input models.UserRegisterInput) (models.User, err
 	}
 
 	if input.PosPIN != "" {
-		pin := input.PosPIN
-		newUser.PosPIN = &pin
+		pinHash, err := hashPosPIN(input.PosPIN)
+		if err != nil {
+			return models.User{}, err
+		}
+		newUser.PosPIN = &pinHash
 	}
 
 	newOutlet := models.OutletInput{
@@ -142,7 +185,7 @@ func (s *authService) Register(input models.UserRegisterInput) (models.User, err
 	}
 
 	// 8. Generate JWT for verify email
-	tokenString, _, err := generateJWT(createdUser, true)
+	tokenString, _, err := generateJWT(createdUser, true, models.UserSession{})
 	if err != nil {
 		return models.User{}, err
 	}
@@ -174,16 +217,171 @@ func (s *authService) Login(input models.LoginInput) (models.AuthResponse, error
 		return models.AuthResponse{}, errors.New("account not active")
 	}
 
-	// 4. Generate JWT
-	tokenString, expireTime, err := generateJWT(user, false)
+	// 4. Create session (refresh token)
+	refreshToken, err := generateOpaqueToken()
+	if err != nil {
+		return models.AuthResponse{}, err
+	}
+	now := time.Now().UTC()
+	session, err := s.sessionRepo.Create(models.UserSession{
+		UserID:     user.ID,
+		UserAgent:  input.UserAgent,
+		IPAddress:  input.IPAddress,
+		ExpiresAt:  now.Add(refreshTokenTTL),
+		LastUsedAt: now,
+		CreatedAt:  now,
+	}, hashToken(refreshToken))
+	if err != nil {
+		return models.AuthResponse{}, err
+	}
+
+	// 5. Generate JWT
+	return buildAuthResponse(user, session, refreshToken)
+}
+
+// Refresh menukar refresh token dengan access token dan refresh token baru (rotasi).
+// Refresh token lama langsung tidak berlaku; memakainya lagi mencabut sesi.
+func (s *authService) Refresh(input models.RefreshTokenInput) (models.AuthResponse, error) {
+	refreshToken := strings.TrimSpace(input.RefreshToken)
+	if refreshToken == "" {
+		return models.AuthResponse{}, ErrRefreshTokenInvalid
+	}
+
+	newRefreshToken, err := generateOpaqueToken()
+	if err != nil {
+		return models.AuthResponse{}, err
+	}
+	now := time.Now().UTC()
+	session, err := s.sessionRepo.Rotate(hashToken(refreshToken), hashToken(newRefreshToken), now.Add(refreshTokenTTL), now)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.AuthResponse{}, ErrRefreshTokenInvalid
+		}
+		return models.AuthResponse{}, err
+	}
+
+	user, err := s.userRepo.FindByID(session.UserID)
+	if err != nil {
+		return models.AuthResponse{}, err
+	}
+	if user.ID == "" {
+		return models.AuthResponse{}, ErrRefreshTokenInvalid
+	}
+
+	return buildAuthResponse(user, session, newRefreshToken)
+}
+
+// PinLogin adalah login cepat kasir di terminal POS terdaftar. Token yang terbit hanya berlaku
+// untuk outlet dan terminal tersebut, dan sesi kasir sebelumnya di terminal itu dicabut.
+// PIN salah maxPinAttempts kali berturut-turut mengunci login PIN user selama pinLockDuration.
+func (s *authService) PinLogin(input models.PinLoginInput) (models.AuthResponse, error) {
+	deviceKey := strings.TrimSpace(input.DeviceKey)
+	if deviceKey == "" {
+		return models.AuthResponse{}, ErrPosDeviceInvalid
+	}
+	device, err := s.posDeviceRepo.FindActiveByKey(hashToken(deviceKey))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.AuthResponse{}, ErrPosDeviceInvalid
+		}
+		return models.AuthResponse{}, err
+	}
+
+	username := strings.TrimSpace(input.Username)
+	if username == "" || !posPINPattern.MatchString(input.PIN) {
+		return models.AuthResponse{}, ErrPinLoginInvalid
+	}
+	user, lock, err := s.userRepo.FindForPinLogin(username, device.CompanyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.AuthResponse{}, ErrPinLoginInvalid
+		}
+		return models.AuthResponse{}, err
+	}
+
+	now := time.Now().UTC()
+	if lock.LockedUntil != nil && lock.LockedUntil.After(now) {
+		return models.AuthResponse{}, ErrPinLocked
+	}
+	if !user.Active || user.PosPIN == nil {
+		return models.AuthResponse{}, ErrPinLoginInvalid
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(*user.PosPIN), []byte(input.PIN)); err != nil {
+		locked, err := s.userRepo.RecordPinFailure(user.ID, maxPinAttempts, now.Add(pinLockDuration))
+		if err != nil {
+			return models.AuthResponse{}, err
+		}
+		if locked {
+			return models.AuthResponse{}, ErrPinLocked
+		}
+		return models.AuthResponse{}, ErrPinLoginInvalid
+	}
+	if lock.FailedAttempts > 0 || lock.LockedUntil != nil {
+		if err := s.userRepo.ResetPinAttempts(user.ID); err != nil {
+			return models.AuthResponse{}, err
+		}
+	}
+
+	// Satu terminal hanya dipakai satu kasir; sesi kasir sebelumnya diakhiri
+	if err := s.sessionRepo.RevokeAllByDevice(device.ID); err != nil {
+		return models.AuthResponse{}, err
+	}
+	if err := s.posDeviceRepo.TouchLastSeen(device.ID, now); err != nil {
+		return models.AuthResponse{}, err
+	}
+
+	refreshToken, err := generateOpaqueToken()
+	if err != nil {
+		return models.AuthResponse{}, err
+	}
+	session, err := s.sessionRepo.Create(models.UserSession{
+		UserID:     user.ID,
+		OutletID:   &device.OutletID,
+		DeviceID:   &device.ID,
+		UserAgent:  input.UserAgent,
+		IPAddress:  input.IPAddress,
+		ExpiresAt:  now.Add(posSessionTTL),
+		LastUsedAt: now,
+		CreatedAt:  now,
+	}, hashToken(refreshToken))
+	if err != nil {
+		return models.AuthResponse{}, err
+	}
+
+	return buildAuthResponse(user, session, refreshToken)
+}
+
+// Logout mencabut sesi token yang sedang dipakai
+func (s *authService) Logout(userID string, sessionID string) error {
+	if sessionID == "" {
+		return ErrSessionNotFound
+	}
+	if err := s.sessionRepo.Revoke(sessionID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// LogoutAll mencabut semua sesi user (logout dari semua perangkat)
+func (s *authService) LogoutAll(userID string) error {
+	return s.sessionRepo.RevokeAllByUser(userID)
+}
+
+func buildAuthResponse(user models.User, session models.UserSession, refreshToken string) (models.AuthResponse, error) {
+	tokenString, expireTime, err := generateJWT(user, false, session)
 	if err != nil {
 		return models.AuthResponse{}, err
 	}
 
 	return models.AuthResponse{
-		AccessToken: tokenString,
-		ExpireTime:  expireTime,
-		User:        user,
+		AccessToken:       tokenString,
+		ExpireTime:        expireTime,
+		RefreshToken:      refreshToken,
+		RefreshExpireTime: fmt.Sprintf("%d", session.ExpiresAt.Unix()),
+		User:              user,
 	}, nil
 }
 
@@ -214,19 +412,98 @@ func (s *authService) VerifyEmail(token string) error {
 	return nil
 }
 
-func generateJWT(user models.User, for_verified bool) (string, string, error) {
+// ForgotPassword mengirim link reset password ke email user. Email yang tidak terdaftar
+// tidak dianggap error agar endpoint tidak bisa dipakai untuk mengecek email yang terdaftar.
+func (s *authService) ForgotPassword(input models.ForgotPasswordInput) error {
+	email := strings.TrimSpace(input.Email)
+	if email == "" {
+		return ErrResetEmailRequired
+	}
+
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+	if user.ID == "" {
+		return nil
+	}
+
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	if err := s.passwordResetRepo.Create(user.ID, hashToken(token), time.Now().UTC().Add(passwordResetTTL)); err != nil {
+		return err
+	}
+
+	resetLink := fmt.Sprintf("%s?token=%s", os.Getenv("FE_RESET_PASSWORD"), token)
+	return s.emailRepo.SendResetPasswordEmail(context.Background(), user.Email, user.Username, resetLink)
+}
+
+// ResetPassword mengganti password memakai token dari email. Setelah berhasil, semua token
+// akses yang sudah terbit untuk user tersebut tidak berlaku lagi.
+func (s *authService) ResetPassword(input models.ResetPasswordInput) error {
+	token := strings.TrimSpace(input.Token)
+	if token == "" {
+		return ErrResetTokenInvalid
+	}
+	if len(input.Password) < 6 {
+		return ErrPasswordTooShort
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.passwordResetRepo.ResetPassword(hashToken(token), string(hashedPassword), time.Now().UTC()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrResetTokenInvalid
+		}
+		return err
+	}
+	return nil
+}
+
+// generateOpaqueToken membuat token acak untuk link reset password dan refresh token
+func generateOpaqueToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// hashToken: yang disimpan di DB hanya hash token, sehingga isi tabel tidak bisa dipakai langsung
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateJWT(user models.User, for_verified bool, session models.UserSession) (string, string, error) {
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
 		jwtSecret = "default-secret-change-me" // Fallback for dev
 	}
-	expireTime := time.Now().Add(time.Hour * 24).Unix()
-	fmt.Println(*user.CompanyID)
+	ttl := accessTokenTTL
+	if for_verified {
+		ttl = verifyTokenTTL
+	}
+	expireTime := time.Now().Add(ttl).Unix()
 	claims := jwt.MapClaims{
 		"sub":          user.ID,
 		"role":         user.Role,
 		"company_id":   user.CompanyID,
 		"exp":          expireTime,
 		"for_verified": for_verified,
+		"ver":          user.TokenVersion,
+		"sid":          session.ID,
+	}
+	// Token login PIN hanya berlaku di outlet dan terminal POS-nya
+	if session.OutletID != nil && session.DeviceID != nil {
+		claims["outlet_id"] = *session.OutletID
+		claims["device_id"] = *session.DeviceID
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -16,16 +16,26 @@ var (
 )
 
 type CashierShiftService interface {
+	ListShifts(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.CashierShift, int, error)
 	StartShift(companyID string, authUserID string, input models.StartCashierShiftInput) (models.CashierShift, error)
 	EndShift(companyID string, authUserID string, input models.EndCashierShiftInput) (models.CashierShift, error)
 }
 
 type cashierShiftService struct {
-	repo repositories.CashierShiftRepository
+	repo        repositories.CashierShiftRepository
+	companyRepo repositories.CompanyRepository
 }
 
-func NewCashierShiftService(repo repositories.CashierShiftRepository) CashierShiftService {
-	return &cashierShiftService{repo: repo}
+func NewCashierShiftService(repo repositories.CashierShiftRepository, companyRepo repositories.CompanyRepository) CashierShiftService {
+	return &cashierShiftService{repo: repo, companyRepo: companyRepo}
+}
+
+func (s *cashierShiftService) ListShifts(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.CashierShift, int, error) {
+	filter, err := companyDateFilter(s.companyRepo, companyID, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.repo.FindAll(companyID, params, filter)
 }
 
 func (s *cashierShiftService) StartShift(companyID string, authUserID string, input models.StartCashierShiftInput) (models.CashierShift, error) {
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"gowes/models"
+	"gowes/repositories"
+	"strings"
+	"time"
+)
+
+// companyLocation memuat zona waktu company. Jadwal diskon (hari & jam) dan batas hari filter
+// tanggal dihitung di zona ini, bukan zona waktu server.
+func companyLocation(repo repositories.CompanyRepository, companyID string) (*time.Location, error) {
+	timezone, err := repo.FindTimezone(companyID)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(timezone) == "" {
+		timezone = models.DefaultCompanyTimezone
+	}
+	return time.LoadLocation(timezone)
+}
+
+// companyDateFilter memindahkan batas hari filter tanggal ke zona waktu company
+func companyDateFilter(repo repositories.CompanyRepository, companyID string, filter models.FilterParams) (models.FilterParams, error) {
+	if len(filter.Dates) == 0 {
+		return filter, nil
+	}
+	loc, err := companyLocation(repo, companyID)
+	if err != nil {
+		return models.FilterParams{}, err
+	}
+	return filter.InLocation(loc), nil
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"fmt"
+	"gowes/models"
+	"math"
+	"sort"
+	"time"
+)
+
+// Kode alasan penolakan diskon pada hasil evaluasi
+const (
+	DiscountRejectOutlet      = "outlet_not_eligible"
+	DiscountRejectOrderType   = "order_type_not_eligible"
+	DiscountRejectNoItems     = "no_matching_items"
+	DiscountRejectMinPurchase = "min_purchase_not_met"
+	DiscountRejectLowPriority = "lower_priority"
+	DiscountRejectZeroAmount  = "zero_amount"
+	DiscountRejectInvalidType = "invalid_type"
+	DiscountRejectOutOfPeriod = "outside_validity_period"
+	DiscountRejectOutOfHours  = "outside_schedule"
+)
+
+// EvaluateDiscounts menghitung diskon yang berlaku untuk sebuah keranjang.
+//
+// Aturan yang dipakai:
+//   - Diskon diproses berdasarkan Priority (semakin kecil semakin diprioritaskan),
+//     lalu CreatedAt dan ID agar hasilnya selalu deterministik.
+//   - Diskon Barang (product_rp / product_pct) diterapkan per baris. Setiap baris
+//     hanya menerima satu Diskon Barang, yaitu yang prioritasnya paling tinggi.
+//   - Diskon Struk (receipt_rp / receipt_pct) dihitung dari subtotal setelah Diskon
+//     Barang. Hanya satu Diskon Struk yang diterapkan per transaksi.
+//   - MaxAmount membatasi total potongan sebuah diskon dalam satu transaksi,
+//     untuk semua jenis diskon.
+//   - MinPurchase berlaku untuk semua jenis diskon. Diskon Barang dibandingkan
+//     dengan subtotal keranjang, Diskon Struk dengan subtotal setelah Diskon Barang.
+//   - Outlet kosong berarti berlaku di semua outlet; order type hanya dicek jika
+//     ApplyToOrderTypes aktif.
+//   - discounts diharapkan sudah berisi diskon aktif saja (difilter repository);
+//     diskon harus dalam masa berlaku dan masuk jadwal mingguan pada waktu at.
+//     at harus sudah dalam zona waktu company karena hari & jam diambil dari at.
+func EvaluateDiscounts(discounts []models.Discount, cart models.DiscountCartInput, at time.Time) models.DiscountEvaluation {
+	sorted := make([]models.Discount, len(discounts))
+	copy(sorted, discounts)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Priority != sorted[j].Priority {
+			return sorted[i].Priority < sorted[j].Priority
+		}
+		if !sorted[i].CreatedAt.Equal(sorted[j].CreatedAt) {
+			return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	result := models.DiscountEvaluation{
+		Lines:            make([]models.DiscountLineResult, len(cart.Lines)),
+		ReceiptDiscounts: []models.AppliedDiscount{},
+		Rejected:         []models.RejectedDiscount{},
+	}
+	for i, line := range cart.Lines {
+		subtotal := roundCurrency(float64(line.Quantity) * line.Price)
+		result.Lines[i] = models.DiscountLineResult{
+			Index:     i,
+			ProductID: line.ProductID,
+			Subtotal:  subtotal,
+			Discounts: []models.AppliedDiscount{},
+			Total:     subtotal,
+		}
+		result.Subtotal += subtotal
+	}
+
+	reject := func(d models.Discount, reason string, message string) {
+		result.Rejected = append(result.Rejected, models.RejectedDiscount{
+			DiscountID: d.ID,
+			Name:       d.Name,
+			Type:       d.Type,
+			Reason:     reason,
+			Message:    message,
+		})
+	}
+
+	// lineWinner menyimpan nama diskon yang sudah mengisi sebuah baris
+	lineWinner := make([]string, len(cart.Lines))
+	receipts := []models.Discount{}
+
+	for _, d := range sorted {
+		if reason, message, ok := discountAvailableAt(d, at); !ok {
+			reject(d, reason, message)
+			continue
+		}
+		if !discountMatchesOutlet(d, cart.OutletID) {
+			reject(d, DiscountRejectOutlet, "discount is not available at this outlet")
+			continue
+		}
+		if !discountMatchesOrderType(d, cart.OrderTypeID) {
+			reject(d, DiscountRejectOrderType, "discount is not available for this order type")
+			continue
+		}
+
+		switch d.Type {
+		case models.DiscountTypeReceiptRp, models.DiscountTypeReceiptPct:
+			receipts = append(receipts, d)
+			continue
+		case models.DiscountTypeProductRp, models.DiscountTypeProductPct:
+		default:
+			reject(d, DiscountRejectInvalidType, fmt.Sprintf("unknown discount type %q", d.Type))
+			continue
+		}
+		if d.MinPurchase != nil && result.Subtotal < *d.MinPurchase {
+			reject(d, DiscountRejectMinPurchase, fmt.Sprintf("minimum purchase of %.2f not met", *d.MinPurchase))
+			continue
+		}
+
+		matched := false
+		blockedBy := ""
+		applied := false
+		remainingCap := math.Inf(1)
+		if d.MaxAmount != nil {
+			remainingCap = *d.MaxAmount
+		}
+
+		for i, line := range cart.Lines {
+			if !discountMatchesLine(d, line) {
+				continue
+			}
+			matched = true
+			if lineWinner[i] != "" {
+				if blockedBy == "" {
+					blockedBy = lineWinner[i]
+				}
+				continue
+			}
+
+			lineResult := &result.Lines[i]
+			var amount float64
+			if d.Type == models.DiscountTypeProductRp {
+				amount = d.DiscountValue * float64(line.Quantity)
+			} else {
+				amount = lineResult.Subtotal * d.DiscountValue / 100
+			}
+			capped := false
+			if amount > remainingCap {
+				amount = remainingCap
+				capped = true
+			}
+			if amount > lineResult.Subtotal {
+				amount = lineResult.Subtotal
+			}
+			amount = roundCurrency(amount)
+			if amount <= 0 {
+				continue
+			}
+			remainingCap -= amount
+
+			lineResult.Discounts = append(lineResult.Discounts, appliedDiscount(d, amount, capped))
+			lineResult.Discount = roundCurrency(lineResult.Discount + amount)
+			lineResult.Total = roundCurrency(lineResult.Subtotal - lineResult.Discount)
+			result.LineDiscountTotal += amount
+			lineWinner[i] = d.Name
+			applied = true
+		}
+
+		if applied {
+			continue
+		}
+		switch {
+		case !matched:
+			reject(d, DiscountRejectNoItems, "no cart item matches the discount target")
+		case blockedBy != "":
+			reject(d, DiscountRejectLowPriority, fmt.Sprintf("superseded by higher priority discount %q", blockedBy))
+		default:
+			reject(d, DiscountRejectZeroAmount, "discount amount is zero")
+		}
+	}
+
+	result.Subtotal = roundCurrency(result.Subtotal)
+	result.LineDiscountTotal = roundCurrency(result.LineDiscountTotal)
+	receiptBase := roundCurrency(result.Subtotal - result.LineDiscountTotal)
+
+	receiptWinner := ""
+	for _, d := range receipts {
+		if receiptWinner != "" {
+			reject(d, DiscountRejectLowPriority, fmt.Sprintf("superseded by higher priority discount %q", receiptWinner))
+			continue
+		}
+		if d.MinPurchase != nil && receiptBase < *d.MinPurchase {
+			reject(d, DiscountRejectMinPurchase, fmt.Sprintf("minimum purchase of %.2f not met", *d.MinPurchase))
+			continue
+		}
+
+		var amount float64
+		capped := false
+		if d.Type == models.DiscountTypeReceiptRp {
+			amount = d.DiscountValue
+		} else {
+			amount = receiptBase * d.DiscountValue / 100
+		}
+		if d.MaxAmount != nil && amount > *d.MaxAmount {
+			amount = *d.MaxAmount
+			capped = true
+		}
+		if amount > receiptBase {
+			amount = receiptBase
+		}
+		amount = roundCurrency(amount)
+		if amount <= 0 {
+			reject(d, DiscountRejectZeroAmount, "discount amount is zero")
+			continue
+		}
+
+		result.ReceiptDiscounts = append(result.ReceiptDiscounts, appliedDiscount(d, amount, capped))
+		result.ReceiptDiscountTotal = amount
+		receiptWinner = d.Name
+	}
+
+	result.DiscountTotal = roundCurrency(result.LineDiscountTotal + result.ReceiptDiscountTotal)
+	result.GrandTotal = roundCurrency(result.Subtotal - result.DiscountTotal)
+	return result
+}
+
+func appliedDiscount(d models.Discount, amount float64, capped bool) models.AppliedDiscount {
+	return models.AppliedDiscount{
+		DiscountID:    d.ID,
+		Name:          d.Name,
+		Type:          d.Type,
+		Priority:      d.Priority,
+		DiscountValue: d.DiscountValue,
+		Amount:        amount,
+		Capped:        capped,
+	}
+}
+
+// discountAvailableAt mengecek masa berlaku dan jadwal diskon pada waktu at.
+// Status aktif tidak dicek di sini; pemanggil yang memuat diskon tanpa filter is_active wajib mengeceknya sendiri.
+// Jika tidak tersedia, mengembalikan kode alasan dan pesan penjelasnya.
+func discountAvailableAt(d models.Discount, at time.Time) (string, string, bool) {
+	if (d.StartAt != nil && at.Before(*d.StartAt)) || (d.EndAt != nil && !at.Before(*d.EndAt)) {
+		return DiscountRejectOutOfPeriod, "discount is outside its validity period", false
+	}
+	if !discountMatchesSchedule(d, at) {
+		return DiscountRejectOutOfHours, "discount is not scheduled for this time", false
+	}
+	return "", "", true
+}
+
+// discountMatchesSchedule mengecek apakah waktu at masuk salah satu jadwal mingguan.
+// Jadwal yang melewati tengah malam (end < start) juga berlaku di awal hari berikutnya.
+func discountMatchesSchedule(d models.Discount, at time.Time) bool {
+	if len(d.Schedules) == 0 {
+		return true
+	}
+	day := int(at.Weekday())
+	prevDay := (day + 6) % 7
+	clock := at.Format("15:04")
+	for _, sc := range d.Schedules {
+		switch {
+		case sc.StartTime < sc.EndTime:
+			if sc.DayOfWeek == day && clock >= sc.StartTime && clock < sc.EndTime {
+				return true
+			}
+		case sc.StartTime > sc.EndTime:
+			if sc.DayOfWeek == day && clock >= sc.StartTime {
+				return true
+			}
+			if sc.DayOfWeek == prevDay && clock < sc.EndTime {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func discountMatchesOutlet(d models.Discount, outletID string) bool {
+	if len(d.OutletIDs) == 0 {
+		return true
+	}
+	for _, o := range d.OutletIDs {
+		if o.OutletID == outletID {
+			return true
+		}
+	}
+	return false
+}
+
+func discountMatchesOrderType(d models.Discount, orderTypeID string) bool {
+	if !d.ApplyToOrderTypes {
+		return true
+	}
+	for _, o := range d.OrderTypeIDs {
+		if o.OrderTypeID == orderTypeID {
+			return true
+		}
+	}
+	return false
+}
+
+func discountMatchesLine(d models.Discount, line models.DiscountCartLine) bool {
+	if d.TargetType == nil {
+		return true
+	}
+	switch *d.TargetType {
+	case models.DiscountTargetTypeProduct:
+		for _, p := range d.TargetProductIDs {
+			if p.ProductId == line.ProductID {
+				return true
+			}
+		}
+		return false
+	case models.DiscountTargetTypeCategory:
+		for _, c := range d.TargetCategoryIDs {
+			if c.CategoryId == line.CategoryID {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// roundCurrency membulatkan nominal ke 2 angka desimal.
+func roundCurrency(v float64) float64 {
+	return math.Round(v*100) / 100
+}
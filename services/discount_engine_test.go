@@ -0,0 +1,198 @@
+package services
+
+import (
+	"gowes/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func timePtr(v time.Time) *time.Time {
+	return &v
+}
+
+func targetPtr(v models.DiscountTarget) *models.DiscountTarget {
+	return &v
+}
+
+func TestEvaluateDiscounts(t *testing.T) {
+	at := time.Date(2026, 5, 20, 12, 0, 0, 0, time.UTC)
+	cart := models.DiscountCartInput{
+		OutletID: "outlet-1",
+		Lines: []models.DiscountCartLine{
+			{ProductID: "prod-1", CategoryID: "cat-1", Quantity: 2, Price: 10000},
+			{ProductID: "prod-2", CategoryID: "cat-2", Quantity: 1, Price: 5000},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		discounts    []models.Discount
+		wantLine     float64
+		wantReceipt  float64
+		wantGrand    float64
+		wantRejected []string
+	}{
+		{
+			name:      "no discounts",
+			wantGrand: 25000,
+		},
+		{
+			name: "product percentage on all items",
+			discounts: []models.Discount{
+				{ID: "d1", Name: "10%", Type: models.DiscountTypeProductPct, DiscountValue: 10, IsActive: true},
+			},
+			wantLine:  2500,
+			wantGrand: 22500,
+		},
+		{
+			name: "product rupiah capped by max amount",
+			discounts: []models.Discount{
+				{ID: "d1", Name: "Rp3000", Type: models.DiscountTypeProductRp, DiscountValue: 3000, MaxAmount: floatPtr(4000), IsActive: true},
+			},
+			wantLine:  4000,
+			wantGrand: 21000,
+		},
+		{
+			name: "higher priority product discount wins the line",
+			discounts: []models.Discount{
+				{ID: "d2", Name: "50%", Type: models.DiscountTypeProductPct, DiscountValue: 50, Priority: 2, IsActive: true},
+				{ID: "d1", Name: "5%", Type: models.DiscountTypeProductPct, DiscountValue: 5, Priority: 1, IsActive: true},
+			},
+			wantLine:     1250,
+			wantGrand:    23750,
+			wantRejected: []string{DiscountRejectLowPriority},
+		},
+		{
+			name: "product target without matching item",
+			discounts: []models.Discount{
+				{
+					ID: "d1", Name: "Produk", Type: models.DiscountTypeProductPct, DiscountValue: 10, IsActive: true,
+					TargetType:       targetPtr(models.DiscountTargetTypeProduct),
+					TargetProductIDs: []models.DiscountTargetProduct{{ProductId: "prod-9"}},
+				},
+			},
+			wantGrand:    25000,
+			wantRejected: []string{DiscountRejectNoItems},
+		},
+		{
+			name: "category target only discounts matching lines",
+			discounts: []models.Discount{
+				{
+					ID: "d1", Name: "Kategori", Type: models.DiscountTypeProductPct, DiscountValue: 20, IsActive: true,
+					TargetType:        targetPtr(models.DiscountTargetTypeCategory),
+					TargetCategoryIDs: []models.DiscountTargetCategory{{CategoryId: "cat-2"}},
+				},
+			},
+			wantLine:  1000,
+			wantGrand: 24000,
+		},
+		{
+			name: "receipt discount below min purchase",
+			discounts: []models.Discount{
+				{ID: "d1", Name: "Struk", Type: models.DiscountTypeReceiptRp, DiscountValue: 5000, MinPurchase: floatPtr(50000), IsActive: true},
+			},
+			wantGrand:    25000,
+			wantRejected: []string{DiscountRejectMinPurchase},
+		},
+		{
+			name: "receipt percentage after product discount is capped",
+			discounts: []models.Discount{
+				{ID: "d1", Name: "10%", Type: models.DiscountTypeProductPct, DiscountValue: 10, Priority: 1, IsActive: true},
+				{ID: "d2", Name: "Struk 10%", Type: models.DiscountTypeReceiptPct, DiscountValue: 10, MaxAmount: floatPtr(1000), Priority: 2, IsActive: true},
+			},
+			wantLine:    2500,
+			wantReceipt: 1000,
+			wantGrand:   21500,
+		},
+		{
+			name: "only one receipt discount applies",
+			discounts: []models.Discount{
+				{ID: "d1", Name: "Struk A", Type: models.DiscountTypeReceiptRp, DiscountValue: 2000, Priority: 1, IsActive: true},
+				{ID: "d2", Name: "Struk B", Type: models.DiscountTypeReceiptRp, DiscountValue: 3000, Priority: 2, IsActive: true},
+			},
+			wantReceipt:  2000,
+			wantGrand:    23000,
+			wantRejected: []string{DiscountRejectLowPriority},
+		},
+		{
+			name: "receipt min purchase uses total after product discount",
+			discounts: []models.Discount{
+				{ID: "d1", Name: "10%", Type: models.DiscountTypeProductPct, DiscountValue: 10, Priority: 1, IsActive: true},
+				{ID: "d2", Name: "Struk", Type: models.DiscountTypeReceiptRp, DiscountValue: 1000, MinPurchase: floatPtr(25000), Priority: 2, IsActive: true},
+			},
+			wantLine:     2500,
+			wantGrand:    22500,
+			wantRejected: []string{DiscountRejectMinPurchase},
+		},
+		{
+			name: "outlet not eligible",
+			discounts: []models.Discount{
+				{
+					ID: "d1", Name: "Outlet lain", Type: models.DiscountTypeProductPct, DiscountValue: 10, IsActive: true,
+					OutletIDs: []models.DiscountTargetOutlet{{OutletID: "outlet-2"}},
+				},
+			},
+			wantGrand:    25000,
+			wantRejected: []string{DiscountRejectOutlet},
+		},
+		{
+			name: "order type not eligible",
+			discounts: []models.Discount{
+				{
+					ID: "d1", Name: "Dine in", Type: models.DiscountTypeProductPct, DiscountValue: 10, IsActive: true,
+					ApplyToOrderTypes: true,
+					OrderTypeIDs:      []models.DiscountTargetOrderType{{OrderTypeID: "ot-1"}},
+				},
+			},
+			wantGrand:    25000,
+			wantRejected: []string{DiscountRejectOrderType},
+		},
+		{
+			name: "outside validity period",
+			discounts: []models.Discount{
+				{
+					ID: "d1", Name: "Kedaluwarsa", Type: models.DiscountTypeProductPct, DiscountValue: 10, IsActive: true,
+					EndAt: timePtr(at.Add(-time.Hour)),
+				},
+			},
+			wantGrand:    25000,
+			wantRejected: []string{DiscountRejectOutOfPeriod},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EvaluateDiscounts(tt.discounts, cart, at)
+
+			if got.Subtotal != 25000 {
+				t.Errorf("Subtotal = %v, want 25000", got.Subtotal)
+			}
+			if got.LineDiscountTotal != tt.wantLine {
+				t.Errorf("LineDiscountTotal = %v, want %v", got.LineDiscountTotal, tt.wantLine)
+			}
+			if got.ReceiptDiscountTotal != tt.wantReceipt {
+				t.Errorf("ReceiptDiscountTotal = %v, want %v", got.ReceiptDiscountTotal, tt.wantReceipt)
+			}
+			if got.GrandTotal != tt.wantGrand {
+				t.Errorf("GrandTotal = %v, want %v", got.GrandTotal, tt.wantGrand)
+			}
+
+			reasons := []string{}
+			for _, r := range got.Rejected {
+				reasons = append(reasons, r.Reason)
+			}
+			want := tt.wantRejected
+			if want == nil {
+				want = []string{}
+			}
+			if !reflect.DeepEqual(reasons, want) {
+				t.Errorf("rejected reasons = %v, want %v", reasons, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"gowes/models"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestDiscountMatchesSchedule(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+
+	// Happy hour Jumat 22:00 - Sabtu 02:00 dan jam kantor Senin 09:00 - 17:00
+	discount := models.Discount{
+		Schedules: []models.DiscountSchedule{
+			{DayOfWeek: int(time.Friday), StartTime: "22:00", EndTime: "02:00"},
+			{DayOfWeek: int(time.Monday), StartTime: "09:00", EndTime: "17:00"},
+		},
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want bool
+	}{
+		{"friday before start", time.Date(2026, 5, 22, 21, 59, 0, 0, jakarta), false},
+		{"friday at start", time.Date(2026, 5, 22, 22, 0, 0, 0, jakarta), true},
+		{"friday before midnight", time.Date(2026, 5, 22, 23, 59, 0, 0, jakarta), true},
+		{"saturday after midnight", time.Date(2026, 5, 23, 1, 30, 0, 0, jakarta), true},
+		{"saturday at end", time.Date(2026, 5, 23, 2, 0, 0, 0, jakarta), false},
+		{"saturday night is not friday", time.Date(2026, 5, 23, 23, 0, 0, 0, jakarta), false},
+		{"sunday after midnight", time.Date(2026, 5, 24, 1, 0, 0, 0, jakarta), false},
+		{"monday inside hours", time.Date(2026, 5, 18, 16, 59, 0, 0, jakarta), true},
+		{"monday at end", time.Date(2026, 5, 18, 17, 0, 0, 0, jakarta), false},
+		// 17:30 UTC hari Jumat = 00:30 WIB hari Sabtu
+		{"utc instant converted to company time", time.Date(2026, 5, 22, 17, 30, 0, 0, time.UTC).In(jakarta), true},
+		{"utc instant not converted", time.Date(2026, 5, 22, 17, 30, 0, 0, time.UTC), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := discountMatchesSchedule(discount, tt.at); got != tt.want {
+				t.Errorf("discountMatchesSchedule(%s) = %v, want %v", tt.at.Format(time.RFC3339), got, tt.want)
+			}
+		})
+	}
+
+	t.Run("no schedule is always available", func(t *testing.T) {
+		if !discountMatchesSchedule(models.Discount{}, time.Date(2026, 5, 24, 3, 0, 0, 0, jakarta)) {
+			t.Error("discount without schedules should match any time")
+		}
+	})
+}
+
+func TestDiscountAvailableAt(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+
+	startAt := time.Date(2026, 5, 22, 0, 0, 0, 0, jakarta)
+	endAt := time.Date(2026, 5, 25, 0, 0, 0, 0, jakarta)
+	discount := models.Discount{
+		StartAt: &startAt,
+		EndAt:   &endAt,
+		Schedules: []models.DiscountSchedule{
+			{DayOfWeek: int(time.Sunday), StartTime: "22:00", EndTime: "01:00"},
+			{DayOfWeek: int(time.Friday), StartTime: "00:00", EndTime: "23:59"},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		at         time.Time
+		wantReason string
+	}{
+		{"before period", time.Date(2026, 5, 21, 23, 0, 0, 0, jakarta), DiscountRejectOutOfPeriod},
+		{"at period start", startAt, ""},
+		{"outside schedule", time.Date(2026, 5, 23, 12, 0, 0, 0, jakarta), DiscountRejectOutOfHours},
+		{"sunday night schedule", time.Date(2026, 5, 24, 23, 30, 0, 0, jakarta), ""},
+		// Jadwal Minggu masih berlaku sampai 01:00, tetapi masa berlaku sudah berakhir
+		{"at period end", endAt, DiscountRejectOutOfPeriod},
+		{"period end in utc", endAt.UTC().Add(-time.Minute).In(jakarta), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, _, ok := discountAvailableAt(discount, tt.at)
+			if ok != (tt.wantReason == "") || reason != tt.wantReason {
+				t.Errorf("discountAvailableAt(%s) = (%q, %v), want %q", tt.at.Format(time.RFC3339), reason, ok, tt.wantReason)
+			}
+		})
+	}
+}
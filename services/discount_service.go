@@ -17,30 +17,51 @@ var (
 	ErrDiscountTypeInvalid         = errors.New("invalid discount type, must be one of: product_rp, product_pct, receipt_rp, receipt_pct")
 	ErrDiscountPctExceeded         = errors.New("discount_value cannot exceed 100 for percentage type")
 	ErrDiscountTargetNotApplicable = errors.New("target_type is only applicable for product discount types")
+	ErrDiscountPeriodInvalid       = errors.New("end_at must be after start_at")
+	ErrDiscountScheduleDayInvalid  = errors.New("schedule day_of_week must be between 0 (Sunday) and 6 (Saturday)")
+	ErrDiscountScheduleTimeInvalid = errors.New("schedule start_time and end_time must use HH:MM format and cannot be equal")
+	ErrDiscountCartOutletRequired  = errors.New("outlet_id is required")
+	ErrDiscountCartLinesRequired   = errors.New("cart lines are required")
+	ErrDiscountCartProductRequired = errors.New("product_id is required for every cart line")
+	ErrDiscountCartQtyInvalid      = errors.New("quantity must be greater than zero")
+	ErrDiscountCartPriceInvalid    = errors.New("price cannot be negative")
+	ErrDiscountCartPromoInvalid    = errors.New("promo code cannot be used")
 )
 
 // ─── Interface ────────────────────────────────────────────────────────────────
 
 type DiscountService interface {
-	ListDiscounts(companyID string, params models.PaginationParams) ([]models.Discount, int, error)
+	ListDiscounts(companyID string, params models.PaginationParams, filter models.DiscountFilter) ([]models.Discount, int, error)
 	GetDiscount(id string) (models.Discount, error)
 	CreateDiscount(companyID string, in models.DiscountInput) (models.Discount, error)
 	UpdateDiscount(id string, in models.DiscountInput) (models.Discount, error)
 	DeleteDiscount(id string) error
+	EvaluateCart(companyID string, cart models.DiscountCartInput) (models.DiscountEvaluation, error)
 }
 
 // ─── Implementation ───────────────────────────────────────────────────────────
 
 type discountService struct {
-	repo repositories.DiscountRepository
+	repo        repositories.DiscountRepository
+	companyRepo repositories.CompanyRepository
+	promoCodes  PromoCodeService
 }
 
-func NewDiscountService(repo repositories.DiscountRepository) DiscountService {
-	return &discountService{repo: repo}
+func NewDiscountService(repo repositories.DiscountRepository, companyRepo repositories.CompanyRepository, promoCodes PromoCodeService) DiscountService {
+	return &discountService{repo: repo, companyRepo: companyRepo, promoCodes: promoCodes}
 }
 
-func (s *discountService) ListDiscounts(companyID string, params models.PaginationParams) ([]models.Discount, int, error) {
-	return s.repo.FindAll(companyID, params)
+func (s *discountService) ListDiscounts(companyID string, params models.PaginationParams, filter models.DiscountFilter) ([]models.Discount, int, error) {
+	// Jadwal mingguan dicocokkan dengan hari & jam di zona waktu company
+	if filter.ActiveAt != nil {
+		loc, err := companyLocation(s.companyRepo, companyID)
+		if err != nil {
+			return nil, 0, err
+		}
+		activeAt := filter.ActiveAt.In(loc)
+		filter.ActiveAt = &activeAt
+	}
+	return s.repo.FindAll(companyID, params, filter)
 }
 
 func (s *discountService) GetDiscount(id string) (models.Discount, error) {
@@ -64,9 +85,16 @@ func (s *discountService) CreateDiscount(companyID string, in models.DiscountInp
 		TargetType:        in.TargetType,
 		Priority:          in.Priority,
 		ApplyToOrderTypes: in.ApplyToOrderTypes,
+		StartAt:           in.StartAt,
+		EndAt:             in.EndAt,
+		Schedules:         buildDiscountSchedules(in.Schedules),
+		IsActive:          true,
 		CreatedAt:         now,
 		UpdatedAt:         now,
 	}
+	if in.IsActive != nil {
+		d.IsActive = *in.IsActive
+	}
 
 	outletIDs, categoryIDs, productIDs, orderTypeIDs := resolveRelationIDs(in)
 
@@ -91,6 +119,12 @@ func (s *discountService) UpdateDiscount(id string, in models.DiscountInput) (mo
 	existing.TargetType = in.TargetType
 	existing.Priority = in.Priority
 	existing.ApplyToOrderTypes = in.ApplyToOrderTypes
+	existing.StartAt = in.StartAt
+	existing.EndAt = in.EndAt
+	existing.Schedules = buildDiscountSchedules(in.Schedules)
+	if in.IsActive != nil {
+		existing.IsActive = *in.IsActive
+	}
 	existing.UpdatedAt = time.Now().UTC()
 
 	outletIDs, categoryIDs, productIDs, orderTypeIDs := resolveRelationIDs(in)
@@ -102,6 +136,60 @@ func (s *discountService) DeleteDiscount(id string) error {
 	return s.repo.Delete(id)
 }
 
+// EvaluateCart memvalidasi keranjang lalu menghitung diskon yang berlaku dengan EvaluateDiscounts.
+func (s *discountService) EvaluateCart(companyID string, cart models.DiscountCartInput) (models.DiscountEvaluation, error) {
+	cart.OutletID = strings.TrimSpace(cart.OutletID)
+	cart.OrderTypeID = strings.TrimSpace(cart.OrderTypeID)
+	if cart.OutletID == "" {
+		return models.DiscountEvaluation{}, ErrDiscountCartOutletRequired
+	}
+	if len(cart.Lines) == 0 {
+		return models.DiscountEvaluation{}, ErrDiscountCartLinesRequired
+	}
+	for i, line := range cart.Lines {
+		if strings.TrimSpace(line.ProductID) == "" {
+			return models.DiscountEvaluation{}, ErrDiscountCartProductRequired
+		}
+		if line.Quantity <= 0 {
+			return models.DiscountEvaluation{}, ErrDiscountCartQtyInvalid
+		}
+		if line.Price < 0 {
+			return models.DiscountEvaluation{}, ErrDiscountCartPriceInvalid
+		}
+		cart.Lines[i].ProductID = strings.TrimSpace(line.ProductID)
+		cart.Lines[i].CategoryID = strings.TrimSpace(line.CategoryID)
+	}
+
+	// Diskon milik kode promo hanya dievaluasi jika kodenya dikirim dan valid
+	promoDiscountID := ""
+	if strings.TrimSpace(cart.PromoCode) != "" {
+		validation, err := s.promoCodes.ValidatePromoCode(companyID, models.PromoCodeRedeemInput{
+			Code:       cart.PromoCode,
+			CustomerID: cart.CustomerID,
+			OutletID:   cart.OutletID,
+		})
+		if err != nil {
+			return models.DiscountEvaluation{}, err
+		}
+		if !validation.Valid {
+			return models.DiscountEvaluation{}, fmt.Errorf("%w: %s", ErrDiscountCartPromoInvalid, validation.Message)
+		}
+		promoDiscountID = validation.PromoCode.DiscountID
+	}
+
+	discounts, err := s.repo.FindAllByCompany(companyID, promoDiscountID)
+	if err != nil {
+		return models.DiscountEvaluation{}, err
+	}
+
+	loc, err := companyLocation(s.companyRepo, companyID)
+	if err != nil {
+		return models.DiscountEvaluation{}, err
+	}
+
+	return EvaluateDiscounts(discounts, cart, time.Now().In(loc)), nil
+}
+
 // ─── helpers ──────────────────────────────────────────────────────────────────
 
 // validateDiscountInput memvalidasi field wajib dan konsistensi antar field.
@@ -127,6 +215,22 @@ func validateDiscountInput(in models.DiscountInput) error {
 		return ErrDiscountPctExceeded
 	}
 
+	// Masa berlaku: end_at harus setelah start_at
+	if in.StartAt != nil && in.EndAt != nil && !in.EndAt.After(*in.StartAt) {
+		return ErrDiscountPeriodInvalid
+	}
+
+	for _, sc := range in.Schedules {
+		if sc.DayOfWeek < 0 || sc.DayOfWeek > 6 {
+			return ErrDiscountScheduleDayInvalid
+		}
+		start, errStart := time.Parse("15:04", strings.TrimSpace(sc.StartTime))
+		end, errEnd := time.Parse("15:04", strings.TrimSpace(sc.EndTime))
+		if errStart != nil || errEnd != nil || start.Equal(end) {
+			return ErrDiscountScheduleTimeInvalid
+		}
+	}
+
 	// target_type hanya relevan untuk Diskon Barang
 	// if in.TargetType != nil {
 	// 	fmt.Println(in.Type != models.DiscountTypeProductRp && in.Type != models.DiscountTypeProductPct, "target type")
@@ -159,6 +263,22 @@ func resolveRelationIDs(in models.DiscountInput) (outletIDs, categoryIDs, produc
 	return
 }
 
+// buildDiscountSchedules mengubah input jadwal (sudah divalidasi) menjadi model DiscountSchedule.
+func buildDiscountSchedules(in []models.DiscountScheduleInput) []models.DiscountSchedule {
+	schedules := make([]models.DiscountSchedule, 0, len(in))
+	for _, sc := range in {
+		// Jam dinormalisasi ke "HH:MM" agar bisa dibandingkan sebagai string
+		start, _ := time.Parse("15:04", strings.TrimSpace(sc.StartTime))
+		end, _ := time.Parse("15:04", strings.TrimSpace(sc.EndTime))
+		schedules = append(schedules, models.DiscountSchedule{
+			DayOfWeek: sc.DayOfWeek,
+			StartTime: start.Format("15:04"),
+			EndTime:   end.Format("15:04"),
+		})
+	}
+	return schedules
+}
+
 // deduplicateIDs menghapus duplikat dan string kosong dari slice ID.
 func deduplicateIDs(ids []string) []string {
 	seen := make(map[string]bool)
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"gowes/models"
+	"strings"
+)
+
+// Jenis penyesuaian harga pada tipe order
+const (
+	OrderTypeAdjustmentPercentage = "percentage"
+	OrderTypeAdjustmentFixed      = "fixed"
+)
+
+// ResolveOrderTypePrice menghitung harga efektif produk untuk sebuah tipe order.
+//
+// Kenaikan dan penurunan dihitung dari harga dasar, lalu digabung:
+//   - "percentage": harga dasar * value / 100 (mis. +10% untuk GoFood)
+//   - "fixed": nominal value ditambahkan / dikurangkan
+//
+// Jika penyesuaian harga tidak aktif, harga dasar dikembalikan apa adanya.
+// Hasil tidak pernah negatif.
+func ResolveOrderTypePrice(orderType models.OrderType, basePrice float64) float64 {
+	if !orderType.IsActivePriceAdjustment {
+		return basePrice
+	}
+
+	price := basePrice +
+		orderTypeAdjustment(orderType.IncreaseType, orderType.IncreaseValue, basePrice) -
+		orderTypeAdjustment(orderType.DecreaseType, orderType.DecreaseValue, basePrice)
+	if price < 0 {
+		price = 0
+	}
+	return roundCurrency(price)
+}
+
+func orderTypeAdjustment(adjustmentType string, value float64, basePrice float64) float64 {
+	if value <= 0 {
+		return 0
+	}
+	switch strings.ToLower(strings.TrimSpace(adjustmentType)) {
+	case OrderTypeAdjustmentPercentage:
+		return basePrice * value / 100
+	case OrderTypeAdjustmentFixed:
+		return value
+	default:
+		return 0
+	}
+}
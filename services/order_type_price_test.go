@@ -0,0 +1,78 @@
+package services
+
+import (
+	"gowes/models"
+	"testing"
+)
+
+func TestResolveOrderTypePrice(t *testing.T) {
+	tests := []struct {
+		name      string
+		orderType models.OrderType
+		basePrice float64
+		want      float64
+	}{
+		{
+			name:      "adjustment inactive",
+			orderType: models.OrderType{IncreaseType: OrderTypeAdjustmentPercentage, IncreaseValue: 10},
+			basePrice: 20000,
+			want:      20000,
+		},
+		{
+			name:      "percentage increase",
+			orderType: models.OrderType{IsActivePriceAdjustment: true, IncreaseType: OrderTypeAdjustmentPercentage, IncreaseValue: 10},
+			basePrice: 20000,
+			want:      22000,
+		},
+		{
+			name:      "fixed decrease",
+			orderType: models.OrderType{IsActivePriceAdjustment: true, DecreaseType: OrderTypeAdjustmentFixed, DecreaseValue: 2500},
+			basePrice: 20000,
+			want:      17500,
+		},
+		{
+			name: "increase and decrease from base price",
+			orderType: models.OrderType{
+				IsActivePriceAdjustment: true,
+				IncreaseType:            OrderTypeAdjustmentPercentage,
+				IncreaseValue:           20,
+				DecreaseType:            OrderTypeAdjustmentFixed,
+				DecreaseValue:           1000,
+			},
+			basePrice: 15000,
+			want:      17000,
+		},
+		{
+			name:      "type is case insensitive",
+			orderType: models.OrderType{IsActivePriceAdjustment: true, IncreaseType: " Fixed ", IncreaseValue: 500},
+			basePrice: 10000,
+			want:      10500,
+		},
+		{
+			name:      "unknown type is ignored",
+			orderType: models.OrderType{IsActivePriceAdjustment: true, IncreaseType: "markup", IncreaseValue: 500},
+			basePrice: 10000,
+			want:      10000,
+		},
+		{
+			name:      "result is rounded to cents",
+			orderType: models.OrderType{IsActivePriceAdjustment: true, IncreaseType: OrderTypeAdjustmentPercentage, IncreaseValue: 12.5},
+			basePrice: 999.99,
+			want:      1124.99,
+		},
+		{
+			name:      "never negative",
+			orderType: models.OrderType{IsActivePriceAdjustment: true, DecreaseType: OrderTypeAdjustmentFixed, DecreaseValue: 50000},
+			basePrice: 20000,
+			want:      0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResolveOrderTypePrice(tt.orderType, tt.basePrice); got != tt.want {
+				t.Errorf("ResolveOrderTypePrice() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
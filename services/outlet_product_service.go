@@ -0,0 +1,200 @@
+package services
+
+import (
+	"errors"
+	"gowes/models"
+	"gowes/repositories"
+	"strings"
+	"time"
+)
+
+var (
+	ErrOutletProductOutletRequired  = errors.New("outlet_id is required")
+	ErrOutletProductProductRequired = errors.New("product_id is required")
+	ErrOutletProductOutletNotFound  = errors.New("outlet not found")
+	ErrOutletProductProductNotFound = errors.New("product not found")
+	ErrOutletProductPriceInvalid    = errors.New("price cannot be negative")
+	ErrOutletProductCostInvalid     = errors.New("cost cannot be negative")
+	ErrOutletProductDuplicate       = errors.New("product is already assigned to this outlet")
+)
+
+type OutletProductService interface {
+	ListOutletProducts(companyID string, params models.PaginationParams, filter models.OutletProductFilter) ([]models.OutletProduct, int, error)
+	GetOutletProduct(id string, companyID string) (models.OutletProduct, error)
+	CreateOutletProduct(companyID string, input models.OutletProductInput) (models.OutletProduct, error)
+	UpdateOutletProduct(id string, companyID string, input models.OutletProductInput) (models.OutletProduct, error)
+	DeleteOutletProduct(id string, companyID string) error
+	BulkAssign(companyID string, input models.OutletProductBulkInput) (int, error)
+}
+
+type outletProductService struct {
+	repo        repositories.OutletProductRepository
+	outletRepo  repositories.OutletRepository
+	productRepo repositories.ProductRepository
+}
+
+func NewOutletProductService(repo repositories.OutletProductRepository, outletRepo repositories.OutletRepository, productRepo repositories.ProductRepository) OutletProductService {
+	return &outletProductService{repo: repo, outletRepo: outletRepo, productRepo: productRepo}
+}
+
+func (s *outletProductService) ListOutletProducts(companyID string, params models.PaginationParams, filter models.OutletProductFilter) ([]models.OutletProduct, int, error) {
+	return s.repo.FindAll(companyID, params, filter)
+}
+
+func (s *outletProductService) GetOutletProduct(id string, companyID string) (models.OutletProduct, error) {
+	return s.repo.FindByID(id, companyID)
+}
+
+func (s *outletProductService) CreateOutletProduct(companyID string, input models.OutletProductInput) (models.OutletProduct, error) {
+	outletID := strings.TrimSpace(input.OutletID)
+	productID := strings.TrimSpace(input.ProductID)
+	if outletID == "" {
+		return models.OutletProduct{}, ErrOutletProductOutletRequired
+	}
+	if productID == "" {
+		return models.OutletProduct{}, ErrOutletProductProductRequired
+	}
+	if err := validateOutletProductAmounts(input.Price, input.Cost); err != nil {
+		return models.OutletProduct{}, err
+	}
+	if err := s.ensureOwnership(companyID, []string{outletID}, []string{productID}); err != nil {
+		return models.OutletProduct{}, err
+	}
+
+	existing, err := s.repo.FindByOutletAndProducts(companyID, outletID, []string{productID})
+	if err != nil {
+		return models.OutletProduct{}, err
+	}
+	if len(existing) > 0 {
+		return models.OutletProduct{}, ErrOutletProductDuplicate
+	}
+
+	now := time.Now().UTC()
+	outletProduct := models.OutletProduct{
+		CompanyID: companyID,
+		OutletID:  outletID,
+		ProductID: productID,
+		Price:     input.Price,
+		Cost:      input.Cost,
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if input.IsActive != nil {
+		outletProduct.IsActive = *input.IsActive
+	}
+
+	return s.repo.Create(outletProduct)
+}
+
+// UpdateOutletProduct hanya mengubah harga, HPP dan status; outlet dan produk tidak bisa dipindah.
+func (s *outletProductService) UpdateOutletProduct(id string, companyID string, input models.OutletProductInput) (models.OutletProduct, error) {
+	existing, err := s.repo.FindByID(id, companyID)
+	if err != nil {
+		return models.OutletProduct{}, err
+	}
+	if err := validateOutletProductAmounts(input.Price, input.Cost); err != nil {
+		return models.OutletProduct{}, err
+	}
+
+	existing.Price = input.Price
+	existing.Cost = input.Cost
+	if input.IsActive != nil {
+		existing.IsActive = *input.IsActive
+	}
+	existing.UpdatedAt = time.Now().UTC()
+
+	return s.repo.Update(existing)
+}
+
+func (s *outletProductService) DeleteOutletProduct(id string, companyID string) error {
+	return s.repo.Delete(id, companyID)
+}
+
+// BulkAssign memasang pengaturan yang sama ke setiap kombinasi outlet dan produk,
+// misalnya untuk menonaktifkan beberapa produk di semua outlet sekaligus.
+func (s *outletProductService) BulkAssign(companyID string, input models.OutletProductBulkInput) (int, error) {
+	outletIDs := uniqueTrimmedIDs(input.OutletIDs)
+	productIDs := uniqueTrimmedIDs(input.ProductIDs)
+	if len(outletIDs) == 0 {
+		return 0, ErrOutletProductOutletRequired
+	}
+	if len(productIDs) == 0 {
+		return 0, ErrOutletProductProductRequired
+	}
+	if err := validateOutletProductAmounts(input.Price, input.Cost); err != nil {
+		return 0, err
+	}
+	if err := s.ensureOwnership(companyID, outletIDs, productIDs); err != nil {
+		return 0, err
+	}
+
+	isActive := true
+	if input.IsActive != nil {
+		isActive = *input.IsActive
+	}
+
+	now := time.Now().UTC()
+	outletProducts := make([]models.OutletProduct, 0, len(outletIDs)*len(productIDs))
+	for _, outletID := range outletIDs {
+		for _, productID := range productIDs {
+			outletProducts = append(outletProducts, models.OutletProduct{
+				CompanyID: companyID,
+				OutletID:  outletID,
+				ProductID: productID,
+				Price:     input.Price,
+				Cost:      input.Cost,
+				IsActive:  isActive,
+				CreatedAt: now,
+				UpdatedAt: now,
+			})
+		}
+	}
+
+	return s.repo.BulkUpsert(outletProducts)
+}
+
+// ensureOwnership memastikan semua outlet dan produk milik company yang sama.
+func (s *outletProductService) ensureOwnership(companyID string, outletIDs []string, productIDs []string) error {
+	outlets, err := s.outletRepo.FindByIDs(companyID, outletIDs)
+	if err != nil {
+		return err
+	}
+	if len(outlets) != len(outletIDs) {
+		return ErrOutletProductOutletNotFound
+	}
+
+	products, err := s.productRepo.FindByIDs(companyID, productIDs)
+	if err != nil {
+		return err
+	}
+	if len(products) != len(productIDs) {
+		return ErrOutletProductProductNotFound
+	}
+	return nil
+}
+
+func validateOutletProductAmounts(price *float64, cost *float64) error {
+	if price != nil && *price < 0 {
+		return ErrOutletProductPriceInvalid
+	}
+	if cost != nil && *cost < 0 {
+		return ErrOutletProductCostInvalid
+	}
+	return nil
+}
+
+// uniqueTrimmedIDs membuang ID kosong dan duplikat dengan tetap menjaga urutan.
+func uniqueTrimmedIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+	return result
+}
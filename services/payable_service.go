@@ -0,0 +1,168 @@
+package services
+
+import (
+	"errors"
+	"gowes/models"
+	"gowes/repositories"
+	"strings"
+	"time"
+)
+
+var (
+	ErrPayableInvoiceNumberRequired = errors.New("invoice_number is required")
+	ErrPayableDateInvalid           = errors.New("dates must use the YYYY-MM-DD format")
+	ErrPayableTermInvalid           = errors.New("payment_term_days cannot be negative")
+	ErrPayableDueBeforeInvoice      = errors.New("due_date cannot be before invoice_date")
+	ErrPayableAmountInvalid         = errors.New("amount must be greater than zero")
+	ErrPayablePaidAtInvalid         = errors.New("paid_at must use the YYYY-MM-DD or RFC3339 format")
+	ErrPurchaseNotPayable           = repositories.ErrPurchaseNotPayable
+	ErrPurchasePaymentExceeds       = repositories.ErrPurchasePaymentExceeds
+)
+
+type PayableService interface {
+	RecordInvoice(purchaseID string, companyID string, input models.PurchaseInvoiceInput) (models.Purchase, error)
+	ListPayments(purchaseID string, companyID string) ([]models.PurchasePayment, error)
+	AddPayment(purchaseID string, companyID string, userID string, input models.PurchasePaymentInput) (models.Purchase, error)
+	GetAging(companyID string, filter models.PayableAgingFilter) (models.PayableAgingReport, error)
+}
+
+type payableService struct {
+	repo         repositories.PayableRepository
+	purchaseRepo repositories.PurchaseRepository
+}
+
+func NewPayableService(repo repositories.PayableRepository, purchaseRepo repositories.PurchaseRepository) PayableService {
+	return &payableService{repo: repo, purchaseRepo: purchaseRepo}
+}
+
+// RecordInvoice mencatat invoice supplier; jatuh tempo dihitung dari tanggal invoice + termin jika tidak diisi.
+func (s *payableService) RecordInvoice(purchaseID string, companyID string, input models.PurchaseInvoiceInput) (models.Purchase, error) {
+	invoiceNumber := strings.TrimSpace(input.InvoiceNumber)
+	if invoiceNumber == "" {
+		return models.Purchase{}, ErrPayableInvoiceNumberRequired
+	}
+
+	purchase, err := s.purchaseRepo.FindByID(purchaseID, companyID)
+	if err != nil {
+		return models.Purchase{}, err
+	}
+
+	now := time.Now().UTC()
+	invoiceDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if raw := strings.TrimSpace(input.InvoiceDate); raw != "" {
+		invoiceDate, err = time.Parse("2006-01-02", raw)
+		if err != nil {
+			return models.Purchase{}, ErrPayableDateInvalid
+		}
+	}
+
+	termDays := purchase.PaymentTermDays
+	if input.PaymentTermDays != nil {
+		termDays = *input.PaymentTermDays
+	}
+	if termDays < 0 {
+		return models.Purchase{}, ErrPayableTermInvalid
+	}
+
+	dueDate := invoiceDate.AddDate(0, 0, termDays)
+	if raw := strings.TrimSpace(input.DueDate); raw != "" {
+		dueDate, err = time.Parse("2006-01-02", raw)
+		if err != nil {
+			return models.Purchase{}, ErrPayableDateInvalid
+		}
+		if dueDate.Before(invoiceDate) {
+			return models.Purchase{}, ErrPayableDueBeforeInvoice
+		}
+	}
+
+	purchase.InvoiceNumber = invoiceNumber
+	purchase.InvoiceDate = &invoiceDate
+	purchase.PaymentTermDays = termDays
+	purchase.DueDate = &dueDate
+	purchase.UpdatedAt = now
+	if err := s.repo.SetInvoice(purchase); err != nil {
+		return models.Purchase{}, err
+	}
+	return s.purchaseRepo.FindByID(purchaseID, companyID)
+}
+
+func (s *payableService) ListPayments(purchaseID string, companyID string) ([]models.PurchasePayment, error) {
+	return s.repo.FindPayments(purchaseID, companyID)
+}
+
+// AddPayment mencatat pembayaran ke supplier; payment_status purchase ikut diperbarui.
+func (s *payableService) AddPayment(purchaseID string, companyID string, userID string, input models.PurchasePaymentInput) (models.Purchase, error) {
+	amount := roundCurrency(input.Amount)
+	if amount <= 0 {
+		return models.Purchase{}, ErrPayableAmountInvalid
+	}
+
+	now := time.Now().UTC()
+	paidAt := now
+	if raw := strings.TrimSpace(input.PaidAt); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			parsed, err = time.Parse("2006-01-02", raw)
+			if err != nil {
+				return models.Purchase{}, ErrPayablePaidAtInvalid
+			}
+		}
+		paidAt = parsed.UTC()
+	}
+
+	paymentMethod := strings.TrimSpace(input.PaymentMethod)
+	if paymentMethod == "" {
+		paymentMethod = "cash"
+	}
+
+	payment := models.PurchasePayment{
+		CompanyID:     companyID,
+		PurchaseID:    purchaseID,
+		UserID:        userID,
+		Amount:        amount,
+		PaymentMethod: paymentMethod,
+		Reference:     strings.TrimSpace(input.Reference),
+		Note:          strings.TrimSpace(input.Note),
+		PaidAt:        paidAt,
+		CreatedAt:     now,
+	}
+	if _, err := s.repo.CreatePayment(payment); err != nil {
+		return models.Purchase{}, err
+	}
+	return s.purchaseRepo.FindByID(purchaseID, companyID)
+}
+
+// GetAging menyusun laporan umur hutang per supplier (current / 1-30 / 31-60 / 61-90 / 90+ hari).
+func (s *payableService) GetAging(companyID string, filter models.PayableAgingFilter) (models.PayableAgingReport, error) {
+	if filter.AsOf.IsZero() {
+		now := time.Now().UTC()
+		filter.AsOf = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	}
+
+	suppliers, err := s.repo.Aging(companyID, filter)
+	if err != nil {
+		return models.PayableAgingReport{}, err
+	}
+
+	var totals models.PayableAging
+	for _, a := range suppliers {
+		totals.Current += a.Current
+		totals.Days1To30 += a.Days1To30
+		totals.Days31To60 += a.Days31To60
+		totals.Days61To90 += a.Days61To90
+		totals.Over90 += a.Over90
+		totals.Total += a.Total
+	}
+	totals.Current = roundCurrency(totals.Current)
+	totals.Days1To30 = roundCurrency(totals.Days1To30)
+	totals.Days31To60 = roundCurrency(totals.Days31To60)
+	totals.Days61To90 = roundCurrency(totals.Days61To90)
+	totals.Over90 = roundCurrency(totals.Over90)
+	totals.Total = roundCurrency(totals.Total)
+
+	return models.PayableAgingReport{
+		AsOf:      filter.AsOf,
+		Suppliers: suppliers,
+		Totals:    totals,
+	}, nil
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"errors"
+	"gowes/models"
+	"gowes/repositories"
+	"strings"
+	"time"
+)
+
+var (
+	ErrPosDeviceNameRequired   = errors.New("name cannot be empty")
+	ErrPosDeviceOutletRequired = errors.New("outlet_id is required")
+	ErrPosDeviceOutletNotFound = errors.New("outlet not found")
+)
+
+type PosDeviceService interface {
+	ListDevices(companyID string, params models.PaginationParams) ([]models.PosDevice, int, error)
+	GetDevice(id string, companyID string) (models.PosDevice, error)
+	RegisterDevice(companyID string, userID string, input models.PosDeviceInput) (models.PosDevice, error)
+	DeactivateDevice(id string, companyID string) error
+}
+
+type posDeviceService struct {
+	repo        repositories.PosDeviceRepository
+	outletRepo  repositories.OutletRepository
+	sessionRepo repositories.SessionRepository
+}
+
+func NewPosDeviceService(repo repositories.PosDeviceRepository, outletRepo repositories.OutletRepository, sessionRepo repositories.SessionRepository) PosDeviceService {
+	return &posDeviceService{repo: repo, outletRepo: outletRepo, sessionRepo: sessionRepo}
+}
+
+func (s *posDeviceService) ListDevices(companyID string, params models.PaginationParams) ([]models.PosDevice, int, error) {
+	return s.repo.FindAll(companyID, params)
+}
+
+func (s *posDeviceService) GetDevice(id string, companyID string) (models.PosDevice, error) {
+	return s.repo.FindByID(id, companyID)
+}
+
+// RegisterDevice mendaftarkan terminal POS di outlet. Device key di respons hanya muncul sekali
+// dan dipakai terminal untuk login PIN.
+func (s *posDeviceService) RegisterDevice(companyID string, userID string, input models.PosDeviceInput) (models.PosDevice, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return models.PosDevice{}, ErrPosDeviceNameRequired
+	}
+	outletID := strings.TrimSpace(input.OutletID)
+	if outletID == "" {
+		return models.PosDevice{}, ErrPosDeviceOutletRequired
+	}
+	outlets, err := s.outletRepo.FindByIDs(companyID, []string{outletID})
+	if err != nil {
+		return models.PosDevice{}, err
+	}
+	if len(outlets) == 0 {
+		return models.PosDevice{}, ErrPosDeviceOutletNotFound
+	}
+
+	deviceKey, err := generateOpaqueToken()
+	if err != nil {
+		return models.PosDevice{}, err
+	}
+
+	now := time.Now().UTC()
+	device, err := s.repo.Create(models.PosDevice{
+		CompanyID: companyID,
+		OutletID:  outletID,
+		Name:      name,
+		IsActive:  true,
+		CreatedBy: optionalID(userID),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, hashToken(deviceKey))
+	if err != nil {
+		return models.PosDevice{}, err
+	}
+	device.DeviceKey = deviceKey
+	return device, nil
+}
+
+// DeactivateDevice menonaktifkan terminal; semua sesi kasir di terminal itu ikut dicabut.
+func (s *posDeviceService) DeactivateDevice(id string, companyID string) error {
+	if err := s.repo.Deactivate(id, companyID); err != nil {
+		return err
+	}
+	return s.sessionRepo.RevokeAllByDevice(id)
+}
@@ -2,32 +2,47 @@ package services
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"gowes/models"
 	"gowes/repositories"
 	"log"
 	"mime/multipart"
 )
 
+var ErrProductOrderTypeNotFound = errors.New("order type not found")
+var ErrProductOrderTypeInactive = errors.New("order type is not active")
+var ErrProductOutletNotFound = errors.New("outlet not found")
+var ErrProductTypeInvalid = errors.New("type must be raw_material or finished_goods")
+var ErrProductCostingMethodInvalid = errors.New("costing_method must be average or fifo")
+
 type ProductService interface {
-	FindAll(companyID string, params models.PaginationParams) ([]models.ProductList, int, error)
+	FindAll(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.ProductList, int, error)
 	Create(companyID string, payload models.ProductInput, imageFile multipart.File, imageHeader *multipart.FileHeader, addOnIDList []string) (models.Product, error)
 	FindByID(productID string) (models.Product, error)
 	DeleteById(productID string) error
 	Update(productID string, payload models.ProductInput, imageFile multipart.File, imageHeader *multipart.FileHeader) (models.Product, error)
-	FindAllMobile(companyID string) ([]models.ProductList, error)
+	FindAllMobile(companyID string, outletID string, orderTypeID string) ([]models.ProductList, error)
 }
 
 type productService struct {
-	productRepository repositories.ProductRepository
-	storageRepository repositories.StorageRepository
+	productRepository   repositories.ProductRepository
+	storageRepository   repositories.StorageRepository
+	orderTypeRepository repositories.OrderTypeRepository
+	outletRepository    repositories.OutletRepository
+	companyRepository   repositories.CompanyRepository
 }
 
-func NewProductService(productRepository repositories.ProductRepository, storageRepository repositories.StorageRepository) ProductService {
-	return &productService{productRepository: productRepository, storageRepository: storageRepository}
+func NewProductService(productRepository repositories.ProductRepository, storageRepository repositories.StorageRepository, orderTypeRepository repositories.OrderTypeRepository, outletRepository repositories.OutletRepository, companyRepository repositories.CompanyRepository) ProductService {
+	return &productService{productRepository: productRepository, storageRepository: storageRepository, orderTypeRepository: orderTypeRepository, outletRepository: outletRepository, companyRepository: companyRepository}
 }
 
-func (s *productService) FindAll(companyID string, params models.PaginationParams) ([]models.ProductList, int, error) {
-	products, total, err := s.productRepository.FindAll(companyID, params)
+func (s *productService) FindAll(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.ProductList, int, error) {
+	filter, err := companyDateFilter(s.companyRepository, companyID, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	products, total, err := s.productRepository.FindAll(companyID, params, filter)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -35,6 +50,19 @@ func (s *productService) FindAll(companyID string, params models.PaginationParam
 }
 
 func (s *productService) Create(companyID string, payload models.ProductInput, imageFile multipart.File, imageHeader *multipart.FileHeader, addOnIDList []string) (models.Product, error) {
+	if payload.Type == "" {
+		payload.Type = models.ProductTypeFinishedGoods
+	}
+	if !validProductType(payload.Type) {
+		return models.Product{}, ErrProductTypeInvalid
+	}
+	if payload.CostingMethod == "" {
+		payload.CostingMethod = models.CostingMethodAverage
+	}
+	if !validCostingMethod(payload.CostingMethod) {
+		return models.Product{}, ErrProductCostingMethodInvalid
+	}
+
 	imageURL, err := s.storageRepository.SaveImage(context.Background(), imageFile, imageHeader)
 	if err != nil {
 		return models.Product{}, err
@@ -88,8 +116,20 @@ func (s *productService) Update(productID string, payload models.ProductInput, i
 		return models.Product{}, err
 	}
 
-	// Gunakan image_url lama sebagai default
+	// Gunakan image_url, jenis produk, dan metode costing lama sebagai default
 	payload.ImageURL = existing.ImageURL
+	if payload.Type == "" {
+		payload.Type = existing.Type
+	}
+	if !validProductType(payload.Type) {
+		return models.Product{}, ErrProductTypeInvalid
+	}
+	if payload.CostingMethod == "" {
+		payload.CostingMethod = existing.CostingMethod
+	}
+	if !validCostingMethod(payload.CostingMethod) {
+		return models.Product{}, ErrProductCostingMethodInvalid
+	}
 
 	// Jika ada gambar baru dikirim, upload terlebih dahulu
 	if imageFile != nil && imageHeader != nil {
@@ -116,10 +156,56 @@ func (s *productService) Update(productID string, payload models.ProductInput, i
 	return updated, nil
 }
 
-func (s *productService) FindAllMobile(companyID string) ([]models.ProductList, error) {
-	products, err := s.productRepository.FindAllMobile(companyID)
+// FindAllMobile mengembalikan daftar produk untuk POS. Jika outletID diisi, harga memakai
+// harga outlet dan produk nonaktif di outlet disembunyikan. Jika orderTypeID diisi,
+// Price berisi harga efektif setelah penyesuaian tipe order dan BasePrice harga sebelum penyesuaian.
+func (s *productService) FindAllMobile(companyID string, outletID string, orderTypeID string) ([]models.ProductList, error) {
+	if outletID != "" {
+		outlets, err := s.outletRepository.FindByIDs(companyID, []string{outletID})
+		if err != nil {
+			return nil, err
+		}
+		if len(outlets) == 0 {
+			return nil, ErrProductOutletNotFound
+		}
+	}
+
+	var orderType *models.OrderType
+	if orderTypeID != "" {
+		ot, err := s.orderTypeRepository.FindByID(orderTypeID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, ErrProductOrderTypeNotFound
+			}
+			return nil, err
+		}
+		if ot.CompanyID != companyID {
+			return nil, ErrProductOrderTypeNotFound
+		}
+		// Sama dengan penjualan: tipe order nonaktif tidak bisa dipakai, jadi harganya juga tidak ditampilkan
+		if !ot.IsActive {
+			return nil, ErrProductOrderTypeInactive
+		}
+		orderType = &ot
+	}
+
+	products, err := s.productRepository.FindAllMobile(companyID, outletID)
 	if err != nil {
 		return nil, err
 	}
+	for i := range products {
+		products[i].BasePrice = products[i].Price
+		if orderType != nil {
+			products[i].Price = ResolveOrderTypePrice(*orderType, products[i].Price)
+		}
+	}
 	return products, nil
 }
+
+func validProductType(t models.ProductType) bool {
+	return t == models.ProductTypeRawMaterial || t == models.ProductTypeFinishedGoods
+}
+
+func validCostingMethod(m models.CostingMethod) bool {
+	return m == models.CostingMethodAverage || m == models.CostingMethodFIFO
+}
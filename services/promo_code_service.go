@@ -0,0 +1,322 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"gowes/models"
+	"gowes/repositories"
+	"strings"
+	"time"
+)
+
+var (
+	ErrPromoCodeRequired             = errors.New("code is required")
+	ErrPromoCodeTooLong              = errors.New("code cannot be longer than 50 characters")
+	ErrPromoCodeDuplicate            = errors.New("promo code already exists")
+	ErrPromoCodeDiscountRequired     = errors.New("discount_id is required")
+	ErrPromoCodeDiscountNotFound     = errors.New("discount not found")
+	ErrPromoCodeMaxUsesInvalid       = errors.New("max_uses must be greater than zero")
+	ErrPromoCodeMaxPerCustomer       = errors.New("max_uses_per_customer must be greater than zero")
+	ErrPromoCodeCustomerRequired     = errors.New("customer_id is required for this promo code")
+	ErrPromoCodeDiscountUnavailable  = errors.New("discount for this promo code is not available right now")
+	ErrPromoCodeOutletUnavailable    = errors.New("discount for this promo code is not available at this outlet")
+	ErrPromoCodeSaleNotFound         = errors.New("sale_id not found")
+	ErrPromoCodeCustomerNotFound     = errors.New("customer not found")
+	ErrPromoCodeInactive             = repositories.ErrPromoCodeInactive
+	ErrPromoCodeUsageLimitReached    = repositories.ErrPromoCodeUsageLimitReached
+	ErrPromoCodeCustomerLimitReached = repositories.ErrPromoCodeCustomerLimitReached
+)
+
+// Kode alasan pada hasil validasi kode promo
+const (
+	PromoCodeRejectNotFound        = "not_found"
+	PromoCodeRejectInactive        = "inactive"
+	PromoCodeRejectUsageLimit      = "usage_limit_reached"
+	PromoCodeRejectCustomer        = "customer_required"
+	PromoCodeRejectCustomerLimit   = "customer_limit_reached"
+	PromoCodeRejectOutlet          = "outlet_not_eligible"
+	PromoCodeRejectCustomerUnknown = "customer_not_found"
+	PromoCodeRejectDiscountOff     = "discount_not_active"
+)
+
+type PromoCodeService interface {
+	ListPromoCodes(companyID string, params models.PaginationParams) ([]models.PromoCode, int, error)
+	GetPromoCode(id string, companyID string) (models.PromoCode, error)
+	CreatePromoCode(companyID string, input models.PromoCodeInput) (models.PromoCode, error)
+	UpdatePromoCode(id string, companyID string, input models.PromoCodeInput) (models.PromoCode, error)
+	DeletePromoCode(id string, companyID string) error
+	ValidatePromoCode(companyID string, input models.PromoCodeRedeemInput) (models.PromoCodeValidation, error)
+	RedeemPromoCode(companyID string, userID string, input models.PromoCodeRedeemInput) (models.PromoCodeRedeemResult, error)
+}
+
+type promoCodeService struct {
+	repo         repositories.PromoCodeRepository
+	discountRepo repositories.DiscountRepository
+	companyRepo  repositories.CompanyRepository
+	saleRepo     repositories.SaleRepository
+	customerRepo repositories.CustomerRepository
+}
+
+func NewPromoCodeService(repo repositories.PromoCodeRepository, discountRepo repositories.DiscountRepository, companyRepo repositories.CompanyRepository, saleRepo repositories.SaleRepository, customerRepo repositories.CustomerRepository) PromoCodeService {
+	return &promoCodeService{repo: repo, discountRepo: discountRepo, companyRepo: companyRepo, saleRepo: saleRepo, customerRepo: customerRepo}
+}
+
+func (s *promoCodeService) ListPromoCodes(companyID string, params models.PaginationParams) ([]models.PromoCode, int, error) {
+	return s.repo.FindAll(companyID, params)
+}
+
+func (s *promoCodeService) GetPromoCode(id string, companyID string) (models.PromoCode, error) {
+	return s.repo.FindByID(id, companyID)
+}
+
+func (s *promoCodeService) CreatePromoCode(companyID string, input models.PromoCodeInput) (models.PromoCode, error) {
+	if err := s.validateInput(companyID, "", input); err != nil {
+		return models.PromoCode{}, err
+	}
+
+	now := time.Now().UTC()
+	promoCode := models.PromoCode{
+		CompanyID:          companyID,
+		DiscountID:         strings.TrimSpace(input.DiscountID),
+		Code:               normalizePromoCode(input.Code),
+		MaxUses:            input.MaxUses,
+		MaxUsesPerCustomer: input.MaxUsesPerCustomer,
+		IsActive:           true,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+	if input.IsActive != nil {
+		promoCode.IsActive = *input.IsActive
+	}
+
+	return s.repo.Create(promoCode)
+}
+
+func (s *promoCodeService) UpdatePromoCode(id string, companyID string, input models.PromoCodeInput) (models.PromoCode, error) {
+	existing, err := s.repo.FindByID(id, companyID)
+	if err != nil {
+		return models.PromoCode{}, err
+	}
+	if err := s.validateInput(companyID, existing.ID, input); err != nil {
+		return models.PromoCode{}, err
+	}
+
+	existing.DiscountID = strings.TrimSpace(input.DiscountID)
+	existing.Code = normalizePromoCode(input.Code)
+	existing.MaxUses = input.MaxUses
+	existing.MaxUsesPerCustomer = input.MaxUsesPerCustomer
+	if input.IsActive != nil {
+		existing.IsActive = *input.IsActive
+	}
+	existing.UpdatedAt = time.Now().UTC()
+
+	return s.repo.Update(existing)
+}
+
+func (s *promoCodeService) DeletePromoCode(id string, companyID string) error {
+	return s.repo.Delete(id, companyID)
+}
+
+// ValidatePromoCode mengecek apakah kode promo bisa dipakai tanpa mencatat pemakaian.
+// Alasan penolakan dikembalikan di hasil (Valid = false), bukan sebagai error.
+func (s *promoCodeService) ValidatePromoCode(companyID string, input models.PromoCodeRedeemInput) (models.PromoCodeValidation, error) {
+	code := normalizePromoCode(input.Code)
+	if code == "" {
+		return models.PromoCodeValidation{}, ErrPromoCodeRequired
+	}
+
+	promoCode, err := s.repo.FindByCode(companyID, code)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.PromoCodeValidation{Reason: PromoCodeRejectNotFound, Message: "promo code not found"}, nil
+		}
+		return models.PromoCodeValidation{}, err
+	}
+	result := models.PromoCodeValidation{PromoCode: &promoCode}
+
+	discount, err := s.discountRepo.FindByID(promoCode.DiscountID)
+	if err != nil {
+		return models.PromoCodeValidation{}, err
+	}
+	result.Discount = &discount
+
+	if !promoCode.IsActive {
+		result.Reason, result.Message = PromoCodeRejectInactive, ErrPromoCodeInactive.Error()
+		return result, nil
+	}
+	if !discount.IsActive {
+		result.Reason, result.Message = PromoCodeRejectDiscountOff, ErrPromoCodeDiscountUnavailable.Error()
+		return result, nil
+	}
+	loc, err := companyLocation(s.companyRepo, companyID)
+	if err != nil {
+		return models.PromoCodeValidation{}, err
+	}
+	if reason, message, ok := discountAvailableAt(discount, time.Now().In(loc)); !ok {
+		result.Reason, result.Message = reason, message
+		return result, nil
+	}
+	if outletID := strings.TrimSpace(input.OutletID); outletID != "" && !discountMatchesOutlet(discount, outletID) {
+		result.Reason, result.Message = PromoCodeRejectOutlet, ErrPromoCodeOutletUnavailable.Error()
+		return result, nil
+	}
+	if promoCode.MaxUses != nil {
+		remaining := *promoCode.MaxUses - promoCode.UsedCount
+		if remaining <= 0 {
+			result.Reason, result.Message = PromoCodeRejectUsageLimit, ErrPromoCodeUsageLimitReached.Error()
+			return result, nil
+		}
+		result.RemainingUses = &remaining
+	}
+	customerID := strings.TrimSpace(input.CustomerID)
+	if customerID != "" {
+		found, err := s.customerExists(companyID, customerID)
+		if err != nil {
+			return models.PromoCodeValidation{}, err
+		}
+		if !found {
+			result.Reason, result.Message = PromoCodeRejectCustomerUnknown, ErrPromoCodeCustomerNotFound.Error()
+			return result, nil
+		}
+	}
+	if promoCode.MaxUsesPerCustomer != nil {
+		if customerID == "" {
+			result.Reason, result.Message = PromoCodeRejectCustomer, ErrPromoCodeCustomerRequired.Error()
+			return result, nil
+		}
+		used, err := s.repo.CountCustomerRedemptions(promoCode.ID, customerID)
+		if err != nil {
+			return models.PromoCodeValidation{}, err
+		}
+		if used >= *promoCode.MaxUsesPerCustomer {
+			result.Reason, result.Message = PromoCodeRejectCustomerLimit, ErrPromoCodeCustomerLimitReached.Error()
+			return result, nil
+		}
+	}
+
+	result.Valid = true
+	return result, nil
+}
+
+// RedeemPromoCode mencatat pemakaian kode promo. Batas pemakaian dicek ulang di repository
+// dalam transaksi yang mengunci baris kode promo.
+func (s *promoCodeService) RedeemPromoCode(companyID string, userID string, input models.PromoCodeRedeemInput) (models.PromoCodeRedeemResult, error) {
+	code := normalizePromoCode(input.Code)
+	if code == "" {
+		return models.PromoCodeRedeemResult{}, ErrPromoCodeRequired
+	}
+
+	promoCode, err := s.repo.FindByCode(companyID, code)
+	if err != nil {
+		return models.PromoCodeRedeemResult{}, err
+	}
+	customerID := strings.TrimSpace(input.CustomerID)
+	if promoCode.MaxUsesPerCustomer != nil && customerID == "" {
+		return models.PromoCodeRedeemResult{}, ErrPromoCodeCustomerRequired
+	}
+	if customerID != "" {
+		found, err := s.customerExists(companyID, customerID)
+		if err != nil {
+			return models.PromoCodeRedeemResult{}, err
+		}
+		if !found {
+			return models.PromoCodeRedeemResult{}, ErrPromoCodeCustomerNotFound
+		}
+	}
+
+	discount, err := s.discountRepo.FindByID(promoCode.DiscountID)
+	if err != nil {
+		return models.PromoCodeRedeemResult{}, err
+	}
+	loc, err := companyLocation(s.companyRepo, companyID)
+	if err != nil {
+		return models.PromoCodeRedeemResult{}, err
+	}
+	if _, _, ok := discountAvailableAt(discount, time.Now().In(loc)); !discount.IsActive || !ok {
+		return models.PromoCodeRedeemResult{}, ErrPromoCodeDiscountUnavailable
+	}
+	outletID := strings.TrimSpace(input.OutletID)
+	if outletID != "" && !discountMatchesOutlet(discount, outletID) {
+		return models.PromoCodeRedeemResult{}, ErrPromoCodeOutletUnavailable
+	}
+	if saleID := strings.TrimSpace(input.SaleID); saleID != "" {
+		sale, err := s.saleRepo.FindByID(saleID, companyID)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && outletID != "" && sale.OutletID != outletID) {
+			return models.PromoCodeRedeemResult{}, ErrPromoCodeSaleNotFound
+		}
+		if err != nil {
+			return models.PromoCodeRedeemResult{}, err
+		}
+	}
+
+	redemption := models.PromoCodeRedemption{
+		UserID:     userID,
+		CustomerID: optionalID(customerID),
+		SaleID:     optionalID(input.SaleID),
+		RedeemedAt: time.Now().UTC(),
+	}
+	redemption, promoCode, err = s.repo.Redeem(companyID, code, redemption)
+	if err != nil {
+		return models.PromoCodeRedeemResult{}, err
+	}
+
+	return models.PromoCodeRedeemResult{
+		Redemption: redemption,
+		PromoCode:  promoCode,
+		Discount:   discount,
+	}, nil
+}
+
+// customerExists memastikan customer milik company, agar batas per customer tidak bisa diakali dengan ID acak
+func (s *promoCodeService) customerExists(companyID string, customerID string) (bool, error) {
+	customers, err := s.customerRepo.FindByIDs(companyID, []string{customerID})
+	if err != nil {
+		return false, err
+	}
+	return len(customers) == 1, nil
+}
+
+// validateInput memvalidasi payload; currentID diisi saat update agar kode milik sendiri tidak dianggap duplikat.
+func (s *promoCodeService) validateInput(companyID string, currentID string, input models.PromoCodeInput) error {
+	code := normalizePromoCode(input.Code)
+	if code == "" {
+		return ErrPromoCodeRequired
+	}
+	if len(code) > 50 {
+		return ErrPromoCodeTooLong
+	}
+	if strings.TrimSpace(input.DiscountID) == "" {
+		return ErrPromoCodeDiscountRequired
+	}
+	if input.MaxUses != nil && *input.MaxUses <= 0 {
+		return ErrPromoCodeMaxUsesInvalid
+	}
+	if input.MaxUsesPerCustomer != nil && *input.MaxUsesPerCustomer <= 0 {
+		return ErrPromoCodeMaxPerCustomer
+	}
+
+	discount, err := s.discountRepo.FindByID(strings.TrimSpace(input.DiscountID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrPromoCodeDiscountNotFound
+		}
+		return err
+	}
+	if discount.CompanyID != companyID {
+		return ErrPromoCodeDiscountNotFound
+	}
+
+	existing, err := s.repo.FindByCode(companyID, code)
+	if err == nil && existing.ID != currentID {
+		return ErrPromoCodeDuplicate
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	return nil
+}
+
+// normalizePromoCode menyimpan kode promo dalam huruf besar agar tidak case-sensitive.
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"database/sql"
 	"errors"
 	"gowes/models"
 	"gowes/repositories"
@@ -9,45 +10,295 @@ import (
 )
 
 var ErrPurchaseOutletRequired = errors.New("outlet_id is required")
+var ErrPurchaseOutletNotFound = errors.New("outlet not found")
+var ErrPurchaseSupplierRequired = errors.New("supplier_id is required")
+var ErrPurchaseSupplierNotFound = errors.New("supplier not found")
+var ErrPurchaseSupplierInactive = errors.New("supplier is inactive")
 var ErrPurchaseDetailsRequired = errors.New("purchase details are required")
 var ErrPurchaseDetailProductRequired = errors.New("product_id is required for every purchase detail")
 var ErrPurchaseDetailQtyInvalid = errors.New("quantity must be greater than zero")
 var ErrPurchaseDetailPriceInvalid = errors.New("price cannot be negative")
+var ErrPurchaseProductNotFound = errors.New("product not found")
+var ErrPurchaseDuplicateProduct = errors.New("each product can only appear once in a purchase order")
+var ErrPurchaseDiscountInvalid = errors.New("discount_bill cannot be negative")
+var ErrPurchaseStatusInvalid = errors.New("status must be draft, ordered, partially_received, received, partially_returned, returned, cancelled or voided")
+var ErrPurchasePaymentStatusInvalid = errors.New("payment_status must be unpaid, partially_paid or paid")
+var ErrPurchaseCreateStatusInvalid = errors.New("new purchase orders can only be draft or ordered")
+var ErrPurchaseReceiveItemsRequired = errors.New("items are required")
+var ErrPurchaseReceiveQtyInvalid = errors.New("qty must be greater than zero")
+var ErrPurchaseVoidReasonRequired = errors.New("reason is required to void a purchase")
+var ErrPurchaseNotDraft = repositories.ErrPurchaseNotDraft
+var ErrPurchaseNotReceivable = repositories.ErrPurchaseNotReceivable
+var ErrPurchaseNotCancellable = repositories.ErrPurchaseNotCancellable
+var ErrPurchaseItemNotFound = repositories.ErrPurchaseItemNotFound
+var ErrPurchaseReceiveExceeds = repositories.ErrPurchaseReceiveExceeds
+var ErrPurchaseNotReturnable = repositories.ErrPurchaseNotReturnable
+var ErrPurchaseNotVoidable = repositories.ErrPurchaseNotVoidable
+var ErrPurchaseReturnExceeds = repositories.ErrPurchaseReturnExceeds
+var ErrPurchaseInsufficientStock = repositories.ErrPurchaseInsufficientStock
 
 type PurchaseService interface {
-	ListPurchases(companyID string, params models.PaginationParams) ([]models.Purchase, int, error)
+	ListPurchases(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.Purchase, int, error)
+	ListPurchasePage(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.Purchase, models.CursorPage, error)
 	GetPurchase(id string, companyID string) (models.Purchase, error)
 	CreatePurchase(companyID string, userID string, input models.PurchaseInput) (models.Purchase, error)
+	UpdatePurchase(id string, companyID string, input models.PurchaseInput) (models.Purchase, error)
+	DeletePurchase(id string, companyID string) error
+	OrderPurchase(id string, companyID string, userID string) (models.Purchase, error)
+	CancelPurchase(id string, companyID string, userID string) (models.Purchase, error)
+	ReceivePurchase(id string, companyID string, userID string, input models.PurchaseReceiveInput) (models.Purchase, error)
+	ReturnPurchase(id string, companyID string, userID string, input models.PurchaseReturnInput) (models.Purchase, error)
+	VoidPurchase(id string, companyID string, userID string, input models.PurchaseVoidInput) (models.Purchase, error)
 }
 
 type purchaseService struct {
-	repo repositories.PurchaseRepository
+	repo         repositories.PurchaseRepository
+	taxRepo      repositories.TaxRepository
+	productRepo  repositories.ProductRepository
+	supplierRepo repositories.SupplierRepository
+	outletRepo   repositories.OutletRepository
+	companyRepo  repositories.CompanyRepository
+	converter    UnitConverter
 }
 
-func NewPurchaseService(repo repositories.PurchaseRepository) PurchaseService {
-	return &purchaseService{repo: repo}
+func NewPurchaseService(repo repositories.PurchaseRepository, taxRepo repositories.TaxRepository, productRepo repositories.ProductRepository, supplierRepo repositories.SupplierRepository, outletRepo repositories.OutletRepository, companyRepo repositories.CompanyRepository, converter UnitConverter) PurchaseService {
+	return &purchaseService{repo: repo, taxRepo: taxRepo, productRepo: productRepo, supplierRepo: supplierRepo, outletRepo: outletRepo, companyRepo: companyRepo, converter: converter}
 }
 
-func (s *purchaseService) ListPurchases(companyID string, params models.PaginationParams) ([]models.Purchase, int, error) {
-	return s.repo.FindAll(companyID, params)
+func (s *purchaseService) ListPurchases(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.Purchase, int, error) {
+	if err := validatePurchaseFilter(filter); err != nil {
+		return nil, 0, err
+	}
+	filter, err := companyDateFilter(s.companyRepo, companyID, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.repo.FindAll(companyID, params, filter)
+}
+
+func (s *purchaseService) ListPurchasePage(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.Purchase, models.CursorPage, error) {
+	if err := validatePurchaseFilter(filter); err != nil {
+		return nil, models.CursorPage{}, err
+	}
+	filter, err := companyDateFilter(s.companyRepo, companyID, filter)
+	if err != nil {
+		return nil, models.CursorPage{}, err
+	}
+	return s.repo.FindPage(companyID, params, filter)
+}
+
+func validatePurchaseFilter(filter models.FilterParams) error {
+	for _, paymentStatus := range filter.Values["payment_status"] {
+		switch models.PurchasePaymentStatus(paymentStatus) {
+		case models.PurchasePaymentStatusUnpaid, models.PurchasePaymentStatusPartiallyPaid, models.PurchasePaymentStatusPaid:
+		default:
+			return ErrPurchasePaymentStatusInvalid
+		}
+	}
+	for _, status := range filter.Values["status"] {
+		switch models.PurchaseStatus(status) {
+		case models.PurchaseStatusDraft, models.PurchaseStatusOrdered, models.PurchaseStatusPartiallyReceived,
+			models.PurchaseStatusReceived, models.PurchaseStatusPartiallyReturned, models.PurchaseStatusReturned,
+			models.PurchaseStatusCancelled, models.PurchaseStatusVoided:
+		default:
+			return ErrPurchaseStatusInvalid
+		}
+	}
+	return nil
 }
 
 func (s *purchaseService) GetPurchase(id string, companyID string) (models.Purchase, error) {
 	return s.repo.FindByID(id, companyID)
 }
 
+// CreatePurchase membuat purchase order draft (atau langsung ordered); stok belum bertambah.
 func (s *purchaseService) CreatePurchase(companyID string, userID string, input models.PurchaseInput) (models.Purchase, error) {
-	if strings.TrimSpace(input.OutletID) == "" {
+	status := models.PurchaseStatus(strings.TrimSpace(input.Status))
+	if status == "" {
+		status = models.PurchaseStatusDraft
+	}
+	if status != models.PurchaseStatusDraft && status != models.PurchaseStatusOrdered {
+		return models.Purchase{}, ErrPurchaseCreateStatusInvalid
+	}
+
+	purchase, err := s.buildPurchase(companyID, input)
+	if err != nil {
+		return models.Purchase{}, err
+	}
+
+	now := time.Now().UTC()
+	purchase.CompanyID = companyID
+	purchase.UserID = userID
+	purchase.Status = status
+	if status == models.PurchaseStatusOrdered {
+		purchase.OrderedBy = &userID
+		purchase.OrderedAt = &now
+	}
+	purchase.CreatedAt = now
+	purchase.UpdatedAt = now
+
+	return s.repo.Create(purchase)
+}
+
+func (s *purchaseService) UpdatePurchase(id string, companyID string, input models.PurchaseInput) (models.Purchase, error) {
+	existing, err := s.repo.FindByID(id, companyID)
+	if err != nil {
+		return models.Purchase{}, err
+	}
+	if existing.Status != models.PurchaseStatusDraft {
+		return models.Purchase{}, ErrPurchaseNotDraft
+	}
+
+	purchase, err := s.buildPurchase(companyID, input)
+	if err != nil {
+		return models.Purchase{}, err
+	}
+	purchase.ID = existing.ID
+	purchase.CompanyID = companyID
+	purchase.UpdatedAt = time.Now().UTC()
+
+	return s.repo.Update(purchase)
+}
+
+func (s *purchaseService) DeletePurchase(id string, companyID string) error {
+	return s.repo.Delete(id, companyID)
+}
+
+// OrderPurchase menandai purchase order draft sudah dikirim ke supplier.
+func (s *purchaseService) OrderPurchase(id string, companyID string, userID string) (models.Purchase, error) {
+	if err := s.repo.MarkOrdered(id, companyID, userID, time.Now().UTC()); err != nil {
+		return models.Purchase{}, err
+	}
+	return s.repo.FindByID(id, companyID)
+}
+
+func (s *purchaseService) CancelPurchase(id string, companyID string, userID string) (models.Purchase, error) {
+	if err := s.repo.Cancel(id, companyID, userID, time.Now().UTC()); err != nil {
+		return models.Purchase{}, err
+	}
+	return s.repo.FindByID(id, companyID)
+}
+
+// ReceivePurchase mencatat goods received note; stok outlet bertambah sesuai qty yang diterima.
+func (s *purchaseService) ReceivePurchase(id string, companyID string, userID string, input models.PurchaseReceiveInput) (models.Purchase, error) {
+	qtyItems, err := s.stockQtyItems(companyID, input.Items)
+	if err != nil {
+		return models.Purchase{}, err
+	}
+
+	items := make([]models.PurchaseReceiptItem, 0, len(qtyItems))
+	for _, item := range qtyItems {
+		items = append(items, models.PurchaseReceiptItem{ProductID: item.ProductID, Qty: item.Qty})
+	}
+
+	if err := s.repo.Receive(id, companyID, userID, items, strings.TrimSpace(input.Note), time.Now().UTC()); err != nil {
+		return models.Purchase{}, err
+	}
+	return s.repo.FindByID(id, companyID)
+}
+
+// ReturnPurchase mengembalikan sebagian barang yang sudah diterima ke supplier; stok outlet berkurang.
+func (s *purchaseService) ReturnPurchase(id string, companyID string, userID string, input models.PurchaseReturnInput) (models.Purchase, error) {
+	receiveItems := make([]models.PurchaseReceiveItemInput, 0, len(input.Items))
+	for _, in := range input.Items {
+		receiveItems = append(receiveItems, models.PurchaseReceiveItemInput(in))
+	}
+	qtyItems, err := s.stockQtyItems(companyID, receiveItems)
+	if err != nil {
+		return models.Purchase{}, err
+	}
+
+	items := make([]models.PurchaseReturnItem, 0, len(qtyItems))
+	for _, item := range qtyItems {
+		items = append(items, models.PurchaseReturnItem{ProductID: item.ProductID, Qty: item.Qty})
+	}
+
+	if err := s.repo.Return(id, companyID, userID, items, strings.TrimSpace(input.Note), time.Now().UTC()); err != nil {
+		return models.Purchase{}, err
+	}
+	return s.repo.FindByID(id, companyID)
+}
+
+// VoidPurchase membatalkan purchase yang sudah menerima barang dan mengeluarkan seluruh sisa stoknya.
+func (s *purchaseService) VoidPurchase(id string, companyID string, userID string, input models.PurchaseVoidInput) (models.Purchase, error) {
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		return models.Purchase{}, ErrPurchaseVoidReasonRequired
+	}
+	if err := s.repo.Void(id, companyID, userID, reason, time.Now().UTC()); err != nil {
+		return models.Purchase{}, err
+	}
+	return s.repo.FindByID(id, companyID)
+}
+
+// stockQtyItems memvalidasi baris terima/retur, mengonversi qty ke satuan stok,
+// dan menggabungkan baris dengan produk yang sama.
+func (s *purchaseService) stockQtyItems(companyID string, inputs []models.PurchaseReceiveItemInput) ([]models.PurchaseReceiveItemInput, error) {
+	if len(inputs) == 0 {
+		return nil, ErrPurchaseReceiveItemsRequired
+	}
+
+	productIDs := make([]string, 0, len(inputs))
+	for _, in := range inputs {
+		productID := strings.TrimSpace(in.ProductID)
+		if productID == "" {
+			return nil, ErrPurchaseDetailProductRequired
+		}
+		if in.Qty <= 0 {
+			return nil, ErrPurchaseReceiveQtyInvalid
+		}
+		productIDs = append(productIDs, productID)
+	}
+
+	productByID, err := s.findProducts(companyID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []models.PurchaseReceiveItemInput{}
+	indexByProduct := map[string]int{}
+	for _, in := range inputs {
+		productID := strings.TrimSpace(in.ProductID)
+		product, ok := productByID[productID]
+		if !ok {
+			return nil, ErrPurchaseItemNotFound
+		}
+		qty, err := s.converter.ToStockQty(companyID, product, in.UnitID, in.Qty)
+		if err != nil {
+			return nil, err
+		}
+		if idx, ok := indexByProduct[productID]; ok {
+			items[idx].Qty += qty
+			continue
+		}
+		indexByProduct[productID] = len(items)
+		items = append(items, models.PurchaseReceiveItemInput{ProductID: productID, Qty: qty})
+	}
+	return items, nil
+}
+
+// buildPurchase memvalidasi supplier dan baris purchase order lalu menghitung total dan PPN.
+func (s *purchaseService) buildPurchase(companyID string, input models.PurchaseInput) (models.Purchase, error) {
+	outletID := strings.TrimSpace(input.OutletID)
+	supplierID := strings.TrimSpace(input.SupplierID)
+	if outletID == "" {
 		return models.Purchase{}, ErrPurchaseOutletRequired
 	}
+	if supplierID == "" {
+		return models.Purchase{}, ErrPurchaseSupplierRequired
+	}
 	if len(input.Details) == 0 {
 		return models.Purchase{}, ErrPurchaseDetailsRequired
 	}
+	if input.DiscountBill < 0 {
+		return models.Purchase{}, ErrPurchaseDiscountInvalid
+	}
 
-	subtotal := 0.0
-	details := make([]models.PurchaseDetail, 0, len(input.Details))
+	productIDs := make([]string, 0, len(input.Details))
+	seen := map[string]bool{}
 	for _, d := range input.Details {
-		if strings.TrimSpace(d.ProductID) == "" {
+		productID := strings.TrimSpace(d.ProductID)
+		if productID == "" {
 			return models.Purchase{}, ErrPurchaseDetailProductRequired
 		}
 		if d.Quantity <= 0 {
@@ -56,52 +307,115 @@ func (s *purchaseService) CreatePurchase(companyID string, userID string, input
 		if d.Price < 0 {
 			return models.Purchase{}, ErrPurchaseDetailPriceInvalid
 		}
+		if seen[productID] {
+			return models.Purchase{}, ErrPurchaseDuplicateProduct
+		}
+		seen[productID] = true
+		productIDs = append(productIDs, productID)
+	}
+
+	outlets, err := s.outletRepo.FindByIDs(companyID, []string{outletID})
+	if err != nil {
+		return models.Purchase{}, err
+	}
+	if len(outlets) != 1 {
+		return models.Purchase{}, ErrPurchaseOutletNotFound
+	}
+
+	supplier, err := s.supplierRepo.FindByID(supplierID, companyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Purchase{}, ErrPurchaseSupplierNotFound
+		}
+		return models.Purchase{}, err
+	}
+	if !supplier.IsActive {
+		return models.Purchase{}, ErrPurchaseSupplierInactive
+	}
+
+	productByID, err := s.findProducts(companyID, productIDs)
+	if err != nil {
+		return models.Purchase{}, err
+	}
+
+	subtotal := 0.0
+	details := make([]models.PurchaseDetail, 0, len(input.Details))
+	lines := make([]models.TaxLine, 0, len(input.Details))
+	for _, d := range input.Details {
+		product, ok := productByID[strings.TrimSpace(d.ProductID)]
+		if !ok {
+			return models.Purchase{}, ErrPurchaseProductNotFound
+		}
+
+		// Qty disimpan dalam satuan stok produk; harga ikut disesuaikan per satuan stok
+		total := d.Quantity * d.Price
+		qty, err := s.converter.ToStockQty(companyID, product, d.UnitID, d.Quantity)
+		if err != nil {
+			return models.Purchase{}, err
+		}
+		price := d.Price
+		if qty != d.Quantity {
+			price = total / qty
+		}
 
-		total := float64(d.Quantity) * d.Price
 		subtotal += total
 		details = append(details, models.PurchaseDetail{
-			ProductID: strings.TrimSpace(d.ProductID),
-			Quantity:  d.Quantity,
-			Price:     d.Price,
+			ProductID: product.ID,
+			Quantity:  qty,
+			Price:     price,
 			Total:     total,
 		})
+		lines = append(lines, models.TaxLine{ProductID: product.ID, CategoryID: product.CategoryID, Amount: total})
 	}
 
-	grandTotal := subtotal + input.TaxValue - input.DiscountBill
-	if grandTotal < 0 {
-		grandTotal = 0
+	// Pembelian hanya dikenai PPN, service charge tidak berlaku
+	taxes, err := s.taxRepo.FindActiveByCompany(companyID)
+	if err != nil {
+		return models.Purchase{}, err
+	}
+	vatTaxes := make([]models.Tax, 0, len(taxes))
+	for _, t := range taxes {
+		if t.Type == models.TaxTypeVAT {
+			vatTaxes = append(vatTaxes, t)
+		}
 	}
 
-	changeAmount := 0.0
-	if input.PaidAmount > grandTotal {
-		changeAmount = input.PaidAmount - grandTotal
+	discountBill := input.DiscountBill
+	if discountBill > subtotal {
+		discountBill = subtotal
 	}
+	taxCalc := CalculateTaxes(vatTaxes, outletID, ApplyBillDiscount(lines, discountBill))
+
+	grandTotal := roundCurrency(subtotal - discountBill + taxCalc.TaxValue)
 
 	paymentMethod := strings.TrimSpace(input.PaymentMethod)
 	if paymentMethod == "" {
 		paymentMethod = "cash"
 	}
-	status := strings.TrimSpace(input.Status)
-	if status == "" {
-		status = "completed"
-	}
 
-	now := time.Now().UTC()
-	purchase := models.Purchase{
-		CompanyID:     companyID,
-		UserID:        userID,
-		OutletID:      strings.TrimSpace(input.OutletID),
-		PaymentMethod: paymentMethod,
-		GrandTotal:    grandTotal,
-		TaxValue:      input.TaxValue,
-		PaidAmount:    input.PaidAmount,
-		ChangeAmount:  changeAmount,
-		Status:        status,
-		DiscountBill:  input.DiscountBill,
-		Details:       details,
-		CreatedAt:     now,
-		UpdatedAt:     now,
-	}
-
-	return s.repo.CreateWithStockMovement(purchase)
+	return models.Purchase{
+		OutletID:        outletID,
+		SupplierID:      &supplier.ID,
+		PaymentMethod:   paymentMethod,
+		Subtotal:        subtotal,
+		GrandTotal:      grandTotal,
+		TaxValue:        taxCalc.TaxValue,
+		DiscountBill:    discountBill,
+		PaymentTermDays: supplier.PaymentTermDays,
+		Note:            strings.TrimSpace(input.Note),
+		Details:         details,
+		Taxes:           taxCalc.Breakdown,
+	}, nil
+}
+
+func (s *purchaseService) findProducts(companyID string, productIDs []string) (map[string]models.Product, error) {
+	products, err := s.productRepo.FindByIDs(companyID, uniqueTrimmedIDs(productIDs))
+	if err != nil {
+		return nil, err
+	}
+	productByID := make(map[string]models.Product, len(products))
+	for _, p := range products {
+		productByID[p.ID] = p
+	}
+	return productByID, nil
 }
@@ -9,8 +9,15 @@ import (
 )
 
 var (
-	ErrRecipeProductRequired    = errors.New("recipe product_id is required")
-	ErrRecipeIngredientRequired = errors.New("recipe ingredient_id is required")
+	ErrRecipeProductRequired     = errors.New("recipe product_id is required")
+	ErrRecipeIngredientRequired  = errors.New("recipe ingredient_id is required")
+	ErrRecipeQuantityInvalid     = errors.New("recipe quantity must be greater than zero")
+	ErrRecipeProductNotFound     = errors.New("product not found")
+	ErrRecipeIngredientNotFound  = errors.New("ingredient not found")
+	ErrRecipeProductNotFinished  = errors.New("recipe product must be a finished_goods product")
+	ErrRecipeIngredientNotRaw    = errors.New("recipe ingredient must be a raw_material product")
+	ErrRecipeSelfReference       = errors.New("recipe ingredient cannot be the product itself")
+	ErrRecipeDuplicateIngredient = errors.New("ingredient is already in the product recipe")
 )
 
 type RecipeService interface {
@@ -19,14 +26,18 @@ type RecipeService interface {
 	CreateRecipe(companyID string, userID string, in models.RecipeInput) (models.Recipe, error)
 	UpdateRecipe(id string, companyID string, userID string, in models.RecipeInput) (models.Recipe, error)
 	DeleteRecipe(id string, companyID string) error
+	GetBOM(companyID string, productID string) (models.RecipeBOM, error)
+	SaveBOM(companyID string, userID string, productID string, in models.RecipeBOMInput) (models.RecipeBOM, error)
 }
 
 type recipeService struct {
-	repo repositories.RecipeRepository
+	repo        repositories.RecipeRepository
+	productRepo repositories.ProductRepository
+	converter   UnitConverter
 }
 
-func NewRecipeService(repo repositories.RecipeRepository) RecipeService {
-	return &recipeService{repo: repo}
+func NewRecipeService(repo repositories.RecipeRepository, productRepo repositories.ProductRepository, converter UnitConverter) RecipeService {
+	return &recipeService{repo: repo, productRepo: productRepo, converter: converter}
 }
 
 func (s *recipeService) ListRecipes(companyID string, params models.PaginationParams) ([]models.Recipe, int, error) {
@@ -42,6 +53,20 @@ func (s *recipeService) CreateRecipe(companyID string, userID string, in models.
 		return models.Recipe{}, err
 	}
 
+	productID := strings.TrimSpace(in.ProductID)
+	ingredientID := strings.TrimSpace(in.IngredientID)
+	products, err := s.validateProducts(companyID, productID, []string{ingredientID})
+	if err != nil {
+		return models.Recipe{}, err
+	}
+	if err := s.ensureNotInRecipe(companyID, productID, ingredientID, ""); err != nil {
+		return models.Recipe{}, err
+	}
+	unitID, err := s.resolveUnit(companyID, products[ingredientID], in.UnitID, in.Quantity)
+	if err != nil {
+		return models.Recipe{}, err
+	}
+
 	now := time.Now().UTC()
 	isActive := true
 	if in.IsActive != nil {
@@ -50,8 +75,10 @@ func (s *recipeService) CreateRecipe(companyID string, userID string, in models.
 
 	recipe := models.Recipe{
 		CompanyID:    companyID,
-		ProductID:    strings.TrimSpace(in.ProductID),
-		IngredientID: strings.TrimSpace(in.IngredientID),
+		ProductID:    productID,
+		IngredientID: ingredientID,
+		Quantity:     in.Quantity,
+		UnitID:       unitID,
 		IsActive:     isActive,
 		CreatedBy:    userID,
 		CreatedAt:    now,
@@ -72,8 +99,24 @@ func (s *recipeService) UpdateRecipe(id string, companyID string, userID string,
 		return models.Recipe{}, err
 	}
 
-	recipe.ProductID = strings.TrimSpace(in.ProductID)
-	recipe.IngredientID = strings.TrimSpace(in.IngredientID)
+	productID := strings.TrimSpace(in.ProductID)
+	ingredientID := strings.TrimSpace(in.IngredientID)
+	products, err := s.validateProducts(companyID, productID, []string{ingredientID})
+	if err != nil {
+		return models.Recipe{}, err
+	}
+	if err := s.ensureNotInRecipe(companyID, productID, ingredientID, recipe.ID); err != nil {
+		return models.Recipe{}, err
+	}
+	unitID, err := s.resolveUnit(companyID, products[ingredientID], in.UnitID, in.Quantity)
+	if err != nil {
+		return models.Recipe{}, err
+	}
+
+	recipe.ProductID = productID
+	recipe.IngredientID = ingredientID
+	recipe.Quantity = in.Quantity
+	recipe.UnitID = unitID
 	if in.IsActive != nil {
 		recipe.IsActive = *in.IsActive
 	}
@@ -87,6 +130,152 @@ func (s *recipeService) DeleteRecipe(id string, companyID string) error {
 	return s.repo.Delete(id, companyID)
 }
 
+// GetBOM mengembalikan seluruh bahan penyusun satu produk jadi
+func (s *recipeService) GetBOM(companyID string, productID string) (models.RecipeBOM, error) {
+	productID = strings.TrimSpace(productID)
+	if productID == "" {
+		return models.RecipeBOM{}, ErrRecipeProductRequired
+	}
+
+	products, err := s.productRepo.FindByIDs(companyID, []string{productID})
+	if err != nil {
+		return models.RecipeBOM{}, err
+	}
+	if len(products) == 0 {
+		return models.RecipeBOM{}, ErrRecipeProductNotFound
+	}
+
+	items, err := s.repo.FindByProduct(companyID, productID)
+	if err != nil {
+		return models.RecipeBOM{}, err
+	}
+
+	return models.RecipeBOM{
+		ProductID:   products[0].ID,
+		ProductName: products[0].Name,
+		Items:       items,
+	}, nil
+}
+
+// SaveBOM mengganti seluruh resep produk jadi; daftar item kosong berarti resep dikosongkan.
+func (s *recipeService) SaveBOM(companyID string, userID string, productID string, in models.RecipeBOMInput) (models.RecipeBOM, error) {
+	productID = strings.TrimSpace(productID)
+	if productID == "" {
+		return models.RecipeBOM{}, ErrRecipeProductRequired
+	}
+
+	recipes := make([]models.Recipe, 0, len(in.Items))
+	ingredientIDs := make([]string, 0, len(in.Items))
+	seen := map[string]bool{}
+	for _, item := range in.Items {
+		ingredientID := strings.TrimSpace(item.IngredientID)
+		if ingredientID == "" {
+			return models.RecipeBOM{}, ErrRecipeIngredientRequired
+		}
+		if item.Quantity <= 0 {
+			return models.RecipeBOM{}, ErrRecipeQuantityInvalid
+		}
+		if seen[ingredientID] {
+			return models.RecipeBOM{}, ErrRecipeDuplicateIngredient
+		}
+		seen[ingredientID] = true
+
+		isActive := true
+		if item.IsActive != nil {
+			isActive = *item.IsActive
+		}
+		recipes = append(recipes, models.Recipe{
+			IngredientID: ingredientID,
+			Quantity:     item.Quantity,
+			IsActive:     isActive,
+		})
+		ingredientIDs = append(ingredientIDs, ingredientID)
+	}
+
+	products, err := s.validateProducts(companyID, productID, ingredientIDs)
+	if err != nil {
+		return models.RecipeBOM{}, err
+	}
+	for i, item := range in.Items {
+		unitID, err := s.resolveUnit(companyID, products[recipes[i].IngredientID], item.UnitID, item.Quantity)
+		if err != nil {
+			return models.RecipeBOM{}, err
+		}
+		recipes[i].UnitID = unitID
+	}
+
+	if err := s.repo.ReplaceForProduct(companyID, productID, userID, recipes, time.Now().UTC()); err != nil {
+		return models.RecipeBOM{}, err
+	}
+	return s.GetBOM(companyID, productID)
+}
+
+// validateProducts memastikan produk jadi dan bahan-bahannya milik company serta jenisnya sesuai.
+func (s *recipeService) validateProducts(companyID string, productID string, ingredientIDs []string) (map[string]models.Product, error) {
+	ids := []string{productID}
+	for _, ingredientID := range ingredientIDs {
+		if ingredientID == productID {
+			return nil, ErrRecipeSelfReference
+		}
+		ids = append(ids, ingredientID)
+	}
+
+	products, err := s.productRepo.FindByIDs(companyID, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.Product, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+
+	product, ok := byID[productID]
+	if !ok {
+		return nil, ErrRecipeProductNotFound
+	}
+	if product.Type != models.ProductTypeFinishedGoods {
+		return nil, ErrRecipeProductNotFinished
+	}
+
+	for _, ingredientID := range ingredientIDs {
+		ingredient, ok := byID[ingredientID]
+		if !ok {
+			return nil, ErrRecipeIngredientNotFound
+		}
+		if ingredient.Type != models.ProductTypeRawMaterial {
+			return nil, ErrRecipeIngredientNotRaw
+		}
+	}
+	return byID, nil
+}
+
+// ensureNotInRecipe menolak bahan yang sudah tercatat di resep produk (kecuali baris excludeID sendiri)
+func (s *recipeService) ensureNotInRecipe(companyID string, productID string, ingredientID string, excludeID string) error {
+	existing, err := s.repo.FindByProduct(companyID, productID)
+	if err != nil {
+		return err
+	}
+	for _, recipe := range existing {
+		if recipe.IngredientID == ingredientID && recipe.ID != excludeID {
+			return ErrRecipeDuplicateIngredient
+		}
+	}
+	return nil
+}
+
+// resolveUnit memastikan satuan resep bisa dikonversi ke satuan stok bahan
+// dan qty resep sesuai presisi satuannya
+func (s *recipeService) resolveUnit(companyID string, ingredient models.Product, unitID string, qty float64) (*string, error) {
+	if _, err := s.converter.ToStockQty(companyID, ingredient, unitID, qty); err != nil {
+		return nil, err
+	}
+	unitID = strings.TrimSpace(unitID)
+	if unitID == "" {
+		return nil, nil
+	}
+	return &unitID, nil
+}
+
 func validateRecipeInput(in models.RecipeInput) error {
 	if strings.TrimSpace(in.ProductID) == "" {
 		return ErrRecipeProductRequired
@@ -94,5 +283,8 @@ func validateRecipeInput(in models.RecipeInput) error {
 	if strings.TrimSpace(in.IngredientID) == "" {
 		return ErrRecipeIngredientRequired
 	}
+	if in.Quantity <= 0 {
+		return ErrRecipeQuantityInvalid
+	}
 	return nil
 }
@@ -8,7 +8,10 @@ import (
 	"time"
 )
 
-var ErrRoleNameRequired = errors.New("role name is required")
+var (
+	ErrRoleNameRequired      = errors.New("role name is required")
+	ErrRolePermissionInvalid = errors.New("role permission is invalid")
+)
 
 type RoleService interface {
 	ListRoles(companyID string, params models.PaginationParams) ([]models.Role, int, error)
@@ -16,6 +19,8 @@ type RoleService interface {
 	CreateRole(companyID string, userID string, input models.RoleInput) (models.Role, error)
 	UpdateRole(id string, companyID string, userID string, input models.RoleInput) (models.Role, error)
 	DeleteRole(id string, companyID string) error
+	ListPermissions() []models.PermissionInfo
+	UserPermissions(userID string) ([]models.Permission, error)
 }
 
 type roleService struct {
@@ -41,12 +46,13 @@ func (s *roleService) CreateRole(companyID string, userID string, input models.R
 
 	now := time.Now().UTC()
 	role := models.Role{
-		Name:      strings.TrimSpace(input.Name),
-		CompanyID: companyID,
-		CreatedBy: userID,
-		UpdatedBy: userID,
-		CreatedAt: now,
-		UpdatedAt: now,
+		Name:        strings.TrimSpace(input.Name),
+		CompanyID:   companyID,
+		Permissions: uniquePermissions(input.Permissions),
+		CreatedBy:   userID,
+		UpdatedBy:   userID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
 	}
 
 	return s.repo.Create(role)
@@ -64,6 +70,7 @@ func (s *roleService) UpdateRole(id string, companyID string, userID string, inp
 
 	role.Name = strings.TrimSpace(input.Name)
 	role.CompanyID = companyID
+	role.Permissions = uniquePermissions(input.Permissions)
 	role.UpdatedBy = userID
 	role.UpdatedAt = time.Now().UTC()
 
@@ -74,10 +81,57 @@ func (s *roleService) DeleteRole(id string, companyID string) error {
 	return s.repo.Delete(id, companyID)
 }
 
+func (s *roleService) ListPermissions() []models.PermissionInfo {
+	return models.PermissionCatalog
+}
+
+// UserPermissions: admin selalu memiliki semua permission, user dengan role kustom memakai permission
+// role tersebut, selain itu memakai permission default role bawaannya.
+func (s *roleService) UserPermissions(userID string) ([]models.Permission, error) {
+	access, err := s.repo.FindUserAccess(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if access.Role == models.RoleAdmin {
+		permissions := make([]models.Permission, 0, len(models.PermissionCatalog))
+		for _, info := range models.PermissionCatalog {
+			permissions = append(permissions, info.Permission)
+		}
+		return permissions, nil
+	}
+
+	if access.RoleID != nil {
+		return access.Permissions, nil
+	}
+
+	return models.DefaultRolePermissions[access.Role], nil
+}
+
 func validateRoleInput(input models.RoleInput) error {
 	if strings.TrimSpace(input.Name) == "" {
 		return ErrRoleNameRequired
 	}
 
+	for _, permission := range input.Permissions {
+		if !models.IsValidPermission(models.Permission(strings.TrimSpace(string(permission)))) {
+			return ErrRolePermissionInvalid
+		}
+	}
+
 	return nil
 }
+
+func uniquePermissions(permissions []models.Permission) []models.Permission {
+	seen := map[models.Permission]bool{}
+	result := []models.Permission{}
+	for _, permission := range permissions {
+		permission = models.Permission(strings.TrimSpace(string(permission)))
+		if seen[permission] {
+			continue
+		}
+		seen[permission] = true
+		result = append(result, permission)
+	}
+	return result
+}
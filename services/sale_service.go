@@ -0,0 +1,379 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"gowes/models"
+	"gowes/repositories"
+	"strings"
+	"time"
+)
+
+var ErrSaleOutletRequired = errors.New("outlet_id is required")
+var ErrSaleDetailsRequired = errors.New("sale details are required")
+var ErrSaleDetailProductRequired = errors.New("product_id is required for every sale detail")
+var ErrSaleDetailQtyInvalid = errors.New("quantity must be greater than zero")
+var ErrSaleAddOnRequired = errors.New("add_on_id is required for every add-on")
+var ErrSaleAddOnQtyInvalid = errors.New("add-on quantity must be greater than zero")
+var ErrSaleAddOnNotFound = errors.New("add-on not found or inactive")
+var ErrSaleOrderTypeNotFound = errors.New("order type not found")
+var ErrSaleOrderTypeInactive = errors.New("order type is not active")
+var ErrSalePaidAmountInsufficient = errors.New("paid_amount is less than grand_total")
+var ErrSaleProductNotFound = errors.New("product not found")
+var ErrSaleProductUnavailable = errors.New("product is not available at this outlet")
+var ErrSaleDiscountInvalid = errors.New("discount_bill cannot be negative")
+var ErrSaleOutletNotFound = errors.New("outlet not found")
+var ErrSaleCustomerNotFound = errors.New("customer not found")
+var ErrSaleStatusInvalid = errors.New("status must be completed or pending")
+var ErrSaleInsufficientStock = repositories.ErrSaleInsufficientStock
+
+type SaleService interface {
+	ListSales(companyID string, outletID string, params models.PaginationParams) ([]models.Sale, int, error)
+	GetSale(id string, companyID string) (models.Sale, error)
+	CreateSale(companyID string, userID string, input models.SaleInput) (models.Sale, error)
+}
+
+type saleService struct {
+	repo              repositories.SaleRepository
+	taxRepo           repositories.TaxRepository
+	productRepo       repositories.ProductRepository
+	orderTypeRepo     repositories.OrderTypeRepository
+	addOnRepo         repositories.AddOnRepository
+	outletProductRepo repositories.OutletProductRepository
+	recipeRepo        repositories.RecipeRepository
+	outletRepo        repositories.OutletRepository
+	customerRepo      repositories.CustomerRepository
+	converter         UnitConverter
+}
+
+func NewSaleService(repo repositories.SaleRepository, taxRepo repositories.TaxRepository, productRepo repositories.ProductRepository, orderTypeRepo repositories.OrderTypeRepository, addOnRepo repositories.AddOnRepository, outletProductRepo repositories.OutletProductRepository, recipeRepo repositories.RecipeRepository, outletRepo repositories.OutletRepository, customerRepo repositories.CustomerRepository, converter UnitConverter) SaleService {
+	return &saleService{repo: repo, taxRepo: taxRepo, productRepo: productRepo, orderTypeRepo: orderTypeRepo, addOnRepo: addOnRepo, outletProductRepo: outletProductRepo, recipeRepo: recipeRepo, outletRepo: outletRepo, customerRepo: customerRepo, converter: converter}
+}
+
+func (s *saleService) ListSales(companyID string, outletID string, params models.PaginationParams) ([]models.Sale, int, error) {
+	return s.repo.FindAll(companyID, outletID, params)
+}
+
+func (s *saleService) GetSale(id string, companyID string) (models.Sale, error) {
+	return s.repo.FindByID(id, companyID)
+}
+
+func (s *saleService) CreateSale(companyID string, userID string, input models.SaleInput) (models.Sale, error) {
+	if strings.TrimSpace(input.OutletID) == "" {
+		return models.Sale{}, ErrSaleOutletRequired
+	}
+	if len(input.Details) == 0 {
+		return models.Sale{}, ErrSaleDetailsRequired
+	}
+	if input.DiscountBill < 0 {
+		return models.Sale{}, ErrSaleDiscountInvalid
+	}
+
+	status := strings.TrimSpace(input.Status)
+	if status == "" {
+		status = models.SaleStatusCompleted
+	}
+	if status != models.SaleStatusCompleted && status != models.SaleStatusPending {
+		return models.Sale{}, ErrSaleStatusInvalid
+	}
+
+	// outlet dan customer harus milik company ini; FK saja tidak membatasi tenant
+	outlets, err := s.outletRepo.FindByIDs(companyID, []string{strings.TrimSpace(input.OutletID)})
+	if err != nil {
+		return models.Sale{}, err
+	}
+	if len(outlets) == 0 {
+		return models.Sale{}, ErrSaleOutletNotFound
+	}
+	if customerID := optionalID(input.CustomerID); customerID != nil {
+		customers, err := s.customerRepo.FindByIDs(companyID, []string{*customerID})
+		if err != nil {
+			return models.Sale{}, err
+		}
+		if len(customers) == 0 {
+			return models.Sale{}, ErrSaleCustomerNotFound
+		}
+	}
+
+	productIDs := make([]string, 0, len(input.Details))
+	addOnIDs := []string{}
+	for _, d := range input.Details {
+		if strings.TrimSpace(d.ProductID) == "" {
+			return models.Sale{}, ErrSaleDetailProductRequired
+		}
+		if d.Quantity <= 0 {
+			return models.Sale{}, ErrSaleDetailQtyInvalid
+		}
+		productIDs = append(productIDs, strings.TrimSpace(d.ProductID))
+		for _, a := range d.AddOns {
+			if strings.TrimSpace(a.AddOnID) == "" {
+				return models.Sale{}, ErrSaleAddOnRequired
+			}
+			if a.Quantity < 0 {
+				return models.Sale{}, ErrSaleAddOnQtyInvalid
+			}
+			addOnIDs = append(addOnIDs, strings.TrimSpace(a.AddOnID))
+		}
+	}
+
+	orderType, err := s.resolveOrderType(companyID, input.OrderTypeID)
+	if err != nil {
+		return models.Sale{}, err
+	}
+
+	products, err := s.productRepo.FindByIDs(companyID, productIDs)
+	if err != nil {
+		return models.Sale{}, err
+	}
+	productByID := make(map[string]models.Product, len(products))
+	for _, p := range products {
+		productByID[p.ID] = p
+	}
+
+	// Pengaturan outlet menimpa harga master dan bisa menonaktifkan produk di outlet ini
+	outletProducts, err := s.outletProductRepo.FindByOutletAndProducts(companyID, strings.TrimSpace(input.OutletID), productIDs)
+	if err != nil {
+		return models.Sale{}, err
+	}
+	for _, op := range outletProducts {
+		p, ok := productByID[op.ProductID]
+		if !ok {
+			continue
+		}
+		if !op.IsActive {
+			return models.Sale{}, ErrSaleProductUnavailable
+		}
+		if op.Price != nil {
+			p.Price = *op.Price
+		}
+		if op.Cost != nil {
+			p.Cost = *op.Cost
+		}
+		productByID[op.ProductID] = p
+	}
+
+	addOnPrice := map[string]float64{}
+	if len(addOnIDs) > 0 {
+		addOns, err := s.addOnRepo.FindActiveByIDs(companyID, addOnIDs)
+		if err != nil {
+			return models.Sale{}, err
+		}
+		for _, a := range addOns {
+			addOnPrice[a.ID] = a.Price
+		}
+	}
+
+	subtotal := 0.0
+	details := make([]models.SaleDetail, 0, len(input.Details))
+	lines := make([]models.TaxLine, 0, len(input.Details))
+	for _, d := range input.Details {
+		product, ok := productByID[strings.TrimSpace(d.ProductID)]
+		if !ok {
+			return models.Sale{}, ErrSaleProductNotFound
+		}
+
+		price := product.Price
+		if orderType != nil {
+			price = ResolveOrderTypePrice(*orderType, price)
+		}
+
+		// Harga add-on dihitung per satu item produk, lalu dikalikan quantity baris
+		addOnUnitTotal := 0.0
+		addOns := make([]models.SaleDetailAddOn, 0, len(d.AddOns))
+		for _, a := range d.AddOns {
+			addOnID := strings.TrimSpace(a.AddOnID)
+			unitPrice, ok := addOnPrice[addOnID]
+			if !ok {
+				return models.Sale{}, ErrSaleAddOnNotFound
+			}
+			qty := a.Quantity
+			if qty == 0 {
+				qty = 1
+			}
+
+			total := float64(qty) * unitPrice
+			addOnUnitTotal += total
+			addOns = append(addOns, models.SaleDetailAddOn{
+				AddOnID:  addOnID,
+				Quantity: qty,
+				Price:    unitPrice,
+				Total:    total,
+			})
+		}
+
+		addOnTotal := addOnUnitTotal * float64(d.Quantity)
+		total := float64(d.Quantity)*price + addOnTotal
+		subtotal += total
+		details = append(details, models.SaleDetail{
+			ProductID:  product.ID,
+			Quantity:   d.Quantity,
+			Price:      price,
+			AddOnTotal: addOnTotal,
+			Total:      total,
+			Note:       strings.TrimSpace(d.Note),
+			AddOns:     addOns,
+		})
+		lines = append(lines, models.TaxLine{ProductID: product.ID, CategoryID: product.CategoryID, Amount: total})
+	}
+
+	taxes, err := s.taxRepo.FindActiveByCompany(companyID)
+	if err != nil {
+		return models.Sale{}, err
+	}
+
+	discountBill := input.DiscountBill
+	if discountBill > subtotal {
+		discountBill = subtotal
+	}
+	taxCalc := CalculateTaxes(taxes, strings.TrimSpace(input.OutletID), ApplyBillDiscount(lines, discountBill))
+
+	grandTotal := roundCurrency(subtotal - discountBill + taxCalc.TaxValue)
+
+	if status == models.SaleStatusCompleted && input.PaidAmount < grandTotal {
+		return models.Sale{}, ErrSalePaidAmountInsufficient
+	}
+
+	changeAmount := 0.0
+	if input.PaidAmount > grandTotal {
+		changeAmount = input.PaidAmount - grandTotal
+	}
+
+	deductions, err := s.stockDeductions(companyID, details, productByID)
+	if err != nil {
+		return models.Sale{}, err
+	}
+
+	paymentMethod := strings.TrimSpace(input.PaymentMethod)
+	if paymentMethod == "" {
+		paymentMethod = "cash"
+	}
+
+	now := time.Now().UTC()
+	sale := models.Sale{
+		CompanyID:     companyID,
+		UserID:        userID,
+		OutletID:      strings.TrimSpace(input.OutletID),
+		OrderTypeID:   optionalID(input.OrderTypeID),
+		CustomerID:    optionalID(input.CustomerID),
+		PaymentMethod: paymentMethod,
+		Subtotal:      subtotal,
+		DiscountBill:  discountBill,
+		TaxValue:      taxCalc.TaxValue,
+		GrandTotal:    grandTotal,
+		PaidAmount:    input.PaidAmount,
+		ChangeAmount:  changeAmount,
+		Status:        status,
+		Note:          strings.TrimSpace(input.Note),
+		Details:       details,
+		Taxes:         taxCalc.Breakdown,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+
+		StockDeductions: deductions,
+	}
+
+	return s.repo.CreateWithStockMovement(sale)
+}
+
+// stockDeductions menghitung stok yang keluar dari penjualan. Barang jadi yang punya resep aktif
+// mengurangi bahan bakunya (qty terjual x qty resep, dikonversi ke satuan stok bahan);
+// produk lain mengurangi stoknya sendiri.
+// Hasilnya digabung per produk agar satu produk hanya punya satu pergerakan stok per transaksi;
+// komponen per baris disimpan di StockComponents untuk menghitung HPP baris tersebut.
+func (s *saleService) stockDeductions(companyID string, details []models.SaleDetail, productByID map[string]models.Product) ([]models.SaleStockDeduction, error) {
+	finishedIDs := []string{}
+	for _, d := range details {
+		if productByID[d.ProductID].Type == models.ProductTypeFinishedGoods {
+			finishedIDs = append(finishedIDs, d.ProductID)
+		}
+	}
+
+	recipesByProduct := map[string][]models.Recipe{}
+	ingredientByID := map[string]models.Product{}
+	if len(finishedIDs) > 0 {
+		recipes, err := s.recipeRepo.FindActiveByProducts(companyID, uniqueTrimmedIDs(finishedIDs))
+		if err != nil {
+			return nil, err
+		}
+		ingredientIDs := make([]string, 0, len(recipes))
+		for _, r := range recipes {
+			recipesByProduct[r.ProductID] = append(recipesByProduct[r.ProductID], r)
+			ingredientIDs = append(ingredientIDs, r.IngredientID)
+		}
+		if len(ingredientIDs) > 0 {
+			ingredients, err := s.productRepo.FindByIDs(companyID, uniqueTrimmedIDs(ingredientIDs))
+			if err != nil {
+				return nil, err
+			}
+			for _, p := range ingredients {
+				ingredientByID[p.ID] = p
+			}
+		}
+	}
+
+	deductions := []models.SaleStockDeduction{}
+	indexByProduct := map[string]int{}
+	add := func(productID string, qty float64, note string) {
+		if idx, ok := indexByProduct[productID]; ok {
+			deductions[idx].Qty = roundQty(deductions[idx].Qty + qty)
+			return
+		}
+		indexByProduct[productID] = len(deductions)
+		deductions = append(deductions, models.SaleStockDeduction{ProductID: productID, Qty: qty, Note: note})
+	}
+
+	for i := range details {
+		d := &details[i]
+		recipes := recipesByProduct[d.ProductID]
+		if len(recipes) == 0 {
+			add(d.ProductID, float64(d.Quantity), "sale stock out")
+			d.StockComponents = append(d.StockComponents, models.SaleStockDeduction{ProductID: d.ProductID, Qty: float64(d.Quantity)})
+			continue
+		}
+		for _, r := range recipes {
+			unitID := ""
+			if r.UnitID != nil {
+				unitID = *r.UnitID
+			}
+			qty, err := s.converter.ToStockUnit(companyID, ingredientByID[r.IngredientID], unitID, float64(d.Quantity)*r.Quantity)
+			if err != nil {
+				return nil, err
+			}
+			qty = roundQty(qty)
+			add(r.IngredientID, qty, "sale ingredient out")
+			d.StockComponents = append(d.StockComponents, models.SaleStockDeduction{ProductID: r.IngredientID, Qty: qty})
+		}
+	}
+	return deductions, nil
+}
+
+// resolveOrderType mengambil tipe order transaksi; nil jika transaksi tidak memakai tipe order.
+func (s *saleService) resolveOrderType(companyID string, orderTypeID string) (*models.OrderType, error) {
+	orderTypeID = strings.TrimSpace(orderTypeID)
+	if orderTypeID == "" {
+		return nil, nil
+	}
+	orderType, err := s.orderTypeRepo.FindByID(orderTypeID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSaleOrderTypeNotFound
+		}
+		return nil, err
+	}
+	if orderType.CompanyID != companyID {
+		return nil, ErrSaleOrderTypeNotFound
+	}
+	if !orderType.IsActive {
+		return nil, ErrSaleOrderTypeInactive
+	}
+	return &orderType, nil
+}
+
+// optionalID mengubah ID kosong menjadi nil agar tersimpan sebagai NULL.
+func optionalID(id string) *string {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil
+	}
+	return &id
+}
@@ -1,28 +1,119 @@
 package services
 
 import (
+	"errors"
 	"gowes/models"
 	"gowes/repositories"
 	"strings"
+	"time"
+)
+
+var (
+	ErrStockLedgerProductRequired = errors.New("product_id is required")
+	ErrStockLedgerOutletRequired  = errors.New("outlet_id is required")
+	ErrStockLedgerDateRange       = errors.New("date_from cannot be after date_to")
 )
 
 type StockMovementService interface {
-	ListStockMovements(companyID string, params models.PaginationParams, outletID string, productID string, movementType string, referenceType string) ([]models.StockMovement, int, error)
+	ListStockMovements(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.StockMovement, int, error)
+	ListStockMovementPage(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.StockMovement, models.CursorPage, error)
 	GetStockMovement(companyID string, id string) (models.StockMovement, error)
+	GetStockCard(companyID string, filter models.StockLedgerFilter) (models.StockCard, error)
+	GetStockSummary(companyID string, filter models.StockLedgerFilter) (models.StockSummaryReport, error)
 }
 
 type stockMovementService struct {
-	repo repositories.StockMovementRepository
+	repo        repositories.StockMovementRepository
+	companyRepo repositories.CompanyRepository
+}
+
+func NewStockMovementService(repo repositories.StockMovementRepository, companyRepo repositories.CompanyRepository) StockMovementService {
+	return &stockMovementService{repo: repo, companyRepo: companyRepo}
 }
 
-func NewStockMovementService(repo repositories.StockMovementRepository) StockMovementService {
-	return &stockMovementService{repo: repo}
+func (s *stockMovementService) ListStockMovements(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.StockMovement, int, error) {
+	filter, err := companyDateFilter(s.companyRepo, companyID, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.repo.FindAll(companyID, params, filter)
 }
 
-func (s *stockMovementService) ListStockMovements(companyID string, params models.PaginationParams, outletID string, productID string, movementType string, referenceType string) ([]models.StockMovement, int, error) {
-	return s.repo.FindAll(companyID, params, strings.TrimSpace(outletID), strings.TrimSpace(productID), strings.TrimSpace(movementType), strings.TrimSpace(referenceType))
+func (s *stockMovementService) ListStockMovementPage(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.StockMovement, models.CursorPage, error) {
+	filter, err := companyDateFilter(s.companyRepo, companyID, filter)
+	if err != nil {
+		return nil, models.CursorPage{}, err
+	}
+	return s.repo.FindPage(companyID, params, filter)
 }
 
 func (s *stockMovementService) GetStockMovement(companyID string, id string) (models.StockMovement, error) {
 	return s.repo.FindByID(companyID, strings.TrimSpace(id))
 }
+
+func (s *stockMovementService) GetStockCard(companyID string, filter models.StockLedgerFilter) (models.StockCard, error) {
+	filter, err := s.normalizeStockLedgerFilter(companyID, filter)
+	if err != nil {
+		return models.StockCard{}, err
+	}
+	if filter.ProductID == "" {
+		return models.StockCard{}, ErrStockLedgerProductRequired
+	}
+	if filter.OutletID == "" {
+		return models.StockCard{}, ErrStockLedgerOutletRequired
+	}
+	return s.repo.FindStockCard(companyID, filter)
+}
+
+func (s *stockMovementService) GetStockSummary(companyID string, filter models.StockLedgerFilter) (models.StockSummaryReport, error) {
+	filter, err := s.normalizeStockLedgerFilter(companyID, filter)
+	if err != nil {
+		return models.StockSummaryReport{}, err
+	}
+	items, err := s.repo.Summary(companyID, filter)
+	if err != nil {
+		return models.StockSummaryReport{}, err
+	}
+
+	report := models.StockSummaryReport{
+		DateFrom: filter.DateFrom,
+		DateTo:   filter.DateTo,
+		Items:    items,
+	}
+	for _, item := range items {
+		report.Totals.InQty = roundQty(report.Totals.InQty + item.InQty)
+		report.Totals.OutQty = roundQty(report.Totals.OutQty + item.OutQty)
+		report.Totals.AdjustmentQty = roundQty(report.Totals.AdjustmentQty + item.AdjustmentQty)
+		report.Totals.TransferIn = roundQty(report.Totals.TransferIn + item.TransferIn)
+		report.Totals.TransferOut = roundQty(report.Totals.TransferOut + item.TransferOut)
+	}
+	return report, nil
+}
+
+// normalizeStockLedgerFilter: batas hari memakai zona waktu company (sama seperti filter tanggal
+// daftar mutasi). Periode default adalah awal bulan berjalan s/d hari ini.
+func (s *stockMovementService) normalizeStockLedgerFilter(companyID string, filter models.StockLedgerFilter) (models.StockLedgerFilter, error) {
+	filter.ProductID = strings.TrimSpace(filter.ProductID)
+	filter.OutletID = strings.TrimSpace(filter.OutletID)
+
+	loc, err := companyLocation(s.companyRepo, companyID)
+	if err != nil {
+		return models.StockLedgerFilter{}, err
+	}
+
+	now := time.Now().In(loc)
+	if filter.DateTo.IsZero() {
+		filter.DateTo = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	} else {
+		filter.DateTo = time.Date(filter.DateTo.Year(), filter.DateTo.Month(), filter.DateTo.Day(), 0, 0, 0, 0, loc)
+	}
+	if filter.DateFrom.IsZero() {
+		filter.DateFrom = time.Date(filter.DateTo.Year(), filter.DateTo.Month(), 1, 0, 0, 0, 0, loc)
+	} else {
+		filter.DateFrom = time.Date(filter.DateFrom.Year(), filter.DateFrom.Month(), filter.DateFrom.Day(), 0, 0, 0, 0, loc)
+	}
+	if filter.DateFrom.After(filter.DateTo) {
+		return models.StockLedgerFilter{}, ErrStockLedgerDateRange
+	}
+	return filter, nil
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"errors"
+	"gowes/models"
+	"gowes/repositories"
+	"strings"
+	"time"
+)
+
+var (
+	ErrStockOpnameOutletRequired  = errors.New("outlet_id is required")
+	ErrStockOpnameOutletNotFound  = errors.New("outlet not found")
+	ErrStockOpnameItemsRequired   = errors.New("items are required")
+	ErrStockOpnameProductRequired = errors.New("product_id is required for every item")
+	ErrStockOpnameProductNotFound = errors.New("product not found")
+	ErrStockOpnameQtyInvalid      = errors.New("counted_qty cannot be negative")
+	ErrStockOpnameNoItems         = errors.New("stock opname has no items to post")
+	ErrStockOpnameReasonRequired  = errors.New("note (adjustment reason) is required")
+	ErrStockOpnameStatusInvalid   = errors.New("status must be draft or posted")
+	ErrStockOpnameNotDraft        = repositories.ErrStockOpnameNotDraft
+)
+
+type StockOpnameService interface {
+	ListStockOpnames(companyID string, params models.PaginationParams, filter models.StockOpnameFilter) ([]models.StockOpname, int, error)
+	GetStockOpname(id string, companyID string) (models.StockOpname, error)
+	CreateStockOpname(companyID string, userID string, input models.StockOpnameInput) (models.StockOpname, error)
+	DeleteStockOpname(id string, companyID string) error
+	SaveItems(id string, companyID string, input models.StockOpnameItemsInput) (models.StockOpname, error)
+	DeleteItem(id string, companyID string, productID string) (models.StockOpname, error)
+	PostStockOpname(id string, companyID string, userID string, input models.StockOpnamePostInput) (models.StockOpname, error)
+}
+
+type stockOpnameService struct {
+	repo        repositories.StockOpnameRepository
+	outletRepo  repositories.OutletRepository
+	productRepo repositories.ProductRepository
+	converter   UnitConverter
+}
+
+func NewStockOpnameService(repo repositories.StockOpnameRepository, outletRepo repositories.OutletRepository, productRepo repositories.ProductRepository, converter UnitConverter) StockOpnameService {
+	return &stockOpnameService{repo: repo, outletRepo: outletRepo, productRepo: productRepo, converter: converter}
+}
+
+func (s *stockOpnameService) ListStockOpnames(companyID string, params models.PaginationParams, filter models.StockOpnameFilter) ([]models.StockOpname, int, error) {
+	if filter.Status != "" &&
+		filter.Status != string(models.StockOpnameStatusDraft) &&
+		filter.Status != string(models.StockOpnameStatusPosted) {
+		return nil, 0, ErrStockOpnameStatusInvalid
+	}
+	return s.repo.FindAll(companyID, params, filter)
+}
+
+// GetStockOpname mengembalikan sesi opname; untuk draft, selisih dihitung terhadap stok saat ini (preview).
+func (s *stockOpnameService) GetStockOpname(id string, companyID string) (models.StockOpname, error) {
+	return s.repo.FindByID(id, companyID)
+}
+
+func (s *stockOpnameService) CreateStockOpname(companyID string, userID string, input models.StockOpnameInput) (models.StockOpname, error) {
+	outletID := strings.TrimSpace(input.OutletID)
+	if outletID == "" {
+		return models.StockOpname{}, ErrStockOpnameOutletRequired
+	}
+	outlets, err := s.outletRepo.FindByIDs(companyID, []string{outletID})
+	if err != nil {
+		return models.StockOpname{}, err
+	}
+	if len(outlets) == 0 {
+		return models.StockOpname{}, ErrStockOpnameOutletNotFound
+	}
+
+	now := time.Now().UTC()
+	return s.repo.Create(models.StockOpname{
+		CompanyID: companyID,
+		OutletID:  outletID,
+		UserID:    userID,
+		Status:    models.StockOpnameStatusDraft,
+		Note:      strings.TrimSpace(input.Note),
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+func (s *stockOpnameService) DeleteStockOpname(id string, companyID string) error {
+	return s.repo.Delete(id, companyID)
+}
+
+func (s *stockOpnameService) SaveItems(id string, companyID string, input models.StockOpnameItemsInput) (models.StockOpname, error) {
+	if len(input.Items) == 0 {
+		return models.StockOpname{}, ErrStockOpnameItemsRequired
+	}
+
+	items := make([]models.StockOpnameItem, 0, len(input.Items))
+	productIDs := make([]string, 0, len(input.Items))
+	for _, in := range input.Items {
+		productID := strings.TrimSpace(in.ProductID)
+		if productID == "" {
+			return models.StockOpname{}, ErrStockOpnameProductRequired
+		}
+		if in.CountedQty < 0 {
+			return models.StockOpname{}, ErrStockOpnameQtyInvalid
+		}
+		items = append(items, models.StockOpnameItem{
+			ProductID:  productID,
+			CountedQty: in.CountedQty,
+			Note:       strings.TrimSpace(in.Note),
+		})
+		productIDs = append(productIDs, productID)
+	}
+
+	productIDs = uniqueTrimmedIDs(productIDs)
+	products, err := s.productRepo.FindByIDs(companyID, productIDs)
+	if err != nil {
+		return models.StockOpname{}, err
+	}
+	if len(products) != len(productIDs) {
+		return models.StockOpname{}, ErrStockOpnameProductNotFound
+	}
+	productByID := make(map[string]models.Product, len(products))
+	for _, p := range products {
+		productByID[p.ID] = p
+	}
+
+	// Hasil hitung disimpan dalam satuan stok produk
+	for i, in := range input.Items {
+		qty, err := s.converter.ToStockQty(companyID, productByID[items[i].ProductID], in.UnitID, items[i].CountedQty)
+		if err != nil {
+			return models.StockOpname{}, err
+		}
+		items[i].CountedQty = qty
+	}
+
+	if err := s.repo.UpsertItems(id, companyID, items, time.Now().UTC()); err != nil {
+		return models.StockOpname{}, err
+	}
+	return s.repo.FindByID(id, companyID)
+}
+
+func (s *stockOpnameService) DeleteItem(id string, companyID string, productID string) (models.StockOpname, error) {
+	productID = strings.TrimSpace(productID)
+	if productID == "" {
+		return models.StockOpname{}, ErrStockOpnameProductRequired
+	}
+	if err := s.repo.DeleteItem(id, companyID, productID, time.Now().UTC()); err != nil {
+		return models.StockOpname{}, err
+	}
+	return s.repo.FindByID(id, companyID)
+}
+
+// PostStockOpname memposting sesi opname. Alasan penyesuaian wajib, boleh diambil dari
+// catatan sesi jika tidak dikirim ulang saat posting.
+func (s *stockOpnameService) PostStockOpname(id string, companyID string, userID string, input models.StockOpnamePostInput) (models.StockOpname, error) {
+	opname, err := s.repo.FindByID(id, companyID)
+	if err != nil {
+		return models.StockOpname{}, err
+	}
+	if opname.Status != models.StockOpnameStatusDraft {
+		return models.StockOpname{}, ErrStockOpnameNotDraft
+	}
+	if len(opname.Items) == 0 {
+		return models.StockOpname{}, ErrStockOpnameNoItems
+	}
+
+	note := strings.TrimSpace(input.Note)
+	if note == "" {
+		note = opname.Note
+	}
+	if note == "" {
+		return models.StockOpname{}, ErrStockOpnameReasonRequired
+	}
+
+	if err := s.repo.Post(id, companyID, userID, note, time.Now().UTC()); err != nil {
+		return models.StockOpname{}, err
+	}
+	return s.repo.FindByID(id, companyID)
+}
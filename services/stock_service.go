@@ -11,7 +11,7 @@ var ErrStockOutletRequired = errors.New("outlet_id is required")
 var ErrStockProductRequired = errors.New("product_id is required")
 
 type StockService interface {
-	ListStocks(companyID string, params models.PaginationParams, outletID string, productID string) ([]models.StockPerOutlet, int, error)
+	ListStocks(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.StockPerOutlet, int, error)
 	GetStock(companyID string, outletID string, productID string) (models.StockPerOutlet, error)
 }
 
@@ -23,8 +23,8 @@ func NewStockService(repo repositories.StockRepository) StockService {
 	return &stockService{repo: repo}
 }
 
-func (s *stockService) ListStocks(companyID string, params models.PaginationParams, outletID string, productID string) ([]models.StockPerOutlet, int, error) {
-	return s.repo.FindAll(companyID, params, outletID, productID)
+func (s *stockService) ListStocks(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.StockPerOutlet, int, error) {
+	return s.repo.FindAll(companyID, params, filter)
 }
 
 func (s *stockService) GetStock(companyID string, outletID string, productID string) (models.StockPerOutlet, error) {
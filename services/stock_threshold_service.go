@@ -0,0 +1,209 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"gowes/models"
+	"gowes/repositories"
+	"strings"
+	"time"
+)
+
+var (
+	ErrStockThresholdProductRequired  = errors.New("product_id is required")
+	ErrStockThresholdProductNotFound  = errors.New("product not found")
+	ErrStockThresholdOutletRequired   = errors.New("outlet_id is required")
+	ErrStockThresholdOutletNotFound   = errors.New("outlet not found")
+	ErrStockThresholdQtyInvalid       = errors.New("min_qty and max_qty cannot be negative")
+	ErrStockThresholdMaxBelowMin      = errors.New("max_qty must be greater than or equal to min_qty")
+	ErrStockThresholdSupplierNotFound = errors.New("preferred supplier not found")
+	ErrStockThresholdSupplierInactive = errors.New("preferred supplier is inactive")
+	ErrStockThresholdDaysInvalid      = errors.New("days and cover_days must be between 1 and 365")
+)
+
+const (
+	defaultUsageDays = 30
+	defaultCoverDays = 7
+	maxUsageDays     = 365
+)
+
+type StockThresholdService interface {
+	ListThresholds(companyID string, params models.PaginationParams, filter models.StockThresholdFilter) ([]models.StockThreshold, int, error)
+	GetThreshold(id string, companyID string) (models.StockThreshold, error)
+	SaveThreshold(companyID string, input models.StockThresholdInput) (models.StockThreshold, error)
+	DeleteThreshold(id string, companyID string) error
+	ListLowStock(companyID string, filter models.LowStockFilter) ([]models.LowStockItem, error)
+	ReorderSuggestions(companyID string, filter models.LowStockFilter) ([]models.ReorderSuggestion, error)
+}
+
+type stockThresholdService struct {
+	repo         repositories.StockThresholdRepository
+	productRepo  repositories.ProductRepository
+	outletRepo   repositories.OutletRepository
+	supplierRepo repositories.SupplierRepository
+}
+
+func NewStockThresholdService(repo repositories.StockThresholdRepository, productRepo repositories.ProductRepository, outletRepo repositories.OutletRepository, supplierRepo repositories.SupplierRepository) StockThresholdService {
+	return &stockThresholdService{repo: repo, productRepo: productRepo, outletRepo: outletRepo, supplierRepo: supplierRepo}
+}
+
+func (s *stockThresholdService) ListThresholds(companyID string, params models.PaginationParams, filter models.StockThresholdFilter) ([]models.StockThreshold, int, error) {
+	return s.repo.FindAll(companyID, params, filter)
+}
+
+func (s *stockThresholdService) GetThreshold(id string, companyID string) (models.StockThreshold, error) {
+	return s.repo.FindByID(id, companyID)
+}
+
+func (s *stockThresholdService) SaveThreshold(companyID string, input models.StockThresholdInput) (models.StockThreshold, error) {
+	productID := strings.TrimSpace(input.ProductID)
+	if productID == "" {
+		return models.StockThreshold{}, ErrStockThresholdProductRequired
+	}
+	outletID := strings.TrimSpace(input.OutletID)
+	if outletID == "" {
+		return models.StockThreshold{}, ErrStockThresholdOutletRequired
+	}
+	if input.MinQty < 0 || input.MaxQty < 0 {
+		return models.StockThreshold{}, ErrStockThresholdQtyInvalid
+	}
+	// max_qty 0 berarti tidak diatur; saran pembelian cukup menutup min_qty dan pemakaian
+	if input.MaxQty > 0 && input.MaxQty < input.MinQty {
+		return models.StockThreshold{}, ErrStockThresholdMaxBelowMin
+	}
+
+	products, err := s.productRepo.FindByIDs(companyID, []string{productID})
+	if err != nil {
+		return models.StockThreshold{}, err
+	}
+	if len(products) == 0 {
+		return models.StockThreshold{}, ErrStockThresholdProductNotFound
+	}
+	outlets, err := s.outletRepo.FindByIDs(companyID, []string{outletID})
+	if err != nil {
+		return models.StockThreshold{}, err
+	}
+	if len(outlets) == 0 {
+		return models.StockThreshold{}, ErrStockThresholdOutletNotFound
+	}
+
+	supplierID := optionalID(input.PreferredSupplierID)
+	if supplierID != nil {
+		supplier, err := s.supplierRepo.FindByID(*supplierID, companyID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return models.StockThreshold{}, ErrStockThresholdSupplierNotFound
+			}
+			return models.StockThreshold{}, err
+		}
+		if !supplier.IsActive {
+			return models.StockThreshold{}, ErrStockThresholdSupplierInactive
+		}
+	}
+
+	now := time.Now().UTC()
+	return s.repo.Upsert(models.StockThreshold{
+		CompanyID:           companyID,
+		ProductID:           productID,
+		OutletID:            outletID,
+		MinQty:              roundQty(input.MinQty),
+		MaxQty:              roundQty(input.MaxQty),
+		PreferredSupplierID: supplierID,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	})
+}
+
+func (s *stockThresholdService) DeleteThreshold(id string, companyID string) error {
+	return s.repo.Delete(id, companyID)
+}
+
+func (s *stockThresholdService) ListLowStock(companyID string, filter models.LowStockFilter) ([]models.LowStockItem, error) {
+	filter, err := normalizeLowStockFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.FindLowStock(companyID, filter.OutletID, usageSince(filter.Days))
+}
+
+// ReorderSuggestions menyusun draft purchase order untuk stok yang menipis, dikelompokkan per
+// supplier dan outlet. Qty yang disarankan mengisi stok sampai max_qty, atau lebih tinggi jika
+// rata-rata pemakaian harian selama cover_days hari ke depan melebihi max_qty dikurangi min_qty.
+func (s *stockThresholdService) ReorderSuggestions(companyID string, filter models.LowStockFilter) ([]models.ReorderSuggestion, error) {
+	filter, err := normalizeLowStockFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.repo.FindLowStock(companyID, filter.OutletID, usageSince(filter.Days))
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := []models.ReorderSuggestion{}
+	indexByGroup := map[string]int{}
+	for _, item := range items {
+		avgDailyUsage := roundQty(item.UsageQty / float64(filter.Days))
+		target := item.MinQty + avgDailyUsage*float64(filter.CoverDays)
+		if item.MaxQty > target {
+			target = item.MaxQty
+		}
+		quantity := roundQty(target - item.Qty)
+		if quantity <= 0 {
+			continue
+		}
+
+		supplierKey := ""
+		if item.SupplierID != nil {
+			supplierKey = *item.SupplierID
+		}
+		groupKey := supplierKey + "|" + item.OutletID
+		idx, ok := indexByGroup[groupKey]
+		if !ok {
+			idx = len(suggestions)
+			indexByGroup[groupKey] = idx
+			suggestions = append(suggestions, models.ReorderSuggestion{
+				SupplierID:   item.SupplierID,
+				SupplierName: item.SupplierName,
+				OutletID:     item.OutletID,
+				OutletName:   item.OutletName,
+				Items:        []models.ReorderSuggestionItem{},
+			})
+		}
+
+		total := roundCurrency(quantity * item.UnitCost)
+		suggestions[idx].Items = append(suggestions[idx].Items, models.ReorderSuggestionItem{
+			ProductID:     item.ProductID,
+			ProductName:   item.ProductName,
+			ProductSKU:    item.ProductSKU,
+			Unit:          item.Unit,
+			Qty:           item.Qty,
+			MinQty:        item.MinQty,
+			MaxQty:        item.MaxQty,
+			AvgDailyUsage: avgDailyUsage,
+			Quantity:      quantity,
+			Price:         item.UnitCost,
+			Total:         total,
+		})
+		suggestions[idx].Subtotal = roundCurrency(suggestions[idx].Subtotal + total)
+	}
+	return suggestions, nil
+}
+
+// normalizeLowStockFilter mengisi periode default dan membatasi panjang periode.
+func normalizeLowStockFilter(filter models.LowStockFilter) (models.LowStockFilter, error) {
+	filter.OutletID = strings.TrimSpace(filter.OutletID)
+	if filter.Days == 0 {
+		filter.Days = defaultUsageDays
+	}
+	if filter.CoverDays == 0 {
+		filter.CoverDays = defaultCoverDays
+	}
+	if filter.Days < 1 || filter.Days > maxUsageDays || filter.CoverDays < 1 || filter.CoverDays > maxUsageDays {
+		return models.LowStockFilter{}, ErrStockThresholdDaysInvalid
+	}
+	return filter, nil
+}
+
+func usageSince(days int) time.Time {
+	return time.Now().UTC().AddDate(0, 0, -days)
+}
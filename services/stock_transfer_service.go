@@ -0,0 +1,245 @@
+package services
+
+import (
+	"errors"
+	"gowes/models"
+	"gowes/repositories"
+	"strings"
+	"time"
+)
+
+var (
+	ErrStockTransferSourceRequired      = errors.New("source_outlet_id is required")
+	ErrStockTransferDestinationRequired = errors.New("destination_outlet_id is required")
+	ErrStockTransferSameOutlet          = errors.New("source and destination outlet must be different")
+	ErrStockTransferOutletNotFound      = errors.New("outlet not found")
+	ErrStockTransferItemsRequired       = errors.New("items are required")
+	ErrStockTransferProductRequired     = errors.New("product_id is required for every item")
+	ErrStockTransferProductNotFound     = errors.New("product not found")
+	ErrStockTransferQtyInvalid          = errors.New("qty must be greater than zero")
+	ErrStockTransferReceiveQtyInvalid   = errors.New("received_qty cannot be negative")
+	ErrStockTransferReceiveEmpty        = errors.New("items are required unless completing the transfer")
+	ErrStockTransferStatusInvalid       = errors.New("status must be draft, sent, partially_received or received")
+	ErrStockTransferNotDraft            = repositories.ErrStockTransferNotDraft
+	ErrStockTransferNotInTransit        = repositories.ErrStockTransferNotInTransit
+	ErrStockTransferInsufficientStock   = repositories.ErrStockTransferInsufficientStock
+	ErrStockTransferItemNotFound        = repositories.ErrStockTransferItemNotFound
+	ErrStockTransferReceiveExceeds      = repositories.ErrStockTransferReceiveExceeds
+	ErrStockTransferDiscrepancyNote     = repositories.ErrStockTransferDiscrepancyNote
+)
+
+type StockTransferService interface {
+	ListStockTransfers(companyID string, params models.PaginationParams, filter models.StockTransferFilter) ([]models.StockTransfer, int, error)
+	GetStockTransfer(id string, companyID string) (models.StockTransfer, error)
+	CreateStockTransfer(companyID string, userID string, input models.StockTransferInput) (models.StockTransfer, error)
+	UpdateStockTransfer(id string, companyID string, input models.StockTransferInput) (models.StockTransfer, error)
+	DeleteStockTransfer(id string, companyID string) error
+	SendStockTransfer(id string, companyID string, userID string) (models.StockTransfer, error)
+	ReceiveStockTransfer(id string, companyID string, userID string, input models.StockTransferReceiveInput) (models.StockTransfer, error)
+}
+
+type stockTransferService struct {
+	repo        repositories.StockTransferRepository
+	outletRepo  repositories.OutletRepository
+	productRepo repositories.ProductRepository
+	converter   UnitConverter
+}
+
+func NewStockTransferService(repo repositories.StockTransferRepository, outletRepo repositories.OutletRepository, productRepo repositories.ProductRepository, converter UnitConverter) StockTransferService {
+	return &stockTransferService{repo: repo, outletRepo: outletRepo, productRepo: productRepo, converter: converter}
+}
+
+func (s *stockTransferService) ListStockTransfers(companyID string, params models.PaginationParams, filter models.StockTransferFilter) ([]models.StockTransfer, int, error) {
+	switch models.StockTransferStatus(filter.Status) {
+	case "", models.StockTransferStatusDraft, models.StockTransferStatusSent,
+		models.StockTransferStatusPartiallyReceived, models.StockTransferStatusReceived:
+	default:
+		return nil, 0, ErrStockTransferStatusInvalid
+	}
+	return s.repo.FindAll(companyID, params, filter)
+}
+
+func (s *stockTransferService) GetStockTransfer(id string, companyID string) (models.StockTransfer, error) {
+	return s.repo.FindByID(id, companyID)
+}
+
+func (s *stockTransferService) CreateStockTransfer(companyID string, userID string, input models.StockTransferInput) (models.StockTransfer, error) {
+	transfer, err := s.buildTransfer(companyID, input)
+	if err != nil {
+		return models.StockTransfer{}, err
+	}
+
+	now := time.Now().UTC()
+	transfer.CompanyID = companyID
+	transfer.UserID = userID
+	transfer.Status = models.StockTransferStatusDraft
+	transfer.CreatedAt = now
+	transfer.UpdatedAt = now
+
+	return s.repo.Create(transfer)
+}
+
+func (s *stockTransferService) UpdateStockTransfer(id string, companyID string, input models.StockTransferInput) (models.StockTransfer, error) {
+	existing, err := s.repo.FindByID(id, companyID)
+	if err != nil {
+		return models.StockTransfer{}, err
+	}
+	if existing.Status != models.StockTransferStatusDraft {
+		return models.StockTransfer{}, ErrStockTransferNotDraft
+	}
+
+	transfer, err := s.buildTransfer(companyID, input)
+	if err != nil {
+		return models.StockTransfer{}, err
+	}
+	transfer.ID = existing.ID
+	transfer.CompanyID = companyID
+	transfer.UpdatedAt = time.Now().UTC()
+
+	return s.repo.Update(transfer)
+}
+
+func (s *stockTransferService) DeleteStockTransfer(id string, companyID string) error {
+	return s.repo.Delete(id, companyID)
+}
+
+// SendStockTransfer mengirim barang: stok outlet asal berkurang dan transfer berstatus sent.
+func (s *stockTransferService) SendStockTransfer(id string, companyID string, userID string) (models.StockTransfer, error) {
+	if err := s.repo.Send(id, companyID, userID, time.Now().UTC()); err != nil {
+		return models.StockTransfer{}, err
+	}
+	return s.repo.FindByID(id, companyID)
+}
+
+// ReceiveStockTransfer mencatat barang yang diterima outlet tujuan (boleh parsial).
+func (s *stockTransferService) ReceiveStockTransfer(id string, companyID string, userID string, input models.StockTransferReceiveInput) (models.StockTransfer, error) {
+	if len(input.Items) == 0 && !input.Complete {
+		return models.StockTransfer{}, ErrStockTransferReceiveEmpty
+	}
+
+	productIDs := make([]string, 0, len(input.Items))
+	for _, in := range input.Items {
+		productID := strings.TrimSpace(in.ProductID)
+		if productID == "" {
+			return models.StockTransfer{}, ErrStockTransferProductRequired
+		}
+		if in.ReceivedQty < 0 {
+			return models.StockTransfer{}, ErrStockTransferReceiveQtyInvalid
+		}
+		productIDs = append(productIDs, productID)
+	}
+
+	productByID, err := s.findProducts(companyID, productIDs)
+	if err != nil {
+		return models.StockTransfer{}, err
+	}
+
+	receipts := make([]models.StockTransferItem, 0, len(input.Items))
+	for _, in := range input.Items {
+		productID := strings.TrimSpace(in.ProductID)
+		product, ok := productByID[productID]
+		if !ok {
+			return models.StockTransfer{}, ErrStockTransferItemNotFound
+		}
+		qty, err := s.converter.ToStockQty(companyID, product, in.UnitID, in.ReceivedQty)
+		if err != nil {
+			return models.StockTransfer{}, err
+		}
+		receipts = append(receipts, models.StockTransferItem{
+			ProductID:       productID,
+			ReceivedQty:     qty,
+			DiscrepancyNote: strings.TrimSpace(in.Note),
+		})
+	}
+
+	err = s.repo.Receive(id, companyID, userID, receipts, strings.TrimSpace(input.Note), input.Complete, time.Now().UTC())
+	if err != nil {
+		return models.StockTransfer{}, err
+	}
+	return s.repo.FindByID(id, companyID)
+}
+
+// buildTransfer memvalidasi payload dan menggabungkan baris dengan produk yang sama.
+func (s *stockTransferService) buildTransfer(companyID string, input models.StockTransferInput) (models.StockTransfer, error) {
+	sourceID := strings.TrimSpace(input.SourceOutletID)
+	destinationID := strings.TrimSpace(input.DestinationOutletID)
+	if sourceID == "" {
+		return models.StockTransfer{}, ErrStockTransferSourceRequired
+	}
+	if destinationID == "" {
+		return models.StockTransfer{}, ErrStockTransferDestinationRequired
+	}
+	if sourceID == destinationID {
+		return models.StockTransfer{}, ErrStockTransferSameOutlet
+	}
+	if len(input.Items) == 0 {
+		return models.StockTransfer{}, ErrStockTransferItemsRequired
+	}
+
+	productIDs := make([]string, 0, len(input.Items))
+	for _, in := range input.Items {
+		productID := strings.TrimSpace(in.ProductID)
+		if productID == "" {
+			return models.StockTransfer{}, ErrStockTransferProductRequired
+		}
+		if in.Qty <= 0 {
+			return models.StockTransfer{}, ErrStockTransferQtyInvalid
+		}
+		productIDs = append(productIDs, productID)
+	}
+
+	outlets, err := s.outletRepo.FindByIDs(companyID, []string{sourceID, destinationID})
+	if err != nil {
+		return models.StockTransfer{}, err
+	}
+	if len(outlets) != 2 {
+		return models.StockTransfer{}, ErrStockTransferOutletNotFound
+	}
+
+	productByID, err := s.findProducts(companyID, productIDs)
+	if err != nil {
+		return models.StockTransfer{}, err
+	}
+
+	items := []models.StockTransferItem{}
+	indexByProduct := map[string]int{}
+	for _, in := range input.Items {
+		productID := strings.TrimSpace(in.ProductID)
+		product, ok := productByID[productID]
+		if !ok {
+			return models.StockTransfer{}, ErrStockTransferProductNotFound
+		}
+		qty, err := s.converter.ToStockQty(companyID, product, in.UnitID, in.Qty)
+		if err != nil {
+			return models.StockTransfer{}, err
+		}
+		if idx, ok := indexByProduct[productID]; ok {
+			items[idx].Qty += qty
+			continue
+		}
+		indexByProduct[productID] = len(items)
+		items = append(items, models.StockTransferItem{ProductID: productID, Qty: qty})
+	}
+
+	return models.StockTransfer{
+		SourceOutletID:      sourceID,
+		DestinationOutletID: destinationID,
+		Note:                strings.TrimSpace(input.Note),
+		Items:               items,
+	}, nil
+}
+
+func (s *stockTransferService) findProducts(companyID string, productIDs []string) (map[string]models.Product, error) {
+	productByID := map[string]models.Product{}
+	if len(productIDs) == 0 {
+		return productByID, nil
+	}
+	products, err := s.productRepo.FindByIDs(companyID, uniqueTrimmedIDs(productIDs))
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range products {
+		productByID[p.ID] = p
+	}
+	return productByID, nil
+}
@@ -9,6 +9,7 @@ import (
 )
 
 var ErrSupplierNameRequired = errors.New("supplier name is required")
+var ErrSupplierPaymentTermInvalid = errors.New("payment_term_days cannot be negative")
 
 type SupplierService interface {
 	ListSuppliers(companyID string, params models.PaginationParams) ([]models.Supplier, int, error)
@@ -38,6 +39,9 @@ func (s *supplierService) CreateSupplier(companyID string, userID string, in mod
 	if strings.TrimSpace(in.Name) == "" {
 		return models.Supplier{}, ErrSupplierNameRequired
 	}
+	if in.PaymentTermDays < 0 {
+		return models.Supplier{}, ErrSupplierPaymentTermInvalid
+	}
 
 	now := time.Now().UTC()
 	isActive := true
@@ -46,18 +50,19 @@ func (s *supplierService) CreateSupplier(companyID string, userID string, in mod
 	}
 
 	supplier := models.Supplier{
-		Name:        strings.TrimSpace(in.Name),
-		CompanyID:   companyID,
-		Address:     strings.TrimSpace(in.Address),
-		Phone:       strings.TrimSpace(in.Phone),
-		Email:       strings.TrimSpace(in.Email),
-		CompanyName: strings.TrimSpace(in.CompanyName),
-		TaxNumber:   strings.TrimSpace(in.TaxNumber),
-		IsActive:    isActive,
-		CreatedBy:   userID,
-		CreatedAt:   now,
-		UpdatedAt:   now,
-		UpdatedBy:   userID,
+		Name:            strings.TrimSpace(in.Name),
+		CompanyID:       companyID,
+		Address:         strings.TrimSpace(in.Address),
+		Phone:           strings.TrimSpace(in.Phone),
+		Email:           strings.TrimSpace(in.Email),
+		CompanyName:     strings.TrimSpace(in.CompanyName),
+		TaxNumber:       strings.TrimSpace(in.TaxNumber),
+		PaymentTermDays: in.PaymentTermDays,
+		IsActive:        isActive,
+		CreatedBy:       userID,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		UpdatedBy:       userID,
 	}
 
 	return s.repo.Create(supplier)
@@ -67,6 +72,9 @@ func (s *supplierService) UpdateSupplier(id string, companyID string, userID str
 	if strings.TrimSpace(in.Name) == "" {
 		return models.Supplier{}, ErrSupplierNameRequired
 	}
+	if in.PaymentTermDays < 0 {
+		return models.Supplier{}, ErrSupplierPaymentTermInvalid
+	}
 
 	supplier, err := s.repo.FindByID(id, companyID)
 	if err != nil {
@@ -79,6 +87,7 @@ func (s *supplierService) UpdateSupplier(id string, companyID string, userID str
 	supplier.Email = strings.TrimSpace(in.Email)
 	supplier.CompanyName = strings.TrimSpace(in.CompanyName)
 	supplier.TaxNumber = strings.TrimSpace(in.TaxNumber)
+	supplier.PaymentTermDays = in.PaymentTermDays
 	if in.IsActive != nil {
 		supplier.IsActive = *in.IsActive
 	}
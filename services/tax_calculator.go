@@ -0,0 +1,168 @@
+package services
+
+import (
+	"gowes/models"
+	"sort"
+)
+
+// CalculateTaxes menghitung pajak untuk baris-baris transaksi di sebuah outlet.
+//
+// Aturan yang dipakai:
+//   - Hanya pajak aktif yang berlaku di outlet (outlet kosong = semua outlet) dan cocok
+//     dengan target baris (all / product / category) yang dihitung.
+//   - Pajak inklusif sudah termasuk di Amount, sehingga dasar pengenaan pajak (DPP)
+//     baris = Amount / (1 + total tarif inklusif).
+//   - Pajak eksklusif dihitung dari DPP sesuai Sequence. Pajak compound dihitung dari
+//     DPP + pajak eksklusif yang sudah dihitung sebelumnya pada baris yang sama.
+//   - TaxValue hanya berisi pajak eksklusif, yaitu nilai yang ditambahkan ke tagihan.
+func CalculateTaxes(taxes []models.Tax, outletID string, lines []models.TaxLine) models.TaxCalculation {
+	sorted := make([]models.Tax, 0, len(taxes))
+	for _, t := range taxes {
+		if t.IsActive && taxMatchesOutlet(t, outletID) {
+			sorted = append(sorted, t)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Sequence < sorted[j].Sequence
+	})
+
+	breakdown := make([]models.TaxBreakdown, len(sorted))
+	used := make([]bool, len(sorted))
+	for i, t := range sorted {
+		taxID := t.ID
+		breakdown[i] = models.TaxBreakdown{
+			TaxID:       &taxID,
+			Name:        t.Name,
+			Type:        t.Type,
+			Rate:        t.Rate,
+			IsInclusive: t.IsInclusive,
+		}
+	}
+
+	for _, line := range lines {
+		if line.Amount <= 0 {
+			continue
+		}
+
+		inclusiveRate := 0.0
+		for _, t := range sorted {
+			if t.IsInclusive && taxMatchesLine(t, line) {
+				inclusiveRate += t.Rate
+			}
+		}
+		base := line.Amount / (1 + inclusiveRate/100)
+
+		exclusiveSoFar := 0.0
+		for i, t := range sorted {
+			if !taxMatchesLine(t, line) {
+				continue
+			}
+			used[i] = true
+
+			taxable := base
+			if !t.IsInclusive && t.IsCompound {
+				taxable += exclusiveSoFar
+			}
+			amount := taxable * t.Rate / 100
+			if !t.IsInclusive {
+				exclusiveSoFar += amount
+			}
+
+			breakdown[i].TaxableAmount += taxable
+			breakdown[i].Amount += amount
+		}
+	}
+
+	result := models.TaxCalculation{Breakdown: []models.TaxBreakdown{}}
+	for i, b := range breakdown {
+		if !used[i] {
+			continue
+		}
+		b.TaxableAmount = roundCurrency(b.TaxableAmount)
+		b.Amount = roundCurrency(b.Amount)
+		if b.IsInclusive {
+			result.InclusiveTaxValue += b.Amount
+		} else {
+			result.TaxValue += b.Amount
+		}
+		result.Breakdown = append(result.Breakdown, b)
+	}
+	result.TaxValue = roundCurrency(result.TaxValue)
+	result.InclusiveTaxValue = roundCurrency(result.InclusiveTaxValue)
+	return result
+}
+
+// ApplyBillDiscount membagi diskon struk ke setiap baris secara proporsional
+// terhadap nilai baris, sehingga pajak dihitung dari nilai setelah diskon.
+func ApplyBillDiscount(lines []models.TaxLine, discount float64) []models.TaxLine {
+	result := make([]models.TaxLine, len(lines))
+	copy(result, lines)
+	if discount <= 0 {
+		return result
+	}
+
+	total := 0.0
+	for _, line := range lines {
+		total += line.Amount
+	}
+	if total <= 0 {
+		return result
+	}
+	if discount > total {
+		discount = total
+	}
+
+	// Sisa pembulatan dibebankan ke baris terakhir yang bernilai
+	remaining := discount
+	last := -1
+	for i, line := range lines {
+		if line.Amount > 0 {
+			last = i
+		}
+	}
+	for i, line := range lines {
+		if line.Amount <= 0 {
+			continue
+		}
+		share := roundCurrency(discount * line.Amount / total)
+		if i == last {
+			share = remaining
+		}
+		remaining -= share
+		result[i].Amount = line.Amount - share
+	}
+	return result
+}
+
+func taxMatchesOutlet(t models.Tax, outletID string) bool {
+	if len(t.OutletIDs) == 0 {
+		return true
+	}
+	for _, id := range t.OutletIDs {
+		if id == outletID {
+			return true
+		}
+	}
+	return false
+}
+
+func taxMatchesLine(t models.Tax, line models.TaxLine) bool {
+	switch t.TargetType {
+	case models.TaxTargetProduct:
+		for _, id := range t.ProductIDs {
+			if id == line.ProductID {
+				return true
+			}
+		}
+		return false
+	case models.TaxTargetCategory:
+		for _, id := range t.CategoryIDs {
+			if id == line.CategoryID {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"gowes/models"
+	"math"
+	"testing"
+)
+
+func TestCalculateTaxes(t *testing.T) {
+	ppnInclusive := models.Tax{ID: "ppn", Name: "PPN", Rate: 11, TargetType: models.TaxTargetAll, IsInclusive: true, Sequence: 1, IsActive: true}
+	service := models.Tax{ID: "service", Name: "Service", Rate: 5, TargetType: models.TaxTargetAll, Sequence: 2, IsActive: true}
+	pb1Compound := models.Tax{ID: "pb1", Name: "PB1", Rate: 10, TargetType: models.TaxTargetAll, Sequence: 3, IsCompound: true, IsActive: true}
+
+	tests := []struct {
+		name          string
+		taxes         []models.Tax
+		outletID      string
+		lines         []models.TaxLine
+		wantTax       float64
+		wantInclusive float64
+		wantBreakdown map[string]float64
+	}{
+		{
+			name:          "exclusive tax",
+			taxes:         []models.Tax{{ID: "pb1", Rate: 10, TargetType: models.TaxTargetAll, IsActive: true}},
+			lines:         []models.TaxLine{{Amount: 10000}, {Amount: 5000}},
+			wantTax:       1500,
+			wantBreakdown: map[string]float64{"pb1": 1500},
+		},
+		{
+			name:          "inclusive tax is extracted from amount",
+			taxes:         []models.Tax{ppnInclusive},
+			lines:         []models.TaxLine{{Amount: 11100}},
+			wantInclusive: 1100,
+			wantBreakdown: map[string]float64{"ppn": 1100},
+		},
+		{
+			name:          "compound tax includes previous exclusive taxes",
+			taxes:         []models.Tax{pb1Compound, service},
+			lines:         []models.TaxLine{{Amount: 10000}},
+			wantTax:       1550,
+			wantBreakdown: map[string]float64{"service": 500, "pb1": 1050},
+		},
+		{
+			name:          "compound tax on inclusive base",
+			taxes:         []models.Tax{pb1Compound, service, ppnInclusive},
+			lines:         []models.TaxLine{{Amount: 11100}},
+			wantTax:       1550,
+			wantInclusive: 1100,
+			wantBreakdown: map[string]float64{"ppn": 1100, "service": 500, "pb1": 1050},
+		},
+		{
+			name: "inactive tax and other outlet are skipped",
+			taxes: []models.Tax{
+				{ID: "off", Rate: 10, TargetType: models.TaxTargetAll},
+				{ID: "other", Rate: 10, TargetType: models.TaxTargetAll, IsActive: true, OutletIDs: []string{"outlet-2"}},
+				{ID: "here", Rate: 10, TargetType: models.TaxTargetAll, IsActive: true, OutletIDs: []string{"outlet-1"}},
+			},
+			outletID:      "outlet-1",
+			lines:         []models.TaxLine{{Amount: 10000}},
+			wantTax:       1000,
+			wantBreakdown: map[string]float64{"here": 1000},
+		},
+		{
+			name: "product and category targets",
+			taxes: []models.Tax{
+				{ID: "prod", Rate: 10, TargetType: models.TaxTargetProduct, IsActive: true, ProductIDs: []string{"prod-1"}},
+				{ID: "cat", Rate: 5, TargetType: models.TaxTargetCategory, IsActive: true, CategoryIDs: []string{"cat-2"}},
+			},
+			lines: []models.TaxLine{
+				{ProductID: "prod-1", CategoryID: "cat-1", Amount: 10000},
+				{ProductID: "prod-2", CategoryID: "cat-2", Amount: 4000},
+			},
+			wantTax:       1200,
+			wantBreakdown: map[string]float64{"prod": 1000, "cat": 200},
+		},
+		{
+			name:          "zero amount line is ignored",
+			taxes:         []models.Tax{{ID: "pb1", Rate: 10, TargetType: models.TaxTargetAll, IsActive: true}},
+			lines:         []models.TaxLine{{Amount: 0}},
+			wantBreakdown: map[string]float64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CalculateTaxes(tt.taxes, tt.outletID, tt.lines)
+
+			if got.TaxValue != tt.wantTax {
+				t.Errorf("TaxValue = %v, want %v", got.TaxValue, tt.wantTax)
+			}
+			if got.InclusiveTaxValue != tt.wantInclusive {
+				t.Errorf("InclusiveTaxValue = %v, want %v", got.InclusiveTaxValue, tt.wantInclusive)
+			}
+			if len(got.Breakdown) != len(tt.wantBreakdown) {
+				t.Fatalf("len(Breakdown) = %d, want %d", len(got.Breakdown), len(tt.wantBreakdown))
+			}
+			for _, b := range got.Breakdown {
+				want, ok := tt.wantBreakdown[*b.TaxID]
+				if !ok {
+					t.Errorf("unexpected tax %s in breakdown", *b.TaxID)
+					continue
+				}
+				if b.Amount != want {
+					t.Errorf("tax %s amount = %v, want %v", *b.TaxID, b.Amount, want)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyBillDiscount(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []models.TaxLine
+		discount float64
+		want     []float64
+	}{
+		{"no discount", []models.TaxLine{{Amount: 10000}, {Amount: 5000}}, 0, []float64{10000, 5000}},
+		{"proportional split", []models.TaxLine{{Amount: 10000}, {Amount: 5000}}, 3000, []float64{8000, 4000}},
+		{"rounding remainder on last line", []models.TaxLine{{Amount: 100}, {Amount: 100}, {Amount: 100}}, 100, []float64{66.67, 66.67, 66.66}},
+		{"zero amount line is untouched", []models.TaxLine{{Amount: 10000}, {Amount: 0}}, 1000, []float64{9000, 0}},
+		{"discount larger than total", []models.TaxLine{{Amount: 10000}, {Amount: 5000}}, 20000, []float64{0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ApplyBillDiscount(tt.lines, tt.discount)
+			if len(got) != len(tt.want) {
+				t.Fatalf("len = %d, want %d", len(got), len(tt.want))
+			}
+			for i, line := range got {
+				if math.Abs(line.Amount-tt.want[i]) > 1e-9 {
+					t.Errorf("line %d amount = %v, want %v", i, line.Amount, tt.want[i])
+				}
+			}
+		})
+	}
+}
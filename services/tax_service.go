@@ -1,6 +1,7 @@
 package services
 
 import (
+	"database/sql"
 	"errors"
 	"gowes/models"
 	"gowes/repositories"
@@ -9,24 +10,32 @@ import (
 )
 
 var (
-	ErrTaxNameRequired = errors.New("tax name is required")
-	ErrTaxRateInvalid  = errors.New("tax rate must be between 0 and 100")
+	ErrTaxNameRequired     = errors.New("tax name is required")
+	ErrTaxRateInvalid      = errors.New("tax rate must be between 0 and 100")
+	ErrTaxTypeInvalid      = errors.New("invalid tax type, must be one of: vat, service_charge")
+	ErrTaxTargetInvalid    = errors.New("invalid target_type, must be one of: all, product, category")
+	ErrTaxOutletNotFound   = errors.New("one or more outlet_ids not found")
+	ErrTaxProductNotFound  = errors.New("one or more product_ids not found")
+	ErrTaxCategoryNotFound = errors.New("one or more category_ids not found")
 )
 
 type TaxService interface {
 	ListTaxes(companyID string, params models.PaginationParams) ([]models.Tax, int, error)
 	GetTax(id string) (models.Tax, error)
 	CreateTax(companyID string, in models.TaxInput) (models.Tax, error)
-	UpdateTax(id string, in models.TaxInput) (models.Tax, error)
+	UpdateTax(id string, companyID string, in models.TaxInput) (models.Tax, error)
 	DeleteTax(id string) error
 }
 
 type taxService struct {
-	repo repositories.TaxRepository
+	repo         repositories.TaxRepository
+	outletRepo   repositories.OutletRepository
+	productRepo  repositories.ProductRepository
+	categoryRepo repositories.CategoryRepository
 }
 
-func NewTaxService(repo repositories.TaxRepository) TaxService {
-	return &taxService{repo: repo}
+func NewTaxService(repo repositories.TaxRepository, outletRepo repositories.OutletRepository, productRepo repositories.ProductRepository, categoryRepo repositories.CategoryRepository) TaxService {
+	return &taxService{repo: repo, outletRepo: outletRepo, productRepo: productRepo, categoryRepo: categoryRepo}
 }
 
 func (s *taxService) ListTaxes(companyID string, params models.PaginationParams) ([]models.Tax, int, error) {
@@ -44,16 +53,19 @@ func (s *taxService) CreateTax(companyID string, in models.TaxInput) (models.Tax
 
 	t := models.Tax{
 		CompanyID: companyID,
-		Name:      strings.TrimSpace(in.Name),
-		Rate:      in.Rate,
+		IsActive:  true,
 		CreatedAt: time.Now().UTC(),
 		UpdatedAt: time.Now().UTC(),
 	}
+	applyTaxInput(&t, in)
+	if err := s.validateRelations(companyID, t); err != nil {
+		return models.Tax{}, err
+	}
 
 	return s.repo.Create(t)
 }
 
-func (s *taxService) UpdateTax(id string, in models.TaxInput) (models.Tax, error) {
+func (s *taxService) UpdateTax(id string, companyID string, in models.TaxInput) (models.Tax, error) {
 	if err := validateTaxInput(in); err != nil {
 		return models.Tax{}, err
 	}
@@ -62,9 +74,14 @@ func (s *taxService) UpdateTax(id string, in models.TaxInput) (models.Tax, error
 	if err != nil {
 		return models.Tax{}, err
 	}
+	if existing.CompanyID != companyID {
+		return models.Tax{}, sql.ErrNoRows
+	}
 
-	existing.Name = strings.TrimSpace(in.Name)
-	existing.Rate = in.Rate
+	applyTaxInput(&existing, in)
+	if err := s.validateRelations(companyID, existing); err != nil {
+		return models.Tax{}, err
+	}
 	existing.UpdatedAt = time.Now().UTC()
 
 	return s.repo.Update(existing)
@@ -81,5 +98,77 @@ func validateTaxInput(in models.TaxInput) error {
 	if in.Rate < 0 || in.Rate > 100 {
 		return ErrTaxRateInvalid
 	}
+	switch in.Type {
+	case "", models.TaxTypeVAT, models.TaxTypeServiceCharge:
+	default:
+		return ErrTaxTypeInvalid
+	}
+	switch in.TargetType {
+	case "", models.TaxTargetAll, models.TaxTargetProduct, models.TaxTargetCategory:
+	default:
+		return ErrTaxTargetInvalid
+	}
+	return nil
+}
+
+// validateRelations memastikan outlet, produk, dan kategori target pajak milik company
+func (s *taxService) validateRelations(companyID string, t models.Tax) error {
+	if len(t.OutletIDs) > 0 {
+		outlets, err := s.outletRepo.FindByIDs(companyID, t.OutletIDs)
+		if err != nil {
+			return err
+		}
+		if len(outlets) != len(t.OutletIDs) {
+			return ErrTaxOutletNotFound
+		}
+	}
+	if len(t.ProductIDs) > 0 {
+		products, err := s.productRepo.FindByIDs(companyID, t.ProductIDs)
+		if err != nil {
+			return err
+		}
+		if len(products) != len(t.ProductIDs) {
+			return ErrTaxProductNotFound
+		}
+	}
+	if len(t.CategoryIDs) > 0 {
+		categories, err := s.categoryRepo.FindByIDs(companyID, t.CategoryIDs)
+		if err != nil {
+			return err
+		}
+		if len(categories) != len(t.CategoryIDs) {
+			return ErrTaxCategoryNotFound
+		}
+	}
 	return nil
 }
+
+// applyTaxInput menyalin payload ke model pajak, termasuk default tipe dan target.
+func applyTaxInput(t *models.Tax, in models.TaxInput) {
+	t.Name = strings.TrimSpace(in.Name)
+	t.Rate = in.Rate
+	t.Type = in.Type
+	if t.Type == "" {
+		t.Type = models.TaxTypeVAT
+	}
+	t.TargetType = in.TargetType
+	if t.TargetType == "" {
+		t.TargetType = models.TaxTargetAll
+	}
+	t.IsInclusive = in.IsInclusive
+	t.Sequence = in.Sequence
+	t.IsCompound = in.IsCompound
+	if in.IsActive != nil {
+		t.IsActive = *in.IsActive
+	}
+
+	t.OutletIDs = deduplicateIDs(in.OutletIDs)
+	t.ProductIDs = []string{}
+	t.CategoryIDs = []string{}
+	switch t.TargetType {
+	case models.TaxTargetProduct:
+		t.ProductIDs = deduplicateIDs(in.ProductIDs)
+	case models.TaxTargetCategory:
+		t.CategoryIDs = deduplicateIDs(in.CategoryIDs)
+	}
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"gowes/models"
+	"gowes/repositories"
+	"math"
+	"strings"
+)
+
+var (
+	ErrUnitConversionUnitNotFound = errors.New("unit not found")
+	ErrUnitConversionIncompatible = errors.New("units have different types and cannot be converted")
+	ErrUnitQtyPrecision           = errors.New("qty has more decimal places than the unit allows")
+)
+
+// QtyDecimalPlaces adalah skala kolom qty di database (NUMERIC(18,4))
+const QtyDecimalPlaces = 4
+
+// UnitConverter menormalkan jumlah yang diinput dalam satuan apa pun ke satuan stok produk.
+type UnitConverter interface {
+	Convert(companyID string, fromUnitID string, toUnitID string, qty float64) (float64, error)
+	ToStockUnit(companyID string, product models.Product, unitID string, qty float64) (float64, error)
+	ToStockQty(companyID string, product models.Product, unitID string, qty float64) (float64, error)
+}
+
+type unitConverter struct {
+	unitRepo repositories.UnitRepository
+}
+
+func NewUnitConverter(unitRepo repositories.UnitRepository) UnitConverter {
+	return &unitConverter{unitRepo: unitRepo}
+}
+
+// ConvertUnitQty mengonversi qty dari satuan from ke satuan to melalui satuan dasar tipenya.
+func ConvertUnitQty(qty float64, from models.Unit, to models.Unit) (float64, error) {
+	if from.ID == to.ID {
+		return qty, nil
+	}
+	if !strings.EqualFold(from.Type, to.Type) {
+		return 0, ErrUnitConversionIncompatible
+	}
+	fromFactor := from.ConversionFactor
+	if fromFactor <= 0 {
+		fromFactor = 1
+	}
+	toFactor := to.ConversionFactor
+	if toFactor <= 0 {
+		toFactor = 1
+	}
+	return qty * fromFactor / toFactor, nil
+}
+
+func (c *unitConverter) Convert(companyID string, fromUnitID string, toUnitID string, qty float64) (float64, error) {
+	fromUnitID = strings.TrimSpace(fromUnitID)
+	toUnitID = strings.TrimSpace(toUnitID)
+	if fromUnitID == "" || toUnitID == "" {
+		return 0, ErrUnitConversionUnitNotFound
+	}
+	if fromUnitID == toUnitID {
+		return qty, nil
+	}
+
+	units, err := c.unitRepo.FindByIDs(companyID, []string{fromUnitID, toUnitID})
+	if err != nil {
+		return 0, err
+	}
+	byID := make(map[string]models.Unit, len(units))
+	for _, u := range units {
+		byID[u.ID] = u
+	}
+	from, ok := byID[fromUnitID]
+	if !ok {
+		return 0, ErrUnitConversionUnitNotFound
+	}
+	to, ok := byID[toUnitID]
+	if !ok {
+		return 0, ErrUnitConversionUnitNotFound
+	}
+	return ConvertUnitQty(qty, from, to)
+}
+
+// ToStockUnit mengonversi qty ke satuan stok produk. Tanpa unitID (atau produk tanpa satuan)
+// qty dianggap sudah dalam satuan stok.
+func (c *unitConverter) ToStockUnit(companyID string, product models.Product, unitID string, qty float64) (float64, error) {
+	unitID = strings.TrimSpace(unitID)
+	if unitID == "" || unitID == product.UnitID {
+		return qty, nil
+	}
+	if product.UnitID == "" {
+		return 0, ErrUnitConversionIncompatible
+	}
+	return c.Convert(companyID, unitID, product.UnitID, qty)
+}
+
+// ToStockQty dipakai untuk qty yang diinput user: jumlah digit desimal dicek terhadap presisi
+// satuan input (atau satuan stok jika unitID kosong), lalu dikonversi ke satuan stok produk.
+func (c *unitConverter) ToStockQty(companyID string, product models.Product, unitID string, qty float64) (float64, error) {
+	unitID = strings.TrimSpace(unitID)
+	inputUnitID := unitID
+	if inputUnitID == "" {
+		inputUnitID = product.UnitID
+	}
+
+	// Produk tanpa satuan hanya dibatasi skala kolom database
+	places := QtyDecimalPlaces
+	if inputUnitID != "" {
+		unit, err := c.unitRepo.FindByID(inputUnitID, companyID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return 0, ErrUnitConversionUnitNotFound
+			}
+			return 0, err
+		}
+		places = unit.DecimalPlaces
+	}
+	if math.Abs(roundQtyTo(qty, places)-qty) > 1e-9 {
+		return 0, ErrUnitQtyPrecision
+	}
+
+	converted, err := c.ToStockUnit(companyID, product, unitID, qty)
+	if err != nil {
+		return 0, err
+	}
+	return roundQty(converted), nil
+}
+
+// roundQty membulatkan qty ke skala kolom database
+func roundQty(v float64) float64 {
+	return roundQtyTo(v, QtyDecimalPlaces)
+}
+
+func roundQtyTo(v float64, places int) float64 {
+	factor := math.Pow(10, float64(places))
+	return math.Round(v*factor) / factor
+}
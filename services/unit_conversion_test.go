@@ -0,0 +1,113 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"gowes/models"
+	"gowes/repositories"
+	"math"
+	"testing"
+)
+
+// fakeUnitRepository hanya mengimplementasikan pencarian satuan yang dipakai UnitConverter
+type fakeUnitRepository struct {
+	repositories.UnitRepository
+	units map[string]models.Unit
+}
+
+func (r fakeUnitRepository) FindByID(id string, companyID string) (models.Unit, error) {
+	u, ok := r.units[id]
+	if !ok {
+		return models.Unit{}, sql.ErrNoRows
+	}
+	return u, nil
+}
+
+func (r fakeUnitRepository) FindByIDs(companyID string, ids []string) ([]models.Unit, error) {
+	units := []models.Unit{}
+	for _, id := range ids {
+		if u, ok := r.units[id]; ok {
+			units = append(units, u)
+		}
+	}
+	return units, nil
+}
+
+var (
+	unitGram = models.Unit{ID: "g", Type: "weight", ConversionFactor: 1, DecimalPlaces: 0}
+	unitKg   = models.Unit{ID: "kg", Type: "weight", ConversionFactor: 1000, DecimalPlaces: 3}
+	unitMl   = models.Unit{ID: "ml", Type: "volume", ConversionFactor: 1, DecimalPlaces: 0}
+	unitPcs  = models.Unit{ID: "pcs", Type: "count", ConversionFactor: 1, DecimalPlaces: 0}
+)
+
+func TestConvertUnitQty(t *testing.T) {
+	tests := []struct {
+		name    string
+		qty     float64
+		from    models.Unit
+		to      models.Unit
+		want    float64
+		wantErr error
+	}{
+		{"same unit", 2.5, unitKg, unitKg, 2.5, nil},
+		{"kg to gram", 1.25, unitKg, unitGram, 1250, nil},
+		{"gram to kg", 250, unitGram, unitKg, 0.25, nil},
+		{"type is case insensitive", 1, unitKg, models.Unit{ID: "g2", Type: "WEIGHT", ConversionFactor: 1}, 1000, nil},
+		{"zero factor counts as base unit", 3, models.Unit{ID: "x", Type: "weight"}, unitGram, 3, nil},
+		{"different type", 1, unitKg, unitMl, 0, ErrUnitConversionIncompatible},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ConvertUnitQty(tt.qty, tt.from, tt.to)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("qty = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToStockQty(t *testing.T) {
+	converter := NewUnitConverter(fakeUnitRepository{units: map[string]models.Unit{
+		unitGram.ID: unitGram,
+		unitKg.ID:   unitKg,
+		unitMl.ID:   unitMl,
+		unitPcs.ID:  unitPcs,
+	}})
+	flour := models.Product{UnitID: unitGram.ID}
+
+	tests := []struct {
+		name    string
+		product models.Product
+		unitID  string
+		qty     float64
+		want    float64
+		wantErr error
+	}{
+		{"stock unit without decimals", flour, "", 500, 500, nil},
+		{"stock unit rejects decimals", flour, "", 0.5, 0, ErrUnitQtyPrecision},
+		{"kg within precision", flour, unitKg.ID, 1.255, 1255, nil},
+		{"kg beyond precision", flour, unitKg.ID, 1.2555, 0, ErrUnitQtyPrecision},
+		{"gram into kg stock is rounded to column scale", models.Product{UnitID: unitKg.ID}, unitGram.ID, 1, 0.001, nil},
+		{"incompatible unit", flour, unitMl.ID, 1, 0, ErrUnitConversionIncompatible},
+		{"unknown unit", flour, "liter", 1, 0, ErrUnitConversionUnitNotFound},
+		{"product without unit uses column scale", models.Product{}, "", 1.2345, 1.2345, nil},
+		{"product without unit beyond column scale", models.Product{}, "", 1.23456, 0, ErrUnitQtyPrecision},
+		{"product without unit cannot convert", models.Product{}, unitPcs.ID, 1, 0, ErrUnitConversionIncompatible},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := converter.ToStockQty("company-1", tt.product, tt.unitID, tt.qty)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("qty = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -12,6 +12,11 @@ var (
 	ErrUnitNameRequired   = errors.New("unit name is required")
 	ErrUnitSymbolRequired = errors.New("unit symbol is required")
 	ErrUnitTypeRequired   = errors.New("unit type is required")
+	ErrUnitTypeInvalid    = errors.New("unit type must be weight, volume or qty")
+	ErrUnitFactorInvalid  = errors.New("unit conversion_factor must be greater than zero")
+	ErrUnitDecimalInvalid = errors.New("unit decimal_places must be between 0 and 4")
+	ErrUnitConvertQty     = errors.New("qty is required")
+	ErrUnitInUse          = errors.New("type and conversion_factor cannot be changed while the unit is used by products or recipes")
 )
 
 type UnitService interface {
@@ -20,14 +25,16 @@ type UnitService interface {
 	CreateUnit(companyID string, userID string, input models.UnitInput) (models.Unit, error)
 	UpdateUnit(id string, companyID string, userID string, input models.UnitInput) (models.Unit, error)
 	DeleteUnit(id string, companyID string) error
+	ConvertUnit(companyID string, fromUnitID string, toUnitID string, qty float64) (models.UnitConversion, error)
 }
 
 type unitService struct {
-	repo repositories.UnitRepository
+	repo      repositories.UnitRepository
+	converter UnitConverter
 }
 
-func NewUnitService(repo repositories.UnitRepository) UnitService {
-	return &unitService{repo: repo}
+func NewUnitService(repo repositories.UnitRepository, converter UnitConverter) UnitService {
+	return &unitService{repo: repo, converter: converter}
 }
 
 func (s *unitService) ListUnits(companyID string, params models.PaginationParams) ([]models.Unit, int, error) {
@@ -47,12 +54,17 @@ func (s *unitService) CreateUnit(companyID string, userID string, input models.U
 	unit := models.Unit{
 		Name:      strings.TrimSpace(input.Name),
 		Symbol:    strings.TrimSpace(input.Symbol),
-		Type:      strings.TrimSpace(input.Type),
+		Type:      normalizeUnitType(input.Type),
 		CompanyID: companyID,
 		CreatedBy: userID,
 		UpdatedBy: userID,
 		CreatedAt: now,
 		UpdatedAt: now,
+
+		ConversionFactor: unitConversionFactor(input.ConversionFactor),
+	}
+	if input.DecimalPlaces != nil {
+		unit.DecimalPlaces = *input.DecimalPlaces
 	}
 
 	return s.repo.Create(unit)
@@ -68,9 +80,26 @@ func (s *unitService) UpdateUnit(id string, companyID string, userID string, inp
 		return models.Unit{}, err
 	}
 
+	// Mengubah tipe atau faktor konversi akan mengubah arti qty yang sudah tersimpan
+	unitType := normalizeUnitType(input.Type)
+	factor := unitConversionFactor(input.ConversionFactor)
+	if unitType != unit.Type || factor != unit.ConversionFactor {
+		inUse, err := s.repo.IsInUse(unit.ID)
+		if err != nil {
+			return models.Unit{}, err
+		}
+		if inUse {
+			return models.Unit{}, ErrUnitInUse
+		}
+	}
+
 	unit.Name = strings.TrimSpace(input.Name)
 	unit.Symbol = strings.TrimSpace(input.Symbol)
-	unit.Type = strings.TrimSpace(input.Type)
+	unit.Type = unitType
+	unit.ConversionFactor = factor
+	if input.DecimalPlaces != nil {
+		unit.DecimalPlaces = *input.DecimalPlaces
+	}
 	unit.CompanyID = companyID
 	unit.UpdatedBy = userID
 	unit.UpdatedAt = time.Now().UTC()
@@ -82,6 +111,34 @@ func (s *unitService) DeleteUnit(id string, companyID string) error {
 	return s.repo.Delete(id, companyID)
 }
 
+// ConvertUnit mengonversi qty antar dua satuan bertipe sama
+func (s *unitService) ConvertUnit(companyID string, fromUnitID string, toUnitID string, qty float64) (models.UnitConversion, error) {
+	if qty == 0 {
+		return models.UnitConversion{}, ErrUnitConvertQty
+	}
+	result, err := s.converter.Convert(companyID, fromUnitID, toUnitID, qty)
+	if err != nil {
+		return models.UnitConversion{}, err
+	}
+	return models.UnitConversion{
+		FromUnitID: strings.TrimSpace(fromUnitID),
+		ToUnitID:   strings.TrimSpace(toUnitID),
+		Qty:        qty,
+		Result:     result,
+	}, nil
+}
+
+func normalizeUnitType(unitType string) string {
+	return strings.ToLower(strings.TrimSpace(unitType))
+}
+
+func unitConversionFactor(factor float64) float64 {
+	if factor == 0 {
+		return 1
+	}
+	return factor
+}
+
 func validateUnitInput(input models.UnitInput) error {
 	if strings.TrimSpace(input.Name) == "" {
 		return ErrUnitNameRequired
@@ -92,6 +149,17 @@ func validateUnitInput(input models.UnitInput) error {
 	if strings.TrimSpace(input.Type) == "" {
 		return ErrUnitTypeRequired
 	}
+	switch normalizeUnitType(input.Type) {
+	case models.UnitTypeWeight, models.UnitTypeVolume, models.UnitTypeQty:
+	default:
+		return ErrUnitTypeInvalid
+	}
+	if input.ConversionFactor < 0 {
+		return ErrUnitFactorInvalid
+	}
+	if input.DecimalPlaces != nil && (*input.DecimalPlaces < 0 || *input.DecimalPlaces > QtyDecimalPlaces) {
+		return ErrUnitDecimalInvalid
+	}
 
 	return nil
 }
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"gowes/models"
+	"gowes/repositories"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrUserUsernameRequired = errors.New("username cannot be empty")
+	ErrUserEmailRequired    = errors.New("email cannot be empty")
+	ErrUserRoleInvalid      = errors.New("role must be cashier or waiter")
+	ErrUserPINInvalid       = errors.New("pos_pin must be 4 to 6 digits")
+	ErrUserEmailTaken       = errors.New("email already registered")
+	ErrUserUsernameTaken    = errors.New("username already taken")
+	ErrUserOwnerLocked      = errors.New("owner account cannot be changed from staff management")
+	ErrUserSelfDeactivate   = errors.New("you cannot deactivate your own account")
+	ErrUserRoleNotFound     = errors.New("role_id not found")
+	ErrUserRoleAdminOnly    = errors.New("only admin can assign a custom role")
+	ErrUserSelfRoleChange   = errors.New("you cannot change your own role")
+)
+
+var posPINPattern = regexp.MustCompile(`^[0-9]{4,6}$`)
+
+type UserService interface {
+	ListUsers(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.User, int, error)
+	GetUser(id string, companyID string) (models.User, error)
+	CreateUser(companyID string, actor models.User, input models.UserInput) (models.User, error)
+	UpdateUser(id string, companyID string, actor models.User, input models.UserUpdateInput) (models.User, error)
+	SetUserActive(id string, companyID string, actorID string, active bool) (models.User, error)
+	ResetUserPassword(id string, companyID string, input models.UserPasswordInput) error
+	ResetUserPIN(id string, companyID string, input models.UserPINInput) error
+}
+
+type userService struct {
+	repo        repositories.UserRepository
+	sessionRepo repositories.SessionRepository
+	roleRepo    repositories.RoleRepository
+	companyRepo repositories.CompanyRepository
+}
+
+func NewUserService(repo repositories.UserRepository, sessionRepo repositories.SessionRepository, roleRepo repositories.RoleRepository, companyRepo repositories.CompanyRepository) UserService {
+	return &userService{repo: repo, sessionRepo: sessionRepo, roleRepo: roleRepo, companyRepo: companyRepo}
+}
+
+func (s *userService) ListUsers(companyID string, params models.PaginationParams, filter models.FilterParams) ([]models.User, int, error) {
+	for _, role := range filter.Values["role"] {
+		switch models.UserRole(role) {
+		case models.RoleAdmin, models.RoleCashier, models.RoleWaiter:
+		default:
+			return nil, 0, ErrUserRoleInvalid
+		}
+	}
+	filter, err := companyDateFilter(s.companyRepo, companyID, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.repo.FindAllByCompany(companyID, params, filter)
+}
+
+func (s *userService) GetUser(id string, companyID string) (models.User, error) {
+	return s.repo.FindByIDAndCompany(id, companyID)
+}
+
+// CreateUser menambah staff ke company admin. Staff langsung aktif tanpa verifikasi email.
+// Hanya admin yang boleh memasang role kustom (role_id).
+func (s *userService) CreateUser(companyID string, actor models.User, input models.UserInput) (models.User, error) {
+	username := strings.TrimSpace(input.Username)
+	email := strings.TrimSpace(input.Email)
+	if username == "" {
+		return models.User{}, ErrUserUsernameRequired
+	}
+	if email == "" {
+		return models.User{}, ErrUserEmailRequired
+	}
+	if !isStaffRole(input.Role) {
+		return models.User{}, ErrUserRoleInvalid
+	}
+	if len(input.Password) < 6 {
+		return models.User{}, ErrPasswordTooShort
+	}
+	if err := s.ensureUnique("", username, email); err != nil {
+		return models.User{}, err
+	}
+	roleID, err := s.customRoleID(input.RoleID, companyID)
+	if err != nil {
+		return models.User{}, err
+	}
+	if roleID != nil && actor.Role != models.RoleAdmin {
+		return models.User{}, ErrUserRoleAdminOnly
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	now := time.Now().UTC()
+	user := models.User{
+		Username:     username,
+		Email:        email,
+		PasswordHash: string(hashedPassword),
+		Role:         input.Role,
+		RoleID:       roleID,
+		CompanyID:    &companyID,
+		Active:       true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if input.PosPIN != "" {
+		pinHash, err := hashPosPIN(input.PosPIN)
+		if err != nil {
+			return models.User{}, err
+		}
+		user.PosPIN = &pinHash
+	}
+
+	created, err := s.repo.Create(context.Background(), nil, user)
+	if err != nil {
+		return models.User{}, err
+	}
+	return s.repo.FindByIDAndCompany(created.ID, companyID)
+}
+
+// UpdateUser mengubah data staff. role_id hanya boleh diubah admin, dan tidak ada yang boleh
+// mengubah role / role_id miliknya sendiri.
+func (s *userService) UpdateUser(id string, companyID string, actor models.User, input models.UserUpdateInput) (models.User, error) {
+	user, err := s.staffUser(id, companyID)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	username := strings.TrimSpace(input.Username)
+	email := strings.TrimSpace(input.Email)
+	if username == "" {
+		return models.User{}, ErrUserUsernameRequired
+	}
+	if email == "" {
+		return models.User{}, ErrUserEmailRequired
+	}
+	if !isStaffRole(input.Role) {
+		return models.User{}, ErrUserRoleInvalid
+	}
+	if err := s.ensureUnique(id, username, email); err != nil {
+		return models.User{}, err
+	}
+	roleID, err := s.customRoleID(input.RoleID, companyID)
+	if err != nil {
+		return models.User{}, err
+	}
+	roleIDChanged := roleIDValue(roleID) != roleIDValue(user.RoleID)
+	if id == actor.ID && (roleIDChanged || input.Role != user.Role) {
+		return models.User{}, ErrUserSelfRoleChange
+	}
+	if roleIDChanged && actor.Role != models.RoleAdmin {
+		return models.User{}, ErrUserRoleAdminOnly
+	}
+
+	user.Username = username
+	user.Email = email
+	user.Role = input.Role
+	user.RoleID = roleID
+	user.UpdatedAt = time.Now().UTC()
+	return s.repo.Update(user)
+}
+
+// SetUserActive mengaktifkan / menonaktifkan staff. Staff yang dinonaktifkan langsung dikeluarkan
+// dari semua sesinya.
+func (s *userService) SetUserActive(id string, companyID string, actorID string, active bool) (models.User, error) {
+	if !active && id == actorID {
+		return models.User{}, ErrUserSelfDeactivate
+	}
+	if _, err := s.staffUser(id, companyID); err != nil {
+		return models.User{}, err
+	}
+
+	if err := s.repo.SetActive(id, companyID, active); err != nil {
+		return models.User{}, err
+	}
+	if !active {
+		if err := s.sessionRepo.RevokeAllByUser(id); err != nil {
+			return models.User{}, err
+		}
+	}
+	return s.repo.FindByIDAndCompany(id, companyID)
+}
+
+// ResetUserPassword mengganti password staff oleh admin; sesi staff yang sedang login ikut dicabut.
+func (s *userService) ResetUserPassword(id string, companyID string, input models.UserPasswordInput) error {
+	if len(input.Password) < 6 {
+		return ErrPasswordTooShort
+	}
+	if _, err := s.staffUser(id, companyID); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdatePassword(id, companyID, string(hashedPassword)); err != nil {
+		return err
+	}
+	return s.sessionRepo.RevokeAllByUser(id)
+}
+
+func (s *userService) ResetUserPIN(id string, companyID string, input models.UserPINInput) error {
+	if _, err := s.staffUser(id, companyID); err != nil {
+		return err
+	}
+	pinHash, err := hashPosPIN(input.PosPIN)
+	if err != nil {
+		return err
+	}
+	return s.repo.UpdatePosPIN(id, companyID, pinHash)
+}
+
+// staffUser mengambil user di company; akun owner tidak boleh diubah lewat manajemen staff.
+func (s *userService) staffUser(id string, companyID string) (models.User, error) {
+	user, err := s.repo.FindByIDAndCompany(id, companyID)
+	if err != nil {
+		return models.User{}, err
+	}
+	if user.IsOwner {
+		return models.User{}, ErrUserOwnerLocked
+	}
+	return user, nil
+}
+
+func roleIDValue(roleID *string) string {
+	if roleID == nil {
+		return ""
+	}
+	return *roleID
+}
+
+// customRoleID memastikan role kustom milik company; role_id kosong berarti memakai permission default role
+func (s *userService) customRoleID(roleID string, companyID string) (*string, error) {
+	roleID = strings.TrimSpace(roleID)
+	if roleID == "" {
+		return nil, nil
+	}
+	role, err := s.roleRepo.FindByID(roleID, companyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserRoleNotFound
+		}
+		return nil, err
+	}
+	return &role.ID, nil
+}
+
+// ensureUnique: username dan email unik untuk semua company; excludeID diisi saat update
+func (s *userService) ensureUnique(excludeID string, username string, email string) error {
+	existing, err := s.repo.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+	if existing.ID != "" && existing.ID != excludeID {
+		return ErrUserEmailTaken
+	}
+	existing, err = s.repo.FindByUsername(username)
+	if err != nil {
+		return err
+	}
+	if existing.ID != "" && existing.ID != excludeID {
+		return ErrUserUsernameTaken
+	}
+	return nil
+}
+
+func isStaffRole(role models.UserRole) bool {
+	return role == models.RoleCashier || role == models.RoleWaiter
+}
+
+// hashPosPIN: PIN POS disimpan sebagai hash bcrypt, sama seperti password
+func hashPosPIN(pin string) (string, error) {
+	if !posPINPattern.MatchString(pin) {
+		return "", ErrUserPINInvalid
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
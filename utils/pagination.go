@@ -1,12 +1,16 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
 	"gowes/models"
 	"math"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 func ParsePaginationParams(r *http.Request) models.PaginationParams {
@@ -38,13 +42,37 @@ func ParsePaginationParams(r *http.Request) models.PaginationParams {
 
 	search := q.Get("search")
 
+	withTotal, _ := strconv.ParseBool(q.Get("with_total"))
+
 	return models.PaginationParams{
-		Page:      page,
-		Limit:     limit,
-		SortBy:    sortBy,
-		SortOrder: sortOrder,
-		Search:    search,
+		Page:       page,
+		Limit:      limit,
+		SortBy:     sortBy,
+		SortOrder:  sortOrder,
+		Search:     search,
+		CursorMode: q.Has("cursor"),
+		WithTotal:  withTotal,
+	}
+}
+
+// ParseCursorPaginationParams dipakai endpoint yang mendukung pagination cursor.
+// Cursor hanya bisa dipakai dengan sort_by=created_at karena keyset-nya (created_at, id).
+func ParseCursorPaginationParams(r *http.Request) (models.PaginationParams, error) {
+	params := ParsePaginationParams(r)
+	if !params.CursorMode {
+		return params, nil
 	}
+	if params.SortBy != "created_at" {
+		return models.PaginationParams{}, errors.New("cursor pagination only supports sort_by=created_at")
+	}
+	if raw := strings.TrimSpace(r.URL.Query().Get("cursor")); raw != "" {
+		cursor, err := models.DecodePageCursor(raw)
+		if err != nil {
+			return models.PaginationParams{}, err
+		}
+		params.Cursor = &cursor
+	}
+	return params, nil
 }
 
 func CalculateMeta(totalData int, params models.PaginationParams) models.PaginationMeta {
@@ -60,3 +88,97 @@ func CalculateMeta(totalData int, params models.PaginationParams) models.Paginat
 		Limit:       params.Limit,
 	}
 }
+
+func CalculateCursorMeta(page models.CursorPage, params models.PaginationParams) models.CursorPaginationMeta {
+	return models.CursorPaginationMeta{
+		TotalData:  page.Total,
+		Limit:      params.Limit,
+		NextCursor: page.NextCursor,
+	}
+}
+
+// filterReservedKeys adalah query param milik pagination, bukan filter
+var filterReservedKeys = map[string]bool{
+	"page":       true,
+	"limit":      true,
+	"sort_by":    true,
+	"sort_order": true,
+	"search":     true,
+	"cursor":     true,
+	"with_total": true,
+}
+
+// ParseFilterParams membaca filter dari query string (lihat models.FilterParams).
+// Error dikembalikan jika rentang tanggal atau angka tidak bisa dibaca.
+func ParseFilterParams(r *http.Request) (models.FilterParams, error) {
+	filter := models.FilterParams{
+		Values:  map[string][]string{},
+		Dates:   map[string]models.DateRange{},
+		Numbers: map[string]models.NumberRange{},
+	}
+
+	for key, rawValues := range r.URL.Query() {
+		if filterReservedKeys[key] || len(rawValues) == 0 {
+			continue
+		}
+		raw := strings.TrimSpace(rawValues[0])
+		if raw == "" {
+			continue
+		}
+
+		switch {
+		case strings.HasSuffix(key, "_from"), strings.HasSuffix(key, "_to"):
+			t, err := time.Parse("2006-01-02", raw)
+			if err != nil {
+				return models.FilterParams{}, fmt.Errorf("%s must use the YYYY-MM-DD format", key)
+			}
+			if field, ok := strings.CutSuffix(key, "_from"); ok {
+				dr := filter.Dates[field]
+				dr.From = &t
+				filter.Dates[field] = dr
+			} else {
+				field = strings.TrimSuffix(key, "_to")
+				dr := filter.Dates[field]
+				dr.To = &t
+				filter.Dates[field] = dr
+			}
+		case strings.HasSuffix(key, "_min"), strings.HasSuffix(key, "_max"):
+			n, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return models.FilterParams{}, fmt.Errorf("%s must be a number", key)
+			}
+			if field, ok := strings.CutSuffix(key, "_min"); ok {
+				nr := filter.Numbers[field]
+				nr.Min = &n
+				filter.Numbers[field] = nr
+			} else {
+				field = strings.TrimSuffix(key, "_max")
+				nr := filter.Numbers[field]
+				nr.Max = &n
+				filter.Numbers[field] = nr
+			}
+		default:
+			values := []string{}
+			for _, rawValue := range rawValues {
+				for _, v := range strings.Split(rawValue, ",") {
+					if v = strings.TrimSpace(v); v != "" {
+						values = append(values, v)
+					}
+				}
+			}
+			// kolom *_id bertipe UUID; nilai rusak ditolak di sini agar tidak menjadi error database
+			if strings.HasSuffix(key, "_id") {
+				for _, v := range values {
+					if _, err := uuid.Parse(v); err != nil {
+						return models.FilterParams{}, fmt.Errorf("%s must be a valid UUID", key)
+					}
+				}
+			}
+			if len(values) > 0 {
+				filter.Values[key] = values
+			}
+		}
+	}
+
+	return filter, nil
+}